@@ -1967,6 +1967,7 @@ type ResponseCheckTx struct {
 	GasUsed   int64   `protobuf:"varint,6,opt,name=gas_used,proto3" json:"gas_used,omitempty"`
 	Events    []Event `protobuf:"bytes,7,rep,name=events,proto3" json:"events,omitempty"`
 	Codespace string  `protobuf:"bytes,8,opt,name=codespace,proto3" json:"codespace,omitempty"`
+	Priority  int64   `protobuf:"varint,10,opt,name=priority,proto3" json:"priority,omitempty"`
 }
 
 func (m *ResponseCheckTx) Reset()         { *m = ResponseCheckTx{} }
@@ -2058,6 +2059,13 @@ func (m *ResponseCheckTx) GetCodespace() string {
 	return ""
 }
 
+func (m *ResponseCheckTx) GetPriority() int64 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
 type ResponseDeliverTx struct {
 	Code      uint32  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
 	Data      []byte  `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
@@ -3199,179 +3207,180 @@ func init() {
 func init() { proto.RegisterFile("tendermint/abci/types.proto", fileDescriptor_252557cfdd89a31a) }
 
 var fileDescriptor_252557cfdd89a31a = []byte{
-	// 2741 bytes of a gzipped FileDescriptorProto
+	// 2759 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe4, 0x5a, 0x4b, 0x73, 0x1b, 0xc7,
-	0x11, 0xc6, 0xfb, 0xd1, 0x24, 0x1e, 0x1c, 0xd1, 0x12, 0xb4, 0x92, 0x48, 0x79, 0x55, 0x72, 0x2c,
-	0xd9, 0x26, 0x63, 0xaa, 0xa4, 0x48, 0xb1, 0x13, 0x9b, 0x80, 0x20, 0x83, 0x26, 0x4d, 0x30, 0x4b,
-	0x48, 0xce, 0xcb, 0x5a, 0x2f, 0xb0, 0x43, 0x60, 0x2d, 0x60, 0x77, 0x8d, 0x1d, 0x50, 0xa4, 0x8f,
-	0x71, 0x72, 0x51, 0x2e, 0xce, 0x2d, 0x17, 0xff, 0x8f, 0x9c, 0x72, 0xc9, 0xc5, 0x55, 0xb9, 0xf8,
-	0x98, 0x93, 0x93, 0x92, 0x2a, 0x97, 0xfc, 0x81, 0x9c, 0x52, 0x49, 0xcd, 0x63, 0x5f, 0x00, 0x16,
-	0x00, 0xed, 0xdc, 0x7c, 0x9b, 0x99, 0xed, 0xee, 0xc5, 0xf4, 0x4e, 0x7f, 0xfd, 0x75, 0x0f, 0xe0,
-	0x12, 0xc1, 0xa6, 0x8e, 0x87, 0x03, 0xc3, 0x24, 0x9b, 0x5a, 0xbb, 0x63, 0x6c, 0x92, 0x53, 0x1b,
-	0x3b, 0x1b, 0xf6, 0xd0, 0x22, 0x16, 0x2a, 0xf9, 0x0f, 0x37, 0xe8, 0x43, 0xe9, 0x4a, 0x40, 0xba,
-	0x33, 0x3c, 0xb5, 0x89, 0xb5, 0x69, 0x0f, 0x2d, 0xeb, 0x88, 0xcb, 0x4b, 0x97, 0x03, 0x8f, 0x99,
-	0x9d, 0xa0, 0xb5, 0xd0, 0x53, 0xa1, 0xfc, 0x04, 0x9f, 0xba, 0x4f, 0xaf, 0x4c, 0xe8, 0xda, 0xda,
-	0x50, 0x1b, 0xb8, 0x8f, 0xd7, 0xbb, 0x96, 0xd5, 0xed, 0xe3, 0x4d, 0x36, 0x6b, 0x8f, 0x8e, 0x36,
-	0x89, 0x31, 0xc0, 0x0e, 0xd1, 0x06, 0xb6, 0x10, 0x58, 0xed, 0x5a, 0x5d, 0x8b, 0x0d, 0x37, 0xe9,
-	0x88, 0xaf, 0xca, 0x7f, 0xc8, 0x41, 0x56, 0xc1, 0x9f, 0x8e, 0xb0, 0x43, 0xd0, 0x16, 0xa4, 0x70,
-	0xa7, 0x67, 0x55, 0xe2, 0x57, 0xe3, 0xaf, 0x2e, 0x6d, 0x5d, 0xde, 0x18, 0xdb, 0xdc, 0x86, 0x90,
-	0xab, 0x77, 0x7a, 0x56, 0x23, 0xa6, 0x30, 0x59, 0x74, 0x1b, 0xd2, 0x47, 0xfd, 0x91, 0xd3, 0xab,
-	0x24, 0x98, 0xd2, 0x95, 0x28, 0xa5, 0x07, 0x54, 0xa8, 0x11, 0x53, 0xb8, 0x34, 0x7d, 0x95, 0x61,
-	0x1e, 0x59, 0x95, 0xe4, 0xec, 0x57, 0xed, 0x98, 0x47, 0xec, 0x55, 0x54, 0x16, 0x55, 0x01, 0x1c,
-	0x4c, 0x54, 0xcb, 0x26, 0x86, 0x65, 0x56, 0x52, 0x4c, 0xf3, 0xe5, 0x28, 0xcd, 0x43, 0x4c, 0x9a,
-	0x4c, 0xb0, 0x11, 0x53, 0xf2, 0x8e, 0x3b, 0xa1, 0x36, 0x0c, 0xd3, 0x20, 0x6a, 0xa7, 0xa7, 0x19,
-	0x66, 0x25, 0x3d, 0xdb, 0xc6, 0x8e, 0x69, 0x90, 0x1a, 0x15, 0xa4, 0x36, 0x0c, 0x77, 0x42, 0xb7,
-	0xfc, 0xe9, 0x08, 0x0f, 0x4f, 0x2b, 0x99, 0xd9, 0x5b, 0xfe, 0x19, 0x15, 0xa2, 0x5b, 0x66, 0xd2,
-	0xa8, 0x0e, 0x4b, 0x6d, 0xdc, 0x35, 0x4c, 0xb5, 0xdd, 0xb7, 0x3a, 0x4f, 0x2a, 0x59, 0xa6, 0x2c,
-	0x47, 0x29, 0x57, 0xa9, 0x68, 0x95, 0x4a, 0x36, 0x62, 0x0a, 0xb4, 0xbd, 0x19, 0x7a, 0x1b, 0x72,
-	0x9d, 0x1e, 0xee, 0x3c, 0x51, 0xc9, 0x49, 0x25, 0xc7, 0x6c, 0xac, 0x47, 0xd9, 0xa8, 0x51, 0xb9,
-	0xd6, 0x49, 0x23, 0xa6, 0x64, 0x3b, 0x7c, 0x48, 0xf7, 0xaf, 0xe3, 0xbe, 0x71, 0x8c, 0x87, 0x54,
-	0x3f, 0x3f, 0x7b, 0xff, 0xf7, 0xb9, 0x24, 0xb3, 0x90, 0xd7, 0xdd, 0x09, 0x7a, 0x07, 0xf2, 0xd8,
-	0xd4, 0xc5, 0x36, 0x80, 0x99, 0xb8, 0x1a, 0x79, 0x56, 0x4c, 0xdd, 0xdd, 0x44, 0x0e, 0x8b, 0x31,
-	0xba, 0x0b, 0x99, 0x8e, 0x35, 0x18, 0x18, 0xa4, 0xb2, 0xc4, 0xb4, 0xd7, 0x22, 0x37, 0xc0, 0xa4,
-	0x1a, 0x31, 0x45, 0xc8, 0xa3, 0x7d, 0x28, 0xf6, 0x0d, 0x87, 0xa8, 0x8e, 0xa9, 0xd9, 0x4e, 0xcf,
-	0x22, 0x4e, 0x65, 0x99, 0x59, 0xb8, 0x1e, 0x65, 0x61, 0xcf, 0x70, 0xc8, 0xa1, 0x2b, 0xdc, 0x88,
-	0x29, 0x85, 0x7e, 0x70, 0x81, 0xda, 0xb3, 0x8e, 0x8e, 0xf0, 0xd0, 0x33, 0x58, 0x29, 0xcc, 0xb6,
-	0xd7, 0xa4, 0xd2, 0xae, 0x3e, 0xb5, 0x67, 0x05, 0x17, 0xd0, 0xaf, 0xe0, 0x5c, 0xdf, 0xd2, 0x74,
-	0xcf, 0x9c, 0xda, 0xe9, 0x8d, 0xcc, 0x27, 0x95, 0x22, 0x33, 0x7a, 0x23, 0xf2, 0x47, 0x5a, 0x9a,
-	0xee, 0x9a, 0xa8, 0x51, 0x85, 0x46, 0x4c, 0x59, 0xe9, 0x8f, 0x2f, 0xa2, 0xc7, 0xb0, 0xaa, 0xd9,
-	0x76, 0xff, 0x74, 0xdc, 0x7a, 0x89, 0x59, 0xbf, 0x19, 0x65, 0x7d, 0x9b, 0xea, 0x8c, 0x9b, 0x47,
-	0xda, 0xc4, 0x6a, 0x35, 0x0b, 0xe9, 0x63, 0xad, 0x3f, 0xc2, 0xf2, 0x0f, 0x60, 0x29, 0x10, 0xea,
-	0xa8, 0x02, 0xd9, 0x01, 0x76, 0x1c, 0xad, 0x8b, 0x19, 0x32, 0xe4, 0x15, 0x77, 0x2a, 0x17, 0x61,
-	0x39, 0x18, 0xde, 0xf2, 0xc0, 0x53, 0xa4, 0x81, 0x4b, 0x15, 0x8f, 0xf1, 0xd0, 0xa1, 0xd1, 0x2a,
-	0x14, 0xc5, 0x14, 0x5d, 0x83, 0x02, 0x3b, 0x3e, 0xaa, 0xfb, 0x9c, 0xa2, 0x47, 0x4a, 0x59, 0x66,
-	0x8b, 0x8f, 0x84, 0xd0, 0x3a, 0x2c, 0xd9, 0x5b, 0xb6, 0x27, 0x92, 0x64, 0x22, 0x60, 0x6f, 0xd9,
-	0x42, 0x40, 0xfe, 0x31, 0x94, 0xc7, 0xa3, 0x1d, 0x95, 0x21, 0xf9, 0x04, 0x9f, 0x8a, 0xf7, 0xd1,
-	0x21, 0x5a, 0x15, 0xdb, 0x62, 0xef, 0xc8, 0x2b, 0x62, 0x8f, 0x7f, 0x4d, 0x78, 0xca, 0x5e, 0x98,
-	0xa3, 0xbb, 0x90, 0xa2, 0xa8, 0x29, 0x00, 0x50, 0xda, 0xe0, 0x90, 0xba, 0xe1, 0x42, 0xea, 0x46,
-	0xcb, 0x85, 0xd4, 0x6a, 0xee, 0xab, 0x6f, 0xd6, 0x63, 0x5f, 0xfc, 0x7d, 0x3d, 0xae, 0x30, 0x0d,
-	0x74, 0x91, 0x46, 0xa5, 0x66, 0x98, 0xaa, 0xa1, 0x8b, 0xf7, 0x64, 0xd9, 0x7c, 0x47, 0x47, 0xbb,
-	0x50, 0xee, 0x58, 0xa6, 0x83, 0x4d, 0x67, 0xe4, 0xa8, 0x1c, 0xb2, 0x05, 0xec, 0x4d, 0x46, 0x4d,
-	0xcd, 0x15, 0x3c, 0x60, 0x72, 0x4a, 0xa9, 0x13, 0x5e, 0x40, 0x0f, 0x00, 0x8e, 0xb5, 0xbe, 0xa1,
-	0x6b, 0xc4, 0x1a, 0x3a, 0x95, 0xd4, 0xd5, 0xe4, 0x54, 0x33, 0x8f, 0x5c, 0x91, 0x87, 0xb6, 0xae,
-	0x11, 0x5c, 0x4d, 0xd1, 0x5f, 0xab, 0x04, 0x34, 0xd1, 0x2b, 0x50, 0xd2, 0x6c, 0x5b, 0x75, 0x88,
-	0x46, 0xb0, 0xda, 0x3e, 0x25, 0xd8, 0x61, 0x60, 0xb8, 0xac, 0x14, 0x34, 0xdb, 0x3e, 0xa4, 0xab,
-	0x55, 0xba, 0x88, 0xae, 0x43, 0x91, 0x02, 0x9f, 0xa1, 0xf5, 0xd5, 0x1e, 0x36, 0xba, 0x3d, 0xc2,
-	0x40, 0x2f, 0xa9, 0x14, 0xc4, 0x6a, 0x83, 0x2d, 0xca, 0xba, 0x77, 0x10, 0x18, 0xe8, 0x21, 0x04,
-	0x29, 0x5d, 0x23, 0x1a, 0x73, 0xe4, 0xb2, 0xc2, 0xc6, 0x74, 0xcd, 0xd6, 0x48, 0x4f, 0xb8, 0x87,
-	0x8d, 0xd1, 0x79, 0xc8, 0x08, 0xb3, 0x49, 0x66, 0x56, 0xcc, 0xe8, 0x37, 0xb3, 0x87, 0xd6, 0x31,
-	0x66, 0x28, 0x9f, 0x53, 0xf8, 0x44, 0xfe, 0x6d, 0x02, 0x56, 0x26, 0xe0, 0x91, 0xda, 0xed, 0x69,
-	0x4e, 0xcf, 0x7d, 0x17, 0x1d, 0xa3, 0x3b, 0xd4, 0xae, 0xa6, 0xe3, 0xa1, 0x48, 0x4b, 0x95, 0xa0,
-	0x8b, 0x78, 0xca, 0x6d, 0xb0, 0xe7, 0xc2, 0x35, 0x42, 0x1a, 0x35, 0xa1, 0xdc, 0xd7, 0x1c, 0xa2,
-	0x72, 0xb8, 0x51, 0x03, 0x29, 0x6a, 0x12, 0x64, 0xf7, 0x34, 0x17, 0xa0, 0xe8, 0x61, 0x17, 0x86,
-	0x8a, 0xfd, 0xd0, 0x2a, 0x52, 0x60, 0xb5, 0x7d, 0xfa, 0x99, 0x66, 0x12, 0xc3, 0xc4, 0xea, 0xc4,
-	0x97, 0xbb, 0x38, 0x61, 0xb4, 0x7e, 0x6c, 0xe8, 0xd8, 0xec, 0xb8, 0x9f, 0xec, 0x9c, 0xa7, 0xec,
-	0x7d, 0x52, 0x47, 0x56, 0xa0, 0x18, 0x06, 0x78, 0x54, 0x84, 0x04, 0x39, 0x11, 0x0e, 0x48, 0x90,
-	0x13, 0xf4, 0x43, 0x48, 0xd1, 0x4d, 0xb2, 0xcd, 0x17, 0xa7, 0x64, 0x57, 0xa1, 0xd7, 0x3a, 0xb5,
-	0xb1, 0xc2, 0x24, 0x65, 0xd9, 0x8b, 0x06, 0x0f, 0xf4, 0xc7, 0xad, 0xca, 0x37, 0xa0, 0x34, 0x86,
-	0xea, 0x81, 0xef, 0x17, 0x0f, 0x7e, 0x3f, 0xb9, 0x04, 0x85, 0x10, 0x84, 0xcb, 0xe7, 0x61, 0x75,
-	0x1a, 0x22, 0xcb, 0x3d, 0x6f, 0x3d, 0x84, 0xac, 0xe8, 0x36, 0xe4, 0x3c, 0x48, 0xe6, 0xd1, 0x38,
-	0xe9, 0x2b, 0x57, 0x58, 0xf1, 0x44, 0x69, 0x18, 0xd2, 0x63, 0xcd, 0xce, 0x43, 0x82, 0xfd, 0xf0,
-	0xac, 0x66, 0xdb, 0x0d, 0xcd, 0xe9, 0xc9, 0x1f, 0x43, 0x25, 0x0a, 0x6e, 0xc7, 0xb6, 0x91, 0xf2,
-	0x8e, 0xe1, 0x79, 0xc8, 0x1c, 0x59, 0xc3, 0x81, 0x46, 0x98, 0xb1, 0x82, 0x22, 0x66, 0xf4, 0x78,
-	0x72, 0xe8, 0x4d, 0xb2, 0x65, 0x3e, 0x91, 0x55, 0xb8, 0x18, 0x09, 0xb9, 0x54, 0xc5, 0x30, 0x75,
-	0xcc, 0xfd, 0x59, 0x50, 0xf8, 0xc4, 0x37, 0xc4, 0x7f, 0x2c, 0x9f, 0xd0, 0xd7, 0x3a, 0x6c, 0xaf,
-	0xcc, 0x7e, 0x5e, 0x11, 0x33, 0xf9, 0x9f, 0x39, 0xc8, 0x29, 0xd8, 0xb1, 0x29, 0x26, 0xa0, 0x2a,
-	0xe4, 0xf1, 0x49, 0x07, 0x73, 0x32, 0x14, 0x8f, 0x24, 0x13, 0x5c, 0xba, 0xee, 0x4a, 0xd2, 0x4c,
-	0xee, 0xa9, 0xa1, 0x5b, 0x82, 0xf0, 0x45, 0x73, 0x37, 0xa1, 0x1e, 0x64, 0x7c, 0x77, 0x5c, 0xc6,
-	0x97, 0x8c, 0x4c, 0xde, 0x5c, 0x6b, 0x8c, 0xf2, 0xdd, 0x12, 0x94, 0x2f, 0x35, 0xe7, 0x65, 0x21,
-	0xce, 0x57, 0x0b, 0x71, 0xbe, 0xf4, 0x9c, 0x6d, 0x46, 0x90, 0xbe, 0x5a, 0x88, 0xf4, 0x65, 0xe6,
-	0x18, 0x89, 0x60, 0x7d, 0x77, 0x5c, 0xd6, 0x97, 0x9d, 0xb3, 0xed, 0x31, 0xda, 0xf7, 0x20, 0x4c,
-	0xfb, 0x38, 0x65, 0xbb, 0x16, 0xa9, 0x1d, 0xc9, 0xfb, 0x7e, 0x12, 0xe0, 0x7d, 0xf9, 0x48, 0xd2,
-	0xc5, 0x8d, 0x4c, 0x21, 0x7e, 0xb5, 0x10, 0xf1, 0x83, 0x39, 0x3e, 0x88, 0x60, 0x7e, 0xef, 0x06,
-	0x99, 0xdf, 0x52, 0x24, 0x79, 0x14, 0x87, 0x66, 0x1a, 0xf5, 0xbb, 0xe7, 0x51, 0xbf, 0xe5, 0x48,
-	0xee, 0x2a, 0xf6, 0x30, 0xce, 0xfd, 0x9a, 0x13, 0xdc, 0x8f, 0x73, 0xb5, 0x57, 0x22, 0x4d, 0xcc,
-	0x21, 0x7f, 0xcd, 0x09, 0xf2, 0x57, 0x9c, 0x63, 0x70, 0x0e, 0xfb, 0xfb, 0xf5, 0x74, 0xf6, 0x17,
-	0xcd, 0xcf, 0xc4, 0xcf, 0x5c, 0x8c, 0xfe, 0xa9, 0x11, 0xf4, 0xaf, 0xcc, 0xcc, 0xbf, 0x16, 0x69,
-	0xfe, 0xec, 0xfc, 0xef, 0x06, 0x4d, 0xb3, 0x63, 0xc0, 0x41, 0xa1, 0x0a, 0x0f, 0x87, 0xd6, 0x50,
-	0x50, 0x2b, 0x3e, 0x91, 0x5f, 0xa5, 0x89, 0xdf, 0x07, 0x89, 0x19, 0x5c, 0x91, 0xa5, 0x84, 0x00,
-	0x30, 0xc8, 0x7f, 0x8a, 0xfb, 0xba, 0x2c, 0x57, 0x06, 0x49, 0x43, 0x5e, 0x90, 0x86, 0x00, 0x85,
-	0x4c, 0x84, 0x29, 0xe4, 0x3a, 0x2c, 0x51, 0xa8, 0x1f, 0x63, 0x87, 0x9a, 0xed, 0xb2, 0x43, 0x74,
-	0x13, 0x56, 0x58, 0x2e, 0xe7, 0x44, 0x53, 0xe0, 0x7b, 0x8a, 0xa5, 0xa9, 0x12, 0x7d, 0xc0, 0x0f,
-	0x27, 0x07, 0xfa, 0x37, 0xe0, 0x5c, 0x40, 0xd6, 0x4b, 0x21, 0x9c, 0x12, 0x95, 0x3d, 0xe9, 0x6d,
-	0x91, 0x4b, 0x3e, 0xf0, 0x1d, 0xe4, 0x33, 0x4f, 0x04, 0xa9, 0x8e, 0xa5, 0x63, 0x01, 0xf0, 0x6c,
-	0x4c, 0xd9, 0x68, 0xdf, 0xea, 0x0a, 0x18, 0xa7, 0x43, 0x2a, 0xe5, 0xa1, 0x60, 0x9e, 0x83, 0x9c,
-	0xfc, 0x97, 0xb8, 0x6f, 0xcf, 0x27, 0xa3, 0xd3, 0x78, 0x63, 0xfc, 0xff, 0xc3, 0x1b, 0x13, 0xdf,
-	0x9a, 0x37, 0x06, 0x13, 0x6c, 0x32, 0x9c, 0x60, 0xff, 0x1d, 0xf7, 0xbf, 0xb0, 0xc7, 0x02, 0xbf,
-	0x9d, 0x47, 0xfc, 0x6c, 0x99, 0x66, 0xdf, 0x4b, 0x64, 0x4b, 0xc1, 0xed, 0x33, 0xec, 0xbd, 0x61,
-	0x6e, 0x9f, 0xe5, 0xf9, 0x93, 0x4d, 0xd0, 0x5d, 0xc8, 0xb3, 0xa6, 0x8b, 0x6a, 0xd9, 0x8e, 0x00,
-	0xdc, 0x4b, 0xc1, 0xbd, 0xf2, 0xde, 0xca, 0xc6, 0x01, 0x95, 0x69, 0xda, 0x8e, 0x92, 0xb3, 0xc5,
-	0x28, 0x40, 0x04, 0xf2, 0x21, 0x3e, 0x7a, 0x19, 0xf2, 0xf4, 0xd7, 0x3b, 0xb6, 0xd6, 0xc1, 0x0c,
-	0x3c, 0xf3, 0x8a, 0xbf, 0x20, 0x3f, 0x06, 0x34, 0x09, 0xdf, 0xa8, 0x01, 0x19, 0x7c, 0x8c, 0x4d,
-	0x42, 0xbf, 0x1a, 0x75, 0xf7, 0xf9, 0x29, 0x64, 0x0f, 0x9b, 0xa4, 0x5a, 0xa1, 0x4e, 0xfe, 0xd7,
-	0x37, 0xeb, 0x65, 0x2e, 0xfd, 0xba, 0x35, 0x30, 0x08, 0x1e, 0xd8, 0xe4, 0x54, 0x11, 0xfa, 0xf2,
-	0xe7, 0x09, 0xca, 0xbc, 0x42, 0xd0, 0x3e, 0xd5, 0xb7, 0x6e, 0x00, 0x25, 0x02, 0xac, 0x7b, 0x31,
-	0x7f, 0xaf, 0x01, 0x74, 0x35, 0x47, 0x7d, 0xaa, 0x99, 0x04, 0xeb, 0xc2, 0xe9, 0x81, 0x15, 0x24,
-	0x41, 0x8e, 0xce, 0x46, 0x0e, 0xd6, 0x45, 0x01, 0xe0, 0xcd, 0x03, 0xfb, 0xcc, 0x7e, 0xb7, 0x7d,
-	0x86, 0xbd, 0x9c, 0x1b, 0xf7, 0xf2, 0xef, 0x12, 0x7e, 0x94, 0xf8, 0x24, 0xf5, 0xfb, 0xe7, 0x87,
-	0xdf, 0xb3, 0xca, 0x35, 0x9c, 0x63, 0xd1, 0x21, 0xac, 0x78, 0x51, 0xaa, 0x8e, 0x58, 0xf4, 0xba,
-	0xe7, 0x6e, 0xd1, 0x30, 0x2f, 0x1f, 0x87, 0x97, 0x1d, 0xf4, 0x73, 0xb8, 0x30, 0x86, 0x40, 0x9e,
-	0xe9, 0xc4, 0x82, 0x40, 0xf4, 0x52, 0x18, 0x88, 0x5c, 0xcb, 0xbe, 0xaf, 0x92, 0xdf, 0x31, 0x36,
-	0x76, 0x68, 0x31, 0x14, 0x64, 0x0c, 0x53, 0xbf, 0xfe, 0x35, 0x28, 0x0c, 0x31, 0xa1, 0xf5, 0x79,
-	0xa8, 0xdc, 0x5c, 0xe6, 0x8b, 0xa2, 0x88, 0x3d, 0x80, 0x97, 0xa6, 0x32, 0x07, 0xf4, 0x23, 0xc8,
-	0xfb, 0xa4, 0x23, 0x1e, 0x51, 0xb9, 0x79, 0xd5, 0x88, 0x2f, 0x2b, 0xff, 0x39, 0xee, 0x9b, 0x0c,
-	0xd7, 0x37, 0x75, 0xc8, 0x0c, 0xb1, 0x33, 0xea, 0xf3, 0x8a, 0xa3, 0xb8, 0xf5, 0xc6, 0x62, 0x9c,
-	0x83, 0xae, 0x8e, 0xfa, 0x44, 0x11, 0xca, 0xf2, 0x63, 0xc8, 0xf0, 0x15, 0xb4, 0x04, 0xd9, 0x87,
-	0xfb, 0xbb, 0xfb, 0xcd, 0x0f, 0xf7, 0xcb, 0x31, 0x04, 0x90, 0xd9, 0xae, 0xd5, 0xea, 0x07, 0xad,
-	0x72, 0x1c, 0xe5, 0x21, 0xbd, 0x5d, 0x6d, 0x2a, 0xad, 0x72, 0x82, 0x2e, 0x2b, 0xf5, 0xf7, 0xeb,
-	0xb5, 0x56, 0x39, 0x89, 0x56, 0xa0, 0xc0, 0xc7, 0xea, 0x83, 0xa6, 0xf2, 0xc1, 0x76, 0xab, 0x9c,
-	0x0a, 0x2c, 0x1d, 0xd6, 0xf7, 0xef, 0xd7, 0x95, 0x72, 0x5a, 0x7e, 0x93, 0x96, 0x34, 0x11, 0x2c,
-	0xc5, 0x2f, 0x5e, 0xe2, 0x81, 0xe2, 0x45, 0xfe, 0x63, 0x02, 0xa4, 0x68, 0xea, 0x81, 0xde, 0x1f,
-	0xdb, 0xf8, 0xd6, 0x19, 0x78, 0xcb, 0xd8, 0xee, 0xd1, 0x75, 0x28, 0x0e, 0xf1, 0x11, 0x26, 0x9d,
-	0x1e, 0xa7, 0x42, 0x3c, 0xb1, 0x15, 0x94, 0x82, 0x58, 0x65, 0x4a, 0x0e, 0x17, 0xfb, 0x04, 0x77,
-	0x88, 0xca, 0xeb, 0x28, 0x7e, 0xe8, 0xf2, 0x54, 0x8c, 0xae, 0x1e, 0xf2, 0x45, 0xf9, 0xe3, 0x33,
-	0xf9, 0x32, 0x0f, 0x69, 0xa5, 0xde, 0x52, 0x7e, 0x51, 0x4e, 0x22, 0x04, 0x45, 0x36, 0x54, 0x0f,
-	0xf7, 0xb7, 0x0f, 0x0e, 0x1b, 0x4d, 0xea, 0xcb, 0x73, 0x50, 0x72, 0x7d, 0xe9, 0x2e, 0xa6, 0xe5,
-	0xff, 0xc6, 0xa1, 0x34, 0x16, 0x20, 0x68, 0x0b, 0xd2, 0x9c, 0x4e, 0x47, 0x35, 0xdd, 0x59, 0x7c,
-	0x8b, 0x68, 0xe2, 0xa2, 0xe8, 0x6d, 0xc8, 0x61, 0xd1, 0x27, 0x98, 0x16, 0x88, 0xbc, 0xbf, 0xe1,
-	0x76, 0x12, 0x84, 0xaa, 0xa7, 0x81, 0xde, 0x81, 0xbc, 0x17, 0xe9, 0xa2, 0x86, 0x7b, 0x79, 0x52,
-	0xdd, 0xc3, 0x08, 0xa1, 0xef, 0xeb, 0xa0, 0x7b, 0x3e, 0x27, 0x4b, 0x4d, 0x92, 0x78, 0xa1, 0xce,
-	0x05, 0x84, 0xb2, 0x2b, 0x2f, 0xd7, 0x60, 0x29, 0xb0, 0x1f, 0x74, 0x09, 0xf2, 0x03, 0xed, 0x44,
-	0xf4, 0x9f, 0x78, 0x07, 0x21, 0x37, 0xd0, 0x4e, 0x78, 0xeb, 0xe9, 0x02, 0x64, 0xe9, 0xc3, 0xae,
-	0xc6, 0xd1, 0x26, 0xa9, 0x64, 0x06, 0xda, 0xc9, 0x7b, 0x9a, 0x23, 0x7f, 0x04, 0xc5, 0x70, 0xef,
-	0x85, 0x9e, 0xc4, 0xa1, 0x35, 0x32, 0x75, 0x66, 0x23, 0xad, 0xf0, 0x09, 0xba, 0x0d, 0xe9, 0x63,
-	0x8b, 0x83, 0xd5, 0xf4, 0x90, 0x7d, 0x64, 0x11, 0x1c, 0xe8, 0xdd, 0x70, 0x69, 0xf9, 0x33, 0x48,
-	0x33, 0xf0, 0xa1, 0x40, 0xc2, 0xba, 0x28, 0x82, 0x8f, 0xd2, 0x31, 0xfa, 0x08, 0x40, 0x23, 0x64,
-	0x68, 0xb4, 0x47, 0xbe, 0xe1, 0xf5, 0xe9, 0xe0, 0xb5, 0xed, 0xca, 0x55, 0x2f, 0x0b, 0x14, 0x5b,
-	0xf5, 0x55, 0x03, 0x48, 0x16, 0x30, 0x28, 0xef, 0x43, 0x31, 0xac, 0x1b, 0xec, 0x67, 0x2e, 0x4f,
-	0xe9, 0x67, 0x7a, 0x9c, 0xc7, 0x63, 0x4c, 0x49, 0xde, 0x31, 0x63, 0x13, 0xf9, 0x59, 0x1c, 0x72,
-	0xad, 0x13, 0x71, 0xac, 0x23, 0x9a, 0x35, 0xbe, 0x6a, 0x22, 0xd8, 0x9a, 0xe0, 0xdd, 0x9f, 0xa4,
-	0xd7, 0x53, 0x7a, 0xd7, 0x0b, 0xdc, 0xd4, 0xa2, 0xc5, 0xa3, 0xdb, 0x5c, 0x13, 0x60, 0xf5, 0x16,
-	0xe4, 0xbd, 0x53, 0x45, 0x89, 0xbd, 0xa6, 0xeb, 0x43, 0xec, 0x38, 0x62, 0x6f, 0xee, 0x94, 0xf5,
-	0xfe, 0xac, 0xa7, 0xa2, 0xf9, 0x91, 0x54, 0xf8, 0x44, 0xd6, 0xa1, 0x34, 0x96, 0xb6, 0xd0, 0x5b,
-	0x90, 0xb5, 0x47, 0x6d, 0xd5, 0x75, 0xcf, 0x58, 0xf0, 0xb8, 0x24, 0x6f, 0xd4, 0xee, 0x1b, 0x9d,
-	0x5d, 0x7c, 0xea, 0xfe, 0x18, 0x7b, 0xd4, 0xde, 0xe5, 0x5e, 0xe4, 0x6f, 0x49, 0x04, 0xdf, 0x72,
-	0x0c, 0x39, 0xf7, 0x50, 0xa0, 0x9f, 0x06, 0xe3, 0xc4, 0xed, 0x08, 0x47, 0xa6, 0x52, 0x61, 0x3e,
-	0x10, 0x26, 0x37, 0x61, 0xc5, 0x31, 0xba, 0x26, 0xd6, 0x55, 0xbf, 0xb4, 0x60, 0x6f, 0xcb, 0x29,
-	0x25, 0xfe, 0x60, 0xcf, 0xad, 0x2b, 0xe4, 0xff, 0xc4, 0x21, 0xe7, 0x06, 0x2c, 0x7a, 0x33, 0x70,
-	0xee, 0x8a, 0x53, 0x1a, 0x25, 0xae, 0xa0, 0xdf, 0xbe, 0x0b, 0xff, 0xd6, 0xc4, 0xd9, 0x7f, 0x6b,
-	0x54, 0x1f, 0xd6, 0x6d, 0x88, 0xa7, 0xce, 0xdc, 0x10, 0x7f, 0x1d, 0x10, 0xb1, 0x88, 0xd6, 0x57,
-	0x8f, 0x2d, 0x62, 0x98, 0x5d, 0x95, 0x3b, 0x9b, 0x33, 0xaa, 0x32, 0x7b, 0xf2, 0x88, 0x3d, 0x38,
-	0x60, 0x7e, 0xff, 0x4d, 0x1c, 0x72, 0x5e, 0x6e, 0x3c, 0x6b, 0x37, 0xee, 0x3c, 0x64, 0x04, 0xfc,
-	0xf3, 0x76, 0x9c, 0x98, 0x79, 0x8d, 0xe1, 0x54, 0xa0, 0x31, 0x2c, 0x41, 0x6e, 0x80, 0x89, 0xc6,
-	0x08, 0x02, 0xaf, 0xee, 0xbc, 0xf9, 0xcd, 0x7b, 0xb0, 0x14, 0x68, 0x8c, 0xd2, 0xc8, 0xdb, 0xaf,
-	0x7f, 0x58, 0x8e, 0x49, 0xd9, 0x67, 0x5f, 0x5e, 0x4d, 0xee, 0xe3, 0xa7, 0xf4, 0xcc, 0x2a, 0xf5,
-	0x5a, 0xa3, 0x5e, 0xdb, 0x2d, 0xc7, 0xa5, 0xa5, 0x67, 0x5f, 0x5e, 0xcd, 0x2a, 0x98, 0xf5, 0x57,
-	0x6e, 0x36, 0x60, 0x39, 0xf8, 0x55, 0xc2, 0x19, 0x04, 0x41, 0xf1, 0xfe, 0xc3, 0x83, 0xbd, 0x9d,
-	0xda, 0x76, 0xab, 0xae, 0x3e, 0x6a, 0xb6, 0xea, 0xe5, 0x38, 0xba, 0x00, 0xe7, 0xf6, 0x76, 0xde,
-	0x6b, 0xb4, 0xd4, 0xda, 0xde, 0x4e, 0x7d, 0xbf, 0xa5, 0x6e, 0xb7, 0x5a, 0xdb, 0xb5, 0xdd, 0x72,
-	0x62, 0xeb, 0x73, 0x80, 0xd2, 0x76, 0xb5, 0xb6, 0x43, 0xb3, 0x9f, 0xd1, 0xd1, 0x44, 0xff, 0x2a,
-	0xc5, 0x8a, 0xeb, 0x99, 0x37, 0xb2, 0xd2, 0xec, 0xf6, 0x1d, 0x7a, 0x00, 0x69, 0x56, 0x77, 0xa3,
-	0xd9, 0x57, 0xb4, 0xd2, 0x9c, 0x7e, 0x1e, 0xfd, 0x31, 0x2c, 0x3c, 0x66, 0xde, 0xd9, 0x4a, 0xb3,
-	0xdb, 0x7b, 0x48, 0x81, 0xbc, 0x5f, 0x38, 0xcf, 0xbf, 0xc3, 0x95, 0x16, 0x68, 0xf9, 0x51, 0x9b,
-	0x7e, 0x59, 0x30, 0xff, 0x4e, 0x53, 0x5a, 0x00, 0xc0, 0xd0, 0x1e, 0x64, 0xdd, 0x82, 0x6b, 0xde,
-	0x2d, 0xab, 0x34, 0xb7, 0x1d, 0x47, 0x3f, 0x01, 0x2f, 0x8c, 0x67, 0x5f, 0x19, 0x4b, 0x73, 0x7a,
-	0x8b, 0x68, 0x07, 0x32, 0x82, 0xeb, 0xce, 0xb9, 0x39, 0x95, 0xe6, 0xb5, 0xd7, 0xa8, 0xd3, 0xfc,
-	0x8e, 0xc3, 0xfc, 0x8b, 0x70, 0x69, 0x81, 0xb6, 0x29, 0x7a, 0x08, 0x10, 0x28, 0x83, 0x17, 0xb8,
-	0xe1, 0x96, 0x16, 0x69, 0x87, 0xa2, 0x26, 0xe4, 0xbc, 0x72, 0x67, 0xee, 0x7d, 0xb3, 0x34, 0xbf,
-	0x2f, 0x89, 0x1e, 0x43, 0x21, 0xcc, 0xf3, 0x17, 0xbb, 0x45, 0x96, 0x16, 0x6c, 0x38, 0x52, 0xfb,
-	0x61, 0xd2, 0xbf, 0xd8, 0xad, 0xb2, 0xb4, 0x60, 0xff, 0x11, 0x7d, 0x02, 0x2b, 0x93, 0xa4, 0x7c,
-	0xf1, 0x4b, 0x66, 0xe9, 0x0c, 0x1d, 0x49, 0x34, 0x00, 0x34, 0x85, 0xcc, 0x9f, 0xe1, 0xce, 0x59,
-	0x3a, 0x4b, 0x83, 0xb2, 0x5a, 0xff, 0xea, 0xf9, 0x5a, 0xfc, 0xeb, 0xe7, 0x6b, 0xf1, 0x7f, 0x3c,
-	0x5f, 0x8b, 0x7f, 0xf1, 0x62, 0x2d, 0xf6, 0xf5, 0x8b, 0xb5, 0xd8, 0xdf, 0x5e, 0xac, 0xc5, 0x7e,
-	0xf9, 0x5a, 0xd7, 0x20, 0xbd, 0x51, 0x7b, 0xa3, 0x63, 0x0d, 0x36, 0x83, 0x7f, 0x88, 0x99, 0xf6,
-	0x27, 0x9d, 0x76, 0x86, 0x25, 0xaa, 0x5b, 0xff, 0x0b, 0x00, 0x00, 0xff, 0xff, 0x3b, 0xae, 0x48,
-	0xb4, 0xc4, 0x23, 0x00, 0x00,
+	0xf1, 0xc7, 0xfb, 0xd1, 0x24, 0x1e, 0x1c, 0xd1, 0x32, 0x0c, 0x4b, 0xa4, 0xbc, 0x2a, 0xf9, 0x6f,
+	0xc9, 0x36, 0xf9, 0x37, 0x55, 0x52, 0xa4, 0xd8, 0x89, 0x4d, 0x40, 0x90, 0x41, 0x93, 0x26, 0x98,
+	0x25, 0x24, 0xe7, 0x65, 0xad, 0x17, 0xd8, 0x21, 0xb0, 0x16, 0xb0, 0xbb, 0xde, 0x1d, 0x50, 0x84,
+	0x8f, 0x79, 0x5c, 0x94, 0x8b, 0x73, 0xcb, 0xc5, 0x95, 0xaf, 0x91, 0x53, 0x2e, 0xb9, 0xb8, 0x2a,
+	0x17, 0x1f, 0x73, 0x72, 0x52, 0x52, 0xe5, 0x92, 0x2f, 0x90, 0x53, 0x2a, 0xa9, 0x79, 0xec, 0x0b,
+	0xc0, 0x02, 0xa0, 0x9d, 0x5b, 0x6e, 0x33, 0xb3, 0xdd, 0xbd, 0x98, 0xde, 0xe9, 0x5f, 0xff, 0xba,
+	0x07, 0xf0, 0x32, 0xc1, 0x86, 0x86, 0xed, 0xa1, 0x6e, 0x90, 0x6d, 0xb5, 0xd3, 0xd5, 0xb7, 0xc9,
+	0xd8, 0xc2, 0xce, 0x96, 0x65, 0x9b, 0xc4, 0x44, 0x25, 0xff, 0xe1, 0x16, 0x7d, 0x58, 0xbd, 0x1c,
+	0x90, 0xee, 0xda, 0x63, 0x8b, 0x98, 0xdb, 0x96, 0x6d, 0x9a, 0x27, 0x5c, 0xbe, 0x7a, 0x29, 0xf0,
+	0x98, 0xd9, 0x09, 0x5a, 0x0b, 0x3d, 0x15, 0xca, 0x8f, 0xf1, 0xd8, 0x7d, 0x7a, 0x79, 0x4a, 0xd7,
+	0x52, 0x6d, 0x75, 0xe8, 0x3e, 0xde, 0xec, 0x99, 0x66, 0x6f, 0x80, 0xb7, 0xd9, 0xac, 0x33, 0x3a,
+	0xd9, 0x26, 0xfa, 0x10, 0x3b, 0x44, 0x1d, 0x5a, 0x42, 0x60, 0xbd, 0x67, 0xf6, 0x4c, 0x36, 0xdc,
+	0xa6, 0x23, 0xbe, 0x2a, 0xfd, 0x36, 0x07, 0x59, 0x19, 0x7f, 0x36, 0xc2, 0x0e, 0x41, 0x3b, 0x90,
+	0xc2, 0xdd, 0xbe, 0x59, 0x89, 0x5f, 0x89, 0xbf, 0xb6, 0xb2, 0x73, 0x69, 0x6b, 0x62, 0x73, 0x5b,
+	0x42, 0xae, 0xd1, 0xed, 0x9b, 0xcd, 0x98, 0xcc, 0x64, 0xd1, 0x2d, 0x48, 0x9f, 0x0c, 0x46, 0x4e,
+	0xbf, 0x92, 0x60, 0x4a, 0x97, 0xa3, 0x94, 0xee, 0x53, 0xa1, 0x66, 0x4c, 0xe6, 0xd2, 0xf4, 0x55,
+	0xba, 0x71, 0x62, 0x56, 0x92, 0xf3, 0x5f, 0xb5, 0x67, 0x9c, 0xb0, 0x57, 0x51, 0x59, 0x54, 0x03,
+	0x70, 0x30, 0x51, 0x4c, 0x8b, 0xe8, 0xa6, 0x51, 0x49, 0x31, 0xcd, 0x57, 0xa2, 0x34, 0x8f, 0x31,
+	0x69, 0x31, 0xc1, 0x66, 0x4c, 0xce, 0x3b, 0xee, 0x84, 0xda, 0xd0, 0x0d, 0x9d, 0x28, 0xdd, 0xbe,
+	0xaa, 0x1b, 0x95, 0xf4, 0x7c, 0x1b, 0x7b, 0x86, 0x4e, 0xea, 0x54, 0x90, 0xda, 0xd0, 0xdd, 0x09,
+	0xdd, 0xf2, 0x67, 0x23, 0x6c, 0x8f, 0x2b, 0x99, 0xf9, 0x5b, 0xfe, 0x11, 0x15, 0xa2, 0x5b, 0x66,
+	0xd2, 0xa8, 0x01, 0x2b, 0x1d, 0xdc, 0xd3, 0x0d, 0xa5, 0x33, 0x30, 0xbb, 0x8f, 0x2b, 0x59, 0xa6,
+	0x2c, 0x45, 0x29, 0xd7, 0xa8, 0x68, 0x8d, 0x4a, 0x36, 0x63, 0x32, 0x74, 0xbc, 0x19, 0x7a, 0x07,
+	0x72, 0xdd, 0x3e, 0xee, 0x3e, 0x56, 0xc8, 0x59, 0x25, 0xc7, 0x6c, 0x6c, 0x46, 0xd9, 0xa8, 0x53,
+	0xb9, 0xf6, 0x59, 0x33, 0x26, 0x67, 0xbb, 0x7c, 0x48, 0xf7, 0xaf, 0xe1, 0x81, 0x7e, 0x8a, 0x6d,
+	0xaa, 0x9f, 0x9f, 0xbf, 0xff, 0x7b, 0x5c, 0x92, 0x59, 0xc8, 0x6b, 0xee, 0x04, 0xbd, 0x0b, 0x79,
+	0x6c, 0x68, 0x62, 0x1b, 0xc0, 0x4c, 0x5c, 0x89, 0x3c, 0x2b, 0x86, 0xe6, 0x6e, 0x22, 0x87, 0xc5,
+	0x18, 0xdd, 0x81, 0x4c, 0xd7, 0x1c, 0x0e, 0x75, 0x52, 0x59, 0x61, 0xda, 0x1b, 0x91, 0x1b, 0x60,
+	0x52, 0xcd, 0x98, 0x2c, 0xe4, 0xd1, 0x21, 0x14, 0x07, 0xba, 0x43, 0x14, 0xc7, 0x50, 0x2d, 0xa7,
+	0x6f, 0x12, 0xa7, 0xb2, 0xca, 0x2c, 0x5c, 0x8b, 0xb2, 0x70, 0xa0, 0x3b, 0xe4, 0xd8, 0x15, 0x6e,
+	0xc6, 0xe4, 0xc2, 0x20, 0xb8, 0x40, 0xed, 0x99, 0x27, 0x27, 0xd8, 0xf6, 0x0c, 0x56, 0x0a, 0xf3,
+	0xed, 0xb5, 0xa8, 0xb4, 0xab, 0x4f, 0xed, 0x99, 0xc1, 0x05, 0xf4, 0x33, 0xb8, 0x30, 0x30, 0x55,
+	0xcd, 0x33, 0xa7, 0x74, 0xfb, 0x23, 0xe3, 0x71, 0xa5, 0xc8, 0x8c, 0x5e, 0x8f, 0xfc, 0x91, 0xa6,
+	0xaa, 0xb9, 0x26, 0xea, 0x54, 0xa1, 0x19, 0x93, 0xd7, 0x06, 0x93, 0x8b, 0xe8, 0x11, 0xac, 0xab,
+	0x96, 0x35, 0x18, 0x4f, 0x5a, 0x2f, 0x31, 0xeb, 0x37, 0xa2, 0xac, 0xef, 0x52, 0x9d, 0x49, 0xf3,
+	0x48, 0x9d, 0x5a, 0xad, 0x65, 0x21, 0x7d, 0xaa, 0x0e, 0x46, 0x58, 0xfa, 0x3f, 0x58, 0x09, 0x84,
+	0x3a, 0xaa, 0x40, 0x76, 0x88, 0x1d, 0x47, 0xed, 0x61, 0x86, 0x0c, 0x79, 0xd9, 0x9d, 0x4a, 0x45,
+	0x58, 0x0d, 0x86, 0xb7, 0x34, 0xf4, 0x14, 0x69, 0xe0, 0x52, 0xc5, 0x53, 0x6c, 0x3b, 0x34, 0x5a,
+	0x85, 0xa2, 0x98, 0xa2, 0xab, 0x50, 0x60, 0xc7, 0x47, 0x71, 0x9f, 0x53, 0xf4, 0x48, 0xc9, 0xab,
+	0x6c, 0xf1, 0xa1, 0x10, 0xda, 0x84, 0x15, 0x6b, 0xc7, 0xf2, 0x44, 0x92, 0x4c, 0x04, 0xac, 0x1d,
+	0x4b, 0x08, 0x48, 0xdf, 0x87, 0xf2, 0x64, 0xb4, 0xa3, 0x32, 0x24, 0x1f, 0xe3, 0xb1, 0x78, 0x1f,
+	0x1d, 0xa2, 0x75, 0xb1, 0x2d, 0xf6, 0x8e, 0xbc, 0x2c, 0xf6, 0xf8, 0xe7, 0x84, 0xa7, 0xec, 0x85,
+	0x39, 0xba, 0x03, 0x29, 0x8a, 0x9a, 0x02, 0x00, 0xab, 0x5b, 0x1c, 0x52, 0xb7, 0x5c, 0x48, 0xdd,
+	0x6a, 0xbb, 0x90, 0x5a, 0xcb, 0x7d, 0xf5, 0xcd, 0x66, 0xec, 0x8b, 0xbf, 0x6e, 0xc6, 0x65, 0xa6,
+	0x81, 0x5e, 0xa2, 0x51, 0xa9, 0xea, 0x86, 0xa2, 0x6b, 0xe2, 0x3d, 0x59, 0x36, 0xdf, 0xd3, 0xd0,
+	0x3e, 0x94, 0xbb, 0xa6, 0xe1, 0x60, 0xc3, 0x19, 0x39, 0x0a, 0x87, 0x6c, 0x01, 0x7b, 0xd3, 0x51,
+	0x53, 0x77, 0x05, 0x8f, 0x98, 0x9c, 0x5c, 0xea, 0x86, 0x17, 0xd0, 0x7d, 0x80, 0x53, 0x75, 0xa0,
+	0x6b, 0x2a, 0x31, 0x6d, 0xa7, 0x92, 0xba, 0x92, 0x9c, 0x69, 0xe6, 0xa1, 0x2b, 0xf2, 0xc0, 0xd2,
+	0x54, 0x82, 0x6b, 0x29, 0xfa, 0x6b, 0xe5, 0x80, 0x26, 0x7a, 0x15, 0x4a, 0xaa, 0x65, 0x29, 0x0e,
+	0x51, 0x09, 0x56, 0x3a, 0x63, 0x82, 0x1d, 0x06, 0x86, 0xab, 0x72, 0x41, 0xb5, 0xac, 0x63, 0xba,
+	0x5a, 0xa3, 0x8b, 0xe8, 0x1a, 0x14, 0x29, 0xf0, 0xe9, 0xea, 0x40, 0xe9, 0x63, 0xbd, 0xd7, 0x27,
+	0x0c, 0xf4, 0x92, 0x72, 0x41, 0xac, 0x36, 0xd9, 0xa2, 0xa4, 0x79, 0x07, 0x81, 0x81, 0x1e, 0x42,
+	0x90, 0xd2, 0x54, 0xa2, 0x32, 0x47, 0xae, 0xca, 0x6c, 0x4c, 0xd7, 0x2c, 0x95, 0xf4, 0x85, 0x7b,
+	0xd8, 0x18, 0x5d, 0x84, 0x8c, 0x30, 0x9b, 0x64, 0x66, 0xc5, 0x8c, 0x7e, 0x33, 0xcb, 0x36, 0x4f,
+	0x31, 0x43, 0xf9, 0x9c, 0xcc, 0x27, 0xd2, 0xaf, 0x12, 0xb0, 0x36, 0x05, 0x8f, 0xd4, 0x6e, 0x5f,
+	0x75, 0xfa, 0xee, 0xbb, 0xe8, 0x18, 0xdd, 0xa6, 0x76, 0x55, 0x0d, 0xdb, 0x22, 0x2d, 0x55, 0x82,
+	0x2e, 0xe2, 0x29, 0xb7, 0xc9, 0x9e, 0x0b, 0xd7, 0x08, 0x69, 0xd4, 0x82, 0xf2, 0x40, 0x75, 0x88,
+	0xc2, 0xe1, 0x46, 0x09, 0xa4, 0xa8, 0x69, 0x90, 0x3d, 0x50, 0x5d, 0x80, 0xa2, 0x87, 0x5d, 0x18,
+	0x2a, 0x0e, 0x42, 0xab, 0x48, 0x86, 0xf5, 0xce, 0xf8, 0x73, 0xd5, 0x20, 0xba, 0x81, 0x95, 0xa9,
+	0x2f, 0xf7, 0xd2, 0x94, 0xd1, 0xc6, 0xa9, 0xae, 0x61, 0xa3, 0xeb, 0x7e, 0xb2, 0x0b, 0x9e, 0xb2,
+	0xf7, 0x49, 0x1d, 0x49, 0x86, 0x62, 0x18, 0xe0, 0x51, 0x11, 0x12, 0xe4, 0x4c, 0x38, 0x20, 0x41,
+	0xce, 0xd0, 0xff, 0x43, 0x8a, 0x6e, 0x92, 0x6d, 0xbe, 0x38, 0x23, 0xbb, 0x0a, 0xbd, 0xf6, 0xd8,
+	0xc2, 0x32, 0x93, 0x94, 0x24, 0x2f, 0x1a, 0x3c, 0xd0, 0x9f, 0xb4, 0x2a, 0x5d, 0x87, 0xd2, 0x04,
+	0xaa, 0x07, 0xbe, 0x5f, 0x3c, 0xf8, 0xfd, 0xa4, 0x12, 0x14, 0x42, 0x10, 0x2e, 0x5d, 0x84, 0xf5,
+	0x59, 0x88, 0x2c, 0xf5, 0xbd, 0xf5, 0x10, 0xb2, 0xa2, 0x5b, 0x90, 0xf3, 0x20, 0x99, 0x47, 0xe3,
+	0xb4, 0xaf, 0x5c, 0x61, 0xd9, 0x13, 0xa5, 0x61, 0x48, 0x8f, 0x35, 0x3b, 0x0f, 0x09, 0xf6, 0xc3,
+	0xb3, 0xaa, 0x65, 0x35, 0x55, 0xa7, 0x2f, 0x7d, 0x02, 0x95, 0x28, 0xb8, 0x9d, 0xd8, 0x46, 0xca,
+	0x3b, 0x86, 0x17, 0x21, 0x73, 0x62, 0xda, 0x43, 0x95, 0x30, 0x63, 0x05, 0x59, 0xcc, 0xe8, 0xf1,
+	0xe4, 0xd0, 0x9b, 0x64, 0xcb, 0x7c, 0x22, 0x29, 0xf0, 0x52, 0x24, 0xe4, 0x52, 0x15, 0xdd, 0xd0,
+	0x30, 0xf7, 0x67, 0x41, 0xe6, 0x13, 0xdf, 0x10, 0xff, 0xb1, 0x7c, 0x42, 0x5f, 0xeb, 0xb0, 0xbd,
+	0x32, 0xfb, 0x79, 0x59, 0xcc, 0xa4, 0xbf, 0xe7, 0x20, 0x27, 0x63, 0xc7, 0xa2, 0x98, 0x80, 0x6a,
+	0x90, 0xc7, 0x67, 0x5d, 0xcc, 0xc9, 0x50, 0x3c, 0x92, 0x4c, 0x70, 0xe9, 0x86, 0x2b, 0x49, 0x33,
+	0xb9, 0xa7, 0x86, 0x6e, 0x0a, 0xc2, 0x17, 0xcd, 0xdd, 0x84, 0x7a, 0x90, 0xf1, 0xdd, 0x76, 0x19,
+	0x5f, 0x32, 0x32, 0x79, 0x73, 0xad, 0x09, 0xca, 0x77, 0x53, 0x50, 0xbe, 0xd4, 0x82, 0x97, 0x85,
+	0x38, 0x5f, 0x3d, 0xc4, 0xf9, 0xd2, 0x0b, 0xb6, 0x19, 0x41, 0xfa, 0xea, 0x21, 0xd2, 0x97, 0x59,
+	0x60, 0x24, 0x82, 0xf5, 0xdd, 0x76, 0x59, 0x5f, 0x76, 0xc1, 0xb6, 0x27, 0x68, 0xdf, 0xfd, 0x30,
+	0xed, 0xe3, 0x94, 0xed, 0x6a, 0xa4, 0x76, 0x24, 0xef, 0xfb, 0x41, 0x80, 0xf7, 0xe5, 0x23, 0x49,
+	0x17, 0x37, 0x32, 0x83, 0xf8, 0xd5, 0x43, 0xc4, 0x0f, 0x16, 0xf8, 0x20, 0x82, 0xf9, 0xbd, 0x17,
+	0x64, 0x7e, 0x2b, 0x91, 0xe4, 0x51, 0x1c, 0x9a, 0x59, 0xd4, 0xef, 0xae, 0x47, 0xfd, 0x56, 0x23,
+	0xb9, 0xab, 0xd8, 0xc3, 0x24, 0xf7, 0x6b, 0x4d, 0x71, 0x3f, 0xce, 0xd5, 0x5e, 0x8d, 0x34, 0xb1,
+	0x80, 0xfc, 0xb5, 0xa6, 0xc8, 0x5f, 0x71, 0x81, 0xc1, 0x05, 0xec, 0xef, 0xe7, 0xb3, 0xd9, 0x5f,
+	0x34, 0x3f, 0x13, 0x3f, 0x73, 0x39, 0xfa, 0xa7, 0x44, 0xd0, 0xbf, 0x32, 0x33, 0xff, 0x7a, 0xa4,
+	0xf9, 0xf3, 0xf3, 0xbf, 0xeb, 0x34, 0xcd, 0x4e, 0x00, 0x07, 0x85, 0x2a, 0x6c, 0xdb, 0xa6, 0x2d,
+	0xa8, 0x15, 0x9f, 0x48, 0xaf, 0xd1, 0xc4, 0xef, 0x83, 0xc4, 0x1c, 0xae, 0xc8, 0x52, 0x42, 0x00,
+	0x18, 0xa4, 0x3f, 0xc4, 0x7d, 0x5d, 0x96, 0x2b, 0x83, 0xa4, 0x21, 0x2f, 0x48, 0x43, 0x80, 0x42,
+	0x26, 0xc2, 0x14, 0x72, 0x13, 0x56, 0x28, 0xd4, 0x4f, 0xb0, 0x43, 0xd5, 0x72, 0xd9, 0x21, 0xba,
+	0x01, 0x6b, 0x2c, 0x97, 0x73, 0xa2, 0x29, 0xf0, 0x3d, 0xc5, 0xd2, 0x54, 0x89, 0x3e, 0xe0, 0x87,
+	0x93, 0x03, 0xfd, 0x9b, 0x70, 0x21, 0x20, 0xeb, 0xa5, 0x10, 0x4e, 0x89, 0xca, 0x9e, 0xf4, 0xae,
+	0xc8, 0x25, 0x1f, 0xfa, 0x0e, 0xf2, 0x99, 0x27, 0x82, 0x54, 0xd7, 0xd4, 0xb0, 0x00, 0x78, 0x36,
+	0xa6, 0x6c, 0x74, 0x60, 0xf6, 0x04, 0x8c, 0xd3, 0x21, 0x95, 0xf2, 0x50, 0x30, 0xcf, 0x41, 0x4e,
+	0xfa, 0x53, 0xdc, 0xb7, 0xe7, 0x93, 0xd1, 0x59, 0xbc, 0x31, 0xfe, 0xdf, 0xe1, 0x8d, 0x89, 0x6f,
+	0xcd, 0x1b, 0x83, 0x09, 0x36, 0x19, 0x4e, 0xb0, 0xff, 0x8c, 0xfb, 0x5f, 0xd8, 0x63, 0x81, 0xdf,
+	0xce, 0x23, 0x7e, 0xb6, 0x4c, 0xb3, 0xef, 0x25, 0xb2, 0xa5, 0xe0, 0xf6, 0x19, 0xf6, 0xde, 0x30,
+	0xb7, 0xcf, 0xf2, 0xfc, 0xc9, 0x26, 0xe8, 0x0e, 0xe4, 0x59, 0xd3, 0x45, 0x31, 0x2d, 0x47, 0x00,
+	0xee, 0xcb, 0xc1, 0xbd, 0xf2, 0xde, 0xca, 0xd6, 0x11, 0x95, 0x69, 0x59, 0x8e, 0x9c, 0xb3, 0xc4,
+	0x28, 0x40, 0x04, 0xf2, 0x21, 0x3e, 0x7a, 0x09, 0xf2, 0xf4, 0xd7, 0x3b, 0x96, 0xda, 0xc5, 0x0c,
+	0x3c, 0xf3, 0xb2, 0xbf, 0x20, 0x3d, 0x02, 0x34, 0x0d, 0xdf, 0xa8, 0x09, 0x19, 0x7c, 0x8a, 0x0d,
+	0x42, 0xbf, 0x1a, 0x75, 0xf7, 0xc5, 0x19, 0x64, 0x0f, 0x1b, 0xa4, 0x56, 0xa1, 0x4e, 0xfe, 0xc7,
+	0x37, 0x9b, 0x65, 0x2e, 0xfd, 0x86, 0x39, 0xd4, 0x09, 0x1e, 0x5a, 0x64, 0x2c, 0x0b, 0x7d, 0xe9,
+	0xf7, 0x09, 0xca, 0xbc, 0x42, 0xd0, 0x3e, 0xd3, 0xb7, 0x6e, 0x00, 0x25, 0x02, 0xac, 0x7b, 0x39,
+	0x7f, 0x6f, 0x00, 0xf4, 0x54, 0x47, 0x79, 0xa2, 0x1a, 0x04, 0x6b, 0xc2, 0xe9, 0x81, 0x15, 0x54,
+	0x85, 0x1c, 0x9d, 0x8d, 0x1c, 0xac, 0x89, 0x02, 0xc0, 0x9b, 0x07, 0xf6, 0x99, 0xfd, 0x6e, 0xfb,
+	0x0c, 0x7b, 0x39, 0x37, 0xe1, 0x65, 0xfa, 0x1b, 0x2c, 0x5b, 0x37, 0x6d, 0x9d, 0x8c, 0xd9, 0x27,
+	0x48, 0xca, 0xde, 0x5c, 0xfa, 0x75, 0xc2, 0x8f, 0x20, 0x9f, 0xc0, 0xfe, 0xcf, 0xf9, 0x48, 0xfa,
+	0x0d, 0xab, 0x6a, 0xc3, 0xf9, 0x17, 0x1d, 0xc3, 0x9a, 0x17, 0xc1, 0xca, 0x88, 0x45, 0xb6, 0x7b,
+	0x26, 0x97, 0x85, 0x80, 0xf2, 0x69, 0x78, 0xd9, 0x41, 0x3f, 0x86, 0x17, 0x27, 0xd0, 0xc9, 0x33,
+	0x9d, 0x58, 0x12, 0xa4, 0x5e, 0x08, 0x83, 0x94, 0x6b, 0xd9, 0xf7, 0x55, 0xf2, 0x3b, 0xc6, 0xcd,
+	0x1e, 0x2d, 0x94, 0x82, 0x6c, 0x62, 0xe6, 0xd7, 0xbf, 0x0a, 0x05, 0x1b, 0x13, 0x5a, 0xbb, 0x87,
+	0x4a, 0xd1, 0x55, 0xbe, 0x28, 0x0a, 0xdc, 0x23, 0x78, 0x61, 0x26, 0xab, 0x40, 0xdf, 0x83, 0xbc,
+	0x4f, 0x48, 0xe2, 0x11, 0x55, 0x9d, 0x57, 0xa9, 0xf8, 0xb2, 0xd2, 0x1f, 0xe3, 0xbe, 0xc9, 0x70,
+	0xed, 0xd3, 0x80, 0x8c, 0x8d, 0x9d, 0xd1, 0x80, 0x57, 0x23, 0xc5, 0x9d, 0x37, 0x97, 0xe3, 0x23,
+	0x74, 0x75, 0x34, 0x20, 0xb2, 0x50, 0x96, 0x1e, 0x41, 0x86, 0xaf, 0xa0, 0x15, 0xc8, 0x3e, 0x38,
+	0xdc, 0x3f, 0x6c, 0x7d, 0x74, 0x58, 0x8e, 0x21, 0x80, 0xcc, 0x6e, 0xbd, 0xde, 0x38, 0x6a, 0x97,
+	0xe3, 0x28, 0x0f, 0xe9, 0xdd, 0x5a, 0x4b, 0x6e, 0x97, 0x13, 0x74, 0x59, 0x6e, 0x7c, 0xd0, 0xa8,
+	0xb7, 0xcb, 0x49, 0xb4, 0x06, 0x05, 0x3e, 0x56, 0xee, 0xb7, 0xe4, 0x0f, 0x77, 0xdb, 0xe5, 0x54,
+	0x60, 0xe9, 0xb8, 0x71, 0x78, 0xaf, 0x21, 0x97, 0xd3, 0xd2, 0x5b, 0xb4, 0xdc, 0x89, 0x60, 0x30,
+	0x7e, 0x61, 0x13, 0x0f, 0x14, 0x36, 0xd2, 0xef, 0x12, 0x50, 0x8d, 0xa6, 0x25, 0xe8, 0x83, 0x89,
+	0x8d, 0xef, 0x9c, 0x83, 0xd3, 0x4c, 0xec, 0x1e, 0x5d, 0x83, 0xa2, 0x8d, 0x4f, 0x30, 0xe9, 0xf6,
+	0x39, 0x4d, 0xe2, 0x49, 0xaf, 0x20, 0x17, 0xc4, 0x2a, 0x53, 0x72, 0xb8, 0xd8, 0xa7, 0xb8, 0x4b,
+	0x14, 0x5e, 0x63, 0xf1, 0x43, 0x97, 0xa7, 0x62, 0x74, 0xf5, 0x98, 0x2f, 0x4a, 0x9f, 0x9c, 0xcb,
+	0x97, 0x79, 0x48, 0xcb, 0x8d, 0xb6, 0xfc, 0x93, 0x72, 0x12, 0x21, 0x28, 0xb2, 0xa1, 0x72, 0x7c,
+	0xb8, 0x7b, 0x74, 0xdc, 0x6c, 0x51, 0x5f, 0x5e, 0x80, 0x92, 0xeb, 0x4b, 0x77, 0x31, 0x2d, 0xfd,
+	0x3b, 0x0e, 0xa5, 0x89, 0x00, 0x41, 0x3b, 0x90, 0xe6, 0x54, 0x3b, 0xaa, 0x21, 0xcf, 0xe2, 0x5b,
+	0x44, 0x13, 0x17, 0x45, 0xef, 0x40, 0x0e, 0x8b, 0x1e, 0xc2, 0xac, 0x40, 0xe4, 0xbd, 0x0f, 0xb7,
+	0xcb, 0x20, 0x54, 0x3d, 0x0d, 0xf4, 0x2e, 0xe4, 0xbd, 0x48, 0x17, 0xf5, 0xdd, 0x2b, 0xd3, 0xea,
+	0x1e, 0x46, 0x08, 0x7d, 0x5f, 0x07, 0xdd, 0xf5, 0xf9, 0x5a, 0x6a, 0x9a, 0xe0, 0x0b, 0x75, 0x2e,
+	0x20, 0x94, 0x5d, 0x79, 0xa9, 0x0e, 0x2b, 0x81, 0xfd, 0xa0, 0x97, 0x21, 0x3f, 0x54, 0xcf, 0x44,
+	0x6f, 0x8a, 0x77, 0x17, 0x72, 0x43, 0xf5, 0x8c, 0xb7, 0xa5, 0x5e, 0x84, 0x2c, 0x7d, 0xd8, 0x53,
+	0x39, 0xda, 0x24, 0xe5, 0xcc, 0x50, 0x3d, 0x7b, 0x5f, 0x75, 0xa4, 0x8f, 0xa1, 0x18, 0xee, 0xcb,
+	0xd0, 0x93, 0x68, 0x9b, 0x23, 0x43, 0x63, 0x36, 0xd2, 0x32, 0x9f, 0xa0, 0x5b, 0x90, 0x3e, 0x35,
+	0x39, 0x58, 0xcd, 0x0e, 0xd9, 0x87, 0x26, 0xc1, 0x81, 0xbe, 0x0e, 0x97, 0x96, 0x3e, 0x87, 0x34,
+	0x03, 0x1f, 0x0a, 0x24, 0xac, 0xc3, 0x22, 0xb8, 0x2a, 0x1d, 0xa3, 0x8f, 0x01, 0x54, 0x42, 0x6c,
+	0xbd, 0x33, 0xf2, 0x0d, 0x6f, 0xce, 0x06, 0xaf, 0x5d, 0x57, 0xae, 0x76, 0x49, 0xa0, 0xd8, 0xba,
+	0xaf, 0x1a, 0x40, 0xb2, 0x80, 0x41, 0xe9, 0x10, 0x8a, 0x61, 0xdd, 0x60, 0xaf, 0x73, 0x75, 0x46,
+	0xaf, 0xd3, 0xe3, 0x43, 0x1e, 0x9b, 0x4a, 0xf2, 0x6e, 0x1a, 0x9b, 0x48, 0x4f, 0xe3, 0x90, 0x6b,
+	0x9f, 0x89, 0x63, 0x1d, 0xd1, 0xc8, 0xf1, 0x55, 0x13, 0xc1, 0xb6, 0x05, 0xef, 0x0c, 0x25, 0xbd,
+	0x7e, 0xd3, 0x7b, 0x5e, 0xe0, 0xa6, 0x96, 0x2d, 0x2c, 0xdd, 0xc6, 0x9b, 0x00, 0xab, 0xb7, 0x21,
+	0xef, 0x9d, 0x2a, 0x4a, 0xfa, 0x55, 0x4d, 0xb3, 0xb1, 0xe3, 0x88, 0xbd, 0xb9, 0x53, 0xd6, 0x17,
+	0x34, 0x9f, 0x88, 0xc6, 0x48, 0x52, 0xe6, 0x13, 0x49, 0x83, 0xd2, 0x44, 0xda, 0x42, 0x6f, 0x43,
+	0xd6, 0x1a, 0x75, 0x14, 0xd7, 0x3d, 0x13, 0xc1, 0xe3, 0x12, 0xc0, 0x51, 0x67, 0xa0, 0x77, 0xf7,
+	0xf1, 0xd8, 0xfd, 0x31, 0xd6, 0xa8, 0xb3, 0xcf, 0xbd, 0xc8, 0xdf, 0x92, 0x08, 0xbe, 0xe5, 0x14,
+	0x72, 0xee, 0xa1, 0x40, 0x3f, 0x0c, 0xc6, 0x89, 0xdb, 0x2d, 0x8e, 0x4c, 0xa5, 0xc2, 0x7c, 0x20,
+	0x4c, 0x6e, 0xc0, 0x9a, 0xa3, 0xf7, 0x0c, 0xac, 0x29, 0x7e, 0xd9, 0xc1, 0xde, 0x96, 0x93, 0x4b,
+	0xfc, 0xc1, 0x81, 0x5b, 0x73, 0x48, 0xff, 0x8a, 0x43, 0xce, 0x0d, 0x58, 0xf4, 0x56, 0xe0, 0xdc,
+	0x15, 0x67, 0x34, 0x51, 0x5c, 0x41, 0xbf, 0xb5, 0x17, 0xfe, 0xad, 0x89, 0xf3, 0xff, 0xd6, 0xa8,
+	0x1e, 0xad, 0xdb, 0x2c, 0x4f, 0x9d, 0xbb, 0x59, 0xfe, 0x06, 0x20, 0x62, 0x12, 0x75, 0xa0, 0x9c,
+	0x9a, 0x44, 0x37, 0x7a, 0x0a, 0x77, 0x36, 0x67, 0x54, 0x65, 0xf6, 0xe4, 0x21, 0x7b, 0x70, 0xc4,
+	0xfc, 0xfe, 0x8b, 0x38, 0xe4, 0xbc, 0xdc, 0x78, 0xde, 0x4e, 0xdd, 0x45, 0xc8, 0x08, 0xf8, 0xe7,
+	0xad, 0x3a, 0x31, 0xf3, 0x9a, 0xc6, 0xa9, 0x40, 0xd3, 0xb8, 0x0a, 0xb9, 0x21, 0x26, 0x2a, 0x23,
+	0x08, 0xbc, 0xf2, 0xf3, 0xe6, 0x37, 0xee, 0xc2, 0x4a, 0xa0, 0x69, 0x4a, 0x23, 0xef, 0xb0, 0xf1,
+	0x51, 0x39, 0x56, 0xcd, 0x3e, 0xfd, 0xf2, 0x4a, 0xf2, 0x10, 0x3f, 0xa1, 0x67, 0x56, 0x6e, 0xd4,
+	0x9b, 0x8d, 0xfa, 0x7e, 0x39, 0x5e, 0x5d, 0x79, 0xfa, 0xe5, 0x95, 0xac, 0x8c, 0x59, 0xef, 0xe5,
+	0x46, 0x13, 0x56, 0x83, 0x5f, 0x25, 0x9c, 0x41, 0x10, 0x14, 0xef, 0x3d, 0x38, 0x3a, 0xd8, 0xab,
+	0xef, 0xb6, 0x1b, 0xca, 0xc3, 0x56, 0xbb, 0x51, 0x8e, 0xa3, 0x17, 0xe1, 0xc2, 0xc1, 0xde, 0xfb,
+	0xcd, 0xb6, 0x52, 0x3f, 0xd8, 0x6b, 0x1c, 0xb6, 0x95, 0xdd, 0x76, 0x7b, 0xb7, 0xbe, 0x5f, 0x4e,
+	0xec, 0xfc, 0x12, 0xa0, 0xb4, 0x5b, 0xab, 0xef, 0xd1, 0xec, 0xa7, 0x77, 0x55, 0xd1, 0xdb, 0x4a,
+	0xb1, 0xc2, 0x7b, 0xee, 0x6d, 0x6d, 0x75, 0x7e, 0x6b, 0x0f, 0xdd, 0x87, 0x34, 0xab, 0xc9, 0xd1,
+	0xfc, 0xeb, 0xdb, 0xea, 0x82, 0x5e, 0x1f, 0xfd, 0x31, 0x2c, 0x3c, 0xe6, 0xde, 0xe7, 0x56, 0xe7,
+	0xb7, 0xfe, 0x90, 0x0c, 0x79, 0xbf, 0xa8, 0x5e, 0x7c, 0xbf, 0x5b, 0x5d, 0xa2, 0x1d, 0x48, 0x6d,
+	0xfa, 0x65, 0xc1, 0xe2, 0xfb, 0xce, 0xea, 0x12, 0x00, 0x86, 0x0e, 0x20, 0xeb, 0x16, 0x63, 0x8b,
+	0x6e, 0x60, 0xab, 0x0b, 0x5b, 0x75, 0xf4, 0x13, 0xf0, 0xa2, 0x79, 0xfe, 0x75, 0x72, 0x75, 0x41,
+	0xdf, 0x11, 0xed, 0x41, 0x46, 0x70, 0xdd, 0x05, 0xb7, 0xaa, 0xd5, 0x45, 0xad, 0x37, 0xea, 0x34,
+	0xbf, 0x1b, 0xb1, 0xf8, 0x92, 0xbc, 0xba, 0x44, 0x4b, 0x15, 0x3d, 0x00, 0x08, 0x94, 0xc8, 0x4b,
+	0xdc, 0x7e, 0x57, 0x97, 0x69, 0x95, 0xa2, 0x16, 0xe4, 0xbc, 0x72, 0x67, 0xe1, 0x5d, 0x74, 0x75,
+	0x71, 0xcf, 0x12, 0x3d, 0x82, 0x42, 0x98, 0xe7, 0x2f, 0x77, 0xc3, 0x5c, 0x5d, 0xb2, 0x19, 0x49,
+	0xed, 0x87, 0x49, 0xff, 0x72, 0x37, 0xce, 0xd5, 0x25, 0x7b, 0x93, 0xe8, 0x53, 0x58, 0x9b, 0x26,
+	0xe5, 0xcb, 0x5f, 0x40, 0x57, 0xcf, 0xd1, 0xad, 0x44, 0x43, 0x40, 0x33, 0xc8, 0xfc, 0x39, 0xee,
+	0xa3, 0xab, 0xe7, 0x69, 0x5e, 0xd6, 0x1a, 0x5f, 0x3d, 0xdb, 0x88, 0x7f, 0xfd, 0x6c, 0x23, 0xfe,
+	0xb7, 0x67, 0x1b, 0xf1, 0x2f, 0x9e, 0x6f, 0xc4, 0xbe, 0x7e, 0xbe, 0x11, 0xfb, 0xcb, 0xf3, 0x8d,
+	0xd8, 0x4f, 0x5f, 0xef, 0xe9, 0xa4, 0x3f, 0xea, 0x6c, 0x75, 0xcd, 0xe1, 0x76, 0xf0, 0xcf, 0x32,
+	0xb3, 0xfe, 0xc0, 0xd3, 0xc9, 0xb0, 0x44, 0x75, 0xf3, 0x3f, 0x01, 0x00, 0x00, 0xff, 0xff, 0xee,
+	0xcd, 0x31, 0x65, 0xe0, 0x23, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -5635,6 +5644,11 @@ func (m *ResponseCheckTx) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Priority != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.Priority))
+		i--
+		dAtA[i] = 0x50
+	}
 	if len(m.Codespace) > 0 {
 		i -= len(m.Codespace)
 		copy(dAtA[i:], m.Codespace)
@@ -7390,6 +7404,9 @@ func (m *ResponseCheckTx) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovTypes(uint64(l))
 	}
+	if m.Priority != 0 {
+		n += 1 + sovTypes(uint64(m.Priority))
+	}
 	return n
 }
 
@@ -11929,6 +11946,25 @@ func (m *ResponseCheckTx) Unmarshal(dAtA []byte) error {
 			}
 			m.Codespace = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Priority", wireType)
+			}
+			m.Priority = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Priority |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
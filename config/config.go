@@ -688,6 +688,9 @@ type MempoolConfig struct {
 	MaxTxsBytes int64 `mapstructure:"max_txs_bytes"`
 	// Size of the cache (used to filter transactions we saw earlier) in transactions
 	CacheSize int `mapstructure:"cache_size"`
+	// CacheTTL, if non-zero, is how long a transaction stays in the cache,
+	// after which it is checked again when received.
+	CacheTTL time.Duration `mapstructure:"cache-ttl"`
 	// Do not remove invalid transactions from the cache (default: false)
 	// Set to true if it's not possible for any invalid transaction to become
 	// valid again in the future.
@@ -699,6 +702,33 @@ type MempoolConfig struct {
 	// Including space needed by encoding (one varint per transaction).
 	// XXX: Unused due to https://github.com/consideritdone/landslidecore/issues/5796
 	MaxBatchBytes int `mapstructure:"max_batch_bytes"`
+	// TTLDuration, if non-zero, defines the maximum amount of time a transaction
+	// can exist for in the mempool.
+	//
+	// Note, if TTLNumBlocks is also defined, a transaction will be removed if it
+	// has existed in the mempool at least TTLNumBlocks number of blocks or if it's
+	// insertion time into the mempool is beyond TTLDuration.
+	TTLDuration time.Duration `mapstructure:"ttl-duration"`
+	// TTLNumBlocks, if non-zero, defines the maximum number of blocks a transaction
+	// can exist for in the mempool.
+	//
+	// Note, if TTLDuration is also defined, a transaction will be removed if it
+	// has existed in the mempool at least TTLNumBlocks number of blocks or if
+	// it's insertion time into the mempool is beyond TTLDuration.
+	TTLNumBlocks int64 `mapstructure:"ttl-num-blocks"`
+	// RecheckBatchSize, if non-zero, is the maximum number of transactions
+	// rechecked after a block. The next recheck goes on from the transaction
+	// the last one stopped at, so that all the transactions are rechecked over
+	// several blocks.
+	RecheckBatchSize int `mapstructure:"recheck-batch-size"`
+	// OriginRate, if non-zero, is the number of transactions per second
+	// checked for each origin, e.g. an RPC client or a peer, up to OriginBurst
+	// at once, OriginRate rounded up if zero.
+	OriginRate  float64 `mapstructure:"origin-rate"`
+	OriginBurst int     `mapstructure:"origin-burst"`
+	// OriginMaxTxs, if non-zero, is the number of transactions of an origin
+	// the mempool holds.
+	OriginMaxTxs int `mapstructure:"origin-max-txs"`
 }
 
 // DefaultMempoolConfig returns a default configuration for the Tendermint mempool
@@ -745,9 +775,30 @@ func (cfg *MempoolConfig) ValidateBasic() error {
 	if cfg.CacheSize < 0 {
 		return errors.New("cache_size can't be negative")
 	}
+	if cfg.CacheTTL < 0 {
+		return errors.New("cache-ttl can't be negative")
+	}
 	if cfg.MaxTxBytes < 0 {
 		return errors.New("max_tx_bytes can't be negative")
 	}
+	if cfg.TTLDuration < 0 {
+		return errors.New("ttl-duration can't be negative")
+	}
+	if cfg.TTLNumBlocks < 0 {
+		return errors.New("ttl-num-blocks can't be negative")
+	}
+	if cfg.RecheckBatchSize < 0 {
+		return errors.New("recheck-batch-size can't be negative")
+	}
+	if cfg.OriginRate < 0 {
+		return errors.New("origin-rate can't be negative")
+	}
+	if cfg.OriginBurst < 0 {
+		return errors.New("origin-burst can't be negative")
+	}
+	if cfg.OriginMaxTxs < 0 {
+		return errors.New("origin-max-txs can't be negative")
+	}
 	return nil
 }
 
@@ -357,6 +357,10 @@ max_txs_bytes = {{ .Mempool.MaxTxsBytes }}
 # Size of the cache (used to filter transactions we saw earlier) in transactions
 cache_size = {{ .Mempool.CacheSize }}
 
+# cache-ttl, if non-zero, is how long a transaction stays in the cache, after
+# which it is checked again when received.
+cache-ttl = "{{ .Mempool.CacheTTL }}"
+
 # Do not remove invalid transactions from the cache (default: false)
 # Set to true if it's not possible for any invalid transaction to become valid
 # again in the future.
@@ -371,6 +375,38 @@ max_tx_bytes = {{ .Mempool.MaxTxBytes }}
 # XXX: Unused due to https://github.com/consideritdone/landslidecore/issues/5796
 max_batch_bytes = {{ .Mempool.MaxBatchBytes }}
 
+# ttl-duration, if non-zero, defines the maximum amount of time a transaction
+# can exist for in the mempool.
+#
+# Note, if ttl-num-blocks is also defined, a transaction will be removed if it
+# has existed in the mempool at least ttl-num-blocks number of blocks or if it's
+# insertion time into the mempool is beyond ttl-duration.
+ttl-duration = "{{ .Mempool.TTLDuration }}"
+
+# ttl-num-blocks, if non-zero, defines the maximum number of blocks a transaction
+# can exist for in the mempool.
+#
+# Note, if ttl-duration is also defined, a transaction will be removed if it
+# has existed in the mempool at least ttl-num-blocks number of blocks or if
+# it's insertion time into the mempool is beyond ttl-duration.
+ttl-num-blocks = {{ .Mempool.TTLNumBlocks }}
+
+# recheck-batch-size, if non-zero, is the maximum number of transactions
+# rechecked after a block. The next recheck goes on from the transaction the
+# last one stopped at, so that all the transactions are rechecked over several
+# blocks.
+recheck-batch-size = {{ .Mempool.RecheckBatchSize }}
+
+# origin-rate, if non-zero, is the number of transactions per second checked
+# for each origin, e.g. an RPC client or a peer, up to origin-burst at once
+# (origin-rate rounded up if zero).
+origin-rate = {{ .Mempool.OriginRate }}
+origin-burst = {{ .Mempool.OriginBurst }}
+
+# origin-max-txs, if non-zero, is the number of transactions of an origin the
+# mempool holds.
+origin-max-txs = {{ .Mempool.OriginMaxTxs }}
+
 #######################################################
 ###         State Sync Configuration Options        ###
 #######################################################
@@ -0,0 +1,108 @@
+package bls12381
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+
+	"github.com/consideritdone/landslidecore/crypto"
+	"github.com/consideritdone/landslidecore/crypto/encoding"
+	"github.com/consideritdone/landslidecore/crypto/tmhash"
+	tmjson "github.com/consideritdone/landslidecore/libs/json"
+	pc "github.com/consideritdone/landslidecore/proto/tendermint/crypto"
+)
+
+const (
+	PubKeyName = "tendermint/PubKeyBls12_381"
+	// PubKeySize is the size, in bytes, of compressed public keys, as
+	// Avalanche validators register them on the P-Chain.
+	PubKeySize = bls.PublicKeyLen
+	// SignatureSize is the size, in bytes, of compressed signatures.
+	SignatureSize = bls.SignatureLen
+
+	KeyType = "bls12_381"
+)
+
+// The keys are registered with the codec by importing this package, so that
+// only the importers of the package depend on cgo.
+func init() {
+	tmjson.RegisterType(PubKey{}, PubKeyName)
+	encoding.RegisterPubKeyCodec(pubKeyToProto, pubKeyFromProto)
+}
+
+func pubKeyToProto(k crypto.PubKey) (pc.PublicKey, bool) {
+	pk, ok := k.(PubKey)
+	if !ok {
+		return pc.PublicKey{}, false
+	}
+	return pc.PublicKey{
+		Sum: &pc.PublicKey_Bls12381{
+			Bls12381: pk,
+		},
+	}, true
+}
+
+func pubKeyFromProto(k pc.PublicKey) (crypto.PubKey, bool, error) {
+	kp, ok := k.Sum.(*pc.PublicKey_Bls12381)
+	if !ok {
+		return nil, false, nil
+	}
+	if len(kp.Bls12381) != PubKeySize {
+		return nil, true, fmt.Errorf("invalid size for PubKeyBls12_381. Got %d, expected %d",
+			len(kp.Bls12381), PubKeySize)
+	}
+	pk := make(PubKey, PubKeySize)
+	copy(pk, kp.Bls12381)
+	return pk, true, nil
+}
+
+var _ crypto.PubKey = PubKey{}
+
+// PubKey implements crypto.PubKey for the BLS12-381 keys of Avalanche
+// validators. There is no PrivKey: the VM only mirrors the keys of the
+// validators, which sign with avalanchego.
+type PubKey []byte
+
+// Address is the SHA256-20 of the raw pubkey bytes.
+func (pubKey PubKey) Address() crypto.Address {
+	if len(pubKey) != PubKeySize {
+		panic("pubkey is incorrect size")
+	}
+	return crypto.Address(tmhash.SumTruncated(pubKey))
+}
+
+// Bytes returns the PubKey byte format.
+func (pubKey PubKey) Bytes() []byte {
+	return []byte(pubKey)
+}
+
+func (pubKey PubKey) VerifySignature(msg []byte, sig []byte) bool {
+	if len(sig) != SignatureSize {
+		return false
+	}
+	pk, err := bls.PublicKeyFromBytes(pubKey)
+	if err != nil {
+		return false
+	}
+	signature, err := bls.SignatureFromBytes(sig)
+	if err != nil {
+		return false
+	}
+	return bls.Verify(pk, signature, msg)
+}
+
+func (pubKey PubKey) String() string {
+	return fmt.Sprintf("PubKeyBls12_381{%X}", []byte(pubKey))
+}
+
+func (pubKey PubKey) Type() string {
+	return KeyType
+}
+
+func (pubKey PubKey) Equals(other crypto.PubKey) bool {
+	if otherBls, ok := other.(PubKey); ok {
+		return bytes.Equal(pubKey[:], otherBls[:])
+	}
+	return false
+}
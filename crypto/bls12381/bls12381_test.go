@@ -0,0 +1,26 @@
+package bls12381_test
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/crypto/bls12381"
+)
+
+func TestSignAndValidateBls12381(t *testing.T) {
+	sk, err := bls.NewSecretKey()
+	require.NoError(t, err)
+	pubKey := bls12381.PubKey(bls.PublicKeyToBytes(bls.PublicFromSecretKey(sk)))
+	assert.Len(t, pubKey.Address(), 20)
+
+	msg := []byte("message")
+	sig := bls.SignatureToBytes(bls.Sign(sk, msg))
+	assert.True(t, pubKey.VerifySignature(msg, sig))
+
+	// mutate the signature, just one bit
+	sig[7] ^= byte(0x01)
+	assert.False(t, pubKey.VerifySignature(msg, sig))
+}
@@ -14,6 +14,27 @@ func init() {
 	json.RegisterType((*pc.PublicKey)(nil), "tendermint.crypto.PublicKey")
 	json.RegisterType((*pc.PublicKey_Ed25519)(nil), "tendermint.crypto.PublicKey_Ed25519")
 	json.RegisterType((*pc.PublicKey_Secp256K1)(nil), "tendermint.crypto.PublicKey_Secp256K1")
+	json.RegisterType((*pc.PublicKey_Bls12381)(nil), "tendermint.crypto.PublicKey_Bls12381")
+}
+
+// pubKeyCodec converts a key type registered with RegisterPubKeyCodec.
+type pubKeyCodec struct {
+	toProto   func(crypto.PubKey) (pc.PublicKey, bool)
+	fromProto func(pc.PublicKey) (crypto.PubKey, bool, error)
+}
+
+var pubKeyCodecs []pubKeyCodec
+
+// RegisterPubKeyCodec registers the conversions of a key type which isn't
+// built into the codec, so that the codec doesn't depend on its package:
+// bls12381 registers its keys this way, as it needs cgo. toProto and
+// fromProto return false for the keys of other types. It must be called from
+// an init function.
+func RegisterPubKeyCodec(
+	toProto func(crypto.PubKey) (pc.PublicKey, bool),
+	fromProto func(pc.PublicKey) (crypto.PubKey, bool, error),
+) {
+	pubKeyCodecs = append(pubKeyCodecs, pubKeyCodec{toProto: toProto, fromProto: fromProto})
 }
 
 // PubKeyToProto takes crypto.PubKey and transforms it to a protobuf Pubkey
@@ -33,6 +54,11 @@ func PubKeyToProto(k crypto.PubKey) (pc.PublicKey, error) {
 			},
 		}
 	default:
+		for _, codec := range pubKeyCodecs {
+			if kp, ok := codec.toProto(k); ok {
+				return kp, nil
+			}
+		}
 		return kp, fmt.Errorf("toproto: key type %v is not supported", k)
 	}
 	return kp, nil
@@ -58,6 +84,11 @@ func PubKeyFromProto(k pc.PublicKey) (crypto.PubKey, error) {
 		copy(pk, k.Secp256K1)
 		return pk, nil
 	default:
+		for _, codec := range pubKeyCodecs {
+			if pk, ok, err := codec.fromProto(pc.PublicKey{Sum: k}); ok {
+				return pk, err
+			}
+		}
 		return nil, fmt.Errorf("fromproto: key type %v is not supported", k)
 	}
 }
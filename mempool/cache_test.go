@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -36,6 +37,21 @@ func TestCacheRemove(t *testing.T) {
 	}
 }
 
+func TestCacheTTL(t *testing.T) {
+	cache := newMapTxCache(100)
+	cache.ttl = 50 * time.Millisecond
+
+	tx := []byte{0x01}
+	require.True(t, cache.Push(tx))
+	require.False(t, cache.Push(tx))
+
+	// the tx is seen anew once it expired, and is then cached again
+	time.Sleep(60 * time.Millisecond)
+	require.True(t, cache.Push(tx))
+	require.False(t, cache.Push(tx))
+	require.Equal(t, 1, cache.Len())
+}
+
 func TestCacheAfterUpdate(t *testing.T) {
 	app := kvstore.NewApplication()
 	cc := proxy.NewLocalClientCreator(app)
@@ -83,7 +99,7 @@ func TestCacheAfterUpdate(t *testing.T) {
 			require.NotEqual(t, len(tc.txsInCache), counter,
 				"cache larger than expected on testcase %d", tcIndex)
 
-			nodeVal := node.Value.([sha256.Size]byte)
+			nodeVal := node.Value.(*cacheEntry).txHash
 			expectedBz := sha256.Sum256([]byte{byte(tc.txsInCache[len(tc.txsInCache)-counter-1])})
 			// Reference for reading the errors:
 			// >>> sha256('\x00').hexdigest()
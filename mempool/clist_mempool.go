@@ -3,10 +3,15 @@ package mempool
 import (
 	"bytes"
 	"container/list"
+	"context"
 	"crypto/sha256"
 	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	abci "github.com/consideritdone/landslidecore/abci/types"
 	cfg "github.com/consideritdone/landslidecore/config"
@@ -16,7 +21,6 @@ import (
 	tmmath "github.com/consideritdone/landslidecore/libs/math"
 	tmos "github.com/consideritdone/landslidecore/libs/os"
 	tmsync "github.com/consideritdone/landslidecore/libs/sync"
-	"github.com/consideritdone/landslidecore/p2p"
 	"github.com/consideritdone/landslidecore/proxy"
 	"github.com/consideritdone/landslidecore/types"
 )
@@ -24,6 +28,54 @@ import (
 // TxKeySize is the size of the transaction key index
 const TxKeySize = sha256.Size
 
+// SenderAttributeKey is the key of the CheckTx event attributes through which
+// the app reports the sender accounts of a tx.
+const SenderAttributeKey = "sender"
+
+// SequenceAttributeKey is the key of the CheckTx event attribute through which
+// the app reports the sequence number, in decimal, of a tx of the sender
+// reported in the same event. The txs of a sender are reaped in the order of
+// their sequences, and the txs of the same sender and sequence conflict.
+const SequenceAttributeKey = "sequence"
+
+// ConflictAttributeKey is the key of the CheckTx event attributes through which
+// the app reports conflict keys of a tx, e.g. the coins it spends: the txs
+// sharing a conflict key conflict. Only one of conflicting txs is kept in the
+// mempool: a tx replaces the txs it conflicts with if it has a higher priority
+// than all of them, and is rejected otherwise.
+const ConflictAttributeKey = "conflict"
+
+const (
+	// Codespace is the codespace of the CheckTx responses of the txs the app
+	// accepted but the mempool rejected.
+	Codespace = "mempool"
+	// CodeTypeReplacementUnderpriced is the code of the CheckTx response of a
+	// tx conflicting with a tx in the mempool of the same or a higher
+	// priority.
+	CodeTypeReplacementUnderpriced uint32 = 1
+	// CodeTypeMempoolFull is the code of the CheckTx response of a tx which
+	// doesn't fit in the full mempool, there not being enough txs of a lower
+	// priority to evict.
+	CodeTypeMempoolFull uint32 = 2
+	// CodeTypePostCheckFailed is the code of the CheckTx response of a tx
+	// rejected by the PostCheckFunc, e.g. wanting more gas than a block has.
+	CodeTypePostCheckFailed uint32 = 3
+)
+
+// The reasons a transaction is evicted from the mempool before it expires,
+// see WithEvictedCallback.
+const (
+	// EvictedLowPriority is the reason of the transactions evicted to make room
+	// for a transaction of a higher priority in the full mempool.
+	EvictedLowPriority = "low_priority"
+	// EvictedReplaced is the reason of the transactions replaced by a
+	// conflicting transaction of a higher priority, see ConflictAttributeKey.
+	EvictedReplaced = "replaced"
+	// EvictedInvalidated is the reason of the transactions the app rejected
+	// when rechecking them after a block.
+	EvictedInvalidated = "invalidated"
+)
+
 var newline = []byte("\n")
 
 //--------------------------------------------------------------------------------
@@ -33,6 +85,12 @@ var newline = []byte("\n")
 // CheckTx abci message before the transaction is added to the pool. The
 // mempool uses a concurrent list structure for storing transactions that can
 // be efficiently accessed by multiple concurrent readers.
+//
+// The list keeps the transactions in arrival order. They are reaped in the
+// order of the ReapStrategy, by default by the priority the app returned from
+// CheckTx, and when the mempool is full the lowest priority transactions are
+// evicted to make room for higher priority ones. The transactions of the
+// lanes of WithLanes are held and evicted apart from the others.
 type CListMempool struct {
 	// Atomic integers
 	height   int64 // the last block Update()'d to
@@ -50,28 +108,69 @@ type CListMempool struct {
 	preCheck  PreCheckFunc
 	postCheck PostCheckFunc
 
+	// called with the txs evicted by the TTL of the mempool
+	onExpired func(tx types.Tx, height int64)
+	// called with the txs evicted for another reason
+	onEvicted func(tx types.Tx, reason string)
+
 	wal          *auto.AutoFile // a log of mempool txs
 	txs          *clist.CList   // concurrent linked-list of good txs
 	proxyAppConn proxy.AppConnMempool
 
+	// checkTxConns are the connections, besides proxyAppConn, the txs are
+	// checked on for the first time, see WithCheckTxConns.
+	checkTxConns []proxy.AppConnMempool
+	// txSender returns the sender of a tx, whose txs are checked on the same
+	// connection, see WithTxSender.
+	txSender func(tx types.Tx) string
+	// resMtx serializes the handling of the CheckTx responses, which come from
+	// several connections with checkTxConns.
+	resMtx sync.Mutex
+
 	// Track whether we're rechecking txs.
 	// These are not protected by a mutex and are expected to be mutated in
 	// serial (ie. by abci responses which are called in serial).
 	recheckCursor *clist.CElement // next expected response
 	recheckEnd    *clist.CElement // re-checking stops here
+	// the key of the last tx rechecked, from which the next recheck goes on
+	// when RecheckBatchSize is set
+	recheckLast *[TxKeySize]byte
+
+	// recheckDone is closed once the recheck in progress is done. It is nil
+	// when there is no recheck in progress.
+	recheckMtx  sync.Mutex
+	recheckDone chan struct{}
+	// when the recheck in progress started
+	recheckStart time.Time
 
 	// Map for quick access to txs to record sender in CheckTx.
 	// txsMap: txKey -> CElement
 	txsMap sync.Map
 
+	// txs by their conflict keys, see txConflicts.
+	conflictsMtx sync.Mutex
+	conflicts    map[string]*clist.CElement
+
 	// Keep a cache of already-seen txs.
 	// This reduces the pressure on the proxyApp.
 	cache txCache
 
+	// limits the txs of each origin, nil without limits in the config
+	originLimiter *originLimiter
+
+	// the lanes of WithLanes by name, and their names in order
+	lanes     map[string]Lane
+	laneNames []string
+	// the usage of each lane, the default one included
+	lanesMtx sync.Mutex
+	usage    map[string]laneUsage
+
 	logger log.Logger
 
 	metrics *Metrics
 
+	reapStrategy ReapStrategy
+
 	blockReadyNotifier BlockReadyNotifier
 }
 
@@ -92,22 +191,35 @@ func NewCListMempool(
 		config:             config,
 		proxyAppConn:       proxyAppConn,
 		txs:                clist.New(),
+		conflicts:          make(map[string]*clist.CElement),
+		usage:              make(map[string]laneUsage),
 		height:             height,
 		recheckCursor:      nil,
 		recheckEnd:         nil,
 		logger:             log.NewNopLogger(),
 		metrics:            NopMetrics(),
+		reapStrategy:       PriorityReapStrategy{},
 		blockReadyNotifier: blockReadyNotifier,
 	}
 	if config.CacheSize > 0 {
-		mempool.cache = newMapTxCache(config.CacheSize)
+		cache := newMapTxCache(config.CacheSize)
+		cache.ttl = config.CacheTTL
+		mempool.cache = cache
 	} else {
 		mempool.cache = nopTxCache{}
 	}
+	if config.OriginRate > 0 || config.OriginMaxTxs > 0 {
+		mempool.originLimiter = newOriginLimiter(config.OriginRate, config.OriginBurst, config.OriginMaxTxs)
+	}
 	proxyAppConn.SetResponseCallback(mempool.globalCb)
 	for _, option := range options {
 		option(mempool)
 	}
+	for _, conn := range mempool.checkTxConns {
+		// the txs are only rechecked on proxyAppConn, the responses are handled
+		// by the callbacks of their requests, see reqResCb
+		conn.SetResponseCallback(func(*abci.Request, *abci.Response) {})
+	}
 	return mempool
 }
 
@@ -135,6 +247,44 @@ func WithPostCheck(f PostCheckFunc) CListMempoolOption {
 	return func(mem *CListMempool) { mem.postCheck = f }
 }
 
+// WithExpiredCallback sets a callback called by Update with each transaction
+// evicted for outliving the TTL of the mempool, see TTLDuration and
+// TTLNumBlocks of the config, and the height it was updated to.
+func WithExpiredCallback(cb func(tx types.Tx, height int64)) CListMempoolOption {
+	return func(mem *CListMempool) { mem.onExpired = cb }
+}
+
+// WithEvictedCallback sets a callback called with each transaction evicted
+// before it expired and the reason, one of the Evicted* reasons. It isn't
+// called for the transactions included in a block.
+func WithEvictedCallback(cb func(tx types.Tx, reason string)) CListMempoolOption {
+	return func(mem *CListMempool) { mem.onEvicted = cb }
+}
+
+// WithReapStrategy sets the order in which the transactions are reaped,
+// PriorityReapStrategy by default.
+func WithReapStrategy(strategy ReapStrategy) CListMempoolOption {
+	return func(mem *CListMempool) { mem.reapStrategy = strategy }
+}
+
+// WithCheckTxConns sets connections to the app, besides the one the mempool
+// was created with, over which the transactions are spread by sender to be
+// checked for the first time, so that the app may check them in parallel, see
+// CheckTxBatch. The transactions of a sender are checked on the same
+// connection, in the order they were submitted, see WithTxSender, without
+// which the connections are left unused. The rechecks stay on the connection
+// the mempool was created with.
+func WithCheckTxConns(conns ...proxy.AppConnMempool) CListMempoolOption {
+	return func(mem *CListMempool) { mem.checkTxConns = conns }
+}
+
+// WithTxSender sets the function returning the sender of a transaction before
+// it is checked, so that the transactions of a sender are checked in order
+// with WithCheckTxConns.
+func WithTxSender(f func(tx types.Tx) string) CListMempoolOption {
+	return func(mem *CListMempool) { mem.txSender = f }
+}
+
 // WithMetrics sets the metrics.
 func WithMetrics(metrics *Metrics) CListMempoolOption {
 	return func(mem *CListMempool) { mem.metrics = metrics }
@@ -187,8 +337,16 @@ func (mem *CListMempool) TxsBytes() int64 {
 	return atomic.LoadInt64(&mem.txsBytes)
 }
 
+// FlushAppConn flushes the connections to the app, the responses to the
+// requests sent before being handled once it returns.
+//
 // Lock() must be help by the caller during execution.
 func (mem *CListMempool) FlushAppConn() error {
+	for _, conn := range mem.checkTxConns {
+		if err := conn.FlushSync(); err != nil {
+			return err
+		}
+	}
 	return mem.proxyAppConn.FlushSync()
 }
 
@@ -209,6 +367,29 @@ func (mem *CListMempool) Flush() {
 		mem.txsMap.Delete(key)
 		return true
 	})
+
+	mem.conflictsMtx.Lock()
+	mem.conflicts = make(map[string]*clist.CElement)
+	mem.conflictsMtx.Unlock()
+
+	if mem.originLimiter != nil {
+		mem.originLimiter.reset()
+	}
+
+	mem.lanesMtx.Lock()
+	mem.usage = make(map[string]laneUsage)
+	mem.lanesMtx.Unlock()
+}
+
+// FlushCache empties the cache of seen transactions, so that those submitted
+// again are checked again rather than rejected with ErrTxInCache, and returns
+// the number of transactions it held.
+//
+// Safe for concurrent use by multiple goroutines.
+func (mem *CListMempool) FlushCache() int {
+	n := mem.cache.Len()
+	mem.cache.Reset()
+	return n
 }
 
 // TxsFront returns the first transaction in the ordered list for peer
@@ -220,6 +401,92 @@ func (mem *CListMempool) TxsFront() *clist.CElement {
 	return mem.txs.Front()
 }
 
+// OldestTxTime returns the time the oldest transaction in the mempool was
+// added, or false if the mempool is empty.
+//
+// Safe for concurrent use by multiple goroutines.
+func (mem *CListMempool) OldestTxTime() (time.Time, bool) {
+	e := mem.txs.Front()
+	if e == nil {
+		return time.Time{}, false
+	}
+	return e.Value.(*mempoolTx).timestamp, true
+}
+
+// TxSizes calls [fn] with the size of each transaction in the mempool, oldest
+// first, without copying the transactions or taking the mempool lock.
+//
+// Safe for concurrent use by multiple goroutines.
+func (mem *CListMempool) TxSizes(fn func(size int)) {
+	for e := mem.txs.Front(); e != nil; e = e.Next() {
+		fn(len(e.Value.(*mempoolTx).tx))
+	}
+}
+
+// RecheckDone returns a channel which is closed once the recheck of the
+// transactions left in the mempool by the last Update is done. The channel is
+// already closed if there is no recheck in progress.
+//
+// Safe for concurrent use by multiple goroutines.
+func (mem *CListMempool) RecheckDone() <-chan struct{} {
+	mem.recheckMtx.Lock()
+	defer mem.recheckMtx.Unlock()
+
+	if mem.recheckDone == nil {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	return mem.recheckDone
+}
+
+// WalkTxs calls [fn] with each transaction in the mempool, oldest first, and
+// the events CheckTx returned for it, until [fn] returns false. The mempool
+// lock isn't taken.
+//
+// Safe for concurrent use by multiple goroutines.
+func (mem *CListMempool) WalkTxs(fn func(tx types.Tx, events []abci.Event) bool) {
+	for e := mem.txs.Front(); e != nil; e = e.Next() {
+		memTx := e.Value.(*mempoolTx)
+		if !fn(memTx.tx, memTx.events) {
+			return
+		}
+	}
+}
+
+// PendingTx returns the position in the mempool of the transaction with the
+// given key, 0 being the oldest, and the time it was added. It returns false
+// if the transaction isn't in the mempool.
+//
+// Safe for concurrent use by multiple goroutines.
+func (mem *CListMempool) PendingTx(txKey [TxKeySize]byte) (int, time.Time, bool) {
+	elem, ok := mem.txsMap.Load(txKey)
+	if !ok {
+		return 0, time.Time{}, false
+	}
+	position := 0
+	for e := mem.txs.Front(); e != nil; e = e.Next() {
+		if e == elem.(*clist.CElement) {
+			return position, e.Value.(*mempoolTx).timestamp, true
+		}
+		position++
+	}
+	// removed while walking the list
+	return 0, time.Time{}, false
+}
+
+// TxByKey returns the transaction with the given key, if it is in the
+// mempool.
+//
+// Safe for concurrent use by multiple goroutines.
+func (mem *CListMempool) TxByKey(txKey [TxKeySize]byte) (types.Tx, bool) {
+	elem, ok := mem.txsMap.Load(txKey)
+	if !ok {
+		return nil, false
+	}
+	return elem.(*clist.CElement).Value.(*mempoolTx).tx, true
+}
+
 // TxsWaitChan returns a channel to wait on transactions. It will be closed
 // once the mempool is not empty (ie. the internal `mem.txs` has at least one
 // element)
@@ -238,22 +505,132 @@ func (mem *CListMempool) TxsWaitChan() <-chan struct{} {
 //
 // Safe for concurrent use by multiple goroutines.
 func (mem *CListMempool) CheckTx(tx types.Tx, cb func(*abci.Response), txInfo TxInfo) error {
-	mem.updateMtx.RLock()
+	conn, pooled := mem.checkTxConn(tx)
+	mem.rLockForConns(pooled)
 	// use defer to unlock mutex because application (*local client*) might panic
 	defer mem.updateMtx.RUnlock()
 
-	txSize := len(tx)
+	return mem.checkTxOn(conn, tx, cb, txInfo)
+}
 
-	if err := mem.isFull(txSize); err != nil {
+// CheckTxContext is CheckTx, but gives up on [tx], returning the error of
+// [ctx], if [ctx] is done while waiting on Update() or Reap(), e.g. as the
+// request which submitted it was canceled. Once sent to the app, the tx is
+// checked regardless of [ctx].
+//
+// Safe for concurrent use by multiple goroutines.
+func (mem *CListMempool) CheckTxContext(ctx context.Context, tx types.Tx, cb func(*abci.Response), txInfo TxInfo) error {
+	if ctx.Done() == nil {
+		return mem.CheckTx(tx, cb, txInfo)
+	}
+	if err := ctx.Err(); err != nil {
 		return err
 	}
 
+	conn, pooled := mem.checkTxConn(tx)
+	locked := make(chan struct{})
+	go func() {
+		mem.rLockForConns(pooled)
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-ctx.Done():
+		// release the lock once acquired
+		go func() {
+			<-locked
+			mem.updateMtx.RUnlock()
+		}()
+		return ctx.Err()
+	}
+	// use defer to unlock mutex because application (*local client*) might panic
+	defer mem.updateMtx.RUnlock()
+
+	return mem.checkTxOn(conn, tx, cb, txInfo)
+}
+
+// CheckTxBatch executes CheckTx for every tx in txs under a single
+// acquisition of the update lock. With WithCheckTxConns, the txs are checked
+// by a worker per connection, each checking the txs of its senders in order.
+// The errors are returned in the order of txs, a nil error meaning the tx was
+// sent to the app.
+//
+// Safe for concurrent use by multiple goroutines.
+func (mem *CListMempool) CheckTxBatch(txs types.Txs, cb func(int, *abci.Response), txInfo TxInfo) []error {
+	// the indexes of the txs checked on each connection, in order
+	var (
+		conns  []proxy.AppConnMempool
+		lanes  = make(map[proxy.AppConnMempool][]int)
+		pooled bool
+	)
+	for i, tx := range txs {
+		conn, onPool := mem.checkTxConn(tx)
+		pooled = pooled || onPool
+		if _, ok := lanes[conn]; !ok {
+			conns = append(conns, conn)
+		}
+		lanes[conn] = append(lanes[conn], i)
+	}
+
+	mem.rLockForConns(pooled)
+	// use defer to unlock mutex because application (*local client*) might panic
+	defer mem.updateMtx.RUnlock()
+
+	errs := make([]error, len(txs))
+	check := func(conn proxy.AppConnMempool) {
+		for _, i := range lanes[conn] {
+			var txCb func(*abci.Response)
+			if cb != nil {
+				i := i
+				txCb = func(res *abci.Response) { cb(i, res) }
+			}
+			errs[i] = mem.checkTxOn(conn, txs[i], txCb, txInfo)
+		}
+	}
+	if len(conns) == 1 {
+		check(conns[0])
+		return errs
+	}
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		wg.Add(1)
+		go func(conn proxy.AppConnMempool) {
+			defer wg.Done()
+			check(conn)
+		}(conn)
+	}
+	wg.Wait()
+	return errs
+}
+
+// checkTxOn sends tx to the app for checking on [conn].
+// NOTE: the caller must hold updateMtx.
+func (mem *CListMempool) checkTxOn(
+	conn proxy.AppConnMempool,
+	tx types.Tx,
+	cb func(*abci.Response),
+	txInfo TxInfo,
+) error {
+	txSize := len(tx)
+
+	// Whether there is room for the tx is only known once the app returned its
+	// priority, see makeRoom, but a tx larger than the mempool never fits.
+	if int64(txSize) > mem.config.MaxTxsBytes {
+		mem.rejected(RejectedFull)
+		return ErrMempoolIsFull{
+			mem.Size(), mem.config.Size,
+			mem.TxsBytes(), mem.config.MaxTxsBytes,
+		}
+	}
+
 	if txSize > mem.config.MaxTxBytes {
+		mem.rejected(RejectedTooLarge)
 		return ErrTxTooLarge{mem.config.MaxTxBytes, txSize}
 	}
 
 	if mem.preCheck != nil {
 		if err := mem.preCheck(tx); err != nil {
+			mem.rejected(RejectedPreCheck)
 			return ErrPreCheck{err}
 		}
 	}
@@ -271,11 +648,17 @@ func (mem *CListMempool) CheckTx(tx types.Tx, cb func(*abci.Response), txInfo Tx
 	}
 
 	// NOTE: proxyAppConn may error if tx buffer is full
-	if err := mem.proxyAppConn.Error(); err != nil {
+	if err := conn.Error(); err != nil {
 		return err
 	}
 
-	if !mem.cache.Push(tx) {
+	// the tx may still be in the mempool once the cache was flushed or its
+	// entry expired
+	_, inMempool := mem.txsMap.Load(TxKey(tx))
+	if inMempool || !mem.cache.Push(tx) {
+		mem.metrics.CacheHits.Add(1)
+		mem.rejected(RejectedDuplicate)
+
 		// Record a new sender for a tx we've already seen.
 		// Note it's possible a tx is still in the cache but no longer in the mempool
 		// (eg. after committing a block, txs are removed from mempool but not cache),
@@ -291,12 +674,69 @@ func (mem *CListMempool) CheckTx(tx types.Tx, cb func(*abci.Response), txInfo Tx
 		return ErrTxInCache
 	}
 
-	reqRes := mem.proxyAppConn.CheckTxAsync(abci.RequestCheckTx{Tx: tx})
-	reqRes.SetCallback(mem.reqResCb(tx, txInfo.SenderID, txInfo.SenderP2PID, cb))
+	mem.metrics.CacheMisses.Add(1)
+
+	// the tx is counted for its origin until it is rejected or leaves the
+	// mempool, see resCbFirstTime and removeTx
+	if mem.originLimiter != nil {
+		if err := mem.originLimiter.reserve(txInfo.Origin, time.Now()); err != nil {
+			// remove from cache (the origin may submit it again later)
+			mem.cache.Remove(tx)
+			mem.rejected(RejectedOriginLimited)
+			return err
+		}
+	}
+
+	reqRes := conn.CheckTxAsync(abci.RequestCheckTx{Tx: tx})
+	reqRes.SetCallback(mem.reqResCb(tx, txInfo, cb))
 
 	return nil
 }
 
+// checkTxConn returns the connection [tx] is checked on, the same for all the
+// txs of its sender, and whether it is one of checkTxConns. Without
+// WithTxSender, the sender of a tx isn't known and all the txs are checked on
+// proxyAppConn, so that they stay in order.
+func (mem *CListMempool) checkTxConn(tx types.Tx) (proxy.AppConnMempool, bool) {
+	if len(mem.checkTxConns) == 0 || mem.txSender == nil {
+		return mem.proxyAppConn, false
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(mem.txSender(tx)))
+	i := h.Sum64() % uint64(len(mem.checkTxConns)+1)
+	if i == 0 {
+		return mem.proxyAppConn, false
+	}
+	return mem.checkTxConns[i-1], true
+}
+
+// rLockForConns takes updateMtx.RLock, once no recheck is in progress if
+// [pooled], i.e. if txs are checked on checkTxConns: the responses to the
+// recheck come on proxyAppConn, those on the other connections mustn't come
+// in between, see FlushAppConn, which is called before Update starts the
+// recheck. The recheck is waited for without the lock, so that Update isn't
+// held up meanwhile.
+func (mem *CListMempool) rLockForConns(pooled bool) {
+	for {
+		if pooled {
+			<-mem.RecheckDone()
+		}
+		mem.updateMtx.RLock()
+		if !pooled || !mem.rechecking() {
+			return
+		}
+		// an Update started another recheck in between
+		mem.updateMtx.RUnlock()
+	}
+}
+
+// rechecking returns whether a recheck is in progress.
+func (mem *CListMempool) rechecking() bool {
+	mem.recheckMtx.Lock()
+	defer mem.recheckMtx.Unlock()
+	return mem.recheckDone != nil
+}
+
 // Global callback that will be called after every ABCI response.
 // Having a single global callback avoids needing to set a callback for each request.
 // However, processing the checkTx response requires the peerID (so we can track which txs we heard from who),
@@ -311,11 +751,14 @@ func (mem *CListMempool) globalCb(req *abci.Request, res *abci.Response) {
 		return
 	}
 
+	mem.resMtx.Lock()
+	defer mem.resMtx.Unlock()
+
 	mem.metrics.RecheckTimes.Add(1)
 	mem.resCbRecheck(req, res)
 
 	// update metrics
-	mem.metrics.Size.Set(float64(mem.Size()))
+	mem.updateSizeMetrics()
 }
 
 // Request specific callback that should be set on individual reqRes objects
@@ -329,8 +772,7 @@ func (mem *CListMempool) globalCb(req *abci.Request, res *abci.Response) {
 // Used in CheckTx to record PeerID who sent us the tx.
 func (mem *CListMempool) reqResCb(
 	tx []byte,
-	peerID uint16,
-	peerP2PID p2p.ID,
+	txInfo TxInfo,
 	externalCb func(*abci.Response),
 ) func(res *abci.Response) {
 	return func(res *abci.Response) {
@@ -339,10 +781,12 @@ func (mem *CListMempool) reqResCb(
 			panic("recheck cursor is not nil in reqResCb")
 		}
 
-		mem.resCbFirstTime(tx, peerID, peerP2PID, res)
+		mem.resMtx.Lock()
+		mem.resCbFirstTime(tx, txInfo, res)
 
 		// update metrics
-		mem.metrics.Size.Set(float64(mem.Size()))
+		mem.updateSizeMetrics()
+		mem.resMtx.Unlock()
 
 		// passed in by the caller of CheckTx, eg. the RPC
 		if externalCb != nil {
@@ -357,8 +801,17 @@ func (mem *CListMempool) reqResCb(
 func (mem *CListMempool) addTx(memTx *mempoolTx) {
 	e := mem.txs.PushBack(memTx)
 	mem.txsMap.Store(TxKey(memTx.tx), e)
+	if len(memTx.conflicts) > 0 {
+		mem.conflictsMtx.Lock()
+		for _, key := range memTx.conflicts {
+			mem.conflicts[key] = e
+		}
+		mem.conflictsMtx.Unlock()
+	}
 	atomic.AddInt64(&mem.txsBytes, int64(len(memTx.tx)))
+	mem.addLaneUsage(memTx.lane, 1, int64(len(memTx.tx)))
 	mem.metrics.TxSizeBytes.Observe(float64(len(memTx.tx)))
+	mem.metrics.AddedTxs.Add(1)
 }
 
 // Called from:
@@ -368,7 +821,21 @@ func (mem *CListMempool) removeTx(tx types.Tx, elem *clist.CElement, removeFromC
 	mem.txs.Remove(elem)
 	elem.DetachPrev()
 	mem.txsMap.Delete(TxKey(tx))
+	if conflicts := elem.Value.(*mempoolTx).conflicts; len(conflicts) > 0 {
+		mem.conflictsMtx.Lock()
+		for _, key := range conflicts {
+			if mem.conflicts[key] == elem {
+				delete(mem.conflicts, key)
+			}
+		}
+		mem.conflictsMtx.Unlock()
+	}
 	atomic.AddInt64(&mem.txsBytes, int64(-len(tx)))
+	mem.addLaneUsage(elem.Value.(*mempoolTx).lane, -1, int64(-len(tx)))
+	mem.metrics.TxAgeSeconds.Observe(time.Since(elem.Value.(*mempoolTx).timestamp).Seconds())
+	if mem.originLimiter != nil {
+		mem.originLimiter.release(elem.Value.(*mempoolTx).origin)
+	}
 
 	if removeFromCache {
 		mem.cache.Remove(tx)
@@ -385,32 +852,166 @@ func (mem *CListMempool) RemoveTxByKey(txKey [TxKeySize]byte, removeFromCache bo
 	}
 }
 
-func (mem *CListMempool) isFull(txSize int) error {
+// isFull returns ErrMempoolIsFull if a tx of [txSize] bytes doesn't fit in
+// its [lane] once the [replaced] txs are removed.
+func (mem *CListMempool) isFull(lane string, txSize int, replaced map[*clist.CElement]struct{}) error {
 	var (
-		memSize  = mem.Size()
-		txsBytes = mem.TxsBytes()
+		memSize, txsBytes    = mem.laneUsage(lane)
+		maxSize, maxTxsBytes = mem.laneCapacity(lane)
 	)
 
-	if memSize >= mem.config.Size || int64(txSize)+txsBytes > mem.config.MaxTxsBytes {
+	size, newTxsBytes := mem.sizeAfter(lane, txSize, replaced)
+	if size >= maxSize || newTxsBytes > maxTxsBytes {
 		return ErrMempoolIsFull{
-			memSize, mem.config.Size,
-			txsBytes, mem.config.MaxTxsBytes,
+			memSize, maxSize,
+			txsBytes, maxTxsBytes,
 		}
 	}
 
 	return nil
 }
 
+// sizeAfter returns the number of txs and the bytes [lane] would hold with a
+// tx of [txSize] bytes, once the [replaced] txs are removed.
+func (mem *CListMempool) sizeAfter(lane string, txSize int, replaced map[*clist.CElement]struct{}) (int, int64) {
+	size, txsBytes := mem.laneUsage(lane)
+	txsBytes += int64(txSize)
+	for e := range replaced {
+		if memTx := e.Value.(*mempoolTx); memTx.lane == lane {
+			size--
+			txsBytes -= int64(len(memTx.tx))
+		}
+	}
+	return size, txsBytes
+}
+
+// makeRoom evicts the transactions of [lane] of lower priority than
+// [priority] needed to make room for a tx of [txSize] bytes replacing the
+// [replaced] txs, the lowest priority first and, among equal priorities, the
+// newest first. Nothing is evicted if that isn't enough, and the error of
+// isFull is returned. The [replaced] txs are left to the caller to remove.
+func (mem *CListMempool) makeRoom(lane string, txSize int, priority int64, replaced map[*clist.CElement]struct{}) error {
+	err := mem.isFull(lane, txSize, replaced)
+	// txs can't be removed from under the recheck cursor
+	if err == nil || mem.recheckCursor != nil {
+		return err
+	}
+
+	var victims []*clist.CElement
+	for e := mem.txs.Back(); e != nil; e = e.Prev() {
+		if _, ok := replaced[e]; ok {
+			continue
+		}
+		if memTx := e.Value.(*mempoolTx); memTx.lane == lane && memTx.Priority() < priority {
+			victims = append(victims, e)
+		}
+	}
+	sort.SliceStable(victims, func(i, j int) bool {
+		return victims[i].Value.(*mempoolTx).Priority() < victims[j].Value.(*mempoolTx).Priority()
+	})
+
+	maxSize, maxTxsBytes := mem.laneCapacity(lane)
+	size, txsBytes := mem.sizeAfter(lane, txSize, replaced)
+	n := 0
+	for ; n < len(victims) && (size >= maxSize || txsBytes > maxTxsBytes); n++ {
+		size--
+		txsBytes -= int64(len(victims[n].Value.(*mempoolTx).tx))
+	}
+	if size >= maxSize || txsBytes > maxTxsBytes {
+		return err
+	}
+
+	for _, e := range victims[:n] {
+		memTx := e.Value.(*mempoolTx)
+		// remove from cache (it might fit later)
+		mem.removeTx(memTx.tx, e, true)
+		mem.metrics.EvictedTxs.Add(1)
+		mem.logger.Debug("evicted transaction",
+			"tx", txID(memTx.tx),
+			"priority", memTx.Priority(),
+			"newPriority", priority,
+		)
+		mem.txEvicted(memTx.tx, EvictedLowPriority)
+	}
+	return nil
+}
+
+// conflictingTxs returns the txs in the mempool sharing one of the
+// [conflicts] keys with a tx, which it replaces if [res] has a higher priority
+// than all of them. Otherwise it rejects the tx through [res], and returns
+// false.
+func (mem *CListMempool) conflictingTxs(
+	conflicts []string,
+	res *abci.ResponseCheckTx,
+) (map[*clist.CElement]struct{}, bool) {
+	mem.conflictsMtx.Lock()
+	defer mem.conflictsMtx.Unlock()
+
+	// a tx may conflict with another on several keys
+	replaced := make(map[*clist.CElement]struct{})
+	for _, key := range conflicts {
+		e, ok := mem.conflicts[key]
+		if !ok {
+			continue
+		}
+		if memTx := e.Value.(*mempoolTx); res.Priority <= memTx.Priority() {
+			res.Code = CodeTypeReplacementUnderpriced
+			res.Codespace = Codespace
+			res.Log = fmt.Sprintf("conflicts on %q with tx %X of priority %d, a replacement needs more",
+				key, memTx.tx.Hash(), memTx.Priority())
+			return nil, false
+		}
+		replaced[e] = struct{}{}
+	}
+	return replaced, true
+}
+
+// replaceTxs removes the [replaced] txs, the conflicting txs of [tx].
+func (mem *CListMempool) replaceTxs(tx types.Tx, replaced map[*clist.CElement]struct{}) {
+	for e := range replaced {
+		memTx := e.Value.(*mempoolTx)
+		// remove from cache (it may be submitted again with a higher priority)
+		mem.removeTx(memTx.tx, e, true)
+		mem.metrics.ReplacedTxs.Add(1)
+		mem.logger.Debug("replaced transaction", "tx", txID(memTx.tx), "by", txID(tx))
+		mem.txEvicted(memTx.tx, EvictedReplaced)
+	}
+}
+
+// rejected counts a transaction rejected for [reason].
+func (mem *CListMempool) rejected(reason string) {
+	mem.metrics.RejectedTxs.With("reason", reason).Add(1)
+}
+
+func (mem *CListMempool) updateSizeMetrics() {
+	mem.metrics.Size.Set(float64(mem.Size()))
+	mem.metrics.SizeBytes.Set(float64(mem.TxsBytes()))
+}
+
+func (mem *CListMempool) txEvicted(tx types.Tx, reason string) {
+	if mem.onEvicted != nil {
+		mem.onEvicted(tx, reason)
+	}
+}
+
 // callback, which is called after the app checked the tx for the first time.
 //
 // The case where the app checks the tx for the second and subsequent times is
 // handled by the resCbRecheck callback.
 func (mem *CListMempool) resCbFirstTime(
 	tx []byte,
-	peerID uint16,
-	peerP2PID p2p.ID,
+	txInfo TxInfo,
 	res *abci.Response,
 ) {
+	added := false
+	if mem.originLimiter != nil {
+		defer func() {
+			if !added {
+				mem.originLimiter.release(txInfo.Origin)
+			}
+		}()
+	}
+
 	switch r := res.Value.(type) {
 	case *abci.Response_CheckTx:
 		var postCheckErr error
@@ -418,22 +1019,49 @@ func (mem *CListMempool) resCbFirstTime(
 			postCheckErr = mem.postCheck(tx, r.CheckTx)
 		}
 		if (r.CheckTx.Code == abci.CodeTypeOK) && postCheckErr == nil {
+			sequence := txSequence(r.CheckTx.Events)
+			conflicts := txConflicts(r.CheckTx.Events, sequence)
+			lane := mem.txLane(r.CheckTx.Events)
+			replaced, ok := mem.conflictingTxs(conflicts, r.CheckTx)
+			if !ok {
+				// remove from cache (it may be submitted again with a higher priority)
+				mem.cache.Remove(tx)
+				mem.rejected(RejectedUnderpriced)
+				mem.logger.Debug("rejected underpriced replacement", "tx", txID(tx), "res", r)
+				return
+			}
+
 			// Check mempool isn't full again to reduce the chance of exceeding the
-			// limits.
-			if err := mem.isFull(len(tx)); err != nil {
+			// limits, evicting lower priority txs if it is. The conflicting txs
+			// are only replaced once the tx is known to fit.
+			if err := mem.makeRoom(lane, len(tx), r.CheckTx.Priority, replaced); err != nil {
 				// remove from cache (mempool might have a space later)
 				mem.cache.Remove(tx)
+				mem.rejected(RejectedFull)
 				mem.logger.Error(err.Error())
+				r.CheckTx.Code = CodeTypeMempoolFull
+				r.CheckTx.Codespace = Codespace
+				r.CheckTx.Log = err.Error()
 				return
 			}
+			mem.replaceTxs(tx, replaced)
 
 			memTx := &mempoolTx{
 				height:    mem.height,
 				gasWanted: r.CheckTx.GasWanted,
+				priority:  r.CheckTx.Priority,
 				tx:        tx,
+				timestamp: time.Now(),
+				accounts:  senderAccounts(r.CheckTx.Events),
+				sequence:  sequence,
+				conflicts: conflicts,
+				events:    r.CheckTx.Events,
+				origin:    txInfo.Origin,
+				lane:      lane,
 			}
-			memTx.senders.Store(peerID, true)
+			memTx.senders.Store(txInfo.SenderID, true)
 			mem.addTx(memTx)
+			added = true
 			mem.logger.Debug("added good transaction",
 				"tx", txID(tx),
 				"res", r,
@@ -447,8 +1075,16 @@ func (mem *CListMempool) resCbFirstTime(
 		} else {
 			// ignore bad transaction
 			mem.logger.Debug("rejected bad transaction",
-				"tx", txID(tx), "peerID", peerP2PID, "res", r, "err", postCheckErr)
+				"tx", txID(tx), "peerID", txInfo.SenderP2PID, "res", r, "err", postCheckErr)
 			mem.metrics.FailedTxs.Add(1)
+			if r.CheckTx.Code != abci.CodeTypeOK {
+				mem.rejected(RejectedApp)
+			} else {
+				mem.rejected(RejectedPostCheck)
+				r.CheckTx.Code = CodeTypePostCheckFailed
+				r.CheckTx.Codespace = Codespace
+				r.CheckTx.Log = postCheckErr.Error()
+			}
 			if !mem.config.KeepInvalidTxsInCache {
 				// remove from cache (it might be good later)
 				mem.cache.Remove(tx)
@@ -479,12 +1115,15 @@ func (mem *CListMempool) resCbRecheck(req *abci.Request, res *abci.Response) {
 			postCheckErr = mem.postCheck(tx, r.CheckTx)
 		}
 		if (r.CheckTx.Code == abci.CodeTypeOK) && postCheckErr == nil {
-			// Good, nothing to do.
+			// Good, the priority might have changed though.
+			atomic.StoreInt64(&memTx.priority, r.CheckTx.Priority)
 		} else {
 			// Tx became invalidated due to newly committed block.
 			mem.logger.Debug("tx is no longer valid", "tx", txID(tx), "res", r, "err", postCheckErr)
 			// NOTE: we remove tx from the cache because it might be good later
 			mem.removeTx(tx, mem.recheckCursor, !mem.config.KeepInvalidTxsInCache)
+			mem.metrics.InvalidatedTxs.Add(1)
+			mem.txEvicted(tx, EvictedInvalidated)
 		}
 		if mem.recheckCursor == mem.recheckEnd {
 			mem.recheckCursor = nil
@@ -494,6 +1133,12 @@ func (mem *CListMempool) resCbRecheck(req *abci.Request, res *abci.Response) {
 		if mem.recheckCursor == nil {
 			// Done!
 			mem.logger.Debug("done rechecking txs")
+			mem.metrics.RecheckDurationSeconds.Observe(time.Since(mem.recheckStart).Seconds())
+
+			mem.recheckMtx.Lock()
+			close(mem.recheckDone)
+			mem.recheckDone = nil
+			mem.recheckMtx.Unlock()
 
 			// incase the recheck removed all txs
 			if mem.Size() > 0 {
@@ -527,6 +1172,15 @@ func (mem *CListMempool) notifyTxsAvailable() {
 
 // Safe for concurrent use by multiple goroutines.
 func (mem *CListMempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs {
+	return mem.ReapMaxBytesMaxGasContext(context.Background(), maxBytes, maxGas)
+}
+
+// ReapMaxBytesMaxGasContext is ReapMaxBytesMaxGas, but stops reaping once
+// [ctx] is done, returning the txs reaped so far, which are a valid block on
+// their own.
+//
+// Safe for concurrent use by multiple goroutines.
+func (mem *CListMempool) ReapMaxBytesMaxGasContext(ctx context.Context, maxBytes, maxGas int64) types.Txs {
 	mem.updateMtx.RLock()
 	defer mem.updateMtx.RUnlock()
 
@@ -535,9 +1189,15 @@ func (mem *CListMempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs {
 	// TODO: we will get a performance boost if we have a good estimate of avg
 	// size per tx, and set the initial capacity based off of that.
 	// txs := make([]types.Tx, 0, tmmath.MinInt(mem.txs.Len(), max/mem.avgTxSize))
-	txs := make([]types.Tx, 0, mem.txs.Len())
-	for e := mem.txs.Front(); e != nil; e = e.Next() {
-		memTx := e.Value.(*mempoolTx)
+	memTxs := mem.reapOrder()
+	if len(mem.lanes) > 0 && (maxBytes > -1 || maxGas > -1) {
+		memTxs = mem.laneFirst(memTxs, maxBytes, maxGas)
+	}
+	txs := make([]types.Tx, 0, len(memTxs))
+	for _, memTx := range memTxs {
+		if ctx.Err() != nil {
+			return txs
+		}
 
 		dataSize := types.ComputeProtoSizeForTxs(append(txs, memTx.tx))
 
@@ -568,10 +1228,90 @@ func (mem *CListMempool) ReapMaxTxs(max int) types.Txs {
 		max = mem.txs.Len()
 	}
 
-	txs := make([]types.Tx, 0, tmmath.MinInt(mem.txs.Len(), max))
-	for e := mem.txs.Front(); e != nil && len(txs) <= max; e = e.Next() {
+	memTxs := mem.reapOrder()
+	txs := make([]types.Tx, 0, tmmath.MinInt(len(memTxs), max))
+	for i := 0; i < len(memTxs) && len(txs) <= max; i++ {
+		txs = append(txs, memTxs[i].tx)
+	}
+	return txs
+}
+
+// reapOrder returns the transactions in the mempool in the order of the reap
+// strategy, except that the transactions of a sender are in the order of
+// their sequences.
+func (mem *CListMempool) reapOrder() []*mempoolTx {
+	txs := make([]*ReapTx, 0, mem.txs.Len())
+	for e := mem.txs.Front(); e != nil; e = e.Next() {
 		memTx := e.Value.(*mempoolTx)
-		txs = append(txs, memTx.tx)
+		tx := &ReapTx{
+			Tx:        memTx.tx,
+			Priority:  memTx.Priority(),
+			GasWanted: memTx.gasWanted,
+			Timestamp: memTx.timestamp,
+			memTx:     memTx,
+		}
+		if memTx.sequence != nil {
+			tx.Sender = memTx.sequence.sender
+		} else if len(memTx.accounts) > 0 {
+			tx.Sender = memTx.accounts[0]
+		}
+		txs = append(txs, tx)
+	}
+	mem.reapStrategy.Order(txs)
+
+	// the txs of a sender take the places the strategy got them, but in the
+	// order of their sequences
+	places := make(map[string][]int)
+	for i, tx := range txs {
+		if seq := tx.memTx.sequence; seq != nil {
+			places[seq.sender] = append(places[seq.sender], i)
+		}
+	}
+	for _, senderPlaces := range places {
+		if len(senderPlaces) < 2 {
+			continue
+		}
+		senderTxs := make([]*ReapTx, len(senderPlaces))
+		for j, i := range senderPlaces {
+			senderTxs[j] = txs[i]
+		}
+		sort.Slice(senderTxs, func(i, j int) bool {
+			return senderTxs[i].memTx.sequence.sequence < senderTxs[j].memTx.sequence.sequence
+		})
+		for j, i := range senderPlaces {
+			txs[i] = senderTxs[j]
+		}
+	}
+
+	memTxs := make([]*mempoolTx, len(txs))
+	for i, tx := range txs {
+		memTxs[i] = tx.memTx
+	}
+	return memTxs
+}
+
+// ReapMaxTxsBySender reaps up to max transactions whose CheckTx events report
+// the given sender account (see SenderAttributeKey). If max is negative, there
+// is no cap on the number of returned transactions.
+//
+// Safe for concurrent use by multiple goroutines.
+func (mem *CListMempool) ReapMaxTxsBySender(sender string, max int) types.Txs {
+	mem.updateMtx.RLock()
+	defer mem.updateMtx.RUnlock()
+
+	if max < 0 {
+		max = mem.txs.Len()
+	}
+
+	txs := make([]types.Tx, 0)
+	for e := mem.txs.Front(); e != nil && len(txs) < max; e = e.Next() {
+		memTx := e.Value.(*mempoolTx)
+		for _, account := range memTx.accounts {
+			if account == sender {
+				txs = append(txs, memTx.tx)
+				break
+			}
+		}
 	}
 	return txs
 }
@@ -619,6 +1359,8 @@ func (mem *CListMempool) Update(
 		}
 	}
 
+	mem.purgeExpiredTxs(height)
+
 	// Either recheck non-committed txs to see if they became invalid
 	// or just notify there're some txs left.
 	if mem.Size() > 0 {
@@ -634,27 +1376,79 @@ func (mem *CListMempool) Update(
 	}
 
 	// Update metrics
-	mem.metrics.Size.Set(float64(mem.Size()))
+	mem.updateSizeMetrics()
 
 	return nil
 }
 
+// purgeExpiredTxs removes the transactions which have been in the mempool for
+// more than TTLNumBlocks blocks at [height] or TTLDuration.
+// Lock() must be held by the caller.
+func (mem *CListMempool) purgeExpiredTxs(height int64) {
+	if mem.config.TTLNumBlocks == 0 && mem.config.TTLDuration == 0 {
+		return
+	}
+
+	now := time.Now()
+	for e := mem.txs.Front(); e != nil; {
+		// the next element is read before e is removed
+		next := e.Next()
+		memTx := e.Value.(*mempoolTx)
+		if (mem.config.TTLNumBlocks > 0 && height-memTx.Height() > mem.config.TTLNumBlocks) ||
+			(mem.config.TTLDuration > 0 && now.Sub(memTx.timestamp) > mem.config.TTLDuration) {
+			// remove from cache (it might be resubmitted with a new nonce)
+			mem.removeTx(memTx.tx, e, !mem.config.KeepInvalidTxsInCache)
+			mem.metrics.ExpiredTxs.Add(1)
+			mem.logger.Debug("expired transaction", "tx", txID(memTx.tx), "height", height)
+			if mem.onExpired != nil {
+				mem.onExpired(memTx.tx, height)
+			}
+		}
+		e = next
+	}
+}
+
 func (mem *CListMempool) recheckTxs() {
 	if mem.Size() == 0 {
 		panic("recheckTxs is called, but the mempool is empty")
 	}
 
-	mem.recheckCursor = mem.txs.Front()
-	mem.recheckEnd = mem.txs.Back()
+	// With a batch size, the recheck goes on after the last tx rechecked, and
+	// wraps around at the end of the mempool.
+	start, end := mem.txs.Front(), mem.txs.Back()
+	if batchSize := mem.config.RecheckBatchSize; batchSize > 0 {
+		if mem.recheckLast != nil {
+			if e, ok := mem.txsMap.Load(*mem.recheckLast); ok && e.(*clist.CElement).Next() != nil {
+				start = e.(*clist.CElement).Next()
+			}
+		}
+		end = start
+		for n := 1; n < batchSize && end.Next() != nil; n++ {
+			end = end.Next()
+		}
+		last := TxKey(end.Value.(*mempoolTx).tx)
+		mem.recheckLast = &last
+	}
+
+	mem.recheckCursor = start
+	mem.recheckEnd = end
+	mem.recheckStart = time.Now()
+
+	mem.recheckMtx.Lock()
+	mem.recheckDone = make(chan struct{})
+	mem.recheckMtx.Unlock()
 
 	// Push txs to proxyAppConn
 	// NOTE: globalCb may be called concurrently.
-	for e := mem.txs.Front(); e != nil; e = e.Next() {
+	for e := start; e != nil; e = e.Next() {
 		memTx := e.Value.(*mempoolTx)
 		mem.proxyAppConn.CheckTxAsync(abci.RequestCheckTx{
 			Tx:   memTx.tx,
 			Type: abci.CheckTxType_Recheck,
 		})
+		if e == end {
+			break
+		}
 	}
 
 	mem.proxyAppConn.FlushAsync()
@@ -664,9 +1458,17 @@ func (mem *CListMempool) recheckTxs() {
 
 // mempoolTx is a transaction that successfully ran
 type mempoolTx struct {
-	height    int64    // height that this tx had been validated in
-	gasWanted int64    // amount of gas this tx states it will require
-	tx        types.Tx //
+	height    int64           // height that this tx had been validated in
+	gasWanted int64           // amount of gas this tx states it will require
+	priority  int64           // priority returned by the app in the last CheckTx
+	tx        types.Tx        //
+	timestamp time.Time       // time when this tx was added to the mempool
+	accounts  []string        // sender accounts reported by the app in CheckTx
+	sequence  *senderSequence // sender and sequence reported by the app in CheckTx, if any
+	conflicts []string        // conflict keys of the tx, see txConflicts
+	events    []abci.Event    // events returned by the app in CheckTx
+	origin    string          // origin of the tx, see TxInfo.Origin
+	lane      string          // lane of the tx, see LaneAttributeKey
 
 	// ids of peers who've sent us this tx (as a map for quick lookups).
 	// senders: PeerID -> bool
@@ -678,12 +1480,85 @@ func (memTx *mempoolTx) Height() int64 {
 	return atomic.LoadInt64(&memTx.height)
 }
 
+// Priority returns the priority of this transaction
+func (memTx *mempoolTx) Priority() int64 {
+	return atomic.LoadInt64(&memTx.priority)
+}
+
+// senderAccounts returns the values of the SenderAttributeKey attributes of
+// the given events.
+func senderAccounts(events []abci.Event) []string {
+	var accounts []string
+	for _, event := range events {
+		for _, attr := range event.Attributes {
+			if string(attr.Key) == SenderAttributeKey {
+				accounts = append(accounts, string(attr.Value))
+			}
+		}
+	}
+	return accounts
+}
+
+// txConflicts returns the conflict keys of a tx with the given CheckTx events
+// and [sequence]: the values of the ConflictAttributeKey attributes, and its
+// sender and sequence.
+func txConflicts(events []abci.Event, sequence *senderSequence) []string {
+	var conflicts []string
+	if sequence != nil {
+		conflicts = append(conflicts, fmt.Sprintf("sequence/%s/%d", sequence.sender, sequence.sequence))
+	}
+	for _, event := range events {
+		for _, attr := range event.Attributes {
+			if string(attr.Key) == ConflictAttributeKey {
+				conflicts = append(conflicts, "conflict/"+string(attr.Value))
+			}
+		}
+	}
+	return conflicts
+}
+
+// senderSequence identifies a tx by its sender account and sequence number.
+type senderSequence struct {
+	sender   string
+	sequence uint64
+}
+
+// txSequence returns the sender and sequence reported by the first of the
+// given events with both a SenderAttributeKey and a valid
+// SequenceAttributeKey attribute, or nil if there is none.
+func txSequence(events []abci.Event) *senderSequence {
+	for _, event := range events {
+		var (
+			sender      string
+			sequence    uint64
+			hasSender   bool
+			hasSequence bool
+		)
+		for _, attr := range event.Attributes {
+			switch string(attr.Key) {
+			case SenderAttributeKey:
+				sender, hasSender = string(attr.Value), true
+			case SequenceAttributeKey:
+				seq, err := strconv.ParseUint(string(attr.Value), 10, 64)
+				if err == nil {
+					sequence, hasSequence = seq, true
+				}
+			}
+		}
+		if hasSender && hasSequence {
+			return &senderSequence{sender: sender, sequence: sequence}
+		}
+	}
+	return nil
+}
+
 //--------------------------------------------------------------------------------
 
 type txCache interface {
 	Reset()
 	Push(tx types.Tx) bool
 	Remove(tx types.Tx)
+	Len() int
 }
 
 // mapTxCache maintains a LRU cache of transactions. This only stores the hash
@@ -691,10 +1566,17 @@ type txCache interface {
 type mapTxCache struct {
 	mtx      tmsync.Mutex
 	size     int
+	ttl      time.Duration // how long a tx stays in the cache, forever if 0
 	cacheMap map[[TxKeySize]byte]*list.Element
 	list     *list.List
 }
 
+// cacheEntry is an element of the list of a mapTxCache.
+type cacheEntry struct {
+	txHash [TxKeySize]byte
+	added  time.Time
+}
+
 var _ txCache = (*mapTxCache)(nil)
 
 // newMapTxCache returns a new mapTxCache.
@@ -722,24 +1604,37 @@ func (cache *mapTxCache) Push(tx types.Tx) bool {
 
 	// Use the tx hash in the cache
 	txHash := TxKey(tx)
+	now := time.Now()
 	if moved, exists := cache.cacheMap[txHash]; exists {
 		cache.list.MoveToBack(moved)
-		return false
+		entry := moved.Value.(*cacheEntry)
+		if cache.ttl == 0 || now.Sub(entry.added) <= cache.ttl {
+			return false
+		}
+		// expired, the tx is seen anew
+		entry.added = now
+		return true
 	}
 
 	if cache.list.Len() >= cache.size {
 		popped := cache.list.Front()
 		if popped != nil {
-			poppedTxHash := popped.Value.([TxKeySize]byte)
-			delete(cache.cacheMap, poppedTxHash)
+			delete(cache.cacheMap, popped.Value.(*cacheEntry).txHash)
 			cache.list.Remove(popped)
 		}
 	}
-	e := cache.list.PushBack(txHash)
+	e := cache.list.PushBack(&cacheEntry{txHash: txHash, added: now})
 	cache.cacheMap[txHash] = e
 	return true
 }
 
+// Len returns the number of transactions in the cache.
+func (cache *mapTxCache) Len() int {
+	cache.mtx.Lock()
+	defer cache.mtx.Unlock()
+	return cache.list.Len()
+}
+
 // Remove removes the given tx from the cache.
 func (cache *mapTxCache) Remove(tx types.Tx) {
 	cache.mtx.Lock()
@@ -760,6 +1655,7 @@ var _ txCache = (*nopTxCache)(nil)
 func (nopTxCache) Reset()             {}
 func (nopTxCache) Push(types.Tx) bool { return true }
 func (nopTxCache) Remove(types.Tx)    {}
+func (nopTxCache) Len() int           { return 0 }
 
 //--------------------------------------------------------------------------------
 
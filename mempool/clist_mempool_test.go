@@ -1,6 +1,8 @@
 package mempool
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/binary"
@@ -44,7 +46,7 @@ func newMempoolWithAppAndConfig(cc proxy.ClientCreator, config *cfg.Config) (*CL
 	if err != nil {
 		panic(err)
 	}
-	mempool := NewCListMempool(config.Mempool, appConnMem, 0)
+	mempool := NewCListMempool(config.Mempool, appConnMem, 0, nil)
 	mempool.SetLogger(log.TestingLogger())
 	return mempool, func() { os.RemoveAll(config.RootDir) }
 }
@@ -548,10 +550,18 @@ func TestMempoolTxsBytes(t *testing.T) {
 	mempool.Flush()
 	assert.EqualValues(t, 0, mempool.TxsBytes())
 
-	// 5. ErrMempoolIsFull is returned when/if MaxTxsBytes limit is reached.
+	// 5. the tx is rejected with CodeTypeMempoolFull when/if MaxTxsBytes limit
+	// is reached, and ErrMempoolIsFull is returned for a tx larger than it.
 	err = mempool.CheckTx([]byte{0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04}, nil, TxInfo{})
 	require.NoError(t, err)
-	err = mempool.CheckTx([]byte{0x05}, nil, TxInfo{})
+	var checkTxRes *abci.ResponseCheckTx
+	err = mempool.CheckTx([]byte{0x05}, func(r *abci.Response) { checkTxRes = r.GetCheckTx() }, TxInfo{})
+	require.NoError(t, err)
+	require.NotNil(t, checkTxRes)
+	assert.Equal(t, CodeTypeMempoolFull, checkTxRes.Code)
+	assert.Equal(t, Codespace, checkTxRes.Codespace)
+	assert.EqualValues(t, 10, mempool.TxsBytes())
+	err = mempool.CheckTx(make([]byte, 11), nil, TxInfo{})
 	if assert.Error(t, err) {
 		assert.IsType(t, ErrMempoolIsFull{}, err)
 	}
@@ -601,6 +611,344 @@ func TestMempoolTxsBytes(t *testing.T) {
 
 }
 
+// eventsApp is a kvstore app returning an event with the tx from CheckTx.
+type eventsApp struct {
+	*kvstore.Application
+}
+
+func (app *eventsApp) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	res := app.Application.CheckTx(req)
+	res.Events = []abci.Event{{
+		Type:       "tx",
+		Attributes: []abci.EventAttribute{{Key: []byte("tx"), Value: req.Tx}},
+	}}
+	return res
+}
+
+func TestMempoolWalkTxs(t *testing.T) {
+	app := &eventsApp{Application: kvstore.NewApplication()}
+	cc := proxy.NewLocalClientCreator(app)
+	mempool, cleanup := newMempoolWithApp(cc)
+	defer cleanup()
+
+	txs := types.Txs{{0x01}, {0x02}, {0x03}}
+	for _, tx := range txs {
+		require.NoError(t, mempool.CheckTx(tx, nil, TxInfo{}))
+	}
+	var walked types.Txs
+	mempool.WalkTxs(func(tx types.Tx, events []abci.Event) bool {
+		walked = append(walked, tx)
+		// with the events CheckTx returned for the tx
+		require.Len(t, events, 1)
+		assert.Equal(t, []byte(tx), events[0].Attributes[0].Value)
+		return len(walked) < 2
+	})
+	assert.Equal(t, txs[:2], walked)
+}
+
+func TestMempoolRecheckDone(t *testing.T) {
+	sockPath := fmt.Sprintf("unix:///tmp/echo_%v.sock", tmrand.Str(6))
+	app := kvstore.NewApplication()
+	cc, server := newRemoteApp(t, sockPath, app)
+	t.Cleanup(func() {
+		if err := server.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	mempool, cleanup := newMempoolWithApp(cc)
+	defer cleanup()
+
+	// no recheck in progress
+	select {
+	case <-mempool.RecheckDone():
+	default:
+		t.Fatal("RecheckDone isn't closed without a recheck in progress")
+	}
+
+	for _, tx := range []types.Tx{{0x01}, {0x02}} {
+		require.NoError(t, mempool.CheckTx(tx, nil, TxInfo{}))
+	}
+	require.NoError(t, mempool.FlushAppConn())
+
+	mempool.Lock()
+	err := mempool.Update(1, []types.Tx{{0x01}}, abciResponses(1, abci.CodeTypeOK), nil, nil)
+	done := mempool.RecheckDone()
+	mempool.Unlock()
+	require.NoError(t, err)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("recheck didn't complete")
+	}
+	assert.Equal(t, types.Txs{{0x02}}, mempool.ReapMaxTxs(-1))
+
+	// and the next waiter doesn't wait for the recheck done
+	select {
+	case <-mempool.RecheckDone():
+	default:
+		t.Fatal("RecheckDone isn't closed once the recheck is done")
+	}
+}
+
+// stalledRecheckApp is a kvstore whose rechecks don't return until released.
+type stalledRecheckApp struct {
+	*kvstore.Application
+	release chan struct{}
+}
+
+func (app *stalledRecheckApp) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	if req.Type == abci.CheckTxType_Recheck {
+		<-app.release
+	}
+	return app.Application.CheckTx(req)
+}
+
+func TestMempoolCheckTxConnsWaitForRecheck(t *testing.T) {
+	sockPath := fmt.Sprintf("unix:///tmp/echo_%v.sock", tmrand.Str(6))
+	app := &stalledRecheckApp{Application: kvstore.NewApplication(), release: make(chan struct{})}
+	cc, server := newRemoteApp(t, sockPath, app)
+	t.Cleanup(func() {
+		if err := server.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	newConn := func() proxy.AppConnMempool {
+		client, err := cc.NewABCIClient()
+		require.NoError(t, err)
+		require.NoError(t, client.Start())
+		t.Cleanup(func() { _ = client.Stop() })
+		return proxy.NewAppConnMempool(client)
+	}
+	config := cfg.ResetTestRoot("mempool_test")
+	t.Cleanup(func() { os.RemoveAll(config.RootDir) })
+	mempool := NewCListMempool(config.Mempool, newConn(), 0, nil,
+		WithCheckTxConns(newConn()),
+		WithTxSender(func(tx types.Tx) string { return string(tx) }),
+	)
+	mempool.SetLogger(log.TestingLogger())
+
+	// a tx checked on the other connection
+	var pooledTx types.Tx
+	for i := byte(0); pooledTx == nil; i++ {
+		if _, pooled := mempool.checkTxConn(types.Tx{i}); pooled {
+			pooledTx = types.Tx{i}
+		}
+	}
+
+	for _, tx := range []types.Tx{{0xf0}, {0xf1}} {
+		require.NoError(t, mempool.CheckTx(tx, nil, TxInfo{}))
+	}
+	require.NoError(t, mempool.FlushAppConn())
+	mempool.Lock()
+	err := mempool.Update(1, []types.Tx{{0xf0}}, abciResponses(1, abci.CodeTypeOK), nil, nil)
+	mempool.Unlock()
+	require.NoError(t, err)
+
+	// the tx waits for the recheck, without holding up the next Update
+	checked := make(chan error, 1)
+	go func() { checked <- mempool.CheckTx(pooledTx, nil, TxInfo{}) }()
+	select {
+	case err := <-checked:
+		t.Fatalf("tx checked during the recheck: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	locked := make(chan struct{})
+	go func() {
+		mempool.Lock()
+		mempool.Unlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Update held up by a tx waiting for the recheck")
+	}
+
+	close(app.release)
+	select {
+	case err := <-checked:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("tx not checked once the recheck is done")
+	}
+}
+
+func TestMempoolFlushCache(t *testing.T) {
+	app := kvstore.NewApplication()
+	cc := proxy.NewLocalClientCreator(app)
+	mempool, cleanup := newMempoolWithApp(cc)
+	defer cleanup()
+
+	require.NoError(t, mempool.CheckTx(types.Tx{0x01}, nil, TxInfo{}))
+	require.NoError(t, mempool.CheckTx(types.Tx{0x02}, nil, TxInfo{}))
+	require.NoError(t, mempool.Update(1, types.Txs{{0x01}}, abciResponses(1, abci.CodeTypeOK), nil, nil))
+	require.ErrorIs(t, mempool.CheckTx(types.Tx{0x01}, nil, TxInfo{}), ErrTxInCache)
+
+	// the committed tx is checked again once the cache is flushed
+	assert.Equal(t, 2, mempool.FlushCache())
+	require.NoError(t, mempool.CheckTx(types.Tx{0x01}, nil, TxInfo{}))
+
+	// while the tx still in the mempool isn't added twice
+	require.ErrorIs(t, mempool.CheckTx(types.Tx{0x02}, nil, TxInfo{}), ErrTxInCache)
+	assert.Equal(t, 2, mempool.Size())
+}
+
+func TestMempoolPendingTx(t *testing.T) {
+	app := kvstore.NewApplication()
+	cc := proxy.NewLocalClientCreator(app)
+	mempool, cleanup := newMempoolWithApp(cc)
+	defer cleanup()
+
+	txs := []types.Tx{{0x01}, {0x02}}
+	for _, tx := range txs {
+		require.NoError(t, mempool.CheckTx(tx, nil, TxInfo{}))
+	}
+	position, addedAt, ok := mempool.PendingTx(TxKey(txs[1]))
+	require.True(t, ok)
+	assert.Equal(t, 1, position)
+	assert.False(t, addedAt.IsZero())
+
+	_, _, ok = mempool.PendingTx(TxKey(types.Tx{0x03}))
+	assert.False(t, ok)
+
+	// the position moves up as older txs leave the mempool
+	require.NoError(t, mempool.Update(1, txs[:1], abciResponses(1, abci.CodeTypeOK), nil, nil))
+	_, _, ok = mempool.PendingTx(TxKey(txs[0]))
+	assert.False(t, ok)
+	position, _, ok = mempool.PendingTx(TxKey(txs[1]))
+	require.True(t, ok)
+	assert.Equal(t, 0, position)
+}
+
+func TestMempoolOriginLimits(t *testing.T) {
+	app := kvstore.NewApplication()
+	cc := proxy.NewLocalClientCreator(app)
+	config := cfg.ResetTestRoot("mempool_test")
+	config.Mempool.OriginMaxTxs = 2
+	mempool, cleanup := newMempoolWithAppAndConfig(cc, config)
+	defer cleanup()
+
+	a, b := TxInfo{Origin: "a"}, TxInfo{Origin: "b"}
+	require.NoError(t, mempool.CheckTx(types.Tx{0x01}, nil, a))
+	require.NoError(t, mempool.CheckTx(types.Tx{0x02}, nil, a))
+	err := mempool.CheckTx(types.Tx{0x03}, nil, a)
+	assert.ErrorAs(t, err, &ErrOriginLimited{})
+
+	// the other origins and the txs without an origin aren't limited
+	require.NoError(t, mempool.CheckTx(types.Tx{0x04}, nil, b))
+	require.NoError(t, mempool.CheckTx(types.Tx{0x05}, nil, TxInfo{}))
+	require.NoError(t, mempool.CheckTx(types.Tx{0x06}, nil, TxInfo{}))
+
+	// a tx of the origin leaving the mempool makes room for the next one,
+	// which wasn't kept in the cache
+	require.NoError(t, mempool.Update(1, types.Txs{{0x01}}, abciResponses(1, abci.CodeTypeOK), nil, nil))
+	require.NoError(t, mempool.CheckTx(types.Tx{0x03}, nil, a))
+	assert.Equal(t, 5, mempool.Size())
+
+	// the rate of each origin is limited as well
+	config = cfg.ResetTestRoot("mempool_test")
+	config.Mempool.OriginRate = 1
+	config.Mempool.OriginBurst = 2
+	mempool, cleanup = newMempoolWithAppAndConfig(cc, config)
+	defer cleanup()
+
+	require.NoError(t, mempool.CheckTx(types.Tx{0x01}, nil, a))
+	require.NoError(t, mempool.CheckTx(types.Tx{0x02}, nil, a))
+	err = mempool.CheckTx(types.Tx{0x03}, nil, a)
+	assert.ErrorAs(t, err, &ErrOriginLimited{})
+	require.NoError(t, mempool.CheckTx(types.Tx{0x03}, nil, b))
+}
+
+func TestMempoolCheckTxContext(t *testing.T) {
+	app := kvstore.NewApplication()
+	cc := proxy.NewLocalClientCreator(app)
+	mempool, cleanup := newMempoolWithApp(cc)
+	defer cleanup()
+
+	// the tx waiting on the update lock is given up once the context is done
+	mempool.Lock()
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- mempool.CheckTxContext(ctx, types.Tx{0x01}, nil, TxInfo{})
+	}()
+	cancel()
+	select {
+	case err := <-errCh:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("CheckTxContext still waiting on the update lock")
+	}
+	mempool.Unlock()
+
+	// the lock taken for the given up tx is released
+	require.NoError(t, mempool.CheckTxContext(context.Background(), types.Tx{0x02}, nil, TxInfo{}))
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, mempool.CheckTxContext(ctx, types.Tx{0x03}, nil, TxInfo{}))
+	mempool.Lock()
+	mempool.Unlock()
+	assert.Equal(t, 2, mempool.Size())
+}
+
+// laneApp is a kvstore app putting the txs prefixed with "<lane>:" in the
+// lane.
+type laneApp struct {
+	*kvstore.Application
+}
+
+func (app *laneApp) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	res := app.Application.CheckTx(req)
+	if i := bytes.IndexByte(req.Tx, ':'); i > 0 {
+		res.Events = []abci.Event{{
+			Type:       "tx",
+			Attributes: []abci.EventAttribute{{Key: []byte(LaneAttributeKey), Value: req.Tx[:i]}},
+		}}
+	}
+	return res
+}
+
+func TestMempoolLanes(t *testing.T) {
+	cc := proxy.NewLocalClientCreator(&laneApp{Application: kvstore.NewApplication()})
+	appConnMem, _ := cc.NewABCIClient()
+	require.NoError(t, appConnMem.Start())
+	config := cfg.ResetTestRoot("mempool_test")
+	defer os.RemoveAll(config.RootDir)
+	config.Mempool.Size = 2
+	mempool := NewCListMempool(config.Mempool, appConnMem, 0, nil,
+		WithLanes(Lane{Name: "oracle", Size: 1, MaxTxsBytes: 1024, BlockShare: 0.5}))
+
+	checkTx := func(tx string) uint32 {
+		var code uint32
+		require.NoError(t, mempool.CheckTx(types.Tx(tx), func(res *abci.Response) {
+			code = res.GetCheckTx().Code
+		}, TxInfo{}))
+		return code
+	}
+
+	// the lane holds its txs besides the full default lane
+	require.Equal(t, abci.CodeTypeOK, checkTx("user:a=1"))
+	require.Equal(t, abci.CodeTypeOK, checkTx("user:b=1"))
+	require.Equal(t, abci.CodeTypeOK, checkTx("oracle:a"))
+	assert.Equal(t, CodeTypeMempoolFull, checkTx("user:c=1"))
+	// up to its own capacity
+	assert.Equal(t, CodeTypeMempoolFull, checkTx("oracle:b"))
+	assert.Equal(t, 3, mempool.Size())
+
+	// the lane gets its share of a block first
+	maxBytes := types.ComputeProtoSizeForTxs(types.Txs{types.Tx("user:a=1"), types.Tx("user:b=1")})
+	assert.Equal(t, types.Txs{types.Tx("oracle:a"), types.Tx("user:a=1")}, mempool.ReapMaxBytesMaxGas(maxBytes, -1))
+	// the rest of the block being reaped in order
+	assert.Equal(t, types.Txs{types.Tx("user:a=1"), types.Tx("user:b=1"), types.Tx("oracle:a")},
+		mempool.ReapMaxTxs(-1))
+
+	// a tx leaving the lane makes room in it only
+	require.NoError(t, mempool.Update(1, types.Txs{types.Tx("oracle:a")}, abciResponses(1, abci.CodeTypeOK), nil, nil))
+	assert.Equal(t, CodeTypeMempoolFull, checkTx("user:c=1"))
+	assert.Equal(t, abci.CodeTypeOK, checkTx("oracle:b"))
+}
+
 // This will non-deterministically catch some concurrency failures like
 // https://github.com/consideritdone/landslidecore/issues/3509
 // TODO: all of the tests should probably also run using the remote proxy app
@@ -641,6 +989,22 @@ func TestMempoolRemoteAppConcurrency(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestMempoolTxSizes(t *testing.T) {
+	app := kvstore.NewApplication()
+	cc := proxy.NewLocalClientCreator(app)
+	mempool, cleanup := newMempoolWithApp(cc)
+	defer cleanup()
+
+	for _, tx := range []types.Tx{{0x01}, {0x02, 0x03}, {0x04, 0x05, 0x06}} {
+		require.NoError(t, mempool.CheckTx(tx, nil, TxInfo{}))
+	}
+	var sizes []int
+	mempool.TxSizes(func(size int) {
+		sizes = append(sizes, size)
+	})
+	assert.Equal(t, []int{1, 2, 3}, sizes)
+}
+
 // caller must close server
 func newRemoteApp(
 	t *testing.T,
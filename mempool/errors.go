@@ -36,6 +36,17 @@ func (e ErrMempoolIsFull) Error() string {
 		e.txsBytes, e.maxTxsBytes)
 }
 
+// ErrOriginLimited means the origin of a tx is over one of its limits, see
+// TxInfo.Origin and the Origin* fields of the config.
+type ErrOriginLimited struct {
+	Origin string
+	Reason string
+}
+
+func (e ErrOriginLimited) Error() string {
+	return fmt.Sprintf("origin %s %s", e.Origin, e.Reason)
+}
+
 // ErrPreCheck is returned when tx is too big
 type ErrPreCheck struct {
 	Reason error
@@ -45,6 +56,10 @@ func (e ErrPreCheck) Error() string {
 	return e.Reason.Error()
 }
 
+func (e ErrPreCheck) Unwrap() error {
+	return e.Reason
+}
+
 // IsPreCheckError returns true if err is due to pre check failure.
 func IsPreCheckError(err error) bool {
 	_, ok := err.(ErrPreCheck)
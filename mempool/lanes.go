@@ -0,0 +1,140 @@
+package mempool
+
+import (
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// LaneAttributeKey is the key of the CheckTx event attribute through which the
+// app reports the lane of a tx, see WithLanes. The txs without a lane, or of
+// an unknown one, are in the default lane, whose capacity is the one of the
+// config.
+const LaneAttributeKey = "lane"
+
+// Lane is a class of transactions, e.g. the oracle or IBC relayer ones, with
+// its own capacity in the mempool and a share of every block, so that the
+// other transactions can't crowd them out. The transactions of a sender should
+// all be in the same lane, as they are only reaped in the order of their
+// sequences within a lane.
+type Lane struct {
+	Name string
+	// Size and MaxTxsBytes are the number of transactions and the total size
+	// of those the lane holds, besides the capacity of the other lanes. Once
+	// the lane is full, its transactions of lower priority are evicted for a
+	// new one.
+	Size        int
+	MaxTxsBytes int64
+	// BlockShare is the fraction of the bytes and gas of every block reaped
+	// from the lane first. The rest of the block is filled with the
+	// transactions of all the lanes, in the order of the reap strategy.
+	BlockShare float64
+}
+
+// WithLanes sets the lanes of the mempool, besides the default one.
+func WithLanes(lanes ...Lane) CListMempoolOption {
+	return func(mem *CListMempool) {
+		mem.lanes = make(map[string]Lane, len(lanes))
+		for _, lane := range lanes {
+			mem.lanes[lane.Name] = lane
+			mem.laneNames = append(mem.laneNames, lane.Name)
+		}
+	}
+}
+
+// laneUsage is the number of transactions and the bytes held by a lane.
+type laneUsage struct {
+	size     int
+	txsBytes int64
+}
+
+// txLane returns the lane of a tx with the given CheckTx events, the default
+// one, "", if it reports none or an unknown one.
+func (mem *CListMempool) txLane(events []abci.Event) string {
+	if len(mem.lanes) == 0 {
+		return ""
+	}
+	for _, event := range events {
+		for _, attr := range event.Attributes {
+			if string(attr.Key) != LaneAttributeKey {
+				continue
+			}
+			if _, ok := mem.lanes[string(attr.Value)]; ok {
+				return string(attr.Value)
+			}
+		}
+	}
+	return ""
+}
+
+// laneCapacity returns the number of transactions and the bytes [lane] holds.
+func (mem *CListMempool) laneCapacity(lane string) (int, int64) {
+	if l, ok := mem.lanes[lane]; ok {
+		return l.Size, l.MaxTxsBytes
+	}
+	return mem.config.Size, mem.config.MaxTxsBytes
+}
+
+// laneUsage returns the number of transactions and the bytes [lane] holds.
+func (mem *CListMempool) laneUsage(lane string) (int, int64) {
+	mem.lanesMtx.Lock()
+	defer mem.lanesMtx.Unlock()
+
+	usage := mem.usage[lane]
+	return usage.size, usage.txsBytes
+}
+
+func (mem *CListMempool) addLaneUsage(lane string, txs int, txsBytes int64) {
+	mem.lanesMtx.Lock()
+	defer mem.lanesMtx.Unlock()
+
+	usage := mem.usage[lane]
+	usage.size += txs
+	usage.txsBytes += txsBytes
+	if usage.size == 0 {
+		delete(mem.usage, lane)
+		return
+	}
+	mem.usage[lane] = usage
+}
+
+// laneFirst moves to the front of [memTxs], in the order of the reap
+// strategy, the transactions of each lane up to its BlockShare of [maxBytes]
+// and [maxGas], so that they are reaped first.
+func (mem *CListMempool) laneFirst(memTxs []*mempoolTx, maxBytes, maxGas int64) []*mempoolTx {
+	type share struct {
+		bytes, gas int64
+	}
+	shares := make(map[string]*share, len(mem.lanes))
+	for name, lane := range mem.lanes {
+		if lane.BlockShare > 0 {
+			shares[name] = &share{
+				bytes: int64(lane.BlockShare * float64(maxBytes)),
+				gas:   int64(lane.BlockShare * float64(maxGas)),
+			}
+		}
+	}
+	if len(shares) == 0 {
+		return memTxs
+	}
+
+	first := make([]*mempoolTx, 0, len(memTxs))
+	rest := make([]*mempoolTx, 0, len(memTxs))
+	for _, memTx := range memTxs {
+		s, ok := shares[memTx.lane]
+		if !ok {
+			rest = append(rest, memTx)
+			continue
+		}
+		txBytes := types.ComputeProtoSizeForTxs([]types.Tx{memTx.tx})
+		if (maxBytes > -1 && txBytes > s.bytes) || (maxGas > -1 && memTx.gasWanted > s.gas) {
+			// the next txs of the lane are reaped in order with the others
+			delete(shares, memTx.lane)
+			rest = append(rest, memTx)
+			continue
+		}
+		s.bytes -= txBytes
+		s.gas -= memTx.gasWanted
+		first = append(first, memTx)
+	}
+	return append(first, rest...)
+}
@@ -1,6 +1,7 @@
 package mempool
 
 import (
+	"bytes"
 	"fmt"
 
 	abci "github.com/consideritdone/landslidecore/abci/types"
@@ -17,14 +18,22 @@ type Mempool interface {
 	// its validity and whether it should be added to the mempool.
 	CheckTx(tx types.Tx, callback func(*abci.Response), txInfo TxInfo) error
 
+	// CheckTxBatch executes CheckTx for every transaction in txs, acquiring
+	// the mempool lock only once. It returns the error of each transaction,
+	// in order. The callback is called with the index of the transaction the
+	// response belongs to.
+	CheckTxBatch(txs types.Txs, callback func(int, *abci.Response), txInfo TxInfo) []error
+
 	// ReapMaxBytesMaxGas reaps transactions from the mempool up to maxBytes
 	// bytes total with the condition that the total gasWanted must be less than
-	// maxGas.
+	// maxGas. Transactions are reaped in the order of the ReapStrategy of the
+	// mempool, if it has one.
 	// If both maxes are negative, there is no cap on the size of all returned
 	// transactions (~ all available transactions).
 	ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs
 
-	// ReapMaxTxs reaps up to max transactions from the mempool.
+	// ReapMaxTxs reaps up to max transactions from the mempool, in the order
+	// of ReapMaxBytesMaxGas.
 	// If max is negative, there is no cap on the size of all returned
 	// transactions (~ all available transactions).
 	ReapMaxTxs(max int) types.Txs
@@ -102,6 +111,10 @@ type TxInfo struct {
 	SenderID uint16
 	// SenderP2PID is the actual p2p.ID of the sender, used e.g. for logging.
 	SenderP2PID p2p.ID
+	// Origin identifies where the tx comes from, e.g. an RPC client or a
+	// peer, for the per-origin limits of the config. The txs without an
+	// origin, e.g. those of the node itself, aren't limited.
+	Origin string
 }
 
 //--------------------------------------------------------------------------------
@@ -112,8 +125,36 @@ func PreCheckMaxBytes(maxBytes int64) PreCheckFunc {
 		txSize := types.ComputeProtoSizeForTxs([]types.Tx{tx})
 
 		if txSize > maxBytes {
-			return fmt.Errorf("tx size is too big: %d, max: %d",
-				txSize, maxBytes)
+			return ErrTxTooLarge{int(maxBytes), int(txSize)}
+		}
+		return nil
+	}
+}
+
+// PreCheckBannedPrefixes rejects the transactions starting with one of
+// [prefixes].
+func PreCheckBannedPrefixes(prefixes [][]byte) PreCheckFunc {
+	return func(tx types.Tx) error {
+		for _, prefix := range prefixes {
+			if bytes.HasPrefix(tx, prefix) {
+				return fmt.Errorf("tx prefix %X is banned", prefix)
+			}
+		}
+		return nil
+	}
+}
+
+// PreCheckAll runs [fns] in order, returning the error of the first one
+// rejecting the transaction. Nil functions are skipped.
+func PreCheckAll(fns ...PreCheckFunc) PreCheckFunc {
+	return func(tx types.Tx) error {
+		for _, fn := range fns {
+			if fn == nil {
+				continue
+			}
+			if err := fn(tx); err != nil {
+				return err
+			}
 		}
 		return nil
 	}
@@ -13,17 +13,57 @@ const (
 	MetricsSubsystem = "mempool"
 )
 
+// The values of the reason label of RejectedTxs. RejectedSaturated is for the
+// txs rejected before CheckTx as the mempool is nearly full, e.g. by the RPC.
+const (
+	RejectedDuplicate     = "duplicate"
+	RejectedTooLarge      = "too_large"
+	RejectedPreCheck      = "pre_check"
+	RejectedOriginLimited = "origin_limited"
+	RejectedApp           = "app"
+	RejectedPostCheck     = "post_check"
+	RejectedUnderpriced   = "underpriced"
+	RejectedFull          = "full"
+	RejectedSaturated     = "saturated"
+)
+
 // Metrics contains metrics exposed by this package.
 // see MetricsProvider for descriptions.
 type Metrics struct {
 	// Size of the mempool.
 	Size metrics.Gauge
+	// Total size of the transactions in the mempool, in bytes.
+	SizeBytes metrics.Gauge
 	// Histogram of transaction sizes, in bytes.
 	TxSizeBytes metrics.Histogram
 	// Number of failed transactions.
 	FailedTxs metrics.Counter
+	// Number of transactions added to the mempool.
+	AddedTxs metrics.Counter
+	// Number of transactions rejected, labeled with the reason, one of the
+	// Rejected* reasons.
+	RejectedTxs metrics.Counter
+	// Histogram of the durations of the rechecks, in seconds.
+	RecheckDurationSeconds metrics.Histogram
+	// Number of transactions evicted as the app rejected them when rechecking
+	// them.
+	InvalidatedTxs metrics.Counter
+	// Age of the transactions when they leave the mempool, included in a
+	// block or evicted, in seconds.
+	TxAgeSeconds metrics.Histogram
 	// Number of times transactions are rechecked in the mempool.
 	RecheckTimes metrics.Counter
+	// Number of transactions evicted to make room for higher priority ones.
+	EvictedTxs metrics.Counter
+	// Number of transactions evicted for outliving the TTL of the mempool.
+	ExpiredTxs metrics.Counter
+	// Number of transactions replaced by a higher priority one conflicting
+	// with them.
+	ReplacedTxs metrics.Counter
+	// Number of transactions rejected as already seen by the cache.
+	CacheHits metrics.Counter
+	// Number of transactions not in the cache, which are checked.
+	CacheMisses metrics.Counter
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
@@ -41,6 +81,12 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Name:      "size",
 			Help:      "Size of the mempool (number of uncommitted transactions).",
 		}, labels).With(labelsAndValues...),
+		SizeBytes: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "size_bytes",
+			Help:      "Total size of the transactions in the mempool, in bytes.",
+		}, labels).With(labelsAndValues...),
 		TxSizeBytes: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
@@ -54,21 +100,94 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Name:      "failed_txs",
 			Help:      "Number of failed transactions.",
 		}, labels).With(labelsAndValues...),
+		AddedTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "added_txs",
+			Help:      "Number of transactions added to the mempool.",
+		}, labels).With(labelsAndValues...),
+		RejectedTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "rejected_txs",
+			Help:      "Number of transactions rejected, by reason.",
+		}, append(labels, "reason")).With(labelsAndValues...),
+		RecheckDurationSeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "recheck_duration_seconds",
+			Help:      "Durations of the rechecks, in seconds.",
+			Buckets:   stdprometheus.ExponentialBuckets(0.001, 4, 9),
+		}, labels).With(labelsAndValues...),
+		InvalidatedTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "invalidated_txs",
+			Help:      "Number of transactions evicted as the app rejected them when rechecking them.",
+		}, labels).With(labelsAndValues...),
+		TxAgeSeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "tx_age_seconds",
+			Help:      "Age of the transactions when they leave the mempool, in seconds.",
+			Buckets:   stdprometheus.ExponentialBuckets(0.1, 4, 10),
+		}, labels).With(labelsAndValues...),
 		RecheckTimes: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "recheck_times",
 			Help:      "Number of times transactions are rechecked in the mempool.",
 		}, labels).With(labelsAndValues...),
+		EvictedTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "evicted_txs",
+			Help:      "Number of transactions evicted to make room for higher priority ones.",
+		}, labels).With(labelsAndValues...),
+		ExpiredTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "expired_txs",
+			Help:      "Number of transactions evicted for outliving the TTL of the mempool.",
+		}, labels).With(labelsAndValues...),
+		ReplacedTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "replaced_txs",
+			Help:      "Number of transactions replaced by a higher priority one conflicting with them.",
+		}, labels).With(labelsAndValues...),
+		CacheHits: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "cache_hits",
+			Help:      "Number of transactions rejected as already seen by the cache.",
+		}, labels).With(labelsAndValues...),
+		CacheMisses: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "cache_misses",
+			Help:      "Number of transactions not in the cache, which are checked.",
+		}, labels).With(labelsAndValues...),
 	}
 }
 
 // NopMetrics returns no-op Metrics.
 func NopMetrics() *Metrics {
 	return &Metrics{
-		Size:         discard.NewGauge(),
-		TxSizeBytes:  discard.NewHistogram(),
-		FailedTxs:    discard.NewCounter(),
-		RecheckTimes: discard.NewCounter(),
+		Size:                   discard.NewGauge(),
+		SizeBytes:              discard.NewGauge(),
+		TxSizeBytes:            discard.NewHistogram(),
+		FailedTxs:              discard.NewCounter(),
+		AddedTxs:               discard.NewCounter(),
+		RejectedTxs:            discard.NewCounter(),
+		RecheckTimes:           discard.NewCounter(),
+		RecheckDurationSeconds: discard.NewHistogram(),
+		EvictedTxs:             discard.NewCounter(),
+		InvalidatedTxs:         discard.NewCounter(),
+		ExpiredTxs:             discard.NewCounter(),
+		ReplacedTxs:            discard.NewCounter(),
+		TxAgeSeconds:           discard.NewHistogram(),
+		CacheHits:              discard.NewCounter(),
+		CacheMisses:            discard.NewCounter(),
 	}
 }
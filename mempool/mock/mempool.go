@@ -18,6 +18,9 @@ func (Mempool) Size() int { return 0 }
 func (Mempool) CheckTx(_ types.Tx, _ func(*abci.Response), _ mempl.TxInfo) error {
 	return nil
 }
+func (Mempool) CheckTxBatch(txs types.Txs, _ func(int, *abci.Response), _ mempl.TxInfo) []error {
+	return make([]error, len(txs))
+}
 func (Mempool) ReapMaxBytesMaxGas(_, _ int64) types.Txs { return types.Txs{} }
 func (Mempool) ReapMaxTxs(n int) types.Txs              { return types.Txs{} }
 func (Mempool) Update(
@@ -0,0 +1,120 @@
+package mempool
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// originBucketsSweepInterval is how often the token buckets refilled to the
+// burst, which are the same as new ones, are dropped.
+const originBucketsSweepInterval = time.Minute
+
+// originLimiter applies the limits of the config to the transactions of each
+// origin, see TxInfo.Origin: the rate at which they are checked, with a token
+// bucket per origin, and the number of them the mempool holds.
+type originLimiter struct {
+	rate   float64
+	burst  float64
+	maxTxs int
+
+	mtx       sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	// txs counts the transactions of each origin held by the mempool or being
+	// checked for the first time.
+	txs map[string]int
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newOriginLimiter(rate float64, burst, maxTxs int) *originLimiter {
+	if burst == 0 {
+		burst = int(math.Ceil(rate))
+	}
+	return &originLimiter{
+		rate:      rate,
+		burst:     float64(burst),
+		maxTxs:    maxTxs,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+		txs:       make(map[string]int),
+	}
+}
+
+// reserve takes a token of [origin] and counts a transaction of it, returning
+// ErrOriginLimited if either is over the limit. The transaction must be
+// released if it isn't added to the mempool.
+func (l *originLimiter) reserve(origin string, now time.Time) error {
+	if origin == "" {
+		return nil
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.maxTxs > 0 && l.txs[origin] >= l.maxTxs {
+		return ErrOriginLimited{Origin: origin, Reason: "holds the maximum number of transactions"}
+	}
+	if l.rate > 0 {
+		l.sweep(now)
+		bucket, ok := l.buckets[origin]
+		if !ok {
+			bucket = &tokenBucket{tokens: l.burst, last: now}
+			l.buckets[origin] = bucket
+		}
+		l.refill(bucket, now)
+		if bucket.tokens < 1 {
+			return ErrOriginLimited{Origin: origin, Reason: "over the transaction rate"}
+		}
+		bucket.tokens--
+	}
+	l.txs[origin]++
+	return nil
+}
+
+// release uncounts a transaction of [origin], which left the mempool or was
+// rejected.
+func (l *originLimiter) release(origin string) {
+	if origin == "" {
+		return
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.txs[origin] <= 1 {
+		delete(l.txs, origin)
+		return
+	}
+	l.txs[origin]--
+}
+
+// reset uncounts all the transactions, once the mempool was flushed.
+func (l *originLimiter) reset() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.txs = make(map[string]int)
+}
+
+func (l *originLimiter) refill(bucket *tokenBucket, now time.Time) {
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+}
+
+// sweep drops the buckets refilled to the burst, so that the buckets of the
+// origins seen once don't pile up.
+// NOTE: the caller must hold mtx.
+func (l *originLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < originBucketsSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for origin, bucket := range l.buckets {
+		if l.refill(bucket, now); bucket.tokens >= l.burst {
+			delete(l.buckets, origin)
+		}
+	}
+}
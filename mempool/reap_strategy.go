@@ -0,0 +1,90 @@
+package mempool
+
+import (
+	"sort"
+	"time"
+
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// ReapTx is a transaction of the mempool as seen by a ReapStrategy.
+type ReapTx struct {
+	Tx types.Tx
+	// Priority is the priority returned by the app from CheckTx.
+	Priority  int64
+	GasWanted int64
+	// Sender is the sender the app reported along with a sequence, see
+	// SequenceAttributeKey, or else the first sender account it reported, if
+	// any.
+	Sender string
+	// Timestamp is when the transaction was added to the mempool.
+	Timestamp time.Time
+
+	memTx *mempoolTx
+}
+
+// ReapStrategy selects the transactions of the next block, by the order in
+// which the transactions of the mempool are reaped.
+type ReapStrategy interface {
+	// Order reorders [txs], which are in arrival order, into the order they
+	// are reaped in. The transactions of a sender are then put back in the
+	// order of their sequences, in the places they got.
+	Order(txs []*ReapTx)
+}
+
+// FIFOReapStrategy reaps the transactions in arrival order.
+type FIFOReapStrategy struct{}
+
+var _ ReapStrategy = FIFOReapStrategy{}
+
+func (FIFOReapStrategy) Order([]*ReapTx) {}
+
+// PriorityReapStrategy reaps the transactions of the highest priority first
+// and, among equal priorities, the oldest first. It is the default strategy.
+type PriorityReapStrategy struct{}
+
+var _ ReapStrategy = PriorityReapStrategy{}
+
+func (PriorityReapStrategy) Order(txs []*ReapTx) {
+	sort.SliceStable(txs, func(i, j int) bool {
+		return txs[i].Priority > txs[j].Priority
+	})
+}
+
+// RoundRobinReapStrategy reaps a transaction of each sender in turn, in the
+// order their first transactions arrived, so that a sender with many
+// transactions doesn't delay the others. A transaction without a sender is a
+// sender of its own.
+type RoundRobinReapStrategy struct{}
+
+var _ ReapStrategy = RoundRobinReapStrategy{}
+
+func (RoundRobinReapStrategy) Order(txs []*ReapTx) {
+	var (
+		queues   [][]*ReapTx
+		bySender = make(map[string]int)
+	)
+	for _, tx := range txs {
+		if i, ok := bySender[tx.Sender]; ok {
+			queues[i] = append(queues[i], tx)
+			continue
+		}
+		if tx.Sender != "" {
+			bySender[tx.Sender] = len(queues)
+		}
+		queues = append(queues, []*ReapTx{tx})
+	}
+
+	n := 0
+	for len(queues) > 0 {
+		next := queues[:0]
+		for _, queue := range queues {
+			txs[n] = queue[0]
+			n++
+			if len(queue) > 1 {
+				next = append(next, queue[1:])
+			}
+		}
+		queues = next
+	}
+}
@@ -77,6 +77,19 @@ func (app *appConnConsensus) InitChainSync(req types.RequestInitChain) (*types.R
 	return app.appConn.InitChainSync(req)
 }
 
+// SetOptionSync isn't part of AppConnConsensus. It is meant for callers
+// which signal options to the app in between blocks, see the vm package.
+func (app *appConnConsensus) SetOptionSync(req types.RequestSetOption) (*types.ResponseSetOption, error) {
+	return app.appConn.SetOptionSync(req)
+}
+
+// FlushSync isn't part of AppConnConsensus either. It is meant for callers
+// which need the response to a tx before delivering the next one, see
+// state.DeliverTxsWithinGas.
+func (app *appConnConsensus) FlushSync() error {
+	return app.appConn.FlushSync()
+}
+
 func (app *appConnConsensus) BeginBlockSync(req types.RequestBeginBlock) (*types.ResponseBeginBlock, error) {
 	return app.appConn.BeginBlockSync(req)
 }
@@ -217,6 +217,16 @@ func ReadLimit(readLimit int64) func(*wsConnection) {
 	}
 }
 
+// RemoteAddr overrides the remote address the connection reports, which also
+// identifies its subscriptions. Connections sharing an address, such as those
+// accepted on a Unix socket, must be given distinct addresses.
+// It should only be used in the constructor - not Goroutine-safe.
+func RemoteAddr(remoteAddr string) func(*wsConnection) {
+	return func(wsc *wsConnection) {
+		wsc.remoteAddr = remoteAddr
+	}
+}
+
 // OnStart implements service.Service by starting the read and write routines. It
 // blocks until there's some error.
 func (wsc *wsConnection) OnStart() error {
@@ -0,0 +1,86 @@
+package state
+
+import (
+	"fmt"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	"github.com/consideritdone/landslidecore/proxy"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+const (
+	// CodespaceBlockGas and CodeBlockGasExceeded are the codespace and code of
+	// the responses of the txs skipped because their block used up its gas.
+	CodespaceBlockGas           = "block_gas"
+	CodeBlockGasExceeded uint32 = 1
+)
+
+// flusher is implemented by the consensus connections which can wait for the
+// responses to the txs delivered so far.
+type flusher interface {
+	FlushSync() error
+}
+
+// BlockMaxGas returns the gas limit of the block at [height], -1 if it isn't
+// limited or the params of the height aren't known.
+func BlockMaxGas(store Store, height int64) int64 {
+	params, err := store.LoadConsensusParams(height)
+	if err != nil {
+		return -1
+	}
+	return params.Block.MaxGas
+}
+
+// DeliverTxsWithinGas delivers [txs] to the app until they used more than
+// [maxGas], as reported in their responses, and skips the txs which follow.
+// The responses of the delivered txs are set in [responses] by the response
+// callback of [conn], while those of the skipped txs are set to
+// CodeBlockGasExceeded. It returns the number of skipped txs. A negative
+// [maxGas] doesn't limit the txs.
+//
+// The gas of a tx is only known once the app executed it, so the tx from which
+// the txs used more than [maxGas] is delivered too: the block may use up to
+// the gas of that tx over its limit. The builders keep blocks within the gas
+// the txs wanted in CheckTx, which the apps don't exceed in DeliverTx.
+func DeliverTxsWithinGas(
+	conn proxy.AppConnConsensus,
+	txs types.Txs,
+	maxGas int64,
+	responses []*abci.ResponseDeliverTx,
+) (int, error) {
+	flusher, ok := conn.(flusher)
+	limited := ok && maxGas >= 0
+
+	var gasUsed int64
+	for i, tx := range txs {
+		reqRes := conn.DeliverTxAsync(abci.RequestDeliverTx{Tx: tx})
+		if err := conn.Error(); err != nil {
+			return 0, err
+		}
+		if !limited {
+			continue
+		}
+
+		// the gas used by the tx is known once it is answered
+		if err := flusher.FlushSync(); err != nil {
+			return 0, err
+		}
+		res := reqRes.Response.GetDeliverTx()
+		if res == nil {
+			return 0, fmt.Errorf("no DeliverTx response for tx %X: %v", tx.Hash(), reqRes.Response)
+		}
+		gasUsed += res.GasUsed
+		if gasUsed <= maxGas {
+			continue
+		}
+		for j := i + 1; j < len(txs); j++ {
+			responses[j] = &abci.ResponseDeliverTx{
+				Code:      CodeBlockGasExceeded,
+				Codespace: CodespaceBlockGas,
+				Log:       fmt.Sprintf("block gas limit %d exceeded", maxGas),
+			}
+		}
+		return len(txs) - i - 1, nil
+	}
+	return 0, nil
+}
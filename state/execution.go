@@ -315,12 +315,10 @@ func execBlockOnProxyApp(
 		return nil, err
 	}
 
-	// run txs of block
-	for _, tx := range block.Txs {
-		proxyAppConn.DeliverTxAsync(abci.RequestDeliverTx{Tx: tx})
-		if err := proxyAppConn.Error(); err != nil {
-			return nil, err
-		}
+	// run txs of block, until they used up the gas of the block
+	skippedTxs, err := DeliverTxsWithinGas(proxyAppConn, block.Txs, BlockMaxGas(store, block.Height), dtxs)
+	if err != nil {
+		return nil, err
 	}
 
 	// End block.
@@ -330,7 +328,8 @@ func execBlockOnProxyApp(
 		return nil, err
 	}
 
-	logger.Info("executed block", "height", block.Height, "num_valid_txs", validTxs, "num_invalid_txs", invalidTxs)
+	logger.Info("executed block", "height", block.Height, "num_valid_txs", validTxs, "num_invalid_txs", invalidTxs,
+		"num_skipped_txs", skippedTxs)
 	return abciResponses, nil
 }
 
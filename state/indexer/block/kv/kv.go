@@ -77,6 +77,34 @@ func (idx *BlockerIndexer) Index(bh types.EventDataNewBlockHeader) error {
 	return batch.WriteSync()
 }
 
+// Delete removes the height and the BeginBlock and EndBlock events indexed
+// for a given block. The events must be the same as the ones that were
+// indexed, so that all keys derived from them can be recomputed.
+func (idx *BlockerIndexer) Delete(bh types.EventDataNewBlockHeader) error {
+	batch := idx.store.NewBatch()
+	defer batch.Close()
+
+	height := bh.Header.Height
+
+	key, err := heightKey(height)
+	if err != nil {
+		return fmt.Errorf("failed to create block height index key: %w", err)
+	}
+	if err := batch.Delete(key); err != nil {
+		return err
+	}
+
+	if err := idx.deleteEvents(batch, bh.ResultBeginBlock.Events, "begin_block", height); err != nil {
+		return fmt.Errorf("failed to delete BeginBlock events: %w", err)
+	}
+
+	if err := idx.deleteEvents(batch, bh.ResultEndBlock.Events, "end_block", height); err != nil {
+		return fmt.Errorf("failed to delete EndBlock events: %w", err)
+	}
+
+	return batch.WriteSync()
+}
+
 // Search performs a query for block heights that match a given BeginBlock
 // and Endblock event search criteria. The given query can match against zero,
 // one or more block heights. In the case of height queries, i.e. block.height=H,
@@ -488,3 +516,29 @@ func (idx *BlockerIndexer) indexEvents(batch dbm.Batch, events []abci.Event, typ
 
 	return nil
 }
+
+func (idx *BlockerIndexer) deleteEvents(batch dbm.Batch, events []abci.Event, typ string, height int64) error {
+	for _, event := range events {
+		if len(event.Type) == 0 {
+			continue
+		}
+
+		for _, attr := range event.Attributes {
+			if len(attr.Key) == 0 || !attr.GetIndex() {
+				continue
+			}
+
+			compositeKey := fmt.Sprintf("%s.%s", event.Type, string(attr.Key))
+			key, err := eventKey(compositeKey, typ, string(attr.Value), height)
+			if err != nil {
+				return fmt.Errorf("failed to create block index key: %w", err)
+			}
+
+			if err := batch.Delete(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -140,3 +140,41 @@ func TestBlockIndexer(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockIndexerDelete(t *testing.T) {
+	store := db.NewPrefixDB(db.NewMemDB(), []byte("block_events"))
+	indexer := blockidxkv.New(store)
+
+	header := types.EventDataNewBlockHeader{
+		Header: types.Header{Height: 1},
+		ResultEndBlock: abci.ResponseEndBlock{
+			Events: []abci.Event{
+				{
+					Type: "end_event",
+					Attributes: []abci.EventAttribute{
+						{
+							Key:   []byte("foo"),
+							Value: []byte("100"),
+							Index: true,
+						},
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, indexer.Index(header))
+
+	results, err := indexer.Search(context.Background(), query.MustParse("end_event.foo = 100"))
+	require.NoError(t, err)
+	require.Equal(t, []int64{1}, results)
+
+	require.NoError(t, indexer.Delete(header))
+
+	has, err := indexer.Has(1)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	results, err = indexer.Search(context.Background(), query.MustParse("end_event.foo = 100"))
+	require.NoError(t, err)
+	require.Empty(t, results)
+}
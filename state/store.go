@@ -440,7 +440,10 @@ func (store dbStore) LoadValidators(height int64) (*types.ValidatorSet, error) {
 			return nil, err
 		}
 
-		vs.IncrementProposerPriority(tmmath.SafeConvertInt32(height - lastStoredHeight)) // mutate
+		// an empty set has no proposer to rotate
+		if !vs.IsNilOrEmpty() {
+			vs.IncrementProposerPriority(tmmath.SafeConvertInt32(height - lastStoredHeight)) // mutate
+		}
 		vi2, err := vs.ToProto()
 		if err != nil {
 			return nil, err
@@ -132,6 +132,42 @@ func (txi *TxIndex) Index(result *abci.TxResult) error {
 	return b.WriteSync()
 }
 
+// Delete removes a single transaction and the keys indexed from its events.
+// The result must be the same as the one that was indexed, so that all keys
+// derived from it can be recomputed.
+func (txi *TxIndex) Delete(result *abci.TxResult) error {
+	b := txi.store.NewBatch()
+	defer b.Close()
+
+	hash := types.Tx(result.Tx).Hash()
+
+	for _, event := range result.Result.Events {
+		if len(event.Type) == 0 {
+			continue
+		}
+
+		for _, attr := range event.Attributes {
+			if len(attr.Key) == 0 || !attr.GetIndex() {
+				continue
+			}
+
+			compositeTag := fmt.Sprintf("%s.%s", event.Type, string(attr.Key))
+			if err := b.Delete(keyForEvent(compositeTag, attr.Value, result)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := b.Delete(keyForHeight(result)); err != nil {
+		return err
+	}
+	if err := b.Delete(hash); err != nil {
+		return err
+	}
+
+	return b.WriteSync()
+}
+
 func (txi *TxIndex) indexEvents(result *abci.TxResult, hash []byte, store dbm.Batch) error {
 	for _, event := range result.Result.Events {
 		// only index events with a non-empty type
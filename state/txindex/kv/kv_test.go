@@ -66,6 +66,35 @@ func TestTxIndex(t *testing.T) {
 	assert.True(t, proto.Equal(txResult2, loadedTxResult2))
 }
 
+func TestTxIndexDelete(t *testing.T) {
+	indexer := NewTxIndex(db.NewMemDB())
+
+	txResult := txResultWithEvents([]abci.Event{
+		{Type: "account", Attributes: []abci.EventAttribute{{Key: []byte("number"), Value: []byte("1"), Index: true}}},
+	})
+	hash := types.Tx(txResult.Tx).Hash()
+
+	require.NoError(t, indexer.Index(txResult))
+
+	results, err := indexer.Search(context.Background(), query.MustParse("account.number = 1"))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	require.NoError(t, indexer.Delete(txResult))
+
+	loadedTxResult, err := indexer.Get(hash)
+	require.NoError(t, err)
+	assert.Nil(t, loadedTxResult)
+
+	results, err = indexer.Search(context.Background(), query.MustParse("account.number = 1"))
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	results, err = indexer.Search(context.Background(), query.MustParse("tx.height = 1"))
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
 func TestTxSearch(t *testing.T) {
 	indexer := NewTxIndex(db.NewMemDB())
 
@@ -96,10 +96,41 @@ func (bs *BlockStore) LoadBlock(height int64) *types.Block {
 		return nil
 	}
 
+	buf := bs.loadBlockBytes(blockMeta)
+	if buf == nil {
+		return nil
+	}
 	pbb := new(tmproto.Block)
+	err := proto.Unmarshal(buf, pbb)
+	if err != nil {
+		// NOTE: The existence of meta should imply the existence of the
+		// block. So, make sure meta is only saved after blocks are saved.
+		panic(fmt.Sprintf("Error reading block: %v", err))
+	}
+
+	block, err := types.BlockFromProto(pbb)
+	if err != nil {
+		panic(fmt.Errorf("error from proto block: %w", err))
+	}
+
+	return block
+}
+
+// LoadBlockBytes returns the protobuf encoding of the block at the given
+// height, as saved in its parts, without decoding it.
+// If no block is found for that height, it returns nil.
+func (bs *BlockStore) LoadBlockBytes(height int64) []byte {
+	var blockMeta = bs.LoadBlockMeta(height)
+	if blockMeta == nil {
+		return nil
+	}
+	return bs.loadBlockBytes(blockMeta)
+}
+
+func (bs *BlockStore) loadBlockBytes(blockMeta *types.BlockMeta) []byte {
 	buf := []byte{}
 	for i := 0; i < int(blockMeta.BlockID.PartSetHeader.Total); i++ {
-		part := bs.LoadBlockPart(height, i)
+		part := bs.LoadBlockPart(blockMeta.Header.Height, i)
 		// If the part is missing (e.g. since it has been deleted after we
 		// loaded the block meta) we consider the whole block to be missing.
 		if part == nil {
@@ -107,19 +138,25 @@ func (bs *BlockStore) LoadBlock(height int64) *types.Block {
 		}
 		buf = append(buf, part.Bytes...)
 	}
-	err := proto.Unmarshal(buf, pbb)
+	return buf
+}
+
+// LoadBlockMetaByHash returns the block meta of the block with the given hash.
+// If no block is found for that hash, it returns nil.
+func (bs *BlockStore) LoadBlockMetaByHash(hash []byte) *types.BlockMeta {
+	bz, err := bs.db.Get(calcBlockHashKey(hash))
 	if err != nil {
-		// NOTE: The existence of meta should imply the existence of the
-		// block. So, make sure meta is only saved after blocks are saved.
-		panic(fmt.Sprintf("Error reading block: %v", err))
+		panic(err)
+	}
+	if len(bz) == 0 {
+		return nil
 	}
 
-	block, err := types.BlockFromProto(pbb)
+	height, err := strconv.ParseInt(string(bz), 10, 64)
 	if err != nil {
-		panic(fmt.Errorf("error from proto block: %w", err))
+		panic(fmt.Sprintf("failed to extract height from %s: %v", string(bz), err))
 	}
-
-	return block
+	return bs.LoadBlockMeta(height)
 }
 
 // LoadBlockByHash returns the block with the given hash.
@@ -456,6 +493,58 @@ func (bs *BlockStore) SaveSeenCommit(height int64, seenCommit *types.Commit) err
 	return bs.db.Set(calcSeenCommitKey(height), seenCommitBytes)
 }
 
+// DeleteLatestBlock removes the block pointed to by height,
+// lowering height by one.
+func (bs *BlockStore) DeleteLatestBlock() error {
+	bs.mtx.RLock()
+	targetHeight := bs.height
+	base := bs.base
+	bs.mtx.RUnlock()
+
+	if targetHeight == 0 {
+		return fmt.Errorf("no blocks to delete")
+	}
+
+	batch := bs.db.NewBatch()
+	defer batch.Close()
+
+	// delete what we can, skipping what's already missing, to ensure partial
+	// blocks get deleted fully.
+	if meta := bs.LoadBlockMeta(targetHeight); meta != nil {
+		if err := batch.Delete(calcBlockHashKey(meta.BlockID.Hash)); err != nil {
+			return err
+		}
+		for p := 0; p < int(meta.BlockID.PartSetHeader.Total); p++ {
+			if err := batch.Delete(calcBlockPartKey(targetHeight, p)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := batch.Delete(calcBlockCommitKey(targetHeight - 1)); err != nil {
+		return err
+	}
+	if err := batch.Delete(calcSeenCommitKey(targetHeight)); err != nil {
+		return err
+	}
+	// delete last, so as to not leave keys built on meta.BlockID dangling
+	if err := batch.Delete(calcBlockMetaKey(targetHeight)); err != nil {
+		return err
+	}
+
+	bs.mtx.Lock()
+	bs.height = targetHeight - 1
+	if base == targetHeight {
+		bs.base = 0
+	}
+	bs.mtx.Unlock()
+	bs.saveState()
+
+	if err := batch.WriteSync(); err != nil {
+		return fmt.Errorf("failed to delete height %v: %w", targetHeight, err)
+	}
+	return nil
+}
+
 func (bs *BlockStore) Close() error {
 	return bs.db.Close()
 }
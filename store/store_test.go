@@ -509,6 +509,37 @@ func TestPruneBlocks(t *testing.T) {
 	assert.Nil(t, bs.LoadBlock(1501))
 }
 
+func TestDeleteLatestBlock(t *testing.T) {
+	state, bs, cleanup := makeStateAndBlockStore(log.NewTMLogger(new(bytes.Buffer)))
+	defer cleanup()
+
+	// deleting from an empty store should error
+	require.Error(t, bs.DeleteLatestBlock())
+
+	for h := int64(1); h <= 3; h++ {
+		block := makeBlock(h, state, new(types.Commit))
+		partSet := block.MakePartSet(2)
+		seenCommit := makeTestCommit(h, tmtime.Now())
+		bs.SaveBlock(block, partSet, seenCommit)
+	}
+	latestBlock := bs.LoadBlock(3)
+
+	require.NoError(t, bs.DeleteLatestBlock())
+	assert.EqualValues(t, 1, bs.Base())
+	assert.EqualValues(t, 2, bs.Height())
+	assert.Nil(t, bs.LoadBlock(3))
+	assert.Nil(t, bs.LoadBlockMeta(3))
+	assert.Nil(t, bs.LoadBlockByHash(latestBlock.Hash()))
+	assert.Nil(t, bs.LoadBlockCommit(2))
+	assert.Nil(t, bs.LoadSeenCommit(3))
+	assert.NotNil(t, bs.LoadBlock(2))
+
+	// the next block can be saved again at the deleted height
+	block := makeBlock(3, state, new(types.Commit))
+	bs.SaveBlock(block, block.MakePartSet(2), makeTestCommit(3, tmtime.Now()))
+	assert.EqualValues(t, 3, bs.Height())
+}
+
 func TestLoadBlockMeta(t *testing.T) {
 	bs, db := freshBlockStore()
 	height := int64(10)
@@ -21,6 +21,9 @@ func (emptyMempool) Size() int { return 0 }
 func (emptyMempool) CheckTx(_ types.Tx, _ func(*abci.Response), _ mempl.TxInfo) error {
 	return nil
 }
+func (emptyMempool) CheckTxBatch(txs types.Txs, _ func(int, *abci.Response), _ mempl.TxInfo) []error {
+	return make([]error, len(txs))
+}
 func (emptyMempool) ReapMaxBytesMaxGas(_, _ int64) types.Txs { return types.Txs{} }
 func (emptyMempool) ReapMaxTxs(n int) types.Txs              { return types.Txs{} }
 func (emptyMempool) Update(
@@ -187,6 +187,26 @@ func (b *EventBus) PublishEventTx(data EventDataTx) error {
 	return b.pubsub.PublishWithEvents(ctx, data, events)
 }
 
+// PublishEventTxExpired publishes the expiration of a tx, with its hash under
+// TxHashKey.
+func (b *EventBus) PublishEventTxExpired(data EventDataTxExpired) error {
+	events := map[string][]string{
+		EventTypeKey: {EventTxExpired},
+		TxHashKey:    {fmt.Sprintf("%X", data.Tx.Hash())},
+	}
+	return b.pubsub.PublishWithEvents(context.Background(), data, events)
+}
+
+// PublishEventTxEvicted publishes the eviction of a tx, with its hash under
+// TxHashKey.
+func (b *EventBus) PublishEventTxEvicted(data EventDataTxEvicted) error {
+	events := map[string][]string{
+		EventTypeKey: {EventTxEvicted},
+		TxHashKey:    {fmt.Sprintf("%X", data.Tx.Hash())},
+	}
+	return b.pubsub.PublishWithEvents(context.Background(), data, events)
+}
+
 func (b *EventBus) PublishEventNewRoundStep(data EventDataRoundState) error {
 	return b.Publish(EventNewRoundStep, data)
 }
@@ -267,6 +287,14 @@ func (NopEventBus) PublishEventTx(data EventDataTx) error {
 	return nil
 }
 
+func (NopEventBus) PublishEventTxExpired(data EventDataTxExpired) error {
+	return nil
+}
+
+func (NopEventBus) PublishEventTxEvicted(data EventDataTxEvicted) error {
+	return nil
+}
+
 func (NopEventBus) PublishEventNewRoundStep(data EventDataRoundState) error {
 	return nil
 }
@@ -22,6 +22,15 @@ const (
 	EventTx                  = "Tx"
 	EventValidatorSetUpdates = "ValidatorSetUpdates"
 
+	// EventTxExpired is triggered from the mempool when an unconfirmed tx is
+	// evicted because it outlived the TTL of the mempool.
+	EventTxExpired = "TxExpired"
+	// EventTxEvicted is triggered from the mempool when an unconfirmed tx is
+	// evicted for another reason, see EventDataTxEvicted. Subscribing to the
+	// hash of a tx under TxHashKey follows it until it is included in a
+	// block, expires or is evicted.
+	EventTxEvicted = "TxEvicted"
+
 	// Internal consensus events.
 	// These are used for testing the consensus state machine.
 	// They can also be used to build real-time consensus visualizers.
@@ -50,6 +59,8 @@ func init() {
 	tmjson.RegisterType(EventDataNewBlockHeader{}, "tendermint/event/NewBlockHeader")
 	tmjson.RegisterType(EventDataNewEvidence{}, "tendermint/event/NewEvidence")
 	tmjson.RegisterType(EventDataTx{}, "tendermint/event/Tx")
+	tmjson.RegisterType(EventDataTxExpired{}, "tendermint/event/TxExpired")
+	tmjson.RegisterType(EventDataTxEvicted{}, "tendermint/event/TxEvicted")
 	tmjson.RegisterType(EventDataRoundState{}, "tendermint/event/RoundState")
 	tmjson.RegisterType(EventDataNewRound{}, "tendermint/event/NewRound")
 	tmjson.RegisterType(EventDataCompleteProposal{}, "tendermint/event/CompleteProposal")
@@ -87,6 +98,21 @@ type EventDataTx struct {
 	abci.TxResult
 }
 
+// EventDataTxExpired is fired for the txs evicted from the mempool by its TTL.
+type EventDataTxExpired struct {
+	Tx Tx `json:"tx"`
+	// Height is the height of the block after which the tx expired.
+	Height int64 `json:"height"`
+}
+
+// EventDataTxEvicted is fired for the txs evicted from the mempool before
+// they expired.
+type EventDataTxEvicted struct {
+	Tx Tx `json:"tx"`
+	// Reason is why the tx was evicted, one of the mempool.Evicted* reasons.
+	Reason string `json:"reason"`
+}
+
 // NOTE: This goes into the replay WAL
 type EventDataRoundState struct {
 	Height int64  `json:"height"`
@@ -155,6 +181,8 @@ var (
 	EventQueryTimeoutPropose      = QueryForEvent(EventTimeoutPropose)
 	EventQueryTimeoutWait         = QueryForEvent(EventTimeoutWait)
 	EventQueryTx                  = QueryForEvent(EventTx)
+	EventQueryTxExpired           = QueryForEvent(EventTxExpired)
+	EventQueryTxEvicted           = QueryForEvent(EventTxEvicted)
 	EventQueryUnlock              = QueryForEvent(EventUnlock)
 	EventQueryValidatorSetUpdates = QueryForEvent(EventValidatorSetUpdates)
 	EventQueryValidBlock          = QueryForEvent(EventValidBlock)
@@ -15,6 +15,9 @@ import (
 const (
 	ABCIPubKeyTypeEd25519   = ed25519.KeyType
 	ABCIPubKeyTypeSecp256k1 = secp256k1.KeyType
+	// ABCIPubKeyTypeBls12381 is bls12381.KeyType, which isn't imported as the
+	// package needs cgo.
+	ABCIPubKeyTypeBls12381 = "bls12_381"
 )
 
 // TODO: Make non-global by allowing for registration of more pubkey types
@@ -22,6 +25,7 @@ const (
 var ABCIPubKeyTypesToNames = map[string]string{
 	ABCIPubKeyTypeEd25519:   ed25519.PubKeyName,
 	ABCIPubKeyTypeSecp256k1: secp256k1.PubKeyName,
+	ABCIPubKeyTypeBls12381:  "tendermint/PubKeyBls12_381",
 }
 
 //-------------------------------------------------------
@@ -0,0 +1,176 @@
+package vm
+
+import (
+	"encoding/binary"
+
+	tmstate "github.com/consideritdone/landslidecore/proto/tendermint/state"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// lastIndexedHeightKey holds the height of the last block indexed by the
+// acceptor, so that the blocks still queued when the node stopped are indexed
+// on restart.
+var lastIndexedHeightKey = []byte("lastIndexedHeight")
+
+// acceptedBlock is a block committed by Accept, whose indexing, events and
+// pruning are left to the acceptor.
+type acceptedBlock struct {
+	block         *types.Block
+	abciResponses *tmstate.ABCIResponses
+	retainHeight  int64
+	// flushed is closed once the blocks queued before it are processed, if
+	// it isn't nil, in which case the other fields are unset.
+	flushed chan struct{}
+}
+
+// acceptor processes the accepted blocks in the background, in the order they
+// are accepted, so that a slow indexer or event subscriber doesn't stall
+// consensus. Accept waits once the queue is full.
+type acceptor struct {
+	vm *VM
+	// blocks is nil if the blocks are processed within Accept.
+	blocks chan acceptedBlock
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+func newAcceptor(vm *VM, queueSize int) *acceptor {
+	a := &acceptor{
+		vm:   vm,
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if queueSize == 0 {
+		close(a.done)
+		return a
+	}
+	a.blocks = make(chan acceptedBlock, queueSize)
+	go a.run()
+	return a
+}
+
+func (a *acceptor) run() {
+	defer close(a.done)
+
+	for {
+		select {
+		case b := <-a.blocks:
+			a.process(b)
+		case <-a.quit:
+			// the queued blocks are processed before stopping
+			for {
+				select {
+				case b := <-a.blocks:
+					a.process(b)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (a *acceptor) process(b acceptedBlock) {
+	if b.flushed != nil {
+		close(b.flushed)
+		return
+	}
+	a.vm.processAccepted(b)
+}
+
+// accept queues [b], waiting while the queue is full, or processes it right
+// away if there is no queue.
+func (a *acceptor) accept(b acceptedBlock) {
+	if a.blocks == nil {
+		a.vm.processAccepted(b)
+		return
+	}
+	a.blocks <- b
+}
+
+// flush waits for the blocks queued so far to be processed.
+func (a *acceptor) flush() {
+	if a.blocks == nil {
+		return
+	}
+	flushed := make(chan struct{})
+	a.blocks <- acceptedBlock{flushed: flushed}
+	<-flushed
+}
+
+// stop processes the queued blocks and stops the acceptor.
+func (a *acceptor) stop() {
+	close(a.quit)
+	<-a.done
+}
+
+// processAccepted indexes [b], publishes its events and schedules the pruning
+// below the retain height the app committed it with.
+func (vm *VM) processAccepted(b acceptedBlock) {
+	err := vm.atomically(func() error {
+		if err := vm.indexBlock(b.block, b.abciResponses); err != nil {
+			return err
+		}
+		return vm.setLastIndexedHeight(b.block.Height)
+	})
+	if err != nil {
+		vm.tmLogger.Error("failed to index block", "height", b.block.Height, "err", err)
+	} else if vm.eventStream != nil {
+		vm.eventStream.notify()
+	}
+
+	vm.sinkEvents(b.block, b.abciResponses)
+	fireEvents(vm.tmLogger, vm.eventBus, b.block, b.abciResponses)
+
+	if b.retainHeight > 0 {
+		vm.pruner.retain(b.retainHeight)
+	}
+}
+
+// setLastIndexedHeight records [height] as the height of the last indexed
+// block.
+func (vm *VM) setLastIndexedHeight(height int64) error {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, uint64(height))
+	return vm.stateDB.Set(lastIndexedHeightKey, value)
+}
+
+// lastIndexedHeight returns the height of the last indexed block, if it is
+// recorded.
+func (vm *VM) lastIndexedHeight() (int64, bool, error) {
+	value, err := vm.stateDB.Get(lastIndexedHeightKey)
+	if err != nil || len(value) == 0 {
+		return 0, false, err
+	}
+	return int64(binary.BigEndian.Uint64(value)), true, nil
+}
+
+// indexMissedBlocks indexes the blocks accepted after the last indexed one,
+// which were queued when the node stopped. The blocks of the databases which
+// predate the acceptor were indexed within Accept.
+func (vm *VM) indexMissedBlocks() error {
+	lastIndexed, ok, err := vm.lastIndexedHeight()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if vm.tmState.LastBlockHeight > 0 {
+			return nil
+		}
+		return vm.atomically(func() error {
+			return vm.setLastIndexedHeight(0)
+		})
+	}
+	for height := lastIndexed + 1; height <= vm.tmState.LastBlockHeight; height++ {
+		block := vm.blockStore.LoadBlock(height)
+		if block == nil {
+			continue
+		}
+		abciResponses, err := vm.stateStore.LoadABCIResponses(height)
+		if err != nil {
+			return err
+		}
+		vm.processAccepted(acceptedBlock{block: block, abciResponses: abciResponses})
+	}
+	return nil
+}
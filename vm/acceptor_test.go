@@ -0,0 +1,51 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+func TestAcceptor(t *testing.T) {
+	txIndexed := func(vm *VM, tx types.Tx) bool {
+		r, err := vm.txIndexer.Get(tx.Hash())
+		require.NoError(t, err)
+		return r != nil
+	}
+
+	// without a queue, blocks are indexed within Accept
+	vm, _, _, err := newTestVMWithDB(kvstore.NewApplication(), manager.NewMemDB(&version.Semantic{Major: 1}), []byte(`{"acceptQueueSize":0}`))
+	require.NoError(t, err)
+	mustAcceptBlock(t, vm, NewService(vm), []byte("a=1"))
+	assert.True(t, txIndexed(vm, []byte("a=1")))
+
+	// otherwise in the background
+	app := kvstore.NewApplication()
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	vm, _, _, err = newTestVMWithDB(app, dbManager, nil)
+	require.NoError(t, err)
+	service := NewService(vm)
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	mustAcceptBlock(t, vm, service, []byte("b=2"))
+	vm.acceptor.flush()
+	assert.True(t, txIndexed(vm, []byte("a=1")))
+	assert.True(t, txIndexed(vm, []byte("b=2")))
+
+	// the blocks still queued when the node stopped are indexed on restart
+	require.NoError(t, vm.atomically(func() error {
+		if err := vm.deleteIndexedBlock(2); err != nil {
+			return err
+		}
+		return vm.setLastIndexedHeight(1)
+	}))
+	require.False(t, txIndexed(vm, []byte("b=2")))
+	vm, _, _, err = newTestVMWithDB(app, dbManager, nil)
+	require.NoError(t, err)
+	assert.True(t, txIndexed(vm, []byte("b=2")))
+}
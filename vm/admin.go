@@ -0,0 +1,213 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ava-labs/avalanchego/snow/choices"
+
+	"github.com/consideritdone/landslidecore/types"
+)
+
+var (
+	errRollbackDisabled = errors.New("rollback is only served with adminAPIEnabled")
+	errBlocksProcessing = errors.New("blocks built on the last accepted block are processing")
+)
+
+type (
+	LocalAdminService struct {
+		vm *VM
+	}
+
+	// AdminService is served on a separate handler from Service so that
+	// operators can restrict access to it.
+	AdminService interface {
+		SetLogLevel(_ *http.Request, args *SetLogLevelArgs, reply *LogLevelReply) error
+		GetLogLevel(_ *http.Request, _ *struct{}, reply *LogLevelReply) error
+		Prune(_ *http.Request, args *PruneArgs, reply *PruneReply) error
+		Compact(_ *http.Request, _ *struct{}, reply *struct{}) error
+		Rollback(_ *http.Request, _ *struct{}, reply *RollbackReply) error
+		WSConnections(_ *http.Request, _ *struct{}, reply *WSConnectionsReply) error
+		ExportGenesis(_ *http.Request, args *ExportGenesisArgs, reply *ExportGenesisReply) error
+		FlushMempoolCache(_ *http.Request, _ *struct{}, reply *FlushMempoolCacheReply) error
+	}
+
+	SetLogLevelArgs struct {
+		// Level uses the Tendermint log_level syntax: a single level
+		// ("info") or a comma-separated list of module:level pairs
+		// ("mempool:debug,rpc:error,*:info").
+		Level string `json:"level"`
+	}
+
+	LogLevelReply struct {
+		Level string `json:"level"`
+	}
+
+	PruneArgs struct {
+		// RetainHeight is the lowest height to keep, everything below it is
+		// removed.
+		RetainHeight int64 `json:"retainHeight"`
+	}
+
+	PruneReply struct {
+		Pruned uint64 `json:"pruned"`
+		Base   int64  `json:"base"`
+	}
+
+	RollbackReply struct {
+		Height int64 `json:"height"`
+	}
+
+	FlushMempoolCacheReply struct {
+		// Flushed is the number of txs the cache held.
+		Flushed int `json:"flushed"`
+	}
+
+	WSConnectionsReply struct {
+		Connections []WSConnectionInfo `json:"connections"`
+	}
+
+	ExportGenesisArgs struct {
+		// Height is the last height of the exported chain, the latest one if
+		// it is unset.
+		Height *int64 `json:"height"`
+		// ChainID is the chain_id of the genesis, the one of the chain if it
+		// is empty.
+		ChainID string `json:"chainId"`
+		// AppStatePath is the path of the ABCI query which returns the JSON
+		// app state at a height, "/export" if it is empty.
+		AppStatePath string `json:"appStatePath"`
+	}
+
+	ExportGenesisReply struct {
+		Genesis *types.GenesisDoc `json:"genesis"`
+	}
+)
+
+func NewAdminService(vm *VM) AdminService {
+	return &LocalAdminService{vm}
+}
+
+func (s *LocalAdminService) SetLogLevel(_ *http.Request, args *SetLogLevelArgs, reply *LogLevelReply) error {
+	if err := s.vm.logLevels.SetLevel(args.Level); err != nil {
+		return err
+	}
+	s.vm.tmLogger.Info("log level changed", "level", args.Level)
+
+	reply.Level = s.vm.logLevels.Level()
+	return nil
+}
+
+func (s *LocalAdminService) GetLogLevel(_ *http.Request, _ *struct{}, reply *LogLevelReply) error {
+	reply.Level = s.vm.logLevels.Level()
+	return nil
+}
+
+func (s *LocalAdminService) Prune(_ *http.Request, args *PruneArgs, reply *PruneReply) error {
+	// the queued blocks are indexed before their indexes are pruned
+	s.vm.acceptor.flush()
+	pruned, err := s.vm.pruneBlocks(args.RetainHeight)
+	if err != nil {
+		return err
+	}
+	s.vm.tmLogger.Info("pruned blocks", "pruned", pruned, "retain_height", args.RetainHeight)
+
+	reply.Pruned = pruned
+	reply.Base = s.vm.blockStore.Base()
+	return nil
+}
+
+func (s *LocalAdminService) Compact(_ *http.Request, _ *struct{}, _ *struct{}) error {
+	return s.vm.compactDB()
+}
+
+// Rollback rewinds the state store, block store and indexers by one height,
+// as the rollback flag of the config does on startup, once the app was rolled
+// back after an app hash mismatch. It is only served with adminAPIEnabled,
+// and refuses to run while blocks are processing, as they are built on the
+// block it removes. The consensus engine only reads the last accepted block
+// on startup, so the node must be restarted to continue from the rolled back
+// height.
+func (s *LocalAdminService) Rollback(_ *http.Request, _ *struct{}, reply *RollbackReply) error {
+	vm := s.vm
+	if !vm.config.AdminAPIEnabled {
+		return errRollbackDisabled
+	}
+	if n := len(vm.processing); n > 0 {
+		return fmt.Errorf("%w: %d blocks", errBlocksProcessing, n)
+	}
+
+	vm.mempool.Lock()
+	defer vm.mempool.Unlock()
+
+	// the queued blocks are indexed before the last one is removed
+	vm.acceptor.flush()
+	var height int64
+	err := vm.atomically(func() error {
+		var err error
+		height, err = vm.rollback()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	tmBlock := vm.blockStore.LoadBlock(height)
+	if tmBlock == nil {
+		return fmt.Errorf("rolled back block %d not found", height)
+	}
+	blk, err := vm.newBlock(tmBlock)
+	if err != nil {
+		return err
+	}
+	blk.status = choices.Accepted
+	if err := vm.State.SetLastAcceptedBlock(blk); err != nil {
+		return err
+	}
+	vm.preferred = blk.ID()
+
+	reply.Height = height
+	return nil
+}
+
+func (s *LocalAdminService) WSConnections(_ *http.Request, _ *struct{}, reply *WSConnectionsReply) error {
+	if s.vm.wsServer == nil {
+		reply.Connections = []WSConnectionInfo{}
+		return nil
+	}
+	reply.Connections = s.vm.wsServer.connections()
+	return nil
+}
+
+// ExportGenesis returns a genesis which restarts the chain after a height,
+// with the app state the app exports at that height, to restart the chain or
+// fork it into a testnet.
+func (s *LocalAdminService) ExportGenesis(_ *http.Request, args *ExportGenesisArgs, reply *ExportGenesisReply) error {
+	height, err := getHeight(s.vm.blockStore, args.Height)
+	if err != nil {
+		return err
+	}
+	appStatePath := args.AppStatePath
+	if appStatePath == "" {
+		appStatePath = defaultExportAppStatePath
+	}
+	genesis, err := s.vm.exportGenesis(height, args.ChainID, appStatePath)
+	if err != nil {
+		return err
+	}
+	reply.Genesis = genesis
+	return nil
+}
+
+// FlushMempoolCache empties the cache of the txs seen by the mempool, so that
+// the txs submitted again are checked again rather than rejected as already
+// existing.
+func (s *LocalAdminService) FlushMempoolCache(_ *http.Request, _ *struct{}, reply *FlushMempoolCacheReply) error {
+	flusher, ok := s.vm.mempool.(cacheFlusher)
+	if !ok {
+		return errors.New("the mempool doesn't support flushing its cache")
+	}
+	reply.Flushed = flusher.FlushCache()
+	return nil
+}
@@ -0,0 +1,322 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+	tmjson "github.com/consideritdone/landslidecore/libs/json"
+	mempl "github.com/consideritdone/landslidecore/mempool"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+func TestAdminService(t *testing.T) {
+	vm, _, _ := mustNewCounterTestVm(t)
+	service := NewAdminService(vm)
+
+	t.Run("GetLogLevel", func(t *testing.T) {
+		reply := new(LogLevelReply)
+		assert.NoError(t, service.GetLogLevel(nil, nil, reply))
+		assert.Equal(t, defaultLogLevel, reply.Level)
+	})
+
+	t.Run("SetLogLevel", func(t *testing.T) {
+		reply := new(LogLevelReply)
+		require.NoError(t, service.SetLogLevel(nil, &SetLogLevelArgs{Level: "mempool:debug,rpc:error,*:info"}, reply))
+		assert.Equal(t, "mempool:debug,rpc:error,*:info", reply.Level)
+
+		assert.Error(t, service.SetLogLevel(nil, &SetLogLevelArgs{Level: "mempool:verbose"}, reply))
+		assert.Error(t, service.SetLogLevel(nil, &SetLogLevelArgs{Level: ""}, reply))
+
+		reply = new(LogLevelReply)
+		assert.NoError(t, service.GetLogLevel(nil, nil, reply))
+		assert.Equal(t, "mempool:debug,rpc:error,*:info", reply.Level)
+	})
+}
+
+func TestAdminServicePrune(t *testing.T) {
+	vm, service, _ := mustNewCounterTestVm(t)
+	adminService := NewAdminService(vm)
+
+	for i := byte(0); i < 3; i++ {
+		mustAcceptBlock(t, vm, service, []byte{i})
+	}
+	require.Eventually(t, func() bool {
+		r, err := vm.txIndexer.Get(types.Tx{2}.Hash())
+		return err == nil && r != nil
+	}, 5*time.Second, 100*time.Millisecond)
+
+	t.Run("Prune", func(t *testing.T) {
+		reply := new(PruneReply)
+		require.NoError(t, adminService.Prune(nil, &PruneArgs{RetainHeight: 2}, reply))
+		assert.Equal(t, uint64(1), reply.Pruned)
+		assert.Equal(t, int64(2), reply.Base)
+		assert.Nil(t, vm.blockStore.LoadBlock(1))
+
+		// the indexers are pruned with the blocks
+		r, err := vm.txIndexer.Get(types.Tx{0}.Hash())
+		require.NoError(t, err)
+		assert.Nil(t, r)
+		r, err = vm.txIndexer.Get(types.Tx{1}.Hash())
+		require.NoError(t, err)
+		assert.NotNil(t, r)
+
+		// pruning below the base is a noop
+		reply = new(PruneReply)
+		require.NoError(t, adminService.Prune(nil, &PruneArgs{RetainHeight: 1}, reply))
+		assert.Equal(t, uint64(0), reply.Pruned)
+
+		assert.Error(t, adminService.Prune(nil, &PruneArgs{RetainHeight: 10}, reply))
+
+		// historical queries below the base name the earliest height
+		err = service.ABCIQueryWithOptions(nil, &ABCIQueryWithOptionsArgs{
+			Path: "/store",
+			Opts: ABCIQueryOptions{Height: 1},
+		}, new(ctypes.ResultABCIQuery))
+		assert.Equal(t, ErrHeightPruned{Height: 1, EarliestHeight: 2}, err)
+		assert.NoError(t, service.ABCIQueryWithOptions(nil, &ABCIQueryWithOptionsArgs{
+			Path: "/store",
+			Opts: ABCIQueryOptions{Height: 2},
+		}, new(ctypes.ResultABCIQuery)))
+	})
+
+	t.Run("Compact", func(t *testing.T) {
+		assert.NoError(t, adminService.Compact(nil, nil, nil))
+	})
+}
+
+// heightApp accepts every tx and only keeps track of its height, which tests
+// roll back the way an operator would roll back the app.
+type heightApp struct {
+	atypes.BaseApplication
+	height int64
+}
+
+func (app *heightApp) Info(atypes.RequestInfo) atypes.ResponseInfo {
+	return atypes.ResponseInfo{LastBlockHeight: app.height}
+}
+
+func (app *heightApp) Commit() atypes.ResponseCommit {
+	app.height++
+	return atypes.ResponseCommit{}
+}
+
+func TestAdminServiceRollback(t *testing.T) {
+	ctx := context.Background()
+	app := &heightApp{}
+	vm, _, _, err := newTestVMWithDB(app, manager.NewMemDB(&version.Semantic{Major: 1}), []byte(`{"adminAPIEnabled":true}`))
+	require.NoError(t, err)
+	service := NewService(vm)
+	adminService := NewAdminService(vm)
+	for i := byte(0); i < 3; i++ {
+		mustAcceptBlock(t, vm, service, []byte{i})
+	}
+	lastTxHash := types.Tx{2}.Hash()
+	require.Eventually(t, func() bool {
+		r, err := vm.txIndexer.Get(lastTxHash)
+		return err == nil && r != nil
+	}, 5*time.Second, 100*time.Millisecond)
+
+	// the app must be rolled back first
+	reply := new(RollbackReply)
+	assert.Error(t, adminService.Rollback(nil, nil, reply))
+	assert.Equal(t, int64(3), vm.blockStore.Height())
+
+	// and no block may be processing on top of the last accepted one
+	txReply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte{3}}, txReply))
+	processing, err := vm.BuildBlock(ctx)
+	require.NoError(t, err)
+	require.NoError(t, processing.Verify(ctx))
+	app.height = 2
+	assert.ErrorIs(t, adminService.Rollback(nil, nil, reply), errBlocksProcessing)
+	assert.Equal(t, int64(3), vm.blockStore.Height())
+	require.NoError(t, processing.Reject(ctx))
+
+	require.NoError(t, adminService.Rollback(nil, nil, reply))
+	assert.Equal(t, int64(2), reply.Height)
+	assert.Equal(t, int64(2), vm.blockStore.Height())
+	assert.Nil(t, vm.blockStore.LoadBlockMeta(3))
+	assert.Equal(t, vm.blockStore.LoadBlockMeta(2).BlockID, vm.tmState.LastBlockID)
+	assert.Equal(t, uint64(2), vm.LastAcceptedHeight())
+	r, err := vm.txIndexer.Get(lastTxHash)
+	require.NoError(t, err)
+	assert.Nil(t, r)
+
+	// the chain continues from the rolled back height
+	blk := mustAcceptBlock(t, vm, service, []byte{4})
+	assert.Equal(t, uint64(3), blk.Height())
+	assert.Equal(t, int64(3), vm.blockStore.Height())
+
+	// the rollback isn't served without the admin API
+	vm, _, _, err = newTestVM(&heightApp{})
+	require.NoError(t, err)
+	assert.ErrorIs(t, NewAdminService(vm).Rollback(nil, nil, reply), errRollbackDisabled)
+}
+
+func TestRollbackOnStartup(t *testing.T) {
+	app := &heightApp{}
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	vm, _, _, err := newTestVMWithDB(app, dbManager, nil)
+	require.NoError(t, err)
+	service := NewService(vm)
+	for i := byte(0); i < 3; i++ {
+		mustAcceptBlock(t, vm, service, []byte{i})
+	}
+	lastTxHash := types.Tx{2}.Hash()
+	require.Eventually(t, func() bool {
+		r, err := vm.txIndexer.Get(lastTxHash)
+		return err == nil && r != nil
+	}, 5*time.Second, 100*time.Millisecond)
+
+	// the app is ahead of the rolled back state
+	_, _, _, err = newTestVMWithDB(app, dbManager, []byte(`{"rollback":true}`))
+	require.Error(t, err)
+
+	app.height = 2
+	vm, _, _, err = newTestVMWithDB(app, dbManager, []byte(`{"rollback":true}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), vm.blockStore.Height())
+	assert.Nil(t, vm.blockStore.LoadBlockMeta(3))
+	assert.Equal(t, int64(2), vm.tmState.LastBlockHeight)
+	assert.Equal(t, vm.blockStore.LoadBlockMeta(2).BlockID, vm.tmState.LastBlockID)
+	r, err := vm.txIndexer.Get(lastTxHash)
+	require.NoError(t, err)
+	assert.Nil(t, r)
+
+	// the rollback is only done once
+	vm, _, _, err = newTestVMWithDB(app, dbManager, []byte(`{"rollback":true}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), vm.blockStore.Height())
+	assert.Equal(t, int64(2), vm.tmState.LastBlockHeight)
+
+	// until the flag is unset
+	vm, _, _, err = newTestVMWithDB(app, dbManager, nil)
+	require.NoError(t, err)
+	done, err := vm.stateDB.Has(rollbackMarkerKey)
+	require.NoError(t, err)
+	assert.False(t, done)
+
+	// and the chain continues from the rolled back height
+	blk := mustAcceptBlock(t, vm, NewService(vm), []byte{3})
+	assert.Equal(t, uint64(3), blk.Height())
+	assert.Equal(t, int64(3), vm.blockStore.Height())
+}
+
+// exportApp exports the height of its app state, and answers InitChain with
+// [importedAppHash] as if it imported an exported app state.
+type exportApp struct {
+	*kvstore.Application
+	importedAppHash []byte
+}
+
+func (app *exportApp) InitChain(req atypes.RequestInitChain) atypes.ResponseInitChain {
+	res := app.Application.InitChain(req)
+	res.AppHash = app.importedAppHash
+	return res
+}
+
+func (app *exportApp) Query(req atypes.RequestQuery) atypes.ResponseQuery {
+	if req.Path != defaultExportAppStatePath {
+		return app.Application.Query(req)
+	}
+	return atypes.ResponseQuery{Value: []byte(fmt.Sprintf(`{"height":"%d"}`, req.Height))}
+}
+
+func TestAdminServiceExportGenesis(t *testing.T) {
+	app := &exportApp{Application: kvstore.NewApplication()}
+	vm, _, _, err := newTestVMWithDB(app, manager.NewMemDB(&version.Semantic{Major: 1}), nil)
+	require.NoError(t, err)
+	service := NewService(vm)
+	admin := NewAdminService(vm)
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	mustAcceptBlock(t, vm, service, []byte("b=2"))
+
+	reply := new(ExportGenesisReply)
+	require.NoError(t, admin.ExportGenesis(nil, &ExportGenesisArgs{}, reply))
+	exported := reply.Genesis
+	assert.Equal(t, vm.genesis.ChainID, exported.ChainID)
+	assert.EqualValues(t, 3, exported.InitialHeight)
+	assert.JSONEq(t, `{"height":"2"}`, string(exported.AppState))
+	assert.EqualValues(t, vm.tmState.AppHash, exported.AppHash)
+	assert.Equal(t, vm.tmState.ConsensusParams, *exported.ConsensusParams)
+
+	height := int64(1)
+	reply = new(ExportGenesisReply)
+	require.NoError(t, admin.ExportGenesis(nil, &ExportGenesisArgs{Height: &height, ChainID: "fork"}, reply))
+	assert.Equal(t, "fork", reply.Genesis.ChainID)
+	assert.EqualValues(t, 2, reply.Genesis.InitialHeight)
+	assert.EqualValues(t, vm.blockStore.LoadBlockMeta(2).Header.AppHash, reply.Genesis.AppHash)
+
+	reply = new(ExportGenesisReply)
+	assert.ErrorIs(t, admin.ExportGenesis(nil, &ExportGenesisArgs{AppStatePath: "/none"}, reply), errNoAppState)
+
+	// a chain restarts from the exported genesis
+	genesisBytes, err := tmjson.Marshal(exported)
+	require.NoError(t, err)
+	app = &exportApp{Application: kvstore.NewApplication(), importedAppHash: exported.AppHash}
+	vm, _, _, err = newTestVMWithGenesis(app, manager.NewMemDB(&version.Semantic{Major: 1}), genesisBytes, nil, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, mustAcceptBlock(t, vm, NewService(vm), []byte("c=3")).Height())
+}
+
+func TestAdminServiceFlushMempoolCache(t *testing.T) {
+	vm, service, _ := mustNewKVTestVm(t)
+	admin := NewAdminService(vm)
+
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("b=2")}, reply))
+
+	// the committed tx is rejected as already seen until the cache is flushed
+	err := service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, reply)
+	require.ErrorIs(t, err, mempl.ErrTxInCache)
+	flushReply := new(FlushMempoolCacheReply)
+	require.NoError(t, admin.FlushMempoolCache(nil, nil, flushReply))
+	assert.Equal(t, 2, flushReply.Flushed)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, reply))
+
+	// the txs still in the mempool aren't added twice
+	err = service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("b=2")}, reply)
+	require.ErrorIs(t, err, mempl.ErrTxInCache)
+	assert.Equal(t, 2, vm.mempool.Size())
+}
+
+func TestAdminServiceFlushMempoolCacheHTTP(t *testing.T) {
+	vm, service, _ := mustNewKVTestVm(t)
+
+	// the admin handler is not served on the HTTP server by default
+	handlers, err := vm.CreateHandlers(context.Background())
+	require.NoError(t, err)
+	assert.NotContains(t, handlers, "/admin")
+
+	vm.config.AdminAPIEnabled = true
+	server := newTestServer(t, vm, "/admin")
+
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	body := `{"jsonrpc":"2.0","id":1,"method":"admin.flushMempoolCache","params":{}}`
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var reply struct {
+		Result FlushMempoolCacheReply `json:"result"`
+		Error  interface{}            `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply))
+	require.Nil(t, reply.Error)
+	assert.Equal(t, 1, reply.Result.Flushed)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, new(ctypes.ResultBroadcastTx)))
+}
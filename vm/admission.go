@@ -0,0 +1,119 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/rpc/v2/json2"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	mempl "github.com/consideritdone/landslidecore/mempool"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+)
+
+// The JSON-RPC error codes of the txs BroadcastTx* didn't get into the
+// mempool, in the range of the server errors, so that clients can tell whether
+// to retry them. The data of the errors is the error message, or the
+// ResultBroadcastTx of the rejected tx with checkTxErrors.
+const (
+	// ErrCodeMempoolFull is returned when the mempool is full of txs of the
+	// same or a higher priority. The tx may be retried once blocks made room,
+	// or with a higher priority.
+	ErrCodeMempoolFull json2.ErrorCode = -32001
+	// ErrCodeTxTooLarge is returned when the tx is larger than
+	// mempoolMaxTxBytes or than a block. It is never admitted.
+	ErrCodeTxTooLarge json2.ErrorCode = -32002
+	// ErrCodeDuplicateTx is returned when the tx was seen recently: it is in
+	// the mempool, or was included or rejected lately. Retrying it doesn't
+	// help before it left the cache, see mempoolCacheTTL.
+	ErrCodeDuplicateTx json2.ErrorCode = -32003
+	// ErrCodeTxFiltered is returned when a filter rejected the tx before the
+	// app checked it, see mempoolBannedPrefixes and WithTxFilters.
+	ErrCodeTxFiltered json2.ErrorCode = -32004
+	// ErrCodeCheckTxFailed is returned, with checkTxErrors, when the app
+	// rejected the tx with a non-zero code, or the mempool did in the
+	// "mempool" codespace. The data holds the code.
+	ErrCodeCheckTxFailed json2.ErrorCode = -32005
+	// ErrCodeOriginLimited is returned when the client is over the rate or
+	// the number of txs in the mempool allowed per origin, see
+	// mempoolOriginRate and mempoolOriginMaxTxs. The tx may be retried later.
+	ErrCodeOriginLimited json2.ErrorCode = -32006
+	// ErrCodeMempoolSaturated is returned when the mempool is over
+	// mempoolHighWaterMark. The data is a RetryAfterData, and the response
+	// has a Retry-After header.
+	ErrCodeMempoolSaturated json2.ErrorCode = -32007
+)
+
+// txError is the error of BroadcastTx* for a tx which didn't get into the
+// mempool, reported by the codec with [code].
+type txError struct {
+	code    json2.ErrorCode
+	message string
+	// data is the data of the JSON-RPC error, the message of err if nil
+	data interface{}
+	err  error
+	// retryAfter, if non-zero, is sent as the Retry-After header
+	retryAfter time.Duration
+}
+
+func (e *txError) Error() string {
+	return e.err.Error()
+}
+
+func (e *txError) Unwrap() error {
+	return e.err
+}
+
+// jsonError returns the JSON-RPC error of e.
+func (e *txError) jsonError() *json2.Error {
+	data := e.data
+	if data == nil {
+		data = e.err.Error()
+	}
+	return &json2.Error{Code: e.code, Message: e.message, Data: data}
+}
+
+// admissionError wraps [err], returned by CheckTx for a tx the mempool didn't
+// send to the app, into a txError, or returns it as is if it isn't one of them.
+func admissionError(err error) error {
+	var (
+		mempoolFull mempl.ErrMempoolIsFull
+		tooLarge    mempl.ErrTxTooLarge
+		preCheck    mempl.ErrPreCheck
+		limited     mempl.ErrOriginLimited
+	)
+	switch {
+	case errors.As(err, &mempoolFull):
+		return &txError{code: ErrCodeMempoolFull, message: "Mempool is full", err: err}
+	case errors.As(err, &tooLarge):
+		return &txError{code: ErrCodeTxTooLarge, message: "Tx too large", err: err}
+	case errors.Is(err, mempl.ErrTxInCache):
+		return &txError{code: ErrCodeDuplicateTx, message: "Duplicate tx", err: err}
+	case errors.As(err, &preCheck):
+		return &txError{code: ErrCodeTxFiltered, message: "Tx filtered", err: err}
+	case errors.As(err, &limited):
+		return &txError{code: ErrCodeOriginLimited, message: "Origin limited", err: err}
+	default:
+		return err
+	}
+}
+
+// checkTxError returns the txError of [result], the CheckTx result of a tx,
+// or nil if the tx entered the mempool.
+func checkTxError(result *ctypes.ResultBroadcastTx) error {
+	if result.Code == abci.CodeTypeOK {
+		return nil
+	}
+	err := &txError{
+		code:    ErrCodeCheckTxFailed,
+		message: "CheckTx failed",
+		data:    result,
+		err:     fmt.Errorf("CheckTx failed with code %d (codespace %q): %s", result.Code, result.Codespace, result.Log),
+	}
+	if result.Codespace == mempl.Codespace && result.Code == mempl.CodeTypeMempoolFull {
+		err.code = ErrCodeMempoolFull
+		err.message = "Mempool is full"
+	}
+	return err
+}
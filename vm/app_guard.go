@@ -0,0 +1,435 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	abcicli "github.com/consideritdone/landslidecore/abci/client"
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	"github.com/consideritdone/landslidecore/proxy"
+)
+
+var (
+	errAppTimeout  = errors.New("abci call timed out")
+	errCircuitOpen = errors.New("abci circuit breaker open, the app isn't responding")
+	errAppHalted   = errors.New("abci call executing a block timed out, the chain is halted until the node restarts")
+)
+
+// circuitBreaker fails the calls to the app fast once [threshold] consecutive
+// calls timed out, rather than leaving more callers waiting on a hung app.
+// Once [cooldown] passed, a single call is let through to probe the app, and
+// the breaker closes again if it answers in time. A [permanent] breaker never
+// closes once open.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	permanent bool
+
+	mtx      sync.Mutex
+	timeouts int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow returns errCircuitOpen if the call mustn't be made.
+func (b *circuitBreaker) allow() error {
+	if b.threshold == 0 {
+		return nil
+	}
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.timeouts < b.threshold {
+		return nil
+	}
+	if b.permanent {
+		return errAppHalted
+	}
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return errCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// done records whether an allowed call [timedOut].
+func (b *circuitBreaker) done(timedOut bool) {
+	if b.threshold == 0 {
+		return
+	}
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.probing = false
+	if !timedOut {
+		b.timeouts = 0
+		return
+	}
+	b.timeouts++
+	if b.timeouts >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
+
+// isOpen returns true if the calls to the app fail fast.
+func (b *circuitBreaker) isOpen() bool {
+	if b.threshold == 0 {
+		return false
+	}
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.timeouts >= b.threshold
+}
+
+// guardedCall returns the result of [call], or an error once it takes longer
+// than [timeout], in which case the call is left to complete in the
+// background. 0 doesn't time out. The duration of the calls which complete is
+// recorded in [m], if not nil.
+func guardedCall[T any](
+	b *circuitBreaker,
+	m *vmMetrics,
+	timeout time.Duration,
+	name string,
+	call func() (T, error),
+) (T, error) {
+	start := time.Now()
+	if timeout == 0 {
+		defer m.observeABCICall(name, start)
+		return call()
+	}
+	var zero T
+	if err := b.allow(); err != nil {
+		return zero, fmt.Errorf("%s: %w", name, err)
+	}
+
+	type result struct {
+		res T
+		err error
+	}
+	resCh := make(chan result, 1)
+	go func() {
+		res, err := call()
+		m.observeABCICall(name, start)
+		resCh <- result{res, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-resCh:
+		b.done(false)
+		return r.res, r.err
+	case <-timer.C:
+		b.done(true)
+		return zero, fmt.Errorf("%w: %s after %s", errAppTimeout, name, timeout)
+	}
+}
+
+// guardedAsync is guardedCall for the async calls, which fail with an
+// exception response. The async calls return before the app answers, so their
+// duration isn't recorded.
+func guardedAsync(
+	b *circuitBreaker,
+	timeout time.Duration,
+	req *abci.Request,
+	call func() *abcicli.ReqRes,
+) *abcicli.ReqRes {
+	reqRes, err := guardedCall(b, nil, timeout, fmt.Sprintf("%T", req.Value), func() (*abcicli.ReqRes, error) {
+		return call(), nil
+	})
+	if err != nil {
+		return failedReqRes(req, err)
+	}
+	return reqRes
+}
+
+// guardedAppConns are the connections to the app with the timeouts of the
+// config around the calls which may hang: the queries, CheckTx and the
+// execution of blocks. The queries are spread over the connections of
+// [queryPool], if any, see newQueryPool, and the new txs over those of
+// [checkTxPool], see newCheckTxPool.
+//
+// Each type of connection has its own breaker, so that slow queries don't fail
+// CheckTx nor the execution of blocks. A timed out call is left running in the
+// app, which is harmless for the queries and CheckTx, but not for the calls
+// executing a block, which must not be followed by others: the first of them
+// to time out halts the chain for good, until the node restarts.
+type guardedAppConns struct {
+	proxy.AppConns
+	queryBreaker     *circuitBreaker
+	checkTxBreaker   *circuitBreaker
+	consensusBreaker *circuitBreaker
+	config           Config
+	metrics          *vmMetrics
+
+	queryPool   *queryPool
+	checkTxPool *checkTxPool
+}
+
+func newGuardedAppConns(appConns proxy.AppConns, config Config, metrics *vmMetrics) *guardedAppConns {
+	newBreaker := func() *circuitBreaker {
+		return &circuitBreaker{
+			threshold: config.ABCIBreakerThreshold,
+			cooldown:  config.ABCIBreakerCooldown.Duration,
+		}
+	}
+	return &guardedAppConns{
+		AppConns:         appConns,
+		queryBreaker:     newBreaker(),
+		checkTxBreaker:   newBreaker(),
+		consensusBreaker: &circuitBreaker{threshold: 1, permanent: true},
+		config:           config,
+		metrics:          metrics,
+	}
+}
+
+// echo checks that the app answers on the query connection, up to the query
+// timeout, even while the breaker of the queries is open.
+func (c *guardedAppConns) echo(msg string) error {
+	_, err := guardedCall(&circuitBreaker{}, c.metrics, c.config.ABCIQueryTimeout.Duration, "Echo", func() (*abci.ResponseEcho, error) {
+		return c.AppConns.Query().EchoSync(msg)
+	})
+	return err
+}
+
+func (c *guardedAppConns) Query() proxy.AppConnQuery {
+	conn := c.AppConns.Query()
+	var slots chan struct{}
+	if c.queryPool != nil {
+		conn = c.queryPool.next(conn)
+		slots = c.queryPool.slots
+	}
+	return &guardedQueryConn{
+		AppConnQuery: conn,
+		breaker:      c.queryBreaker,
+		metrics:      c.metrics,
+		timeout:      c.config.ABCIQueryTimeout.Duration,
+		slots:        slots,
+	}
+}
+
+// Stop stops the connections of the pools along with the others.
+func (c *guardedAppConns) Stop() error {
+	if c.queryPool != nil {
+		c.queryPool.stop()
+	}
+	if c.checkTxPool != nil {
+		c.checkTxPool.stop()
+	}
+	return c.AppConns.Stop()
+}
+
+func (c *guardedAppConns) Mempool() proxy.AppConnMempool {
+	return &guardedMempoolConn{
+		AppConnMempool: c.AppConns.Mempool(),
+		breaker:        c.checkTxBreaker,
+		metrics:        c.metrics,
+		timeout:        c.config.ABCICheckTxTimeout.Duration,
+	}
+}
+
+// CheckTxConns returns the mempool connections of the checkTxPool, which the
+// mempool checks the new txs on besides Mempool().
+func (c *guardedAppConns) CheckTxConns() []proxy.AppConnMempool {
+	if c.checkTxPool == nil {
+		return nil
+	}
+	conns := make([]proxy.AppConnMempool, len(c.checkTxPool.conns))
+	for i, conn := range c.checkTxPool.conns {
+		conns[i] = &guardedMempoolConn{
+			AppConnMempool: conn,
+			breaker:        c.checkTxBreaker,
+			metrics:        c.metrics,
+			timeout:        c.config.ABCICheckTxTimeout.Duration,
+		}
+	}
+	return conns
+}
+
+func (c *guardedAppConns) Consensus() proxy.AppConnConsensus {
+	return &guardedConsensusConn{
+		AppConnConsensus: c.AppConns.Consensus(),
+		breaker:          c.consensusBreaker,
+		metrics:          c.metrics,
+		timeout:          c.config.ABCIDeliverTxTimeout.Duration,
+	}
+}
+
+type guardedQueryConn struct {
+	proxy.AppConnQuery
+	breaker *circuitBreaker
+	metrics *vmMetrics
+	timeout time.Duration
+	// slots bounds the queries made at once, if not nil.
+	slots chan struct{}
+}
+
+func (c *guardedQueryConn) EchoSync(msg string) (*abci.ResponseEcho, error) {
+	return guardedCall(c.breaker, c.metrics, c.timeout, "Echo", func() (*abci.ResponseEcho, error) {
+		return c.AppConnQuery.EchoSync(msg)
+	})
+}
+
+func (c *guardedQueryConn) InfoSync(req abci.RequestInfo) (*abci.ResponseInfo, error) {
+	return guardedCall(c.breaker, c.metrics, c.timeout, "Info", func() (*abci.ResponseInfo, error) {
+		return c.AppConnQuery.InfoSync(req)
+	})
+}
+
+func (c *guardedQueryConn) QuerySync(req abci.RequestQuery) (*abci.ResponseQuery, error) {
+	if err := c.acquireSlot(); err != nil {
+		return nil, err
+	}
+	res, err := guardedCall(c.breaker, c.metrics, c.timeout, "Query", func() (*abci.ResponseQuery, error) {
+		// the slot is held until the app answers, even once timed out
+		defer c.releaseSlot()
+		return c.AppConnQuery.QuerySync(req)
+	})
+	if errors.Is(err, errCircuitOpen) {
+		// the query wasn't made
+		c.releaseSlot()
+	}
+	return res, err
+}
+
+// acquireSlot waits for one of the slots of the queries made at once, up to
+// the query timeout.
+func (c *guardedQueryConn) acquireSlot() error {
+	if c.slots == nil {
+		return nil
+	}
+	var timedOut <-chan time.Time
+	if c.timeout > 0 {
+		timer := time.NewTimer(c.timeout)
+		defer timer.Stop()
+		timedOut = timer.C
+	}
+	select {
+	case c.slots <- struct{}{}:
+		return nil
+	case <-timedOut:
+		return fmt.Errorf("%w after %s", errQueriesBusy, c.timeout)
+	}
+}
+
+func (c *guardedQueryConn) releaseSlot() {
+	if c.slots != nil {
+		<-c.slots
+	}
+}
+
+type guardedMempoolConn struct {
+	proxy.AppConnMempool
+	breaker *circuitBreaker
+	metrics *vmMetrics
+	timeout time.Duration
+}
+
+func (c *guardedMempoolConn) CheckTxAsync(req abci.RequestCheckTx) *abcicli.ReqRes {
+	return guardedAsync(c.breaker, c.timeout, abci.ToRequestCheckTx(req), func() *abcicli.ReqRes {
+		return c.AppConnMempool.CheckTxAsync(req)
+	})
+}
+
+func (c *guardedMempoolConn) CheckTxSync(req abci.RequestCheckTx) (*abci.ResponseCheckTx, error) {
+	return guardedCall(c.breaker, c.metrics, c.timeout, "CheckTx", func() (*abci.ResponseCheckTx, error) {
+		return c.AppConnMempool.CheckTxSync(req)
+	})
+}
+
+func (c *guardedMempoolConn) FlushSync() error {
+	_, err := guardedCall(c.breaker, c.metrics, c.timeout, "Flush", func() (struct{}, error) {
+		return struct{}{}, c.AppConnMempool.FlushSync()
+	})
+	return err
+}
+
+// guardedConsensusConn times out the execution of blocks. A block whose
+// execution times out fails, and so do all the calls after, as the timed out
+// call may still complete in the app: the chain is halted until the node
+// restarts, see guardedAppConns.
+type guardedConsensusConn struct {
+	proxy.AppConnConsensus
+	breaker *circuitBreaker
+	metrics *vmMetrics
+	timeout time.Duration
+}
+
+func (c *guardedConsensusConn) SetOptionSync(req abci.RequestSetOption) (*abci.ResponseSetOption, error) {
+	setter, ok := c.AppConnConsensus.(optionSetter)
+	if !ok {
+		return nil, errNoOptionSetter
+	}
+	return setter.SetOptionSync(req)
+}
+
+func (c *guardedConsensusConn) FlushSync() error {
+	flusher, ok := c.AppConnConsensus.(interface{ FlushSync() error })
+	if !ok {
+		return nil
+	}
+	_, err := guardedCall(c.breaker, c.metrics, c.timeout, "Flush", func() (struct{}, error) {
+		return struct{}{}, flusher.FlushSync()
+	})
+	return err
+}
+
+func (c *guardedConsensusConn) BeginBlockSync(req abci.RequestBeginBlock) (*abci.ResponseBeginBlock, error) {
+	return guardedCall(c.breaker, c.metrics, c.timeout, "BeginBlock", func() (*abci.ResponseBeginBlock, error) {
+		return c.AppConnConsensus.BeginBlockSync(req)
+	})
+}
+
+func (c *guardedConsensusConn) DeliverTxAsync(req abci.RequestDeliverTx) *abcicli.ReqRes {
+	return guardedAsync(c.breaker, c.timeout, abci.ToRequestDeliverTx(req), func() *abcicli.ReqRes {
+		return c.AppConnConsensus.DeliverTxAsync(req)
+	})
+}
+
+func (c *guardedConsensusConn) EndBlockSync(req abci.RequestEndBlock) (*abci.ResponseEndBlock, error) {
+	return guardedCall(c.breaker, c.metrics, c.timeout, "EndBlock", func() (*abci.ResponseEndBlock, error) {
+		return c.AppConnConsensus.EndBlockSync(req)
+	})
+}
+
+func (c *guardedConsensusConn) CommitSync() (*abci.ResponseCommit, error) {
+	return guardedCall(c.breaker, c.metrics, c.timeout, "Commit", func() (*abci.ResponseCommit, error) {
+		return c.AppConnConsensus.CommitSync()
+	})
+}
+
+// awaitCheckTx waits for the CheckTx response sent on [resCh], up to the
+// abciCheckTxTimeout config or until [ctx] is done, as the response of an
+// out-of-process app comes after CheckTxAsync returns.
+func (vm *VM) awaitCheckTx(ctx context.Context, resCh <-chan *abci.Response) (*abci.ResponseCheckTx, error) {
+	timeout := vm.config.ABCICheckTxTimeout.Duration
+	var timedOut <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timedOut = timer.C
+	}
+	select {
+	case res := <-resCh:
+		return checkTxResponse(res)
+	case <-timedOut:
+		return nil, fmt.Errorf("%w: CheckTx after %s", errAppTimeout, timeout)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("stopped waiting for CheckTx: %w", ctx.Err())
+	}
+}
+
+// checkTxResponse returns the CheckTx response in [res], or the error the
+// client answered with instead, e.g. once the call timed out.
+func checkTxResponse(res *abci.Response) (*abci.ResponseCheckTx, error) {
+	if r := res.GetCheckTx(); r != nil {
+		return r, nil
+	}
+	if e := res.GetException(); e != nil {
+		return nil, errors.New(e.Error)
+	}
+	return nil, fmt.Errorf("unexpected response to CheckTx: %T", res.GetValue())
+}
@@ -0,0 +1,117 @@
+package vm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+)
+
+// hangingApp is a kvstore app whose queries hang until [release] is closed.
+type hangingApp struct {
+	*kvstore.Application
+	release chan struct{}
+}
+
+func (app *hangingApp) Query(req atypes.RequestQuery) atypes.ResponseQuery {
+	<-app.release
+	return app.Application.Query(req)
+}
+
+func TestAppTimeouts(t *testing.T) {
+	app := &hangingApp{Application: kvstore.NewApplication(), release: make(chan struct{})}
+	configBytes := []byte(`{"abciQueryTimeout": "50ms", "abciBreakerThreshold": 2, "abciBreakerCooldown": "500ms"}`)
+	vm, _, _, err := newTestVMWithDB(app, manager.NewMemDB(&version.Semantic{Major: 1}), configBytes)
+	require.NoError(t, err)
+	service := NewService(vm)
+	ctx := context.Background()
+
+	query := func() error {
+		return service.ABCIQuery(nil, &ABCIQueryArgs{Path: "/key", Data: []byte("a")}, new(ctypes.ResultABCIQuery))
+	}
+	assert.ErrorIs(t, query(), errAppTimeout)
+	assert.ErrorIs(t, query(), errAppTimeout)
+
+	// the breaker is open: the queries fail right away
+	start := time.Now()
+	assert.ErrorIs(t, query(), errCircuitOpen)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	// but neither CheckTx nor the execution of blocks, once the in-process
+	// app is released, and the node stays healthy
+	close(app.release)
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	details, err := vm.HealthCheck(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, true, details.(map[string]interface{})["abciQueryBreakerOpen"])
+
+	// until the app answers a probe again
+	require.Eventually(t, func() bool {
+		return query() == nil
+	}, 5*time.Second, 20*time.Millisecond)
+	details, err = vm.HealthCheck(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, false, details.(map[string]interface{})["abciQueryBreakerOpen"])
+}
+
+// hangingBlockApp is a kvstore app whose BeginBlock hangs until [release] is
+// closed.
+type hangingBlockApp struct {
+	*kvstore.Application
+	release chan struct{}
+}
+
+func (app *hangingBlockApp) BeginBlock(req atypes.RequestBeginBlock) atypes.ResponseBeginBlock {
+	<-app.release
+	return app.Application.BeginBlock(req)
+}
+
+func TestAppHaltsOnBlockTimeout(t *testing.T) {
+	app := &hangingBlockApp{Application: kvstore.NewApplication(), release: make(chan struct{})}
+	configBytes := []byte(`{"abciDeliverTxTimeout": "50ms", "abciBreakerThreshold": 2, "abciBreakerCooldown": "1ms"}`)
+	vm, _, _, err := newTestVMWithDB(app, manager.NewMemDB(&version.Semantic{Major: 1}), configBytes)
+	require.NoError(t, err)
+	service := NewService(vm)
+	ctx := context.Background()
+
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, reply))
+	blk, err := vm.BuildBlock(ctx)
+	require.NoError(t, err)
+	assert.ErrorIs(t, blk.Accept(ctx), errAppTimeout)
+
+	// the block isn't executed again, even once the app answers
+	close(app.release)
+	time.Sleep(10 * time.Millisecond)
+	assert.ErrorIs(t, blk.Accept(ctx), errAppHalted)
+	_, err = vm.HealthCheck(ctx)
+	assert.ErrorIs(t, err, errAppHalted)
+}
+
+func TestMaxConcurrentQueries(t *testing.T) {
+	app := &hangingApp{Application: kvstore.NewApplication(), release: make(chan struct{})}
+	configBytes := []byte(`{"abciQueryTimeout": "50ms", "abciBreakerThreshold": 0, "abciMaxConcurrentQueries": 1}`)
+	vm, _, _, err := newTestVMWithDB(app, manager.NewMemDB(&version.Semantic{Major: 1}), configBytes)
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	query := func() error {
+		return service.ABCIQuery(nil, &ABCIQueryArgs{Path: "/key", Data: []byte("a")}, new(ctypes.ResultABCIQuery))
+	}
+	// the hanging query holds the only slot once it timed out
+	assert.ErrorIs(t, query(), errAppTimeout)
+	assert.ErrorIs(t, query(), errQueriesBusy)
+
+	close(app.release)
+	require.Eventually(t, func() bool {
+		return query() == nil
+	}, 5*time.Second, 20*time.Millisecond)
+}
@@ -0,0 +1,76 @@
+package vm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	tmbytes "github.com/consideritdone/landslidecore/libs/bytes"
+)
+
+var (
+	// appHashKeyPrefix prefixes the app hash the app committed each accepted
+	// block with, which the state summaries at its height carry.
+	appHashKeyPrefix = []byte("appHash/")
+
+	errAppHashMismatch     = errors.New("block app hash doesn't match the app state of its parent")
+	errResultsHashMismatch = errors.New("block last results hash doesn't match the results of its parent")
+)
+
+// verifyExecution checks that the AppHash and LastResultsHash of [b] are
+// those of the execution of its parent, which is the last accepted block, see
+// parentState. Both are known from the state, so blocks are verified after a
+// restart too.
+func (b *Block) verifyExecution() error {
+	vm := b.vm
+	header := &b.tmBlock.Header
+	if !bytes.Equal(vm.tmState.AppHash, header.AppHash) {
+		vm.tmLogger.Error(
+			"block app hash mismatch",
+			"height", header.Height,
+			"parent_height", vm.tmState.LastBlockHeight,
+			"expected", tmbytes.HexBytes(vm.tmState.AppHash),
+			"got", header.AppHash,
+		)
+		return fmt.Errorf("%w: expected %X, got %X", errAppHashMismatch, vm.tmState.AppHash, header.AppHash)
+	}
+	if !bytes.Equal(vm.tmState.LastResultsHash, header.LastResultsHash) {
+		vm.tmLogger.Error(
+			"block last results hash mismatch",
+			"height", header.Height,
+			"parent_height", vm.tmState.LastBlockHeight,
+			"expected", tmbytes.HexBytes(vm.tmState.LastResultsHash),
+			"got", header.LastResultsHash,
+		)
+		return fmt.Errorf("%w: expected %X, got %X", errResultsHashMismatch, vm.tmState.LastResultsHash, header.LastResultsHash)
+	}
+	return nil
+}
+
+func appHashKey(height int64) []byte {
+	key := make([]byte, len(appHashKeyPrefix)+8)
+	copy(key, appHashKeyPrefix)
+	binary.BigEndian.PutUint64(key[len(appHashKeyPrefix):], uint64(height))
+	return key
+}
+
+// appHashAt returns the app hash after the accepted block at [height]. Unlike
+// the hashes blocks carry, it is recorded for every height, but it isn't known
+// for the blocks accepted before it was recorded or since pruned, in which
+// case it returns false.
+func (vm *VM) appHashAt(height int64) ([]byte, bool, error) {
+	if height == vm.tmState.LastBlockHeight {
+		return vm.tmState.AppHash, true, nil
+	}
+	key := appHashKey(height)
+	ok, err := vm.stateDB.Has(key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	appHash, err := vm.stateDB.Get(key)
+	if err != nil {
+		return nil, false, err
+	}
+	return appHash, true, nil
+}
@@ -0,0 +1,101 @@
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/ava-labs/avalanchego/vms/components/chain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	"github.com/consideritdone/landslidecore/crypto/tmhash"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	sm "github.com/consideritdone/landslidecore/state"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+func TestVerifyExecution(t *testing.T) {
+	app := kvstore.NewApplication()
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	vm, _, _, err := newTestVMWithDB(app, dbManager, nil)
+	require.NoError(t, err)
+	service := NewService(vm)
+	ctx := context.Background()
+	first := mustAcceptBlock(t, vm, service, []byte("a=1"))
+	mustAcceptBlock(t, vm, service, []byte("b=2"))
+
+	makeBlock := func(state *sm.State, tamper func(*types.Header)) *Block {
+		height := nextHeight(state)
+		tmBlock, _ := state.MakeBlock(height, types.Txs{[]byte("c=3")}, makeCommitMock(height), nil, proposerAddress)
+		tmBlock.Time = vm.nextBlockTime(state)
+		tamper(&tmBlock.Header)
+		blk, err := vm.newBlock(tmBlock)
+		require.NoError(t, err)
+		return blk
+	}
+
+	badAppHash := makeBlock(vm.tmState, func(header *types.Header) {
+		header.AppHash = []byte("bad app hash")
+	})
+	assert.ErrorIs(t, badAppHash.Verify(ctx), errAppHashMismatch)
+	badResultsHash := makeBlock(vm.tmState, func(header *types.Header) {
+		header.LastResultsHash = tmhash.Sum([]byte("bad results"))
+	})
+	assert.ErrorIs(t, badResultsHash.Verify(ctx), errResultsHashMismatch)
+
+	withHashesOf := func(blk snowman.Block) *Block {
+		header := &blk.(*chain.BlockWrapper).Block.(*Block).tmBlock.Header
+		return makeBlock(vm.tmState, func(h *types.Header) {
+			h.AppHash = header.AppHash
+			h.LastResultsHash = header.LastResultsHash
+		})
+	}
+
+	// a block built on a processing parent can't carry the hashes of its
+	// execution, so it isn't built, nor verified
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("d=4")}, reply))
+	blk1, err := vm.BuildBlock(ctx)
+	require.NoError(t, err)
+	require.NoError(t, blk1.Verify(ctx))
+	require.NoError(t, vm.SetPreference(ctx, blk1.ID()))
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("e=5")}, reply))
+	_, err = vm.BuildBlock(ctx)
+	assert.ErrorIs(t, err, errParentProcessing)
+
+	parent := blk1.(*chain.BlockWrapper).Block.(*Block).tmBlock
+	parentState := vm.tmState.Copy()
+	parentState.LastBlockHeight = parent.Height
+	parentState.LastBlockID = types.BlockID{
+		Hash:          parent.Hash(),
+		PartSetHeader: parent.MakePartSet(types.BlockPartSizeBytes).Header(),
+	}
+	parentState.LastBlockTime = parent.Time
+	child := makeBlock(&parentState, func(*types.Header) {})
+	assert.ErrorIs(t, child.Verify(ctx), errParentProcessing)
+
+	// once it is accepted, the next block carries the hashes of its
+	// execution, and not the older ones its parent carries
+	require.NoError(t, blk1.Accept(ctx))
+	assert.ErrorIs(t, withHashesOf(blk1).Verify(ctx), errAppHashMismatch)
+	assert.ErrorIs(t, withHashesOf(first).Verify(ctx), errAppHashMismatch)
+	blk2, err := vm.BuildBlock(ctx)
+	require.NoError(t, err)
+	require.NoError(t, blk2.Verify(ctx))
+	require.NoError(t, blk2.Accept(ctx))
+	assert.Equal(t, int64(blk2.Height()), vm.tmState.LastBlockHeight)
+
+	// the hashes are verified after a restart too
+	vm, _, _, err = newTestVMWithDB(app, dbManager, nil)
+	require.NoError(t, err)
+	badAppHash = makeBlock(vm.tmState, func(header *types.Header) {
+		header.AppHash = []byte("bad app hash")
+	})
+	assert.ErrorIs(t, badAppHash.Verify(ctx), errAppHashMismatch)
+	assert.ErrorIs(t, withHashesOf(blk2).Verify(ctx), errAppHashMismatch)
+	assert.NoError(t, makeBlock(vm.tmState, func(*types.Header) {}).Verify(ctx))
+}
@@ -0,0 +1,58 @@
+package vm
+
+import (
+	"errors"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+
+	tmbytes "github.com/consideritdone/landslidecore/libs/bytes"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// headerAttestationDomain starts the messages of the header attestations.
+// Like every attestation domain, it isn't a valid codec version of a warp
+// message, which starts with 0x0000, so no attestation signature verifies as
+// the signature of a warp message, even if the attestation key is the node's
+// BLS key.
+var headerAttestationDomain = []byte("landslide-header-attestation")
+
+var errNoAttestationKey = errors.New("attestations require the key given to WithAttestationKey")
+
+// CommitAttestation is the node's BLS signature over the hash of a block
+// header. Blocks carry no Tendermint commit signatures, so it is what off-chain
+// consumers can verify a header against, given they trust the node's key.
+//
+// Message is the "landslide-header-attestation" domain, followed by the ID of
+// this chain and the header hash. Signature verifies against PublicKey over
+// the message bytes.
+type CommitAttestation struct {
+	NodeID    ids.NodeID       `json:"nodeId"`
+	PublicKey tmbytes.HexBytes `json:"publicKey"`
+	Message   tmbytes.HexBytes `json:"message"`
+	Signature tmbytes.HexBytes `json:"signature"`
+}
+
+// attestHeader signs the hash of [header] with the attestation key.
+func (vm *VM) attestHeader(header *types.Header) (*CommitAttestation, error) {
+	return vm.attest(headerAttestationDomain, vm.ctx.ChainID[:], header.Hash())
+}
+
+// attest signs the message made of [domain] followed by [fields] with the
+// attestation key. The fields of a domain must all have a fixed length but the
+// last, so that its messages are unambiguous.
+func (vm *VM) attest(domain []byte, fields ...[]byte) (*CommitAttestation, error) {
+	if vm.attestationKey == nil {
+		return nil, errNoAttestationKey
+	}
+	msg := append([]byte(nil), domain...)
+	for _, field := range fields {
+		msg = append(msg, field...)
+	}
+	return &CommitAttestation{
+		NodeID:    vm.ctx.NodeID,
+		PublicKey: bls.PublicKeyToBytes(bls.PublicFromSecretKey(vm.attestationKey)),
+		Message:   msg,
+		Signature: bls.SignatureToBytes(bls.Sign(vm.attestationKey, msg)),
+	}, nil
+}
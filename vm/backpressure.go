@@ -0,0 +1,57 @@
+package vm
+
+import (
+	"fmt"
+	"math"
+
+	mempl "github.com/consideritdone/landslidecore/mempool"
+)
+
+// RetryAfterData is the data of the ErrCodeMempoolSaturated errors.
+type RetryAfterData struct {
+	// Usage is the fraction of the mempool capacity used.
+	Usage float64 `json:"usage"`
+	// RetryAfter is how long the client should wait before submitting the tx
+	// again, see mempoolRetryAfter.
+	RetryAfter Duration `json:"retryAfter"`
+}
+
+// mempoolUsage returns the fraction of the mempool capacity used, in txs or
+// bytes, whichever is higher.
+func (vm *VM) mempoolUsage() float64 {
+	return math.Max(
+		float64(vm.mempool.Size())/float64(vm.config.MempoolSize),
+		float64(vm.mempool.TxsBytes())/float64(vm.config.MempoolMaxTxsBytes),
+	)
+}
+
+// mempoolSaturated returns the usage of the mempool and whether it is over
+// MempoolHighWaterMark.
+func (vm *VM) mempoolSaturated() (float64, bool) {
+	highWaterMark := vm.config.MempoolHighWaterMark
+	if highWaterMark == 0 {
+		return 0, false
+	}
+	usage := vm.mempoolUsage()
+	return usage, usage >= highWaterMark
+}
+
+// checkBackpressure returns an ErrCodeMempoolSaturated txError if the mempool
+// is over MempoolHighWaterMark, so that BroadcastTx* reject the txs before
+// the app checks them.
+func (vm *VM) checkBackpressure() error {
+	usage, saturated := vm.mempoolSaturated()
+	if !saturated {
+		return nil
+	}
+	vm.mempoolMetrics.RejectedTxs.With("reason", mempl.RejectedSaturated).Add(1)
+	retryAfter := vm.config.MempoolRetryAfter
+	return &txError{
+		code:    ErrCodeMempoolSaturated,
+		message: "Mempool saturated",
+		data:    &RetryAfterData{Usage: usage, RetryAfter: retryAfter},
+		err: fmt.Errorf("%w: %.2f of its capacity used, from %.2f, retry after %s",
+			errMempoolSaturated, usage, vm.config.MempoolHighWaterMark, retryAfter),
+		retryAfter: retryAfter.Duration,
+	}
+}
@@ -0,0 +1,62 @@
+package vm
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	mempl "github.com/consideritdone/landslidecore/mempool"
+)
+
+func TestMempoolBackpressure(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	vm, _, _, err := newTestVMWithDB(kvstore.NewApplication(), dbManager,
+		[]byte(`{"mempoolSize":4,"mempoolHighWaterMark":0.5,"mempoolRetryAfter":"1500ms"}`))
+	require.NoError(t, err)
+	server := newTestServer(t, vm, "/rpc")
+
+	broadcast := func(tx string) (*http.Response, *RetryAfterData) {
+		body := `{"jsonrpc":"2.0","id":1,"method":"` + Name + `.broadcastTxSync","params":{"tx":"` + tx + `"}}`
+		resp, err := http.Post(server.URL, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var reply struct {
+			Error *struct {
+				Code int             `json:"code"`
+				Data *RetryAfterData `json:"data"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply))
+		if reply.Error == nil {
+			return resp, nil
+		}
+		assert.Equal(t, int(ErrCodeMempoolSaturated), reply.Error.Code)
+		return resp, reply.Error.Data
+	}
+
+	// "a=1" and "b=2"
+	_, data := broadcast("YT0x")
+	require.Nil(t, data)
+	_, data = broadcast("Yj0y")
+	require.Nil(t, data)
+
+	// the mempool is half full, the next txs are rejected with a hint
+	resp, data := broadcast("Yz0z")
+	require.NotNil(t, data)
+	assert.Equal(t, 0.5, data.Usage)
+	assert.Equal(t, "1.5s", data.RetryAfter.String())
+	assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+	assert.Equal(t, 2, vm.mempool.Size())
+
+	_, saturated := vm.mempoolSaturated()
+	assert.True(t, saturated)
+	assert.EqualValues(t, 1, mempoolRejectedTxs(t, vm, mempl.RejectedSaturated))
+}
@@ -0,0 +1,115 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+// GetAncestors returns the bytes of [blkID] and of its ancestors, from the
+// newest to the oldest, up to [maxBlocksNum] blocks, [maxBlocksSize] bytes and
+// [maxBlocksRetrivalTime], as block.GetAncestors does.
+//
+// Processing blocks are only known to the chain state and are fetched one by
+// one, but accepted blocks, which bootstrapping peers ask for, are read by
+// height from the block store, as saved, without being decoded and encoded
+// again. It returns no block if [blkID] is unknown, e.g. pruned.
+func (vm *VM) GetAncestors(
+	ctx context.Context,
+	blkID ids.ID,
+	maxBlocksNum int,
+	maxBlocksSize int,
+	maxBlocksRetrivalTime time.Duration,
+) ([][]byte, error) {
+	startTime := time.Now()
+	var (
+		ancestorsBytes    = make([][]byte, 0, maxBlocksNum)
+		ancestorsBytesLen int
+	)
+	// add appends [blkBytes] unless it would exceed [maxBlocksSize]; the first
+	// block is always returned.
+	add := func(blkBytes []byte) bool {
+		newLen := ancestorsBytesLen + len(blkBytes) + wrappers.IntLen
+		if len(ancestorsBytes) > 0 && newLen > maxBlocksSize {
+			return false
+		}
+		ancestorsBytes = append(ancestorsBytes, blkBytes)
+		ancestorsBytesLen = newLen
+		return true
+	}
+	more := func() bool {
+		return len(ancestorsBytes) < maxBlocksNum && time.Since(startTime) < maxBlocksRetrivalTime
+	}
+
+	nextID := blkID
+	for len(ancestorsBytes) == 0 || more() {
+		if vm.blockStore.LoadBlockMetaByHash(nextID[:]) != nil {
+			break
+		}
+		blk, err := vm.GetBlock(ctx, nextID)
+		if errors.Is(err, database.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !add(blk.Bytes()) {
+			return ancestorsBytes, nil
+		}
+		nextID = blk.Parent()
+	}
+
+	// The accepted chain is linear, so the parent of the block at a height is
+	// the one below it.
+	if blockMeta := vm.blockStore.LoadBlockMetaByHash(nextID[:]); blockMeta != nil {
+		for height := blockMeta.Header.Height; len(ancestorsBytes) == 0 || more(); height-- {
+			blkBytes := vm.blockStore.LoadBlockBytes(height)
+			if blkBytes == nil || !add(blkBytes) {
+				break
+			}
+		}
+	}
+	if len(ancestorsBytes) == 0 {
+		// signals the peer not to ask this node for these ancestors again
+		return nil, nil
+	}
+	return ancestorsBytes, nil
+}
+
+// batchedParseBlock parses [blksBytes] concurrently, as decoding and hashing
+// the blocks dominates the time bootstrapping spends parsing a batch. The
+// blocks are returned in the order of [blksBytes].
+func (vm *VM) batchedParseBlock(ctx context.Context, blksBytes [][]byte) ([]snowman.Block, error) {
+	var (
+		blks = make([]snowman.Block, len(blksBytes))
+		errs = make([]error, len(blksBytes))
+		sem  = make(chan struct{}, runtime.NumCPU())
+		wg   sync.WaitGroup
+	)
+	for i, blkBytes := range blksBytes {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, blkBytes []byte) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			blks[i], errs[i] = vm.parseBlock(ctx, blkBytes)
+		}(i, blkBytes)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return blks, nil
+}
@@ -1,17 +1,24 @@
 package vm
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+
+	mempl "github.com/consideritdone/landslidecore/mempool"
+	sm "github.com/consideritdone/landslidecore/state"
 	"github.com/consideritdone/landslidecore/types"
 )
 
 var (
-	_ snowman.Block = &Block{}
+	_ snowman.Block           = &Block{}
+	_ block.WithVerifyContext = &Block{}
 )
 
 // Block implements the snowman.Block interface
@@ -20,6 +27,15 @@ type Block struct {
 	tmBlock *types.Block
 	vm      *VM
 	status  choices.Status
+	// pChainHeight is the P-Chain height the block was verified with, 0 if
+	// the proposervm isn't activated.
+	pChainHeight uint64
+	// skippedTxs are the txs skipped because the block used up its gas, once
+	// it is accepted.
+	skippedTxs types.Txs
+	// executionTime is how long the app took to execute the block, once it
+	// did.
+	executionTime time.Duration
 }
 
 // newBlock returns a new Block wrapping the Tendermint Block type and implementing the snowman.Block interface
@@ -40,15 +56,38 @@ func (b *Block) ID() ids.ID {
 
 func (b *Block) Accept(ctx context.Context) error {
 	b.SetStatus(choices.Accepted)
-	return b.vm.applyBlock(b)
+	defer b.decided()
+	if err := b.vm.applyBlock(b); err != nil {
+		return err
+	}
+	// the skipped txs left the mempool with the block, and are checked again
+	// once the mempool is unlocked
+	b.returnTxs(b.skippedTxs)
+	return nil
 }
 
 func (b *Block) Reject(ctx context.Context) error {
 	b.SetStatus(choices.Rejected)
+	b.decided()
+	b.returnTxs(b.tmBlock.Txs)
+	b.vm.metrics.blocksRejected.Inc()
 
 	return nil
 }
 
+// returnTxs checks [txs] of the block again, once it is rejected or skipped
+// them, so that the valid ones go back to the mempool and are included in
+// another block. The txs still in the mempool, or committed by the accepted
+// block, are in the mempool cache and skipped.
+func (b *Block) returnTxs(txs types.Txs) {
+	for _, tx := range txs {
+		if err := b.vm.mempool.CheckTx(tx, nil, mempl.TxInfo{}); err != nil {
+			b.vm.tmLogger.Debug("tx of block not returned to the mempool",
+				"height", b.tmBlock.Height, "tx", tx.Hash(), "err", err)
+		}
+	}
+}
+
 func (b *Block) SetStatus(status choices.Status) {
 	b.status = status
 }
@@ -65,12 +104,96 @@ func (b *Block) Parent() ids.ID {
 	return id
 }
 
-func (b *Block) Verify(context.Context) error {
+func (b *Block) Verify(ctx context.Context) error {
+	return b.verify(ctx, nil)
+}
+
+// ShouldVerifyWithContext always returns true, so that the P-Chain height is
+// known when the block is accepted once the proposervm is activated.
+func (b *Block) ShouldVerifyWithContext(context.Context) (bool, error) {
+	return true, nil
+}
+
+// VerifyWithContext verifies the block like Verify, and records the P-Chain
+// height of [blockCtx], which is signaled to the app before the block is
+// executed.
+func (b *Block) VerifyWithContext(ctx context.Context, blockCtx *block.Context) error {
+	b.pChainHeight = blockCtx.PChainHeight
+	return b.verify(ctx, blockCtx)
+}
+
+// verify checks the block and the warp messages of its txs, with the
+// proposervm context if [blockCtx] isn't nil, against the state after its
+// parent, which must be accepted, see parentState. The block is then
+// processing until it is decided.
+func (b *Block) verify(ctx context.Context, blockCtx *block.Context) error {
 	if b == nil || b.tmBlock == nil {
 		return errInvalidBlock
 	}
+	if err := b.tmBlock.ValidateBasic(); err != nil {
+		return err
+	}
+	parentState, err := b.vm.parentState(b.Parent())
+	if err != nil {
+		return err
+	}
+	if height := nextHeight(parentState); b.tmBlock.Height != height {
+		return fmt.Errorf("%w: %d, expected %d", errWrongBlockHeight, b.tmBlock.Height, height)
+	}
+	if err := b.verifyStructure(parentState); err != nil {
+		return err
+	}
+	if err := b.verifyBlockTime(parentState); err != nil {
+		return err
+	}
+	// The gas of the txs is only known to the app, which checks it when it
+	// executes them, but their size is capped by the consensus params.
+	maxBytes := parentState.ConsensusParams.Block.MaxBytes
+	if size := int64(len(b.Bytes())); size > maxBytes {
+		return fmt.Errorf("%w: %d bytes, max %d", errBlockTooLarge, size, maxBytes)
+	}
+	if err := b.vm.verifyWarpTxs(ctx, b.tmBlock, blockCtx); err != nil {
+		return err
+	}
+	if err := b.verifyExecution(); err != nil {
+		return err
+	}
+	b.vm.processing[b.id] = struct{}{}
+	return nil
+}
 
-	return b.tmBlock.ValidateBasic()
+// verifyStructure checks the fields of the header which ValidateBasic can't
+// check on its own against [parentState]: the block must be built on top of
+// its parent, with the versions and chain ID of the chain, without evidence,
+// and with the LastCommit the VM builds, as blocks aren't signed.
+func (b *Block) verifyStructure(parentState *sm.State) error {
+	header := &b.tmBlock.Header
+	if header.Version.Block != parentState.Version.Consensus.Block ||
+		header.Version.App != parentState.Version.Consensus.App {
+		return fmt.Errorf("%w: version %v, expected %v", errInvalidBlock, header.Version, parentState.Version.Consensus)
+	}
+	if header.ChainID != parentState.ChainID {
+		return fmt.Errorf("%w: chain ID %q, expected %q", errInvalidBlock, header.ChainID, parentState.ChainID)
+	}
+	if !header.LastBlockID.Equals(parentState.LastBlockID) {
+		return fmt.Errorf("%w: last block ID %v, expected %v", errInvalidBlock, header.LastBlockID, parentState.LastBlockID)
+	}
+	if n := len(b.tmBlock.Evidence.Evidence); n != 0 {
+		return fmt.Errorf("%w: %d evidence, expected none", errInvalidBlock, n)
+	}
+	// the hash of a commit only covers its signatures
+	commit, err := b.tmBlock.LastCommit.ToProto().Marshal()
+	if err != nil {
+		return err
+	}
+	expected, err := nextCommit(parentState, header.Height).ToProto().Marshal()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(commit, expected) {
+		return fmt.Errorf("%w: last commit isn't the one built at height %d", errInvalidBlock, header.Height)
+	}
+	return nil
 }
 
 func (b *Block) Bytes() []byte {
@@ -0,0 +1,245 @@
+package vm
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+)
+
+// maxAdaptiveBlockTxs is the number of txs beyond which the blocks are no
+// longer limited once the app keeps up again.
+const maxAdaptiveBlockTxs = 1 << 16
+
+// blockBuilder decides when to tell the engine that a block can be built, so
+// that operators can trade latency against the number of blocks: the engine
+// is signaled once the mempool holds BuildMinTxs txs, or once its oldest tx
+// has waited MaxBuildWait, and never sooner than MinBlockInterval after the
+// last block was built. If empty blocks are enabled, the engine is also
+// signaled once no block has been accepted for their interval.
+//
+// In dev mode, the engine is signaled on a fixed interval instead, with or
+// without txs, see produce.
+//
+// The builder also adapts to an app which takes longer than
+// TargetBlockExecutionTime to execute and commit the blocks, see executed.
+type blockBuilder struct {
+	vm *VM
+
+	minTxs      int
+	minInterval time.Duration
+	maxWait     time.Duration
+	// emptyInterval is 0 if empty blocks are disabled.
+	emptyInterval time.Duration
+	// devInterval is 0 unless in dev mode.
+	devInterval time.Duration
+	// targetExecTime is 0 if the builder doesn't adapt to the app.
+	targetExecTime time.Duration
+	quit           chan struct{}
+
+	mtx            sync.Mutex
+	lastBuildTime  time.Time
+	lastAcceptTime time.Time
+	// maxTxs is the number of txs of the next blocks, 0 if unlimited, and
+	// backoff how long after the last accepted block the engine is signaled,
+	// while the app is falling behind.
+	maxTxs  int
+	backoff time.Duration
+	// timer signals again once the interval or the wait which held back
+	// the last signal is over.
+	timer *time.Timer
+}
+
+func newBlockBuilder(vm *VM, config Config) *blockBuilder {
+	b := &blockBuilder{
+		vm:             vm,
+		minTxs:         config.BuildMinTxs,
+		minInterval:    config.MinBlockInterval.Duration,
+		maxWait:        config.MaxBuildWait.Duration,
+		lastAcceptTime: time.Now(),
+		devInterval:    config.DevBlockInterval.Duration,
+		targetExecTime: config.TargetBlockExecutionTime.Duration,
+		quit:           make(chan struct{}),
+	}
+	if config.CreateEmptyBlocks {
+		b.emptyInterval = config.CreateEmptyBlocksInterval.Duration
+	}
+	if b.devInterval > 0 {
+		go b.produce()
+	}
+	return b
+}
+
+// produce signals the engine every devInterval until the builder is stopped,
+// so that a single node chain gets a block on every tick, as with
+// Tendermint.
+func (b *blockBuilder) produce() {
+	ticker := time.NewTicker(b.devInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.vm.notifyEngine()
+		case <-b.quit:
+			return
+		}
+	}
+}
+
+// signal tells the engine to build a block if the mempool is ready for one,
+// or schedules a later signal if it will be.
+func (b *blockBuilder) signal() {
+	if b.devInterval > 0 {
+		return
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	size := b.vm.mempool.Size()
+	now := time.Now()
+	if size == 0 {
+		if b.emptyInterval <= 0 {
+			return
+		}
+		if wait := b.lastAcceptTime.Add(b.emptyInterval).Sub(now); wait > 0 {
+			b.scheduleLocked(wait)
+			return
+		}
+		b.vm.notifyEngine()
+		return
+	}
+	if wait := b.lastAcceptTime.Add(b.backoff).Sub(now); wait > 0 {
+		b.scheduleLocked(wait)
+		return
+	}
+	if wait := b.lastBuildTime.Add(b.minInterval).Sub(now); wait > 0 {
+		b.scheduleLocked(wait)
+		return
+	}
+	if size < b.minTxs {
+		if b.maxWait <= 0 {
+			return
+		}
+		mempool, ok := b.vm.mempool.(oldestTxTimer)
+		if !ok {
+			return
+		}
+		oldest, ok := mempool.OldestTxTime()
+		if !ok {
+			return
+		}
+		if wait := oldest.Add(b.maxWait).Sub(now); wait > 0 {
+			b.scheduleLocked(wait)
+			return
+		}
+	}
+	b.vm.notifyEngine()
+}
+
+func (b *blockBuilder) scheduleLocked(wait time.Duration) {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.timer = time.AfterFunc(wait, b.signal)
+}
+
+// built records that a block was built.
+func (b *blockBuilder) built() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.lastBuildTime = time.Now()
+}
+
+// accepted records that a block was accepted and signals the txs left in the
+// mempool, which only signals new txs.
+func (b *blockBuilder) accepted() {
+	b.mtx.Lock()
+	b.lastAcceptTime = time.Now()
+	b.mtx.Unlock()
+
+	b.signal()
+}
+
+// executed adapts the next blocks to the time [execTime] the app took to
+// execute and commit a block of [numTxs] txs: once it is over the target, the
+// next blocks hold half as many txs and the engine is signaled later by the
+// excess, and once a full block is within the target again, the number of
+// txs doubles, until it no longer limits the blocks.
+func (b *blockBuilder) executed(numTxs int, execTime time.Duration) {
+	if b.targetExecTime <= 0 {
+		return
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	switch {
+	case execTime > b.targetExecTime:
+		b.backoff = execTime - b.targetExecTime
+		if limit := (numTxs + 1) / 2; numTxs > 0 && (b.maxTxs == 0 || limit < b.maxTxs) {
+			b.maxTxs = limit
+		}
+		b.vm.tmLogger.Info("app is falling behind, building smaller blocks",
+			"exec_time", execTime, "target", b.targetExecTime, "max_txs", b.maxTxs)
+	case b.maxTxs > 0 && numTxs >= b.maxTxs:
+		b.backoff = 0
+		b.maxTxs *= 2
+		if b.maxTxs > maxAdaptiveBlockTxs {
+			b.maxTxs = 0
+		}
+	default:
+		b.backoff = 0
+	}
+	b.vm.metrics.blockTxsLimit.Set(float64(b.maxTxs))
+}
+
+// txLimit returns the number of txs of the next block, 0 if unlimited.
+func (b *blockBuilder) txLimit() int {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	return b.maxTxs
+}
+
+// emptyBlockDue returns whether an empty block may be built, which it always
+// may in dev mode.
+func (b *blockBuilder) emptyBlockDue() bool {
+	if b.devInterval > 0 {
+		return true
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	return b.emptyInterval > 0 && time.Since(b.lastAcceptTime) >= b.emptyInterval
+}
+
+func (b *blockBuilder) stop() {
+	close(b.quit)
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+}
+
+// NotifyBlockReady is called by the mempool when a tx is added.
+func (vm *VM) NotifyBlockReady() {
+	vm.builder.signal()
+}
+
+// notifyEngine tells the consensus engine that a new block is ready to be
+// created.
+func (vm *VM) notifyEngine() {
+	select {
+	case vm.toEngine <- common.PendingTxs:
+		vm.tmLogger.Debug("Notify consensys engine")
+	default:
+		vm.tmLogger.Error("Failed to push PendingTxs notification to the consensus engine.")
+	}
+}
@@ -0,0 +1,192 @@
+package vm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/ava-labs/avalanchego/vms/components/chain"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+)
+
+func TestBlockBuilder(t *testing.T) {
+	newVM := func(configBytes string) (*VM, Service, chan common.Message) {
+		dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+		vm, _, toEngine, err := newTestVMWithDB(kvstore.NewApplication(), dbManager, []byte(configBytes))
+		require.NoError(t, err)
+		return vm, NewService(vm), toEngine
+	}
+	broadcast := func(service Service, tx string) {
+		reply := new(ctypes.ResultBroadcastTx)
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte(tx)}, reply))
+		require.Equal(t, atypes.CodeTypeOK, reply.Code)
+	}
+	assertSignaled := func(toEngine chan common.Message, within time.Duration) {
+		select {
+		case msg := <-toEngine:
+			assert.Equal(t, common.PendingTxs, msg)
+		case <-time.After(within):
+			t.Fatal("the engine wasn't signaled")
+		}
+	}
+	assertNotSignaled := func(toEngine chan common.Message) {
+		select {
+		case msg := <-toEngine:
+			t.Fatalf("the engine was signaled: %v", msg)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	// by default, every tx signals the engine
+	_, service, toEngine := newVM("")
+	broadcast(service, "a=1")
+	assertSignaled(toEngine, time.Second)
+
+	// the engine is signaled once enough txs are pending, or once the oldest
+	// has waited long enough
+	_, service, toEngine = newVM(`{"buildMinTxs":2,"maxBuildWait":"300ms"}`)
+	broadcast(service, "a=1")
+	assertNotSignaled(toEngine)
+	broadcast(service, "b=2")
+	assertSignaled(toEngine, time.Second)
+
+	_, service, toEngine = newVM(`{"buildMinTxs":2,"maxBuildWait":"300ms"}`)
+	broadcast(service, "a=1")
+	assertNotSignaled(toEngine)
+	assertSignaled(toEngine, time.Second)
+
+	// blocks are spaced by the minimum interval, the txs left pending are
+	// signaled once it is over
+	vm, service, toEngine := newVM(`{"minBlockInterval":"300ms"}`)
+	broadcast(service, "a=1")
+	assertSignaled(toEngine, time.Second)
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(t, err)
+	broadcast(service, "b=2")
+	assertNotSignaled(toEngine)
+	require.NoError(t, blk.Accept(context.Background()))
+	assertSignaled(toEngine, time.Second)
+
+	// no empty block is built by default
+	vm, _, toEngine = newVM("")
+	assertNotSignaled(toEngine)
+	_, err = vm.BuildBlock(context.Background())
+	assert.ErrorIs(t, err, errNoPendingTxs)
+
+	// otherwise, one is built once the chain is idle for the interval
+	vm, _, toEngine = newVM(`{"createEmptyBlocks":true,"createEmptyBlocksInterval":"300ms"}`)
+	_, err = vm.BuildBlock(context.Background())
+	assert.ErrorIs(t, err, errNoPendingTxs)
+	assertSignaled(toEngine, time.Second)
+	blk, err = vm.BuildBlock(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, blk.Accept(context.Background()))
+	assert.Empty(t, blk.(*chain.BlockWrapper).Block.(*Block).tmBlock.Txs)
+	assertNotSignaled(toEngine)
+	assertSignaled(toEngine, time.Second)
+
+	// in dev mode, the engine is signaled on every tick, with or without txs
+	vm, _, toEngine = newVM(`{"devBlockInterval":"100ms"}`)
+	for i := 0; i < 2; i++ {
+		assertSignaled(toEngine, time.Second)
+		blk, err = vm.BuildBlock(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, blk.Accept(context.Background()))
+	}
+	assert.EqualValues(t, 2, vm.tmState.LastBlockHeight)
+	require.NoError(t, vm.Shutdown(context.Background()))
+}
+
+func TestBuildBlockDeadline(t *testing.T) {
+	vm, service, _ := mustNewKVTestVm(t)
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, reply))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := vm.BuildBlock(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// the engine isn't held while the mempool is locked
+	vm.mempool.Lock()
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = vm.BuildBlock(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	vm.mempool.Unlock()
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, blk.Accept(context.Background()))
+	assert.Len(t, blk.(*chain.BlockWrapper).Block.(*Block).tmBlock.Txs, 1)
+}
+
+// slowCommitApp is a kvstore app which takes [commitTime] to commit a block.
+type slowCommitApp struct {
+	*kvstore.Application
+	commitTime time.Duration
+}
+
+func (app *slowCommitApp) Commit() atypes.ResponseCommit {
+	time.Sleep(app.commitTime)
+	return app.Application.Commit()
+}
+
+func TestAdaptiveBlockBuilding(t *testing.T) {
+	app := &slowCommitApp{Application: kvstore.NewApplication(), commitTime: 200 * time.Millisecond}
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	vm, _, toEngine, err := newTestVMWithDB(app, dbManager, []byte(`{"targetBlockExecutionTime":"50ms"}`))
+	require.NoError(t, err)
+	service := NewService(vm)
+	ctx := context.Background()
+	broadcast := func(txs ...string) {
+		for _, tx := range txs {
+			reply := new(ctypes.ResultBroadcastTx)
+			require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte(tx)}, reply))
+			require.Equal(t, atypes.CodeTypeOK, reply.Code)
+		}
+	}
+	buildBlock := func() *Block {
+		blk, err := vm.BuildBlock(ctx)
+		require.NoError(t, err)
+		return blk.(*chain.BlockWrapper).Block.(*Block)
+	}
+
+	// a block taking longer than the target halves the next blocks
+	broadcast("a=1", "b=2", "c=3", "d=4")
+	blk := buildBlock()
+	assert.Len(t, blk.tmBlock.Txs, 4)
+	require.NoError(t, blk.Accept(ctx))
+	assert.Equal(t, 2, vm.builder.txLimit())
+	assert.Equal(t, 2.0, testutil.ToFloat64(vm.metrics.blockTxsLimit))
+
+	// and the engine is only signaled once the app had the excess time
+	for len(toEngine) > 0 {
+		<-toEngine
+	}
+	broadcast("e=5", "f=6", "g=7")
+	assert.Len(t, toEngine, 0)
+	select {
+	case <-toEngine:
+	case <-time.After(time.Second):
+		t.Fatal("the engine wasn't signaled")
+	}
+	blk = buildBlock()
+	assert.Len(t, blk.tmBlock.Txs, 2)
+
+	// once a full block is within the target, the limit grows back
+	app.commitTime = 0
+	require.NoError(t, blk.Accept(ctx))
+	assert.Equal(t, 4, vm.builder.txLimit())
+}
@@ -0,0 +1,43 @@
+package vm
+
+import (
+	"fmt"
+	"strconv"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+)
+
+// pChainHeightOptionKey is the key of the SetOption request which signals the
+// P-Chain height a block was verified with to the app, right before the
+// block's BeginBlock. The value is the height in decimal.
+//
+// ABCI 0.34 has no field in RequestBeginBlock for it, and the header is
+// hashed, so the app gets it the way it gets upgrades. As the proposervm
+// records the P-Chain height in its own block, every node signals the same
+// height for a given block. Apps which need it, e.g. to verify Warp messages
+// against the validator set at that height, should persist it with their
+// state, as it isn't signaled again when blocks are replayed on startup.
+const pChainHeightOptionKey = "pChainHeight"
+
+// signalPChainHeight signals the P-Chain height [block] was verified with to
+// the app. Nothing is signaled until the proposervm is activated.
+func (vm *VM) signalPChainHeight(block *Block) error {
+	if block.pChainHeight == 0 {
+		return nil
+	}
+	setter, ok := vm.proxyApp.Consensus().(optionSetter)
+	if !ok {
+		return errNoOptionSetter
+	}
+	res, err := setter.SetOptionSync(abci.RequestSetOption{
+		Key:   pChainHeightOptionKey,
+		Value: strconv.FormatUint(block.pChainHeight, 10),
+	})
+	if err != nil {
+		return err
+	}
+	if res.Code != abci.CodeTypeOK {
+		return fmt.Errorf("app rejected P-Chain height %d: %s", block.pChainHeight, res.Log)
+	}
+	return nil
+}
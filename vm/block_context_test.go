@@ -0,0 +1,52 @@
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+)
+
+// pChainHeightApp records the P-Chain heights signaled to it.
+type pChainHeightApp struct {
+	*kvstore.Application
+	pChainHeights []string
+}
+
+func (app *pChainHeightApp) SetOption(req atypes.RequestSetOption) atypes.ResponseSetOption {
+	if req.Key == pChainHeightOptionKey {
+		app.pChainHeights = append(app.pChainHeights, req.Value)
+	}
+	return atypes.ResponseSetOption{}
+}
+
+func TestVerifyWithContext(t *testing.T) {
+	app := &pChainHeightApp{Application: kvstore.NewApplication()}
+	vm, _, _, err := newTestVM(app)
+	require.NoError(t, err)
+	service := NewService(vm)
+	ctx := context.Background()
+
+	// blocks verified without context don't signal any height
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	assert.Empty(t, app.pChainHeights)
+
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("b=2")}, reply))
+	blk, err := vm.BuildBlock(ctx)
+	require.NoError(t, err)
+	blkWithCtx, ok := blk.(block.WithVerifyContext)
+	require.True(t, ok)
+	shouldVerify, err := blkWithCtx.ShouldVerifyWithContext(ctx)
+	require.NoError(t, err)
+	require.True(t, shouldVerify)
+	require.NoError(t, blkWithCtx.VerifyWithContext(ctx, &block.Context{PChainHeight: 42}))
+	require.NoError(t, blk.Accept(ctx))
+	assert.Equal(t, []string{"42"}, app.pChainHeights)
+}
@@ -0,0 +1,83 @@
+package vm
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	tmstate "github.com/consideritdone/landslidecore/proto/tendermint/state"
+	sm "github.com/consideritdone/landslidecore/state"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+const blockGasMetricsPrefix = "block_gas"
+
+// blockGasMetrics are the metrics of the gas used by the accepted blocks,
+// against their gas limit. The txs following the one from which a block used
+// more than its limit are skipped, see sm.DeliverTxsWithinGas.
+type blockGasMetrics struct {
+	used       prometheus.Gauge
+	limit      prometheus.Gauge
+	usedTotal  prometheus.Counter
+	skippedTxs prometheus.Counter
+}
+
+func newBlockGasMetrics(registerer prometheus.Registerer) (*blockGasMetrics, error) {
+	m := &blockGasMetrics{
+		used: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "used",
+			Help: "Gas used by the txs of the last accepted block.",
+		}),
+		limit: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "limit",
+			Help: "Gas limit of the last accepted block, -1 if unlimited.",
+		}),
+		usedTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "used_total",
+			Help: "Gas used by the txs of all the accepted blocks.",
+		}),
+		skippedTxs: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "skipped_txs",
+			Help: "Number of txs skipped because their block used up its gas.",
+		}),
+	}
+	for _, c := range []prometheus.Collector{m.used, m.limit, m.usedTotal, m.skippedTxs} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// skippedTxs returns the [txs] of the block executed into [abciResponses]
+// which were skipped because it used up its gas. They were never delivered,
+// and are returned to the mempool once the block is accepted.
+func skippedTxs(txs types.Txs, abciResponses *tmstate.ABCIResponses) types.Txs {
+	var skipped types.Txs
+	for i, res := range abciResponses.DeliverTxs {
+		if isSkipped(res) {
+			skipped = append(skipped, txs[i])
+		}
+	}
+	return skipped
+}
+
+// isSkipped is true if [res] is the response of a tx skipped because its
+// block used up its gas.
+func isSkipped(res *abci.ResponseDeliverTx) bool {
+	return res.Codespace == sm.CodespaceBlockGas && res.Code == sm.CodeBlockGasExceeded
+}
+
+// observe records the gas used by the block executed into [abciResponses],
+// with the [maxGas] limit.
+func (m *blockGasMetrics) observe(maxGas int64, abciResponses *tmstate.ABCIResponses) {
+	var used int64
+	for _, res := range abciResponses.DeliverTxs {
+		used += res.GasUsed
+		if isSkipped(res) {
+			m.skippedTxs.Inc()
+		}
+	}
+	m.used.Set(float64(used))
+	m.limit.Set(float64(maxGas))
+	m.usedTotal.Add(float64(used))
+}
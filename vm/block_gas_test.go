@@ -0,0 +1,83 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/vms/components/chain"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	sm "github.com/consideritdone/landslidecore/state"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// gasApp is a paramsApp whose txs want no gas in CheckTx, so that they are
+// all built into a block, and use 1 gas in DeliverTx.
+type gasApp struct {
+	*paramsApp
+}
+
+func (app *gasApp) CheckTx(req atypes.RequestCheckTx) atypes.ResponseCheckTx {
+	res := app.paramsApp.CheckTx(req)
+	res.GasWanted = 0
+	return res
+}
+
+func (app *gasApp) DeliverTx(req atypes.RequestDeliverTx) atypes.ResponseDeliverTx {
+	res := app.paramsApp.DeliverTx(req)
+	res.GasUsed = 1
+	return res
+}
+
+func TestBlockGas(t *testing.T) {
+	app := &gasApp{&paramsApp{Application: kvstore.NewApplication()}}
+	vm, _, _, err := newTestVM(app)
+	require.NoError(t, err)
+	service := NewService(vm)
+	ctx := context.Background()
+
+	app.setBlockParams(65536, 2)
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	assert.EqualValues(t, 1, testutil.ToFloat64(vm.blockGasMetrics.used))
+	assert.EqualValues(t, -1, testutil.ToFloat64(vm.blockGasMetrics.limit))
+
+	// the txs following the one which exceeded the gas limit are skipped
+	for i := 0; i < 4; i++ {
+		reply := new(ctypes.ResultBroadcastTx)
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte(fmt.Sprintf("k%d=v", i))}, reply))
+	}
+	blk := mustAcceptBlock(t, vm, service)
+	height := int64(blk.Height())
+	results := new(ctypes.ResultBlockResults)
+	require.NoError(t, service.BlockResults(nil, &BlockHeightArgs{Height: &height}, results))
+	require.Len(t, results.TxsResults, 4)
+	for i, res := range results.TxsResults[:3] {
+		assert.Equal(t, atypes.CodeTypeOK, res.Code, i)
+	}
+	assert.Equal(t, sm.CodeBlockGasExceeded, results.TxsResults[3].Code)
+	assert.Equal(t, sm.CodespaceBlockGas, results.TxsResults[3].Codespace)
+
+	query := new(ctypes.ResultABCIQuery)
+	require.NoError(t, service.ABCIQuery(nil, &ABCIQueryArgs{Path: "/key", Data: []byte("k2")}, query))
+	assert.Equal(t, []byte("v"), query.Response.Value)
+	require.NoError(t, service.ABCIQuery(nil, &ABCIQueryArgs{Path: "/key", Data: []byte("k3")}, query))
+	assert.Empty(t, query.Response.Value)
+
+	assert.EqualValues(t, 3, testutil.ToFloat64(vm.blockGasMetrics.used))
+	assert.EqualValues(t, 2, testutil.ToFloat64(vm.blockGasMetrics.limit))
+	assert.EqualValues(t, 1, testutil.ToFloat64(vm.blockGasMetrics.skippedTxs))
+
+	// the skipped tx goes back to the mempool, and into the next block
+	require.Equal(t, 1, vm.mempool.Size())
+	blk = mustAcceptBlock(t, vm, service)
+	assert.Equal(t, types.Txs{[]byte("k3=v")}, blk.(*chain.BlockWrapper).Block.(*Block).tmBlock.Txs)
+	require.NoError(t, service.ABCIQuery(nil, &ABCIQueryArgs{Path: "/key", Data: []byte("k3")}, query))
+	assert.Equal(t, []byte("v"), query.Response.Value)
+	require.NoError(t, vm.Shutdown(ctx))
+}
@@ -0,0 +1,103 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/vms/components/chain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+	tmproto "github.com/consideritdone/landslidecore/proto/tendermint/types"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+)
+
+// paramsApp updates the block params in the EndBlock following a call to
+// setBlockParams.
+type paramsApp struct {
+	*kvstore.Application
+	params *atypes.ConsensusParams
+}
+
+func (app *paramsApp) setBlockParams(maxBytes, maxGas int64) {
+	app.params = &atypes.ConsensusParams{
+		Block: &atypes.BlockParams{MaxBytes: maxBytes, MaxGas: maxGas},
+		Evidence: &tmproto.EvidenceParams{
+			MaxAgeNumBlocks: 100000,
+			MaxAgeDuration:  48 * time.Hour,
+			MaxBytes:        0,
+		},
+	}
+}
+
+func (app *paramsApp) EndBlock(req atypes.RequestEndBlock) atypes.ResponseEndBlock {
+	res := app.Application.EndBlock(req)
+	res.ConsensusParamUpdates, app.params = app.params, nil
+	return res
+}
+
+func TestBlockLimits(t *testing.T) {
+	app := &paramsApp{Application: kvstore.NewApplication()}
+	vm, _, _, err := newTestVM(app)
+	require.NoError(t, err)
+	service := NewService(vm)
+	ctx := context.Background()
+
+	broadcast := func(n int, size int) {
+		for i := 0; i < n; i++ {
+			tx := []byte(fmt.Sprintf("%d=%0*d", i, size, i))
+			reply := new(ctypes.ResultBroadcastTx)
+			require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: tx}, reply))
+			require.Equal(t, atypes.CodeTypeOK, reply.Code)
+		}
+	}
+
+	// the kvstore app wants 1 gas per tx
+	app.setBlockParams(65536, 2)
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	assert.EqualValues(t, 2, vm.tmState.ConsensusParams.Block.MaxGas)
+
+	broadcast(3, 8)
+	blk, err := vm.BuildBlock(ctx)
+	require.NoError(t, err)
+	assert.Len(t, blk.(*chain.BlockWrapper).Block.(*Block).tmBlock.Txs, 2)
+	require.NoError(t, blk.Accept(ctx))
+	assert.Equal(t, 1, vm.mempool.Size())
+
+	app.setBlockParams(2048, -1)
+	mustAcceptBlock(t, vm, service)
+	assert.EqualValues(t, 2048, vm.tmState.ConsensusParams.Block.MaxBytes)
+
+	broadcast(20, 100)
+	blk, err = vm.BuildBlock(ctx)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(blk.Bytes()), 2048)
+	assert.Less(t, len(blk.(*chain.BlockWrapper).Block.(*Block).tmBlock.Txs), vm.mempool.Size())
+
+	// a block with all the txs of the mempool is too large
+	height := vm.tmState.LastBlockHeight + 1
+	tmBlock, _ := vm.tmState.MakeBlock(height, vm.mempool.ReapMaxTxs(-1), makeCommitMock(height), nil, proposerAddress)
+	oversized, err := vm.newBlock(tmBlock)
+	require.NoError(t, err)
+	err = oversized.Verify(ctx)
+	assert.True(t, errors.Is(err, errBlockTooLarge), err)
+
+	// nor does the mempool take a tx which can't fit in a block
+	txReply := new(ctypes.ResultBroadcastTx)
+	assert.Error(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: make([]byte, 4096)}, txReply))
+
+	// the params are served by height
+	paramsReply := new(ctypes.ResultConsensusParams)
+	require.NoError(t, service.ConsensusParams(nil, &ConsensusParamsArgs{}, paramsReply))
+	assert.EqualValues(t, 2048, paramsReply.ConsensusParams.Block.MaxBytes)
+	paramsHeight := int64(2)
+	require.NoError(t, service.ConsensusParams(nil, &ConsensusParamsArgs{Height: &paramsHeight}, paramsReply))
+	assert.EqualValues(t, 2, paramsReply.BlockHeight)
+	assert.EqualValues(t, 65536, paramsReply.ConsensusParams.Block.MaxBytes)
+	assert.EqualValues(t, 2, paramsReply.ConsensusParams.Block.MaxGas)
+}
@@ -0,0 +1,52 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	sm "github.com/consideritdone/landslidecore/state"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+var (
+	errWrongBlockHeight = errors.New("block height isn't the height after its parent")
+	errParentProcessing = errors.New("parent block is processing, so the hashes of its execution aren't known yet")
+)
+
+// parentState returns the state after the block [blkID], which the blocks
+// built on top of it are verified and built against. Blocks are executed when
+// they are accepted, and the next block carries the hashes of the execution
+// of its parent, see verifyExecution, so there is no state after a processing
+// block yet. Otherwise, it is the last accepted state.
+func (vm *VM) parentState(blkID ids.ID) (*sm.State, error) {
+	if _, ok := vm.processing[blkID]; ok {
+		return nil, fmt.Errorf("%w: %s", errParentProcessing, blkID)
+	}
+	return vm.tmState, nil
+}
+
+// nextHeight returns the height of the block after [state], which is the
+// initial height of the chain for the first block.
+func nextHeight(state *sm.State) int64 {
+	if state.LastBlockHeight == 0 {
+		return state.InitialHeight
+	}
+	return state.LastBlockHeight + 1
+}
+
+// nextCommit returns the LastCommit of the block at [height] after [state],
+// which has no signature at the initial height of the chain.
+func nextCommit(state *sm.State, height int64) *types.Commit {
+	commit := makeCommitMock(height)
+	if height == state.InitialHeight {
+		commit.Signatures = nil
+	}
+	return commit
+}
+
+// decided forgets [b] once it is accepted or rejected.
+func (b *Block) decided() {
+	delete(b.vm.processing, b.id)
+}
@@ -0,0 +1,109 @@
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+func TestVerifyOnLastAcceptedBlock(t *testing.T) {
+	vm, service, _ := mustNewKVTestVm(t)
+	ctx := context.Background()
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	lastAccepted, err := vm.LastAccepted(ctx)
+	require.NoError(t, err)
+
+	buildBlock := func(tx []byte) (snowman.Block, error) {
+		reply := new(ctypes.ResultBroadcastTx)
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: tx}, reply))
+		return vm.BuildBlock(ctx)
+	}
+
+	// a block is built on the preferred block once it is accepted
+	blk1, err := buildBlock([]byte("b=2"))
+	require.NoError(t, err)
+	require.NoError(t, blk1.Verify(ctx))
+	require.Equal(t, lastAccepted, blk1.Parent())
+	require.NoError(t, vm.SetPreference(ctx, blk1.ID()))
+	_, err = buildBlock([]byte("c=3"))
+	assert.ErrorIs(t, err, errParentProcessing)
+
+	// a competing block is verified against the last accepted state
+	height := vm.tmState.LastBlockHeight + 1
+	tmBlock, _ := vm.tmState.MakeBlock(height, types.Txs{[]byte("d=4")}, makeCommitMock(height), nil, proposerAddress)
+	tmBlock.Time = vm.nextBlockTime(vm.tmState)
+	competing, err := vm.newBlock(tmBlock)
+	require.NoError(t, err)
+	require.NoError(t, competing.Verify(ctx))
+
+	// while a block at the wrong height isn't
+	tmBlock, _ = vm.tmState.MakeBlock(height+1, nil, makeCommitMock(height+1), nil, proposerAddress)
+	tmBlock.Time = vm.nextBlockTime(vm.tmState)
+	wrongHeight, err := vm.newBlock(tmBlock)
+	require.NoError(t, err)
+	assert.ErrorIs(t, wrongHeight.Verify(ctx), errWrongBlockHeight)
+
+	require.NoError(t, blk1.Accept(ctx))
+	require.NoError(t, competing.Reject(ctx))
+	assert.Empty(t, vm.processing)
+	blk2, err := vm.BuildBlock(ctx)
+	require.NoError(t, err)
+	require.NoError(t, blk2.Verify(ctx))
+	assert.Equal(t, blk1.ID(), blk2.Parent())
+	assert.Equal(t, blk1.Height()+1, blk2.Height())
+	assert.True(t, blk2.Timestamp().After(blk1.Timestamp()))
+	require.NoError(t, blk2.Accept(ctx))
+	assert.Equal(t, int64(blk2.Height()), vm.tmState.LastBlockHeight)
+	assert.Empty(t, vm.processing)
+}
+
+func TestVerifyStructure(t *testing.T) {
+	vm, service, _ := mustNewKVTestVm(t)
+	ctx := context.Background()
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+
+	makeBlock := func(tamper func(*types.Block)) *Block {
+		height := vm.tmState.LastBlockHeight + 1
+		tmBlock, _ := vm.tmState.MakeBlock(height, types.Txs{[]byte("b=2")}, makeCommitMock(height), nil, proposerAddress)
+		tmBlock.Time = vm.nextBlockTime(vm.tmState)
+		tamper(tmBlock)
+		blk, err := vm.newBlock(tmBlock)
+		require.NoError(t, err)
+		return blk
+	}
+	require.NoError(t, makeBlock(func(*types.Block) {}).Verify(ctx))
+
+	tests := map[string]func(*types.Block){
+		"version": func(b *types.Block) {
+			b.Version.App++
+		},
+		"chain ID": func(b *types.Block) {
+			b.ChainID = "other"
+		},
+		"last block ID": func(b *types.Block) {
+			b.LastBlockID.PartSetHeader.Total++
+		},
+		"evidence": func(b *types.Block) {
+			ev := types.NewMockDuplicateVoteEvidence(b.Height-1, b.Time, b.ChainID)
+			b.Evidence.Evidence = types.EvidenceList{ev}
+			b.EvidenceHash = nil
+		},
+		"last commit": func(b *types.Block) {
+			b.LastCommit.Round = 1
+			b.LastCommitHash = nil
+		},
+	}
+	for name, tamper := range tests {
+		t.Run(name, func(t *testing.T) {
+			blk := makeBlock(tamper)
+			require.NoError(t, blk.tmBlock.ValidateBasic())
+			assert.ErrorIs(t, blk.Verify(ctx), errInvalidBlock)
+		})
+	}
+}
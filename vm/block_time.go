@@ -0,0 +1,53 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	sm "github.com/consideritdone/landslidecore/state"
+	tmtime "github.com/consideritdone/landslidecore/types/time"
+)
+
+// maxFutureBlockTime is how far ahead of the local clock the time of a block
+// may be, as the proposervm allows for the blocks it wraps.
+const maxFutureBlockTime = 10 * time.Second
+
+var (
+	errBlockTimeInFuture = errors.New("block time is too far in the future")
+	errBlockTimeNotAfter = errors.New("block time isn't after the time of its parent")
+)
+
+// nextBlockTime returns the time of the block built on top of [parentState]:
+// the local time, unless the clock is behind the parent, in which case it is
+// right after the parent, so that block times strictly increase.
+func (vm *VM) nextBlockTime(parentState *sm.State) time.Time {
+	now := vm.now()
+	if minTime := parentState.LastBlockTime.Add(time.Nanosecond); now.Before(minTime) {
+		return minTime
+	}
+	return now
+}
+
+// now returns the time of the clock of the VM, see WithClock, in the
+// canonical form of the block times.
+func (vm *VM) now() time.Time {
+	return tmtime.Canonical(vm.clock.Time())
+}
+
+// verifyBlockTime checks that the time of [b] is after its parent, whose state
+// is [parentState], and at most maxFutureBlockTime ahead of the local clock.
+func (b *Block) verifyBlockTime(parentState *sm.State) error {
+	if b.tmBlock.Height == parentState.InitialHeight {
+		// the genesis time, checked when the block is accepted
+		return nil
+	}
+	blockTime := b.tmBlock.Time
+	if maxTime := b.vm.now().Add(maxFutureBlockTime); blockTime.After(maxTime) {
+		return fmt.Errorf("%w: %s, max %s", errBlockTimeInFuture, blockTime, maxTime)
+	}
+	if parentTime := parentState.LastBlockTime; !blockTime.After(parentTime) {
+		return fmt.Errorf("%w: %s, parent at %s", errBlockTimeNotAfter, blockTime, parentTime)
+	}
+	return nil
+}
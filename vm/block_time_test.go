@@ -0,0 +1,41 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockTime(t *testing.T) {
+	vm, service, _ := mustNewKVTestVm(t)
+	ctx := context.Background()
+
+	blk1 := mustAcceptBlock(t, vm, service, []byte("a=1"))
+	blk2 := mustAcceptBlock(t, vm, service, []byte("b=2"))
+	assert.True(t, blk2.Timestamp().After(blk1.Timestamp()))
+	assert.Equal(t, blk2.Timestamp(), vm.tmState.LastBlockTime)
+
+	// the time of a block built while the clock is behind the parent is
+	// right after it
+	parentTime := time.Now().Add(5 * time.Second)
+	vm.tmState.LastBlockTime = parentTime
+	assert.Equal(t, parentTime.Add(time.Nanosecond), vm.nextBlockTime(vm.tmState))
+
+	verifyWithTime := func(blockTime time.Time) error {
+		height := vm.tmState.LastBlockHeight + 1
+		tmBlock, _ := vm.tmState.MakeBlock(height, nil, makeCommitMock(height), nil, proposerAddress)
+		tmBlock.Time = blockTime
+		blk, err := vm.newBlock(tmBlock)
+		require.NoError(t, err)
+		return blk.Verify(ctx)
+	}
+	assert.NoError(t, verifyWithTime(parentTime.Add(time.Nanosecond)))
+	err := verifyWithTime(parentTime)
+	assert.True(t, errors.Is(err, errBlockTimeNotAfter), err)
+	err = verifyWithTime(time.Now().Add(time.Minute))
+	assert.True(t, errors.Is(err, errBlockTimeInFuture), err)
+}
@@ -3,9 +3,9 @@ package vm
 import (
 	"errors"
 	"fmt"
-	"time"
 
 	"github.com/consideritdone/landslidecore/crypto"
+	cryptoenc "github.com/consideritdone/landslidecore/crypto/encoding"
 	"github.com/consideritdone/landslidecore/state"
 	"github.com/consideritdone/landslidecore/types"
 
@@ -13,13 +13,23 @@ import (
 	"github.com/consideritdone/landslidecore/libs/log"
 	mempl "github.com/consideritdone/landslidecore/mempool"
 	tmstate "github.com/consideritdone/landslidecore/proto/tendermint/state"
+	tmproto "github.com/consideritdone/landslidecore/proto/tendermint/types"
 	"github.com/consideritdone/landslidecore/proxy"
 )
 
-func makeCommitMock(height int64, timestamp time.Time) *types.Commit {
+// makeCommitMock returns the LastCommit of a block at [height]. Blocks are
+// not signed, so past the first height the commit holds a single absent
+// signature.
+//
+// NOTE: blocks built before the signature was made absent hold a signature
+// with only a timestamp, which fails CommitSig.ValidateBasic, so the block
+// store can't load them back.
+func makeCommitMock(height int64) *types.Commit {
 	var commitSig []types.CommitSig = nil
 	if height != 1 {
-		commitSig = []types.CommitSig{{Timestamp: time.Now()}}
+		// the signature must pass CommitSig.ValidateBasic, otherwise the
+		// block can't be decoded back from its proto representation
+		commitSig = []types.CommitSig{types.NewCommitSigAbsent()}
 	}
 	return types.NewCommit(
 		height,
@@ -35,6 +45,13 @@ func makeCommitMock(height int64, timestamp time.Time) *types.Commit {
 	)
 }
 
+// maxBlockDataBytes returns the largest size of the txs of a block under the
+// MaxBytes consensus param. The commit of a block holds at most a single
+// signature, whatever the size of the validator set.
+func maxBlockDataBytes(params tmproto.ConsensusParams) int64 {
+	return types.MaxDataBytes(params.Block.MaxBytes, 0, 1)
+}
+
 func validateBlock(state state.State, block *types.Block) error {
 	// Validate internal consistency.
 	if err := block.ValidateBasic(); err != nil {
@@ -76,8 +93,8 @@ func validateBlock(state state.State, block *types.Block) error {
 	// Validate block Time
 	switch {
 	case block.Height > state.InitialHeight:
-		if !(block.Time.After(state.LastBlockTime) || block.Time.Equal(state.LastBlockTime)) {
-			return fmt.Errorf("block time %v not greater than or equal to last block time %v",
+		if !block.Time.After(state.LastBlockTime) {
+			return fmt.Errorf("block time %v not greater than last block time %v",
 				block.Time,
 				state.LastBlockTime,
 			)
@@ -134,7 +151,7 @@ func execBlockOnProxyApp(
 	}
 	proxyAppConn.SetResponseCallback(proxyCb)
 
-	commitInfo := getBeginBlockValidatorInfo(block, store, initialHeight)
+	commitInfo := lastCommitInfo(block)
 
 	byzVals := make([]abci.Evidence, 0)
 	for _, evidence := range block.Evidence.Evidence {
@@ -159,12 +176,10 @@ func execBlockOnProxyApp(
 		return nil, err
 	}
 
-	// run txs of block
-	for _, tx := range block.Txs {
-		proxyAppConn.DeliverTxAsync(abci.RequestDeliverTx{Tx: tx})
-		if err := proxyAppConn.Error(); err != nil {
-			return nil, err
-		}
+	// run txs of block, until they used up the gas of the block
+	skippedTxs, err := state.DeliverTxsWithinGas(proxyAppConn, block.Txs, state.BlockMaxGas(store, block.Height), dtxs)
+	if err != nil {
+		return nil, err
 	}
 
 	// End block.
@@ -174,15 +189,22 @@ func execBlockOnProxyApp(
 		return nil, err
 	}
 
-	logger.Info("executed block", "height", block.Height, "num_valid_txs", validTxs, "num_invalid_txs", invalidTxs)
+	logger.Info("executed block", "height", block.Height, "num_valid_txs", validTxs, "num_invalid_txs", invalidTxs,
+		"num_skipped_txs", skippedTxs)
 	return abciResponses, nil
 }
 
-func getBeginBlockValidatorInfo(block *types.Block, store state.Store, initialHeight int64) abci.LastCommitInfo {
-	voteInfos := make([]abci.VoteInfo, block.LastCommit.Size())
+// lastCommitInfo returns the commit info BeginBlock is given with [block].
+// Blocks are accepted by Snowman, without precommit votes, so it holds no
+// vote, like the empty extended commit info an app built against ABCI 2.0 is
+// given when vote extensions are disabled: the apps which track the
+// signatures of the validators, such as the slashing module of the Cosmos
+// SDK, see no validator missing a block. See failOnVoteExtensions for the
+// apps which require vote extensions.
+func lastCommitInfo(block *types.Block) abci.LastCommitInfo {
 	return abci.LastCommitInfo{
 		Round: block.LastCommit.Round,
-		Votes: voteInfos,
+		Votes: []abci.VoteInfo{},
 	}
 }
 
@@ -196,32 +218,105 @@ func updateState(
 	header *types.Header,
 	abciResponses *tmstate.ABCIResponses,
 ) (state.State, error) {
+	var abciValUpdates []abci.ValidatorUpdate
+	if abciResponses.EndBlock != nil {
+		abciValUpdates = abciResponses.EndBlock.ValidatorUpdates
+	}
+	if err := validateValidatorUpdates(abciValUpdates, st.ConsensusParams.Validator); err != nil {
+		return st, fmt.Errorf("error in validator updates: %v", err)
+	}
+	validatorUpdates, err := types.PB2TM.ValidatorUpdates(abciValUpdates)
+	if err != nil {
+		return st, err
+	}
+
+	// Copy the valset so we can apply changes from EndBlock
+	// and update s.LastValidators and s.Validators.
+	nValSet := st.NextValidators.Copy()
+
+	// Update the validator set with the latest abciResponses.
+	lastHeightValsChanged := st.LastHeightValidatorsChanged
+	if len(validatorUpdates) > 0 {
+		if err := nValSet.UpdateWithChangeSet(validatorUpdates); err != nil {
+			return st, fmt.Errorf("error changing validator set: %v", err)
+		}
+		// Change results from this height but only applies to the next next height.
+		lastHeightValsChanged = header.Height + 1 + 1
+	}
+
+	// Update validator proposer priority and set state variables.
+	if !nValSet.IsNilOrEmpty() {
+		nValSet.IncrementProposerPriority(1)
+	}
+
+	// Update the params with the latest abciResponses, they apply from the
+	// next height.
+	nextParams := st.ConsensusParams
+	lastHeightParamsChanged := st.LastHeightConsensusParamsChanged
+	nextVersion := st.Version
+	if abciResponses.EndBlock != nil && abciResponses.EndBlock.ConsensusParamUpdates != nil {
+		nextParams = types.UpdateConsensusParams(st.ConsensusParams, abciResponses.EndBlock.ConsensusParamUpdates)
+		if err := types.ValidateConsensusParams(nextParams); err != nil {
+			return st, fmt.Errorf("error updating consensus params: %v", err)
+		}
+		nextVersion.Consensus.App = nextParams.Version.AppVersion
+		lastHeightParamsChanged = header.Height + 1
+	}
+
 	return state.State{
-		Version:         st.Version,
-		ChainID:         st.ChainID,
-		InitialHeight:   st.InitialHeight,
-		LastBlockHeight: header.Height,
-		LastBlockID:     blockID,
-		LastBlockTime:   header.Time,
-		LastResultsHash: ABCIResponsesResultsHash(abciResponses),
-		AppHash:         nil,
+		Version:                          nextVersion,
+		ChainID:                          st.ChainID,
+		InitialHeight:                    st.InitialHeight,
+		LastBlockHeight:                  header.Height,
+		LastBlockID:                      blockID,
+		LastBlockTime:                    header.Time,
+		NextValidators:                   nValSet,
+		Validators:                       st.NextValidators.Copy(),
+		LastValidators:                   st.Validators.Copy(),
+		LastHeightValidatorsChanged:      lastHeightValsChanged,
+		ConsensusParams:                  nextParams,
+		LastHeightConsensusParamsChanged: lastHeightParamsChanged,
+		LastResultsHash:                  ABCIResponsesResultsHash(abciResponses),
+		AppHash:                          nil,
 	}, nil
 }
 
+func validateValidatorUpdates(abciUpdates []abci.ValidatorUpdate, params tmproto.ValidatorParams) error {
+	for _, valUpdate := range abciUpdates {
+		if valUpdate.GetPower() < 0 {
+			return fmt.Errorf("voting power can't be negative %v", valUpdate)
+		} else if valUpdate.GetPower() == 0 {
+			// continue, since this is deleting the validator, and thus there is no
+			// pubkey to check
+			continue
+		}
+
+		// Check if validator's pubkey matches an ABCI type in the consensus params
+		pk, err := cryptoenc.PubKeyFromProto(valUpdate.PubKey)
+		if err != nil {
+			return err
+		}
+
+		if !types.IsValidPubkeyType(params, pk.Type()) {
+			return fmt.Errorf("validator %v is using pubkey %s, which is unsupported for consensus",
+				valUpdate, pk.Type())
+		}
+	}
+	return nil
+}
+
 // TxPreCheck returns a function to filter transactions before processing.
-// The function limits the size of a transaction to the block's maximum data size.
+// The function limits the size of a transaction to the block's maximum data
+// size under the consensus params of [state].
 func TxPreCheck(state state.State) mempl.PreCheckFunc {
-	maxDataBytes := types.MaxDataBytesNoEvidence(
-		22020096,
-		1,
-	)
-	return mempl.PreCheckMaxBytes(maxDataBytes)
+	return mempl.PreCheckMaxBytes(maxBlockDataBytes(state.ConsensusParams))
 }
 
 // TxPostCheck returns a function to filter transactions after processing.
-// The function limits the gas wanted by a transaction to the block's maximum total gas.
+// The function limits the gas wanted by a transaction to the block's maximum
+// total gas under the consensus params of [state].
 func TxPostCheck(state state.State) mempl.PostCheckFunc {
-	return mempl.PostCheckMaxGas(-1)
+	return mempl.PostCheckMaxGas(state.ConsensusParams.Block.MaxGas)
 }
 
 func fireEvents(
@@ -0,0 +1,47 @@
+package vm
+
+import (
+	"fmt"
+
+	abcicli "github.com/consideritdone/landslidecore/abci/client"
+	"github.com/consideritdone/landslidecore/libs/log"
+	"github.com/consideritdone/landslidecore/proxy"
+)
+
+// checkTxPool are the connections to the app the new txs are checked on
+// besides the mempool connection of the AppConns, so that the app may check
+// them in parallel rather than one at a time.
+type checkTxPool struct {
+	conns   []proxy.AppConnMempool
+	clients []abcicli.Client
+}
+
+// newCheckTxPool returns the pool of the abciCheckTxConnections config, or nil
+// if it is not set. The connections are created by [clientCreator].
+func newCheckTxPool(clientCreator proxy.ClientCreator, config Config, logger log.Logger) (*checkTxPool, error) {
+	if config.ABCICheckTxConnections <= 1 {
+		return nil, nil
+	}
+	pool := &checkTxPool{}
+	for i := 1; i < config.ABCICheckTxConnections; i++ {
+		client, err := clientCreator.NewABCIClient()
+		if err != nil {
+			pool.stop()
+			return nil, fmt.Errorf("error creating ABCI client (checkTx connection %d): %w", i, err)
+		}
+		client.SetLogger(logger.With("module", "abci-client", "connection", fmt.Sprintf("checkTx-%d", i)))
+		if err := client.Start(); err != nil {
+			pool.stop()
+			return nil, fmt.Errorf("error starting ABCI client (checkTx connection %d): %w", i, err)
+		}
+		pool.clients = append(pool.clients, client)
+		pool.conns = append(pool.conns, proxy.NewAppConnMempool(client))
+	}
+	return pool, nil
+}
+
+func (p *checkTxPool) stop() {
+	for _, client := range p.clients {
+		_ = client.Stop()
+	}
+}
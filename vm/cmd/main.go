@@ -1,25 +1,47 @@
+// Command main is the Landslide plugin, which avalanchego runs to serve the
+// VM of a subnet over the rpcchainvm protocol.
+//
+// The binary must be named after the VM ID of the subnet and installed in the
+// plugins directory of avalanchego. The VM runs the counter example app in
+// process, unless the chain config sets the proxyApp address of the app.
 package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	"github.com/consideritdone/landslidecore/abci/example/counter"
-	landslideCoreVM "github.com/consideritdone/landslidecore/vm"
 	"os"
 
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/ulimit"
+	"github.com/ava-labs/avalanchego/version"
 	"github.com/ava-labs/avalanchego/vms/rpcchainvm"
+
+	"github.com/consideritdone/landslidecore/abci/example/counter"
+	landslideCoreVM "github.com/consideritdone/landslidecore/vm"
 )
 
 func main() {
+	printVersion := flag.Bool("version", false, "print the versions of the VM and of the rpcchainvm protocol and exit")
+	flag.Parse()
+	if *printVersion {
+		fmt.Println(landslideCoreVM.StaticBuildInfo())
+		fmt.Printf("compatible avalanchego versions: %s\n", version.RPCChainVMProtocolCompatibility[version.RPCChainVMProtocol])
+		return
+	}
 
 	if err := ulimit.Set(ulimit.DefaultFDLimit, logging.NoLog{}); err != nil {
-		fmt.Printf("failed to set fd limit correctly due to: %s", err)
+		fmt.Printf("failed to set fd limit correctly due to: %s\n", err)
 		os.Exit(1)
 	}
 
 	vm := landslideCoreVM.NewVM(counter.NewApplication(true))
 
-	rpcchainvm.Serve(context.Background(), vm)
+	// Serve fails if avalanchego speaks another version of the rpcchainvm
+	// protocol, in which case the plugin must be built against a compatible
+	// avalanchego.
+	if err := rpcchainvm.Serve(context.Background(), vm); err != nil {
+		fmt.Printf("failed to serve the VM with rpcchainvm protocol %d: %s\n", version.RPCChainVMProtocol, err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,94 @@
+package vm
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/gorilla/rpc/v2"
+	"github.com/gorilla/rpc/v2/json2"
+)
+
+// newCodec returns the avalanchego json codec with errors reported using the
+// JSON-RPC error codes of Tendermint's RPC server, so that clients can handle
+// them the same way:
+//
+//   - unknown methods are reported as -32601 "Method not found"
+//   - malformed params are reported as -32602 "Invalid params"
+//   - the txs BroadcastTx* didn't get into the mempool are reported with the
+//     codes of admission.go, e.g. ErrCodeMempoolFull
+//   - other errors returned by the service are reported as -32603 "Internal
+//     error", with the error message in the data field
+func newCodec() rpc.Codec {
+	return &codec{Codec: json.NewCodec()}
+}
+
+type codec struct {
+	rpc.Codec
+}
+
+func (c *codec) NewRequest(r *http.Request) rpc.CodecRequest {
+	return &codecRequest{CodecRequest: c.Codec.NewRequest(r)}
+}
+
+// codecRequest keeps track of how far the server got in handling the request,
+// as the server reports every error through WriteError.
+type codecRequest struct {
+	rpc.CodecRequest
+
+	methodRead bool
+	paramsRead bool
+	paramsErr  error
+}
+
+func (r *codecRequest) Method() (string, error) {
+	method, err := r.CodecRequest.Method()
+	r.methodRead = err == nil
+	return method, err
+}
+
+func (r *codecRequest) ReadRequest(args interface{}) error {
+	r.paramsRead = true
+	r.paramsErr = r.CodecRequest.ReadRequest(args)
+	return r.paramsErr
+}
+
+func (r *codecRequest) WriteError(w http.ResponseWriter, status int, err error) {
+	var txErr *txError
+	if errors.As(err, &txErr) && txErr.retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(txErr.retryAfter.Seconds()))))
+	}
+	r.CodecRequest.WriteError(w, status, r.mapError(err))
+}
+
+func (r *codecRequest) mapError(err error) error {
+	var jsonErr *json2.Error
+	if r.paramsErr != nil {
+		// json2 reports malformed params as an invalid request, with the
+		// unmarshal error as message
+		data := err.Error()
+		if errors.As(err, &jsonErr) {
+			data = jsonErr.Message
+		}
+		return &json2.Error{Code: json2.E_BAD_PARAMS, Message: "Invalid params", Data: data}
+	}
+	if errors.As(err, &jsonErr) {
+		return jsonErr
+	}
+	var txErr *txError
+	if errors.As(err, &txErr) {
+		return txErr.jsonError()
+	}
+
+	switch {
+	case !r.methodRead:
+		return &json2.Error{Code: json2.E_INVALID_REQ, Message: "Invalid Request", Data: err.Error()}
+	case !r.paramsRead:
+		// the method was parsed but isn't registered
+		return &json2.Error{Code: json2.E_NO_METHOD, Message: "Method not found", Data: err.Error()}
+	default:
+		return &json2.Error{Code: json2.E_INTERNAL, Message: "Internal error", Data: err.Error()}
+	}
+}
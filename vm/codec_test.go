@@ -0,0 +1,59 @@
+package vm
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/rpc/v2/json2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+)
+
+func TestCodecErrors(t *testing.T) {
+	vm, service, _ := mustNewCounterTestVm(t)
+	server := newTestServer(t, vm, "/rpc")
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte{0x00}}, new(ctypes.ResultBroadcastTx)))
+
+	call := func(t *testing.T, method string, params string) *json2.Error {
+		body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":` + params + `}`
+		resp, err := http.Post(server.URL, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var reply struct {
+			Error *json2.Error `json:"error"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply))
+		require.NotNil(t, reply.Error)
+		return reply.Error
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		params  string
+		code    json2.ErrorCode
+		message string
+		data    string
+	}{
+		{"unknown method", Name + ".unknown", `{}`, json2.E_NO_METHOD, "Method not found", ""},
+		{"invalid params", Name + ".block", `{"height":"abc"}`, json2.E_BAD_PARAMS, "Invalid params", "couldn't unmarshal an argument"},
+		{"tx not found", Name + ".tx", `{"hash":"AAAA"}`, json2.E_INTERNAL, "Internal error", "tx (000000) not found"},
+		{"duplicate tx", Name + ".broadcastTxSync", `{"tx":"AA=="}`, ErrCodeDuplicateTx, "Duplicate tx", "tx already exists in cache"},
+		{"checkTx failed", Name + ".broadcastTxSync", `{"tx":"AAAAAAAAAAAA","checkTxErrors":true}`, ErrCodeCheckTxFailed, "CheckTx failed", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpcErr := call(t, tt.method, tt.params)
+			assert.Equal(t, tt.code, rpcErr.Code)
+			assert.Equal(t, tt.message, rpcErr.Message)
+			if tt.data != "" {
+				assert.Contains(t, rpcErr.Data, tt.data)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package vm
+
+import (
+	"fmt"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	tmstate "github.com/consideritdone/landslidecore/proto/tendermint/state"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// atomically runs [write] and commits what it wrote to the block store, the
+// state store and the indexers in a single batch, as they all share
+// [vm.versionDB]. The writes are discarded if [write] fails, so neither an
+// error nor a crash can leave one of them ahead of the others.
+func (vm *VM) atomically(write func() error) error {
+	vm.commitMtx.Lock()
+	defer vm.commitMtx.Unlock()
+	// no-op once committed
+	defer vm.versionDB.Abort()
+
+	if err := write(); err != nil {
+		return err
+	}
+	vm.commitErr = vm.versionDB.Commit()
+	if vm.commitErr != nil {
+		return fmt.Errorf("failed to commit database: %w", vm.commitErr)
+	}
+	return nil
+}
+
+// indexBlock indexes [block] and its txs, as the IndexerService does from the
+// events of the block.
+func (vm *VM) indexBlock(block *types.Block, abciResponses *tmstate.ABCIResponses) error {
+	header, txResults := blockEvents(block, abciResponses)
+	sink := kvEventSink{txIndexer: vm.txIndexer, blockIndexer: vm.blockIndexer}
+	if err := sink.IndexBlockEvents(header); err != nil {
+		return fmt.Errorf("failed to index block %d: %w", block.Height, err)
+	}
+	if err := sink.IndexTxEvents(txResults); err != nil {
+		return fmt.Errorf("failed to index txs of block %d: %w", block.Height, err)
+	}
+	return nil
+}
+
+// blockEvents returns the events of [block] and of its txs, which are indexed.
+func blockEvents(block *types.Block, abciResponses *tmstate.ABCIResponses) (types.EventDataNewBlockHeader, []*abci.TxResult) {
+	txResults := make([]*abci.TxResult, len(block.Txs))
+	for i, tx := range block.Txs {
+		txResults[i] = &abci.TxResult{
+			Height: block.Height,
+			Index:  uint32(i),
+			Tx:     tx,
+			Result: *(abciResponses.DeliverTxs[i]),
+		}
+	}
+	return types.EventDataNewBlockHeader{
+		Header:           block.Header,
+		NumTxs:           int64(len(block.Txs)),
+		ResultBeginBlock: *abciResponses.BeginBlock,
+		ResultEndBlock:   *abciResponses.EndBlock,
+	}, txResults
+}
@@ -0,0 +1,41 @@
+package vm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/store"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+func TestAtomicCommit(t *testing.T) {
+	vm, service, _ := mustNewKVTestVm(t)
+
+	tx := types.Tx("a=1")
+	blk := mustAcceptBlock(t, vm, service, tx)
+	vm.acceptor.flush()
+	reply := new(ctypes.ResultTx)
+	require.NoError(t, service.Tx(nil, &TxArgs{Hash: tx.Hash()}, reply))
+	assert.EqualValues(t, blk.Height(), reply.Height)
+
+	// and the block is committed to the underlying database
+	baseDB := vm.dbManager.Current().Database
+	blockStore := store.NewBlockStore(Database{prefixdb.NewNested(blockStoreDBPrefix, baseDB)})
+	assert.Equal(t, vm.blockStore.Height(), blockStore.Height())
+
+	// the writes are discarded on errors
+	errWrite := errors.New("write failed")
+	err := vm.atomically(func() error {
+		require.NoError(t, vm.stateDB.Set([]byte("key"), []byte("value")))
+		return errWrite
+	})
+	assert.ErrorIs(t, err, errWrite)
+	value, err := vm.stateDB.Get([]byte("key"))
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
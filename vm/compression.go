@@ -0,0 +1,119 @@
+package vm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// compressedMethods are the service methods whose replies may be large enough
+// to be worth compressing. Method names are matched case-insensitively, as the
+// codec accepts them with a lowercase first letter.
+var compressedMethods = map[string]struct{}{
+	Name + ".block":          {},
+	Name + ".blockbyhash":    {},
+	Name + ".blockresults":   {},
+	Name + ".genesis":        {},
+	Name + ".genesischunked": {},
+	Name + ".txsearch":       {},
+	Name + ".blocksearch":    {},
+}
+
+// gzipMinSize is the size, in bytes, from which replies are compressed.
+// Smaller replies are sent as is, compressing them isn't worth the cost.
+const gzipMinSize = 1024
+
+// bufferedResponseWriter holds the reply written by a handler, so that it can
+// be compressed or not depending on its size once it is complete.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	buf        bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
+
+// flush writes the buffered reply to the underlying http.ResponseWriter, gzip
+// encoded if it is at least gzipMinSize bytes long.
+func (w *bufferedResponseWriter) flush() error {
+	if w.buf.Len() < gzipMinSize {
+		w.ResponseWriter.WriteHeader(w.statusCode)
+		_, err := w.ResponseWriter.Write(w.buf.Bytes())
+		return err
+	}
+
+	// the length of the compressed body is not known in advance
+	w.Header().Del("Content-Length")
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Add("Vary", "Accept-Encoding")
+	w.ResponseWriter.WriteHeader(w.statusCode)
+	gz := gzip.NewWriter(w.ResponseWriter)
+	if _, err := gz.Write(w.buf.Bytes()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// newGzipHandler wraps [handler] so that replies of the compressed methods are
+// gzip encoded when the client sends "Accept-Encoding: gzip" and they are at
+// least gzipMinSize bytes long. Request bodies larger than [maxBodyBytes] are
+// rejected.
+func newGzipHandler(handler http.Handler, maxBodyBytes int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+		if !acceptsGzip(r) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var req struct {
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			// let the codec report the malformed request
+			handler.ServeHTTP(w, r)
+			return
+		}
+		if _, ok := compressedMethods[strings.ToLower(req.Method)]; !ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		bw := &bufferedResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		handler.ServeHTTP(bw, r)
+		_ = bw.flush()
+	})
+}
+
+// acceptsGzip reports whether the client accepts gzip encoded replies.
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		encoding, _, _ = strings.Cut(strings.TrimSpace(encoding), ";")
+		if encoding == "gzip" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package vm
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzipHandler(t *testing.T) {
+	vm, service, _ := mustNewCounterTestVm(t)
+	server := newTestServer(t, vm, "/rpc")
+
+	for i := byte(0); i < 3; i++ {
+		mustAcceptBlock(t, vm, service, []byte{i})
+	}
+	require.Eventually(t, func() bool {
+		ok, err := vm.blockIndexer.Has(3)
+		return err == nil && ok
+	}, 5*time.Second, 100*time.Millisecond)
+
+	call := func(method string, params string, acceptEncoding string) *http.Response {
+		body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":` + params + `}`
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	const blockSearchParams = `{"query":"block.height > 0"}`
+	resp := call(Name+".blockSearch", blockSearchParams, "gzip, deflate")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	gz, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(body, &reply))
+	assert.NotEmpty(t, reply.Result)
+
+	// replies of compressed methods below gzipMinSize are not compressed
+	resp = call(Name+".genesis", `{}`, "gzip")
+	defer resp.Body.Close()
+	assert.Empty(t, resp.Header.Get("Content-Encoding"))
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Less(t, len(body), gzipMinSize)
+
+	// neither are replies of other methods, nor replies to clients without
+	// gzip support
+	resp = call(Name+".health", `{}`, "gzip")
+	defer resp.Body.Close()
+	assert.Empty(t, resp.Header.Get("Content-Encoding"))
+
+	resp = call(Name+".blockSearch", blockSearchParams, "identity")
+	defer resp.Body.Close()
+	assert.Empty(t, resp.Header.Get("Content-Encoding"))
+
+	// oversized requests are rejected
+	resp = call(Name+".health", `"`+strings.Repeat("a", int(vm.config.MaxRequestBodyBytes))+`"`, "gzip")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
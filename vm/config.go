@@ -0,0 +1,698 @@
+package vm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	tmbytes "github.com/consideritdone/landslidecore/libs/bytes"
+	tmquery "github.com/consideritdone/landslidecore/libs/pubsub/query"
+)
+
+const (
+	defaultABCITransport             = "socket"
+	defaultValidatorUpdates          = validatorUpdatesApply
+	defaultMempoolRecheck            = mempoolRecheckAsync
+	defaultReapStrategy              = reapStrategyPriority
+	defaultMempoolSize               = 5000
+	defaultMempoolMaxTxsBytes        = 1024 * 1024 * 1024 // 1GB
+	defaultMempoolMaxTxBytes         = 1024 * 1024        // 1MB
+	defaultMempoolCacheSize          = 10000
+	defaultAcceptQueueSize           = 64
+	defaultEventSinkQueueSize        = 256
+	defaultProxyAppDialTimeout       = time.Minute
+	defaultABCIInfoCacheTTL          = time.Second
+	defaultABCIQueryTimeout          = 30 * time.Second
+	defaultABCICheckTxTimeout        = 10 * time.Second
+	defaultABCIBreakerThreshold      = 5
+	defaultABCIQueryConnections      = 1
+	defaultABCICheckTxConnections    = 1
+	defaultABCIBreakerCooldown       = 10 * time.Second
+	defaultTxGossipInterval          = 10 * time.Second
+	defaultPersistMempoolInterval    = 30 * time.Second
+	defaultBuildMinTxs               = 1
+	defaultTargetBlockExecutionTime  = 2 * time.Second
+	defaultMaxBatchTxs               = 1000
+	defaultMaxRequestBodyBytes       = 1000000
+	defaultSlowQueryThreshold        = time.Second
+	defaultMaxWSConnections          = 100
+	defaultMaxSubscriptionsPerClient = 5
+	defaultWSWriteBufferSize         = 200
+	defaultWSPingPeriod              = 27 * time.Second
+	defaultWSPongWait                = 30 * time.Second
+	defaultHealthMaxMempoolUsage     = 0.9
+	defaultMempoolRetryAfter         = time.Second
+)
+
+// Config is the VM configuration, passed by avalanchego as JSON in the
+// configBytes argument of Initialize.
+type Config struct {
+	// Rollback rewinds the state store, block store and indexers by one height
+	// on startup, before the ABCI handshake. It is meant for recovering from
+	// an app-hash mismatch after a bad app upgrade, once the app itself has
+	// been rolled back. The rollback is only done on the first start with the
+	// flag set.
+	Rollback bool `json:"rollback"`
+
+	// ChainID overrides the Tendermint chain_id of the genesis, which
+	// otherwise defaults to one derived from the Avalanche chain ID. It can
+	// only be set to another value when the chain is created.
+	ChainID string `json:"chainId"`
+
+	// ProxyApp is the address of an out-of-process ABCI app to run instead of
+	// the in-process one, e.g. "tcp://127.0.0.1:26658" or
+	// "unix:///var/run/app.sock".
+	ProxyApp string `json:"proxyApp"`
+	// ABCITransport is the protocol spoken to ProxyApp: "socket" or "grpc".
+	ABCITransport string `json:"abciTransport"`
+	// ProxyAppDialTimeout is how long to wait on startup for ProxyApp to
+	// accept connections.
+	ProxyAppDialTimeout Duration `json:"proxyAppDialTimeout"`
+
+	// ABCIQueryTimeout is how long the Query, Info and Echo calls to the app
+	// may take before they fail. 0 waits for the app.
+	ABCIQueryTimeout Duration `json:"abciQueryTimeout"`
+	// ABCICheckTxTimeout is how long the CheckTx calls to the app may take
+	// before they fail. 0 waits for the app.
+	ABCICheckTxTimeout Duration `json:"abciCheckTxTimeout"`
+	// ABCIDeliverTxTimeout is how long each of the calls executing a block,
+	// from BeginBlock to Commit, may take before the block fails. As the call
+	// may still complete in the app, no other block is executed after: the
+	// chain is halted, and the node reports itself unhealthy, until it
+	// restarts. 0 waits for the app.
+	ABCIDeliverTxTimeout Duration `json:"abciDeliverTxTimeout"`
+	// ABCIBreakerThreshold is the number of consecutive timed out queries, or
+	// CheckTx calls, from which the calls of the same type fail right away
+	// for ABCIBreakerCooldown, after which a single call probes the app. The
+	// node reports itself unhealthy while CheckTx fails. 0 disables the
+	// breakers.
+	ABCIBreakerThreshold int `json:"abciBreakerThreshold"`
+	// ABCIBreakerCooldown is how long the calls to the app fail right away
+	// once ABCIBreakerThreshold calls timed out.
+	ABCIBreakerCooldown Duration `json:"abciBreakerCooldown"`
+	// ABCIQueryConnections is the number of connections to ProxyApp the
+	// queries are spread over, so that a slow query doesn't hold up the next
+	// ones. The in-process app has a single query connection, as it takes one
+	// call at a time.
+	ABCIQueryConnections int `json:"abciQueryConnections"`
+	// ABCIMaxConcurrentQueries is the number of queries the app is asked at
+	// once, so that heavy query traffic doesn't hold up the execution of
+	// blocks. The next queries wait for one to complete, up to
+	// ABCIQueryTimeout. 0 doesn't bound them.
+	ABCIMaxConcurrentQueries int `json:"abciMaxConcurrentQueries"`
+	// ABCICheckTxConnections is the number of connections to the app the new
+	// txs are checked on, so that the app may check them in parallel, a
+	// worker per connection checking the gossiped and batched txs. The txs of
+	// a sender are checked on the same connection, in order, so more than one
+	// requires WithTxSender. The extra connections of the in-process app don't
+	// take the lock of the other connections: its CheckTx must be safe to call
+	// concurrently.
+	ABCICheckTxConnections int `json:"abciCheckTxConnections"`
+
+	// StateSyncEnabled makes a new node restore the app from a snapshot
+	// served by its peers, instead of executing every block. The app must
+	// implement the ABCI snapshot methods.
+	StateSyncEnabled bool `json:"stateSyncEnabled"`
+
+	// MirrorValidatorSet makes the validators of the subnet, as registered on
+	// the P-Chain, the Tendermint validator set, which the Validators endpoint
+	// returns, instead of the validators of the app. It is refreshed whenever
+	// the P-Chain height blocks are verified with moves, once the proposervm
+	// is activated. It must be set alike on every node, and requires
+	// ValidatorUpdates to be "reject".
+	MirrorValidatorSet bool `json:"mirrorValidatorSet"`
+	// ValidatorUpdates is what becomes of the validator updates the app
+	// returns from EndBlock: "apply" applies them to the validator set, and
+	// "reject", for chains secured by the Avalanche validators only, fails
+	// the block, which halts the chain until the app is fixed. It must be set
+	// alike on every node.
+	ValidatorUpdates string `json:"validatorUpdates"`
+	// FailOnVoteExtensions makes Initialize fail when the genesis enables the
+	// vote extensions of ABCI 2.0, with vote_extensions_enable_height, which
+	// the VM can't provide: blocks are accepted by Snowman, without precommit
+	// votes to extend. Otherwise the app is given an empty commit info,
+	// without signed votes nor extensions, with every block.
+	FailOnVoteExtensions bool `json:"failOnVoteExtensions"`
+
+	// AcceptQueueSize is the number of accepted blocks which may wait for
+	// their indexing, events and pruning, done in the background so that a
+	// slow indexer or event subscriber doesn't stall consensus. Accept waits
+	// once the queue is full. 0 does them within Accept.
+	AcceptQueueSize int `json:"acceptQueueSize"`
+	// EventSinkQueueSize is the number of accepted blocks which may wait for
+	// each event sink, e.g. the ones of WithEventSinks, which is passed the
+	// events in the background so that a slow or failing sink doesn't hold
+	// up the others. The blocks are left out of the queue of a sink while it
+	// is full, and loaded from the block store once the sink caught up; they
+	// aren't pruned until then. 0 passes the events along with the indexing
+	// of the blocks.
+	EventSinkQueueSize int `json:"eventSinkQueueSize"`
+	// EventSinkPsqlConn is the connection string of a PostgreSQL database
+	// the events are indexed in, besides the database of the VM. Its schema,
+	// state/indexer/sink/psql/schema.sql, must be created beforehand. Empty
+	// disables it.
+	EventSinkPsqlConn string `json:"eventSinkPsqlConn"`
+	// Webhooks are the HTTP endpoints notified of the block headers and tx
+	// results matching their query, e.g. the governance proposals, as event
+	// sinks.
+	Webhooks []Webhook `json:"webhooks"`
+	// EventStreamNATS is the URL, nats://host:port, of a NATS server the
+	// block headers and tx results are streamed to with JetStream, unless
+	// WithEventStream sets another publisher. A JetStream stream must capture
+	// their subjects, "blocks" and "txs" after EventStreamTopicPrefix and a
+	// dot, if it is set. Empty disables it.
+	EventStreamNATS        string `json:"eventStreamNATS"`
+	EventStreamTopicPrefix string `json:"eventStreamTopicPrefix"`
+
+	// TxGossipInterval is how often the hashes of the oldest txs of the
+	// mempool are announced again to the peers, which fetch those they miss,
+	// e.g. after a restart. New txs submitted to the node are announced right
+	// away. 0 disables the periodic announcements.
+	TxGossipInterval Duration `json:"txGossipInterval"`
+	// PersistMempool saves the txs of the mempool on shutdown and checks
+	// them into the mempool again on restart. Otherwise they are lost, unless
+	// a peer gossips them again.
+	PersistMempool bool `json:"persistMempool"`
+	// PersistMempoolInterval is how often the txs of the mempool are saved
+	// while the node runs, when PersistMempool is set, so that a crash loses
+	// at most the txs of the last interval. 0 only saves them on shutdown.
+	PersistMempoolInterval Duration `json:"persistMempoolInterval"`
+	// MempoolRecheck is how the txs left in the mempool are checked again by
+	// the app after every accepted block, so that those the block invalidated,
+	// e.g. with a spent nonce, are evicted rather than proposed again: "async"
+	// rechecks them in the background, the next block waiting for the recheck
+	// in progress before it updates the mempool, so that it lags at most a
+	// block, "sync" completes the recheck before Accept returns, so the next
+	// block is built from valid txs only, and "off" doesn't recheck them.
+	MempoolRecheck string `json:"mempoolRecheck"`
+	// MempoolRecheckBatchSize is the number of txs rechecked after a block,
+	// the next recheck going on from the tx the last one stopped at, to bound
+	// the work of a block when the mempool is large. 0 rechecks all the txs.
+	MempoolRecheckBatchSize int `json:"mempoolRecheckBatchSize"`
+	// MempoolSize is the number of txs the mempool holds. Once it is full, a
+	// new tx evicts the txs of lower priority, as returned by the app from
+	// CheckTx, or is rejected if there are not enough of them.
+	MempoolSize int `json:"mempoolSize"`
+	// MempoolMaxTxsBytes is the total size of the txs the mempool holds, the
+	// same as MempoolSize but in bytes.
+	MempoolMaxTxsBytes int64 `json:"mempoolMaxTxsBytes"`
+	// MempoolMaxTxBytes is the size of the largest tx the mempool accepts.
+	MempoolMaxTxBytes int `json:"mempoolMaxTxBytes"`
+	// MempoolBannedPrefixes are the hex-encoded prefixes of the txs the
+	// mempool rejects before the app checks them, along with the filters set
+	// with WithTxFilters.
+	MempoolBannedPrefixes []tmbytes.HexBytes `json:"mempoolBannedPrefixes"`
+	// MempoolCacheSize is the number of recently seen txs the mempool
+	// remembers, so that they aren't checked again when received from
+	// several peers. 0 disables the cache.
+	MempoolCacheSize int `json:"mempoolCacheSize"`
+	// MempoolCacheTTL is how long a tx stays in the cache, after which it is
+	// checked again when submitted rather than rejected as already seen. 0
+	// keeps it until it is pushed out by newer txs.
+	MempoolCacheTTL Duration `json:"mempoolCacheTTL"`
+	// MempoolTTLNumBlocks is the number of blocks after which a tx still in
+	// the mempool expires: it is evicted and a TxExpired event is published.
+	// 0 disables it.
+	MempoolTTLNumBlocks int64 `json:"mempoolTTLNumBlocks"`
+	// MempoolTTLDuration is how long a tx may stay in the mempool before it
+	// expires, checked after every accepted block. 0 disables it.
+	MempoolTTLDuration Duration `json:"mempoolTTLDuration"`
+	// MempoolOriginRate is the number of txs per second the mempool checks
+	// for each origin: an RPC client, by its IP address, or a peer gossiping
+	// txs, so that one of them can't monopolize the mempool. The txs over it
+	// are rejected up to MempoolOriginBurst at once, MempoolOriginRate rounded
+	// up if 0. 0 disables it.
+	MempoolOriginRate  float64 `json:"mempoolOriginRate"`
+	MempoolOriginBurst int     `json:"mempoolOriginBurst"`
+	// MempoolOriginMaxTxs is the number of txs of an origin the mempool holds,
+	// the next ones being rejected until some leave it. 0 disables it.
+	MempoolOriginMaxTxs int `json:"mempoolOriginMaxTxs"`
+	// MempoolHighWaterMark is the fraction of the mempool capacity, in txs or
+	// bytes, from which BroadcastTx* reject the txs with
+	// ErrCodeMempoolSaturated, telling clients to retry them after
+	// MempoolRetryAfter, and the txs of the mempool aren't announced again to
+	// the peers, so that the node sheds load before the mempool is full. 0
+	// disables it.
+	MempoolHighWaterMark float64  `json:"mempoolHighWaterMark"`
+	MempoolRetryAfter    Duration `json:"mempoolRetryAfter"`
+	// MempoolLanes are the lanes of the mempool, e.g. for oracle or IBC
+	// relayer txs, which the app puts its txs in with a "lane" attribute of
+	// a CheckTx event, see mempl.LaneAttributeKey. Each lane holds its txs
+	// besides the capacity of the others, and gets its share of every block,
+	// so that the txs of other lanes can't crowd them out. The txs without a
+	// lane, or of an unknown one, are in the default lane, of mempoolSize
+	// txs and mempoolMaxTxsBytes bytes.
+	MempoolLanes []MempoolLane `json:"mempoolLanes"`
+
+	// ReapStrategy is the order in which the txs of the mempool are included
+	// in the blocks built: "priority" includes the txs of the highest
+	// priority returned by the app from CheckTx first, "fifo" the oldest
+	// first, and "roundRobin" a tx of each sender in turn, so that a sender
+	// with many txs doesn't delay the others. The txs of a sender are always
+	// included in the order of their sequences, see mempl.SequenceAttributeKey.
+	ReapStrategy string `json:"reapStrategy"`
+	// BuildMinTxs is the number of txs the mempool must hold before the
+	// engine is told to build a block.
+	BuildMinTxs int `json:"buildMinTxs"`
+	// MinBlockInterval is the minimum time between two blocks built by the
+	// node. 0 means blocks are built as soon as there are txs.
+	MinBlockInterval Duration `json:"minBlockInterval"`
+	// MaxBuildWait is how long a tx may wait for BuildMinTxs txs to gather
+	// before a block is built anyway. 0 means it waits until they do.
+	MaxBuildWait Duration `json:"maxBuildWait"`
+	// CreateEmptyBlocks lets the node build a block without txs once no
+	// block has been accepted for CreateEmptyBlocksInterval, so that the
+	// header time, which IBC clients and time-based app logic follow,
+	// advances on an idle chain. Otherwise blocks are only built for txs.
+	CreateEmptyBlocks bool `json:"createEmptyBlocks"`
+	// CreateEmptyBlocksInterval is how long the chain may stay idle before an
+	// empty block is built. It must be positive if CreateEmptyBlocks is set.
+	CreateEmptyBlocksInterval Duration `json:"createEmptyBlocksInterval"`
+	// TargetBlockExecutionTime is how long the app should take to execute
+	// and commit a block. Once a block takes longer, the next blocks are
+	// built with half its txs, and the engine is signaled later by the
+	// excess, until the app keeps up again and the number of txs grows back.
+	// 0 disables it.
+	TargetBlockExecutionTime Duration `json:"targetBlockExecutionTime"`
+	// DevBlockInterval makes the node build a block on this interval, with or
+	// without txs, regardless of the settings above, so that a single node
+	// chain in local development gets a block every second or so, as with
+	// Tendermint. It isn't meant for chains of several nodes. 0 disables it.
+	DevBlockInterval Duration `json:"devBlockInterval"`
+
+	// QueryCacheSize is the number of replies of the Block, BlockResults,
+	// Commit and Validators endpoints kept in memory. 0 disables the cache.
+	QueryCacheSize int `json:"queryCacheSize"`
+	// ABCIInfoCacheTTL is how long the reply of the ABCIInfo endpoint may be
+	// served from memory. It is also refetched after every block. 0 disables
+	// the cache.
+	ABCIInfoCacheTTL Duration `json:"abciInfoCacheTtl"`
+
+	// DefaultPerPage is the page size of the TxSearch, BlockSearch, Validators
+	// and UnconfirmedTxs endpoints when the request doesn't set one.
+	DefaultPerPage int `json:"defaultPerPage"`
+	// MaxPerPage is the largest page size a request may ask for. Larger
+	// requested page sizes are capped to it.
+	MaxPerPage int `json:"maxPerPage"`
+
+	// SlowQueryThreshold is the duration from which TxSearch and BlockSearch
+	// queries are logged, with the time spent parsing the query, searching
+	// the indexer and loading the results. 0 disables the logging.
+	SlowQueryThreshold Duration `json:"slowQueryThreshold"`
+
+	// MaxBatchTxs is the largest number of txs a BroadcastTxBatch request may
+	// carry. Larger batches are rejected.
+	MaxBatchTxs int `json:"maxBatchTxs"`
+	// MaxRequestBodyBytes is the largest body, in bytes, of a request to the
+	// rpc handler. Larger requests are rejected.
+	MaxRequestBodyBytes int64 `json:"maxRequestBodyBytes"`
+
+	// AttestCommits makes the Commit endpoint return the node's BLS signature
+	// over the block header, for consumers which need something verifiable.
+	AttestCommits bool `json:"attestCommits"`
+	// CrossChainRequestsEnabled makes the VM answer the ABCI queries and tx
+	// status checks of the other chains of the node, with attested results.
+	// The requests are dropped if it is disabled.
+	CrossChainRequestsEnabled bool `json:"crossChainRequestsEnabled"`
+
+	// RPCUnixSocket is the path of a Unix socket on which the rpc handlers
+	// are also served, for processes running on the same host. The handlers
+	// are only served over the avalanchego HTTP server if it is empty. The
+	// socket is only accessible to the user running the node.
+	RPCUnixSocket string `json:"rpcUnixSocket"`
+	// RPCUnixSocketAdmin also serves the admin handler on RPCUnixSocket.
+	RPCUnixSocketAdmin bool `json:"rpcUnixSocketAdmin"`
+	// AdminAPIEnabled serves the admin handler on the avalanchego HTTP
+	// server, to anyone who can reach it, as the admin API of avalanchego.
+	// It is off by default: the admin handler is then only served on the
+	// RPCUnixSocket, with RPCUnixSocketAdmin.
+	AdminAPIEnabled bool `json:"adminAPIEnabled"`
+
+	// HealthMaxBlockAge is how long ago the last block may have been accepted
+	// before the node reports itself unhealthy. 0 disables the check, as
+	// blocks are only built for txs unless CreateEmptyBlocks is set.
+	HealthMaxBlockAge Duration `json:"healthMaxBlockAge"`
+	// HealthMaxMempoolUsage is the fraction of the mempool capacity, in txs
+	// or bytes, from which the node reports itself unhealthy. 0 disables the check.
+	HealthMaxMempoolUsage float64 `json:"healthMaxMempoolUsage"`
+	// HealthMaxIndexerLag is the number of accepted blocks which may wait to
+	// be indexed before the node reports itself unhealthy. 0 disables the
+	// check.
+	HealthMaxIndexerLag int64 `json:"healthMaxIndexerLag"`
+
+	// MaxWSConnections is the maximum number of concurrent websocket
+	// connections. 0 means unlimited.
+	MaxWSConnections int `json:"maxWSConnections"`
+	// MaxSubscriptionsPerClient is the maximum number of queries a websocket
+	// client can subscribe to.
+	MaxSubscriptionsPerClient int `json:"maxSubscriptionsPerClient"`
+	// WSWriteBufferSize is the number of responses buffered per websocket
+	// connection before events are dropped for the client.
+	WSWriteBufferSize int `json:"wsWriteBufferSize"`
+	// WSPingPeriod is how often pings are sent to websocket clients.
+	WSPingPeriod Duration `json:"wsPingPeriod"`
+	// WSPongWait is how long a websocket connection may stay silent, not even
+	// answering pings, before it is closed. It must exceed WSPingPeriod.
+	WSPongWait Duration `json:"wsPongWait"`
+}
+
+// MempoolLane is a lane of MempoolLanes.
+type MempoolLane struct {
+	Name string `json:"name"`
+	// Size and MaxTxsBytes are the number of txs and the total size of the
+	// txs the lane holds.
+	Size        int   `json:"size"`
+	MaxTxsBytes int64 `json:"maxTxsBytes"`
+	// BlockShare is the fraction of the bytes and gas of every block reserved
+	// to the txs of the lane. The rest of the block is filled with the txs of
+	// all the lanes in the order of ReapStrategy.
+	BlockShare float64 `json:"blockShare"`
+}
+
+func validateMempoolLanes(lanes []MempoolLane) error {
+	names := make(map[string]bool, len(lanes))
+	var blockShares float64
+	for _, lane := range lanes {
+		if lane.Name == "" {
+			return fmt.Errorf("mempoolLanes must have a name")
+		}
+		if names[lane.Name] {
+			return fmt.Errorf("mempoolLanes has lane %q twice", lane.Name)
+		}
+		names[lane.Name] = true
+		if lane.Size < 1 || lane.MaxTxsBytes < 1 {
+			return fmt.Errorf("mempoolLanes lane %q must have a positive size and maxTxsBytes", lane.Name)
+		}
+		if lane.BlockShare < 0 || lane.BlockShare > 1 {
+			return fmt.Errorf("mempoolLanes lane %q blockShare must be between 0 and 1, got %v", lane.Name, lane.BlockShare)
+		}
+		blockShares += lane.BlockShare
+	}
+	if blockShares > 1 {
+		return fmt.Errorf("mempoolLanes blockShares add up to %v, more than 1", blockShares)
+	}
+	return nil
+}
+
+// Webhook is an HTTP endpoint the block headers and tx results matching Query
+// are POSTed to, as they are sent to the websocket subscriptions, e.g. with
+// "tm.event = 'Tx' AND submit_proposal.proposal_id EXISTS".
+type Webhook struct {
+	URL   string `json:"url"`
+	Query string `json:"query"`
+	// MaxRetries is how many times a failed POST is retried, up to 100,
+	// after RetryBackoff, doubled after each retry up to 30s. RetryBackoff
+	// defaults to 1s.
+	MaxRetries   int      `json:"maxRetries"`
+	RetryBackoff Duration `json:"retryBackoff"`
+}
+
+func validateWebhooks(hooks []Webhook) error {
+	for _, hook := range hooks {
+		u, err := url.Parse(hook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhooks url must be an http or https URL, got %q", hook.URL)
+		}
+		if _, err := tmquery.New(hook.Query); err != nil {
+			return fmt.Errorf("webhooks query of %s is invalid: %w", hook.URL, err)
+		}
+		if hook.MaxRetries < 0 || hook.RetryBackoff.Duration < 0 {
+			return fmt.Errorf("webhooks maxRetries and retryBackoff of %s must be non-negative", hook.URL)
+		}
+		if hook.MaxRetries > maxWebhookRetries {
+			return fmt.Errorf("webhooks maxRetries of %s must be at most %d, got %d", hook.URL, maxWebhookRetries, hook.MaxRetries)
+		}
+		if hook.RetryBackoff.Duration > maxWebhookRetryBackoff {
+			return fmt.Errorf("webhooks retryBackoff of %s must be at most %s, got %s", hook.URL, maxWebhookRetryBackoff, hook.RetryBackoff)
+		}
+	}
+	return nil
+}
+
+// Duration is a time.Duration encoded in JSON as a string such as "30s".
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
+
+// DefaultConfig returns the configuration used when no config is provided.
+func DefaultConfig() Config {
+	return Config{
+		Rollback:            false,
+		ABCITransport:       defaultABCITransport,
+		ValidatorUpdates:    defaultValidatorUpdates,
+		AcceptQueueSize:     defaultAcceptQueueSize,
+		EventSinkQueueSize:  defaultEventSinkQueueSize,
+		MempoolRecheck:      defaultMempoolRecheck,
+		ReapStrategy:        defaultReapStrategy,
+		MempoolSize:         defaultMempoolSize,
+		MempoolMaxTxsBytes:  defaultMempoolMaxTxsBytes,
+		MempoolMaxTxBytes:   defaultMempoolMaxTxBytes,
+		MempoolCacheSize:    defaultMempoolCacheSize,
+		MempoolRetryAfter:   Duration{defaultMempoolRetryAfter},
+		ProxyAppDialTimeout: Duration{defaultProxyAppDialTimeout},
+
+		ABCIQueryTimeout:       Duration{defaultABCIQueryTimeout},
+		ABCICheckTxTimeout:     Duration{defaultABCICheckTxTimeout},
+		ABCIBreakerThreshold:   defaultABCIBreakerThreshold,
+		ABCIBreakerCooldown:    Duration{defaultABCIBreakerCooldown},
+		ABCIQueryConnections:   defaultABCIQueryConnections,
+		ABCICheckTxConnections: defaultABCICheckTxConnections,
+
+		TxGossipInterval:         Duration{defaultTxGossipInterval},
+		PersistMempoolInterval:   Duration{defaultPersistMempoolInterval},
+		BuildMinTxs:              defaultBuildMinTxs,
+		TargetBlockExecutionTime: Duration{defaultTargetBlockExecutionTime},
+		QueryCacheSize:           0,
+		ABCIInfoCacheTTL:         Duration{defaultABCIInfoCacheTTL},
+		DefaultPerPage:           defaultPerPage,
+		MaxPerPage:               maxPerPage,
+		SlowQueryThreshold:       Duration{defaultSlowQueryThreshold},
+		MaxBatchTxs:              defaultMaxBatchTxs,
+		MaxRequestBodyBytes:      defaultMaxRequestBodyBytes,
+
+		HealthMaxMempoolUsage: defaultHealthMaxMempoolUsage,
+
+		MaxWSConnections:          defaultMaxWSConnections,
+		MaxSubscriptionsPerClient: defaultMaxSubscriptionsPerClient,
+		WSWriteBufferSize:         defaultWSWriteBufferSize,
+		WSPingPeriod:              Duration{defaultWSPingPeriod},
+		WSPongWait:                Duration{defaultWSPongWait},
+	}
+}
+
+// parseConfig decodes [configBytes] on top of [config].
+func parseConfig(config Config, configBytes []byte) (Config, error) {
+	if len(configBytes) != 0 {
+		if err := json.Unmarshal(configBytes, &config); err != nil {
+			return Config{}, fmt.Errorf("failed to unmarshal config %s: %w", string(configBytes), err)
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// Validate returns an error if the configuration is invalid.
+func (c Config) Validate() error {
+	if c.ABCITransport != "socket" && c.ABCITransport != "grpc" {
+		return fmt.Errorf("abciTransport must be socket or grpc, got %q", c.ABCITransport)
+	}
+	if c.ValidatorUpdates != validatorUpdatesApply && c.ValidatorUpdates != validatorUpdatesReject {
+		return fmt.Errorf("validatorUpdates must be apply or reject, got %q", c.ValidatorUpdates)
+	}
+	if c.MirrorValidatorSet && c.ValidatorUpdates != validatorUpdatesReject {
+		return fmt.Errorf("mirrorValidatorSet requires validatorUpdates to be reject")
+	}
+	if c.AcceptQueueSize < 0 {
+		return fmt.Errorf("acceptQueueSize must be non-negative, got %d", c.AcceptQueueSize)
+	}
+	if c.EventSinkQueueSize < 0 {
+		return fmt.Errorf("eventSinkQueueSize must be non-negative, got %d", c.EventSinkQueueSize)
+	}
+	if c.ProxyAppDialTimeout.Duration < 0 {
+		return fmt.Errorf("proxyAppDialTimeout must be non-negative, got %s", c.ProxyAppDialTimeout)
+	}
+	if c.ABCIQueryTimeout.Duration < 0 {
+		return fmt.Errorf("abciQueryTimeout must be non-negative, got %s", c.ABCIQueryTimeout)
+	}
+	if c.ABCICheckTxTimeout.Duration < 0 {
+		return fmt.Errorf("abciCheckTxTimeout must be non-negative, got %s", c.ABCICheckTxTimeout)
+	}
+	if c.ABCIDeliverTxTimeout.Duration < 0 {
+		return fmt.Errorf("abciDeliverTxTimeout must be non-negative, got %s", c.ABCIDeliverTxTimeout)
+	}
+	if c.ABCIBreakerThreshold < 0 {
+		return fmt.Errorf("abciBreakerThreshold must be non-negative, got %d", c.ABCIBreakerThreshold)
+	}
+	if c.ABCIBreakerCooldown.Duration < 0 {
+		return fmt.Errorf("abciBreakerCooldown must be non-negative, got %s", c.ABCIBreakerCooldown)
+	}
+	if c.ABCIQueryConnections < 1 {
+		return fmt.Errorf("abciQueryConnections must be positive, got %d", c.ABCIQueryConnections)
+	}
+	if c.ABCIQueryConnections > 1 && c.ProxyApp == "" {
+		return fmt.Errorf("abciQueryConnections requires proxyApp to be set")
+	}
+	if c.ABCICheckTxConnections < 1 {
+		return fmt.Errorf("abciCheckTxConnections must be positive, got %d", c.ABCICheckTxConnections)
+	}
+	if c.ABCIMaxConcurrentQueries < 0 {
+		return fmt.Errorf("abciMaxConcurrentQueries must be non-negative, got %d", c.ABCIMaxConcurrentQueries)
+	}
+	if c.TxGossipInterval.Duration < 0 {
+		return fmt.Errorf("txGossipInterval must be non-negative, got %s", c.TxGossipInterval)
+	}
+	if c.PersistMempoolInterval.Duration < 0 {
+		return fmt.Errorf("persistMempoolInterval must be non-negative, got %s", c.PersistMempoolInterval)
+	}
+	switch c.MempoolRecheck {
+	case mempoolRecheckAsync, mempoolRecheckSync, mempoolRecheckOff:
+	default:
+		return fmt.Errorf("mempoolRecheck must be async, sync or off, got %q", c.MempoolRecheck)
+	}
+	for _, prefix := range c.MempoolBannedPrefixes {
+		if len(prefix) == 0 {
+			return fmt.Errorf("mempoolBannedPrefixes mustn't contain an empty prefix, which bans every tx")
+		}
+	}
+	if _, ok := reapStrategies[c.ReapStrategy]; !ok {
+		return fmt.Errorf("reapStrategy must be priority, fifo or roundRobin, got %q", c.ReapStrategy)
+	}
+	if c.MempoolRecheckBatchSize < 0 {
+		return fmt.Errorf("mempoolRecheckBatchSize must be non-negative, got %d", c.MempoolRecheckBatchSize)
+	}
+	if c.MempoolSize < 1 {
+		return fmt.Errorf("mempoolSize must be positive, got %d", c.MempoolSize)
+	}
+	if c.MempoolMaxTxsBytes < 1 {
+		return fmt.Errorf("mempoolMaxTxsBytes must be positive, got %d", c.MempoolMaxTxsBytes)
+	}
+	if c.MempoolMaxTxBytes < 1 || int64(c.MempoolMaxTxBytes) > c.MempoolMaxTxsBytes {
+		return fmt.Errorf("mempoolMaxTxBytes must be positive and at most mempoolMaxTxsBytes, got %d", c.MempoolMaxTxBytes)
+	}
+	if c.MempoolCacheSize < 0 {
+		return fmt.Errorf("mempoolCacheSize must be non-negative, got %d", c.MempoolCacheSize)
+	}
+	if c.MempoolCacheTTL.Duration < 0 {
+		return fmt.Errorf("mempoolCacheTTL must be non-negative, got %s", c.MempoolCacheTTL)
+	}
+	if c.MempoolTTLNumBlocks < 0 {
+		return fmt.Errorf("mempoolTTLNumBlocks must be non-negative, got %d", c.MempoolTTLNumBlocks)
+	}
+	if c.MempoolTTLDuration.Duration < 0 {
+		return fmt.Errorf("mempoolTTLDuration must be non-negative, got %s", c.MempoolTTLDuration)
+	}
+	if c.MempoolOriginRate < 0 {
+		return fmt.Errorf("mempoolOriginRate must be non-negative, got %g", c.MempoolOriginRate)
+	}
+	if c.MempoolOriginBurst < 0 {
+		return fmt.Errorf("mempoolOriginBurst must be non-negative, got %d", c.MempoolOriginBurst)
+	}
+	if c.MempoolOriginMaxTxs < 0 {
+		return fmt.Errorf("mempoolOriginMaxTxs must be non-negative, got %d", c.MempoolOriginMaxTxs)
+	}
+	if c.MempoolHighWaterMark < 0 || c.MempoolHighWaterMark > 1 {
+		return fmt.Errorf("mempoolHighWaterMark must be between 0 and 1, got %v", c.MempoolHighWaterMark)
+	}
+	if c.MempoolRetryAfter.Duration <= 0 {
+		return fmt.Errorf("mempoolRetryAfter must be positive, got %s", c.MempoolRetryAfter)
+	}
+	if err := validateMempoolLanes(c.MempoolLanes); err != nil {
+		return err
+	}
+	if err := validateWebhooks(c.Webhooks); err != nil {
+		return err
+	}
+	if c.EventStreamNATS != "" {
+		u, err := url.Parse(c.EventStreamNATS)
+		if err != nil || u.Scheme != "nats" || u.Host == "" {
+			return fmt.Errorf("eventStreamNATS must be a nats URL, got %q", c.EventStreamNATS)
+		}
+	}
+	if c.BuildMinTxs < 1 {
+		return fmt.Errorf("buildMinTxs must be positive, got %d", c.BuildMinTxs)
+	}
+	if c.MinBlockInterval.Duration < 0 {
+		return fmt.Errorf("minBlockInterval must be non-negative, got %s", c.MinBlockInterval)
+	}
+	if c.MaxBuildWait.Duration < 0 {
+		return fmt.Errorf("maxBuildWait must be non-negative, got %s", c.MaxBuildWait)
+	}
+	if c.CreateEmptyBlocksInterval.Duration < 0 {
+		return fmt.Errorf("createEmptyBlocksInterval must be non-negative, got %s", c.CreateEmptyBlocksInterval)
+	}
+	if c.TargetBlockExecutionTime.Duration < 0 {
+		return fmt.Errorf("targetBlockExecutionTime must be non-negative, got %s", c.TargetBlockExecutionTime)
+	}
+	if c.DevBlockInterval.Duration < 0 {
+		return fmt.Errorf("devBlockInterval must be non-negative, got %s", c.DevBlockInterval)
+	}
+	if c.CreateEmptyBlocks && c.CreateEmptyBlocksInterval.Duration == 0 {
+		return fmt.Errorf("createEmptyBlocksInterval must be positive when createEmptyBlocks is set")
+	}
+	if c.QueryCacheSize < 0 {
+		return fmt.Errorf("queryCacheSize must be non-negative, got %d", c.QueryCacheSize)
+	}
+	if c.ABCIInfoCacheTTL.Duration < 0 {
+		return fmt.Errorf("abciInfoCacheTtl must be non-negative, got %s", c.ABCIInfoCacheTTL)
+	}
+	if c.DefaultPerPage < 1 {
+		return fmt.Errorf("defaultPerPage must be positive, got %d", c.DefaultPerPage)
+	}
+	if c.MaxPerPage < c.DefaultPerPage {
+		return fmt.Errorf("maxPerPage (%d) must not be less than defaultPerPage (%d)", c.MaxPerPage, c.DefaultPerPage)
+	}
+	if c.SlowQueryThreshold.Duration < 0 {
+		return fmt.Errorf("slowQueryThreshold must be non-negative, got %s", c.SlowQueryThreshold)
+	}
+	if c.MaxBatchTxs < 1 {
+		return fmt.Errorf("maxBatchTxs must be positive, got %d", c.MaxBatchTxs)
+	}
+	if c.MaxRequestBodyBytes < 1 {
+		return fmt.Errorf("maxRequestBodyBytes must be positive, got %d", c.MaxRequestBodyBytes)
+	}
+	if c.HealthMaxBlockAge.Duration < 0 {
+		return fmt.Errorf("healthMaxBlockAge must be non-negative, got %s", c.HealthMaxBlockAge)
+	}
+	if c.HealthMaxMempoolUsage < 0 || c.HealthMaxMempoolUsage > 1 {
+		return fmt.Errorf("healthMaxMempoolUsage must be between 0 and 1, got %v", c.HealthMaxMempoolUsage)
+	}
+	if c.HealthMaxIndexerLag < 0 {
+		return fmt.Errorf("healthMaxIndexerLag must be non-negative, got %d", c.HealthMaxIndexerLag)
+	}
+	if c.MaxWSConnections < 0 {
+		return fmt.Errorf("maxWSConnections must be non-negative, got %d", c.MaxWSConnections)
+	}
+	if c.MaxSubscriptionsPerClient < 1 {
+		return fmt.Errorf("maxSubscriptionsPerClient must be positive, got %d", c.MaxSubscriptionsPerClient)
+	}
+	if c.WSWriteBufferSize < 1 {
+		return fmt.Errorf("wsWriteBufferSize must be positive, got %d", c.WSWriteBufferSize)
+	}
+	if c.WSPingPeriod.Duration <= 0 {
+		return fmt.Errorf("wsPingPeriod must be positive, got %s", c.WSPingPeriod)
+	}
+	if c.WSPongWait.Duration <= c.WSPingPeriod.Duration {
+		return fmt.Errorf("wsPongWait (%s) must exceed wsPingPeriod (%s)", c.WSPongWait, c.WSPingPeriod)
+	}
+	return nil
+}
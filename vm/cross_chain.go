@@ -0,0 +1,111 @@
+package vm
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	tmjson "github.com/consideritdone/landslidecore/libs/json"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+)
+
+// crossChainResponseDomain starts the messages of the attestations of the
+// cross-chain responses, see headerAttestationDomain.
+var crossChainResponseDomain = []byte("landslide-xchain-response")
+
+var errUnknownCrossChainRequest = errors.New("unknown cross-chain request")
+
+type (
+	// crossChainRequest is the request other chains of the node, e.g. the
+	// C-Chain through a relayer, send to query this chain. Exactly one of its
+	// fields is set, with the arguments of the rpc method of the same name.
+	crossChainRequest struct {
+		ABCIQuery *ABCIQueryWithOptionsArgs `json:"abciQuery,omitempty"`
+		TxStatus  *TxStatusArgs             `json:"txStatus,omitempty"`
+	}
+
+	// crossChainResponse is the response to a crossChainRequest. Result is
+	// the reply of the rpc method, as it encodes it, and Attestation the
+	// node's signature over it, for the requesting chain: its message is the
+	// "landslide-xchain-response" domain, followed by the ID of this chain,
+	// the ID of the requesting chain, the 4-byte big-endian request ID and the
+	// result. Error is set instead if the request failed.
+	crossChainResponse struct {
+		Result      json.RawMessage    `json:"result,omitempty"`
+		Attestation *CommitAttestation `json:"attestation,omitempty"`
+		Error       string             `json:"error,omitempty"`
+	}
+)
+
+// CrossChainAppRequest serves the ABCI queries and tx status checks of other
+// chains of the node, with attested responses, if the crossChainRequestsEnabled
+// option is enabled.
+func (vm *VM) CrossChainAppRequest(
+	ctx context.Context,
+	chainID ids.ID,
+	requestID uint32,
+	deadline time.Time,
+	request []byte,
+) error {
+	if !vm.config.CrossChainRequestsEnabled || time.Now().After(deadline) {
+		return nil
+	}
+	res := vm.serveCrossChainRequest(chainID, requestID, request)
+	resBytes, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return vm.appSender.SendCrossChainAppResponse(ctx, chainID, requestID, resBytes)
+}
+
+func (vm *VM) serveCrossChainRequest(chainID ids.ID, requestID uint32, request []byte) crossChainResponse {
+	var req crossChainRequest
+	if err := json.Unmarshal(request, &req); err != nil {
+		return crossChainResponse{Error: err.Error()}
+	}
+
+	var (
+		service = &LocalService{vm}
+		reply   interface{}
+		err     error
+	)
+	switch {
+	case req.ABCIQuery != nil:
+		queryReply := new(ctypes.ResultABCIQuery)
+		reply, err = queryReply, service.ABCIQueryWithOptions(nil, req.ABCIQuery, queryReply)
+	case req.TxStatus != nil:
+		statusReply := new(TxStatusReply)
+		reply, err = statusReply, service.TxStatus(nil, req.TxStatus, statusReply)
+	default:
+		err = errUnknownCrossChainRequest
+	}
+	if err != nil {
+		return crossChainResponse{Error: err.Error()}
+	}
+
+	result, err := tmjson.Marshal(reply)
+	if err != nil {
+		return crossChainResponse{Error: err.Error()}
+	}
+	requestIDBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(requestIDBytes, requestID)
+	attestation, err := vm.attest(crossChainResponseDomain, vm.ctx.ChainID[:], chainID[:], requestIDBytes, result)
+	if err != nil {
+		return crossChainResponse{Error: err.Error()}
+	}
+	return crossChainResponse{Result: result, Attestation: attestation}
+}
+
+// CrossChainAppResponse drops responses, as the VM sends no cross-chain
+// requests.
+func (vm *VM) CrossChainAppResponse(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
+
+func (vm *VM) CrossChainAppRequestFailed(context.Context, ids.ID, uint32) error {
+	return nil
+}
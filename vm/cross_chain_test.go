@@ -0,0 +1,105 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	tmjson "github.com/consideritdone/landslidecore/libs/json"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+func TestCrossChainAppRequest(t *testing.T) {
+	sk, err := bls.NewSecretKey()
+	require.NoError(t, err)
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	vm, _, _, err := newTestVMWithGenesis(kvstore.NewApplication(), dbManager, []byte(genesis), nil,
+		[]byte(`{"crossChainRequestsEnabled":true}`), WithAttestationKey(sk))
+	require.NoError(t, err)
+	service := NewService(vm)
+	tx := types.Tx("name=satoshi")
+	mustAcceptBlock(t, vm, service, tx)
+
+	requestingChainID := ids.GenerateTestID()
+	var responses [][]byte
+	vm.appSender = &common.SenderTest{
+		T: t,
+		SendCrossChainAppResponseF: func(_ context.Context, chainID ids.ID, _ uint32, response []byte) {
+			require.Equal(t, requestingChainID, chainID)
+			responses = append(responses, response)
+		},
+	}
+	request := func(req string) crossChainResponse {
+		responses = nil
+		deadline := time.Now().Add(time.Minute)
+		require.NoError(t, vm.CrossChainAppRequest(context.Background(), requestingChainID, 1, deadline, []byte(req)))
+		require.Len(t, responses, 1)
+		var res crossChainResponse
+		require.NoError(t, json.Unmarshal(responses[0], &res))
+		return res
+	}
+
+	// "name" in hex
+	res := request(`{"abciQuery":{"path":"/key","data":"6E616D65"}}`)
+	require.Empty(t, res.Error)
+	queryReply := new(ctypes.ResultABCIQuery)
+	require.NoError(t, tmjson.Unmarshal(res.Result, queryReply))
+	assert.Equal(t, []byte("satoshi"), queryReply.Response.Value)
+
+	// the result is signed for the requesting chain
+	require.NotNil(t, res.Attestation)
+	pk, err := bls.PublicKeyFromBytes(res.Attestation.PublicKey)
+	require.NoError(t, err)
+	sig, err := bls.SignatureFromBytes(res.Attestation.Signature)
+	require.NoError(t, err)
+	assert.True(t, bls.Verify(pk, sig, res.Attestation.Message))
+	expected := append(append([]byte("landslide-xchain-response"), blockchainID[:]...), requestingChainID[:]...)
+	expected = append(append(expected, 0, 0, 0, 1), res.Result...)
+	assert.EqualValues(t, expected, res.Attestation.Message)
+	_, err = warp.ParseUnsignedMessage(res.Attestation.Message)
+	assert.Error(t, err)
+
+	hashBytes, err := json.Marshal(tx.Hash())
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		res = request(`{"txStatus":{"hash":` + string(hashBytes) + `}}`)
+		require.Empty(t, res.Error)
+		statusReply := new(TxStatusReply)
+		require.NoError(t, tmjson.Unmarshal(res.Result, statusReply))
+		return statusReply.Status == TxStatusCommitted
+	}, 5*time.Second, 100*time.Millisecond)
+
+	for _, req := range []string{`{}`, `{"abciQuery":`} {
+		res = request(req)
+		assert.NotEmpty(t, res.Error, req)
+		assert.Nil(t, res.Attestation, req)
+	}
+
+	// requests past their deadline are dropped
+	responses = nil
+	require.NoError(t, vm.CrossChainAppRequest(context.Background(), requestingChainID, 2, time.Now().Add(-time.Second), []byte(`{}`)))
+	assert.Empty(t, responses)
+
+	// as are all requests by default
+	appSender := vm.appSender
+	vm, _, _ = mustNewKVTestVm(t)
+	vm.appSender = appSender
+	require.NoError(t, vm.CrossChainAppRequest(context.Background(), requestingChainID, 3, time.Now().Add(time.Minute), []byte(`{}`)))
+	assert.Empty(t, responses)
+
+	// they can't be enabled without an attestation key
+	_, _, _, err = newTestVMWithDB(kvstore.NewApplication(), manager.NewMemDB(&version.Semantic{Major: 1}), []byte(`{"crossChainRequestsEnabled":true}`))
+	assert.ErrorIs(t, err, errNoAttestationKey)
+}
@@ -1,6 +1,8 @@
 package vm
 
 import (
+	"bytes"
+
 	"github.com/ava-labs/avalanchego/database"
 	dbm "github.com/tendermint/tm-db"
 )
@@ -18,6 +20,7 @@ type (
 
 		start []byte
 		end   []byte
+		valid bool
 	}
 	Batch struct {
 		database.Batch
@@ -48,11 +51,19 @@ func (db Database) DeleteSync(key []byte) error {
 }
 
 func (db Database) Iterator(start, end []byte) (dbm.Iterator, error) {
-	return Iterator{db.Database.NewIteratorWithStart(start), start, end}, nil
+	return newIterator(db.Database.NewIteratorWithStart(start), start, end), nil
 }
 
 func (db Database) ReverseIterator(start, end []byte) (dbm.Iterator, error) {
-	return Iterator{db.Database.NewIteratorWithStart(start), start, end}, nil
+	return newIterator(db.Database.NewIteratorWithStart(start), start, end), nil
+}
+
+// newIterator returns an iterator positioned at the first key of the domain,
+// as the iterators of the database start before it.
+func newIterator(it database.Iterator, start, end []byte) *Iterator {
+	iter := &Iterator{Iterator: it, start: start, end: end}
+	iter.Next()
+	return iter
 }
 
 func (db Database) NewBatch() dbm.Batch {
@@ -69,31 +80,32 @@ func (db Database) Stats() map[string]string {
 	return nil
 }
 
-func (iter Iterator) Domain() (start []byte, end []byte) {
+func (iter *Iterator) Domain() (start []byte, end []byte) {
 	return iter.start, iter.end
 }
 
-func (iter Iterator) Valid() bool {
-	return iter.Iterator.Error() == nil && len(iter.Iterator.Key()) > 0
+func (iter *Iterator) Valid() bool {
+	return iter.valid
 }
 
-func (iter Iterator) Next() {
-	iter.Iterator.Next()
+func (iter *Iterator) Next() {
+	iter.valid = iter.Iterator.Next() &&
+		(iter.end == nil || bytes.Compare(iter.Iterator.Key(), iter.end) < 0)
 }
 
-func (iter Iterator) Key() (key []byte) {
+func (iter *Iterator) Key() (key []byte) {
 	return iter.Iterator.Key()
 }
 
-func (iter Iterator) Value() (value []byte) {
+func (iter *Iterator) Value() (value []byte) {
 	return iter.Iterator.Value()
 }
 
-func (iter Iterator) Error() error {
+func (iter *Iterator) Error() error {
 	return iter.Iterator.Error()
 }
 
-func (iter Iterator) Close() error {
+func (iter *Iterator) Close() error {
 	iter.Iterator.Release()
 	return iter.Error()
 }
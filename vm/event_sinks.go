@@ -0,0 +1,335 @@
+package vm
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	tmstate "github.com/consideritdone/landslidecore/proto/tendermint/state"
+	"github.com/consideritdone/landslidecore/state/indexer"
+	"github.com/consideritdone/landslidecore/state/indexer/sink/psql"
+	"github.com/consideritdone/landslidecore/state/txindex"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// EventSink receives the events of the accepted blocks and of their txs once
+// they are indexed by the VM, e.g. to index them in an external database.
+type EventSink interface {
+	IndexBlockEvents(types.EventDataNewBlockHeader) error
+	IndexTxEvents([]*abci.TxResult) error
+}
+
+// kvEventSink is the EventSink of the blocks and txs indexed in the database
+// of the VM, which the RPC searches. Unlike the other sinks, it is written
+// along with the height of the last indexed block, so it misses no block.
+type kvEventSink struct {
+	txIndexer    txindex.TxIndexer
+	blockIndexer indexer.BlockIndexer
+}
+
+func (s kvEventSink) IndexBlockEvents(header types.EventDataNewBlockHeader) error {
+	return s.blockIndexer.Index(header)
+}
+
+func (s kvEventSink) IndexTxEvents(txResults []*abci.TxResult) error {
+	batch := txindex.NewBatch(int64(len(txResults)))
+	for _, txResult := range txResults {
+		if err := batch.Add(txResult); err != nil {
+			return fmt.Errorf("failed to add tx %X to the batch: %w", types.Tx(txResult.Tx).Hash(), err)
+		}
+	}
+	return s.txIndexer.AddBatch(batch)
+}
+
+// eventSinkHeightPrefix prefixes the height of the last block passed to each
+// event sink, from which it catches up on the blocks its queue had no room
+// for, including after a restart.
+var eventSinkHeightPrefix = []byte("eventSinkHeight/")
+
+// sinkBlock is the events of an accepted block queued for a sink.
+type sinkBlock struct {
+	header    types.EventDataNewBlockHeader
+	txResults []*abci.TxResult
+}
+
+// eventSinkWorker passes the events of the accepted blocks to a sink in the
+// background, so that a slow or failing sink holds up neither the acceptance
+// of the blocks nor the other sinks. The blocks are left out of the queue
+// while it is full, and loaded from the stores once the sink caught up, so
+// that it misses none: the height of the last block passed to the sink is
+// recorded, and the blocks after it aren't pruned.
+type eventSinkWorker struct {
+	vm   *VM
+	sink EventSink
+	name string
+	// close releases the sink once the worker stopped, nil for the sinks
+	// which don't belong to the VM.
+	close func() error
+	// heightKey holds the height of the last block passed to the sink.
+	heightKey []byte
+	// next is the height of the next block passed to the sink. It is only
+	// accessed by the worker, or by the acceptor if there is no queue.
+	next int64
+	// blocks is nil if the events are passed within the acceptor.
+	blocks chan sinkBlock
+	// behind is signaled when a block is left out of the queue.
+	behind chan struct{}
+	done   chan struct{}
+}
+
+// newEventSinkWorker returns the worker of [sink], which is identified by [id]
+// across restarts.
+func newEventSinkWorker(vm *VM, sink EventSink, id string, queueSize int) (*eventSinkWorker, error) {
+	w := &eventSinkWorker{
+		vm:        vm,
+		sink:      sink,
+		name:      fmt.Sprintf("%T", sink),
+		heightKey: append(append([]byte{}, eventSinkHeightPrefix...), id...),
+		behind:    make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+	passed, ok, err := w.passedHeight()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		w.next = passed + 1
+		// the blocks accepted since the sink was last passed one, e.g.
+		// before a crash, are passed first
+		w.behind <- struct{}{}
+	} else {
+		// a new sink is passed the blocks from the next indexed one
+		lastIndexed, ok, err := vm.lastIndexedHeight()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			lastIndexed = vm.tmState.LastBlockHeight
+		}
+		err = vm.atomically(func() error {
+			return w.setPassedHeight(lastIndexed)
+		})
+		if err != nil {
+			return nil, err
+		}
+		w.next = lastIndexed + 1
+	}
+	if queueSize == 0 {
+		close(w.done)
+		return w, nil
+	}
+	w.blocks = make(chan sinkBlock, queueSize)
+	go w.run()
+	return w, nil
+}
+
+func (w *eventSinkWorker) run() {
+	defer close(w.done)
+
+	for {
+		select {
+		case b, ok := <-w.blocks:
+			if !ok {
+				return
+			}
+			w.pass(b)
+		case <-w.behind:
+			lastIndexed, _, err := w.vm.lastIndexedHeight()
+			if err != nil {
+				w.vm.tmLogger.Error("failed to read the last indexed height", "sink", w.name, "err", err)
+				continue
+			}
+			w.catchUp(lastIndexed)
+		}
+	}
+}
+
+// pass passes [b] to the sink, after the blocks before it it missed, unless it
+// was already passed.
+func (w *eventSinkWorker) pass(b sinkBlock) {
+	height := b.header.Header.Height
+	if height < w.next {
+		return
+	}
+	w.catchUp(height - 1)
+	w.index(b)
+	w.passed(height)
+}
+
+// catchUp passes the blocks up to [height] the sink missed, loaded from the
+// stores.
+func (w *eventSinkWorker) catchUp(height int64) {
+	for ; w.next <= height; w.passed(w.next) {
+		block := w.vm.blockStore.LoadBlock(w.next)
+		if block == nil {
+			w.vm.tmLogger.Error("missed block not found for event sink", "sink", w.name, "height", w.next)
+			w.vm.metrics.eventSinkFailed(w.name)
+			continue
+		}
+		abciResponses, err := w.vm.stateStore.LoadABCIResponses(w.next)
+		if err != nil {
+			w.vm.tmLogger.Error("failed to load missed block results for event sink", "sink", w.name, "height", w.next, "err", err)
+			w.vm.metrics.eventSinkFailed(w.name)
+			continue
+		}
+		header, txResults := blockEvents(block, abciResponses)
+		w.index(sinkBlock{header: header, txResults: txResults})
+	}
+}
+
+// index passes the events of [b] to the sink. The failure of a sink is logged,
+// as it doesn't affect the chain.
+func (w *eventSinkWorker) index(b sinkBlock) {
+	height := b.header.Header.Height
+	if err := w.sink.IndexBlockEvents(b.header); err != nil {
+		w.vm.tmLogger.Error("failed to sink block events", "sink", w.name, "height", height, "err", err)
+		w.vm.metrics.eventSinkFailed(w.name)
+	}
+	if err := w.sink.IndexTxEvents(b.txResults); err != nil {
+		w.vm.tmLogger.Error("failed to sink tx events", "sink", w.name, "height", height, "err", err)
+		w.vm.metrics.eventSinkFailed(w.name)
+	}
+}
+
+// passed records [height] as the height of the last block passed to the sink.
+func (w *eventSinkWorker) passed(height int64) {
+	w.next = height + 1
+	err := w.vm.atomically(func() error {
+		return w.setPassedHeight(height)
+	})
+	if err != nil {
+		w.vm.tmLogger.Error("failed to record the height passed to event sink", "sink", w.name, "height", height, "err", err)
+	}
+}
+
+func (w *eventSinkWorker) setPassedHeight(height int64) error {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, uint64(height))
+	return w.vm.stateDB.Set(w.heightKey, value)
+}
+
+// passedHeight returns the height of the last block passed to the sink, if it
+// is recorded.
+func (w *eventSinkWorker) passedHeight() (int64, bool, error) {
+	value, err := w.vm.stateDB.Get(w.heightKey)
+	if err != nil || len(value) == 0 {
+		return 0, false, err
+	}
+	return int64(binary.BigEndian.Uint64(value)), true, nil
+}
+
+// send queues [b], or leaves it out for the sink to catch up on if the queue
+// is full, or passes it right away if there is no queue.
+func (w *eventSinkWorker) send(b sinkBlock) {
+	if w.blocks == nil {
+		w.pass(b)
+		return
+	}
+	select {
+	case w.blocks <- b:
+	default:
+		w.vm.tmLogger.Info("event sink falling behind, deferred block", "sink", w.name, "height", b.header.Header.Height)
+		w.vm.metrics.eventSinkDeferred(w.name)
+		select {
+		case w.behind <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// stop passes the queued blocks to the sink and stops the worker. The blocks
+// left out of the queue are passed after the next start.
+func (w *eventSinkWorker) stop() error {
+	if w.blocks != nil {
+		close(w.blocks)
+	}
+	<-w.done
+	if w.close != nil {
+		return w.close()
+	}
+	return nil
+}
+
+// startEventSinks starts a worker for each sink set by WithEventSinks, for the
+// PostgreSQL database of the eventSinkPsqlConn config and for each of its
+// webhooks, and sets up the event stream to the NATS server of its
+// eventStreamNATS, unless WithEventStream set one.
+func (vm *VM) startEventSinks() error {
+	if addr := vm.config.EventStreamNATS; addr != "" && vm.eventStream == nil {
+		u, err := url.Parse(addr)
+		if err != nil {
+			return err
+		}
+		vm.eventStream = newEventStream(newNATSPublisher(u.Host), vm.config.EventStreamTopicPrefix)
+	}
+
+	queueSize := vm.config.EventSinkQueueSize
+	for i, sink := range vm.eventSinks {
+		w, err := newEventSinkWorker(vm, sink, fmt.Sprintf("option/%d", i), queueSize)
+		if err != nil {
+			return err
+		}
+		vm.sinkWorkers = append(vm.sinkWorkers, w)
+	}
+	if conn := vm.config.EventSinkPsqlConn; conn != "" {
+		sink, err := psql.NewEventSink(conn, vm.genesis.ChainID)
+		if err != nil {
+			return fmt.Errorf("failed to open psql event sink: %w", err)
+		}
+		w, err := newEventSinkWorker(vm, sink, "psql", queueSize)
+		if err != nil {
+			return err
+		}
+		w.close = sink.Stop
+		vm.sinkWorkers = append(vm.sinkWorkers, w)
+	}
+	for _, hook := range vm.config.Webhooks {
+		sink, err := newWebhookSink(hook, vm.webhooksAborted)
+		if err != nil {
+			return err
+		}
+		w, err := newEventSinkWorker(vm, sink, "webhook/"+hook.URL+" "+hook.Query, queueSize)
+		if err != nil {
+			return err
+		}
+		vm.sinkWorkers = append(vm.sinkWorkers, w)
+	}
+	return nil
+}
+
+// stopEventSinks passes the queued blocks to the sinks and stops their
+// workers. The webhooks keep retrying the failed POSTs of the queued events
+// until [ctx] is done.
+func (vm *VM) stopEventSinks(ctx context.Context) error {
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		select {
+		case <-ctx.Done():
+			close(vm.webhooksAborted)
+		case <-stopped:
+		}
+	}()
+
+	var errs []error
+	for _, w := range vm.sinkWorkers {
+		if err := w.stop(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop event sink %s: %w", w.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sinkEvents passes the events of [block] to the event sinks.
+func (vm *VM) sinkEvents(block *types.Block, abciResponses *tmstate.ABCIResponses) {
+	if len(vm.sinkWorkers) == 0 {
+		return
+	}
+	header, txResults := blockEvents(block, abciResponses)
+	for _, w := range vm.sinkWorkers {
+		w.send(sinkBlock{header: header, txResults: txResults})
+	}
+}
@@ -0,0 +1,149 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// failingSink is an EventSink failing to index the events.
+type failingSink struct{}
+
+func (failingSink) IndexBlockEvents(types.EventDataNewBlockHeader) error {
+	return errors.New("sink unavailable")
+}
+
+func (failingSink) IndexTxEvents([]*atypes.TxResult) error {
+	return errors.New("sink unavailable")
+}
+
+// stuckSink is an EventSink which doesn't return until released, and records
+// the heights of the blocks it is passed.
+type stuckSink struct {
+	release chan struct{}
+
+	mtx     sync.Mutex
+	heights []int64
+}
+
+func (s *stuckSink) IndexBlockEvents(header types.EventDataNewBlockHeader) error {
+	<-s.release
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.heights = append(s.heights, header.Header.Height)
+	return nil
+}
+
+func (s *stuckSink) IndexTxEvents([]*atypes.TxResult) error {
+	return nil
+}
+
+func (s *stuckSink) passed() []int64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]int64(nil), s.heights...)
+}
+
+func TestEventSinkIsolation(t *testing.T) {
+	recording := &recordingSink{}
+	stuck := &stuckSink{release: make(chan struct{})}
+	vm, _, _, err := newTestVMWithGenesis(
+		kvstore.NewApplication(),
+		manager.NewMemDB(version.Semantic1_0_0),
+		[]byte(genesis),
+		nil,
+		[]byte(`{"eventSinkQueueSize":2}`),
+		WithEventSinks(failingSink{}, stuck, recording),
+	)
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	// the blocks are accepted and indexed while a sink fails and another
+	// one is stuck
+	for i, tx := range []string{"a=1", "b=2", "c=3", "d=4"} {
+		mustAcceptBlock(t, vm, service, []byte(tx))
+		require.Eventually(t, func() bool {
+			headers, _ := recording.recorded()
+			return len(headers) == i+1
+		}, time.Second, 10*time.Millisecond)
+	}
+	lastIndexed, _, err := vm.lastIndexedHeight()
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), lastIndexed)
+
+	// the failures of a sink are counted
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(vm.metrics.eventSinkErrors.WithLabelValues("vm.failingSink")) == 8
+	}, time.Second, 10*time.Millisecond)
+	// the blocks beyond the queue of the stuck sink are deferred for it
+	assert.NotZero(t, testutil.ToFloat64(vm.metrics.eventSinkDeferrals.WithLabelValues("*vm.stuckSink")))
+
+	// and not pruned until it caught up
+	pruned, err := vm.pruneBlocks(4)
+	require.NoError(t, err)
+	assert.Zero(t, pruned)
+
+	// then passed to it in order, none missing
+	close(stuck.release)
+	require.Eventually(t, func() bool {
+		return len(stuck.passed()) == 4
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int64{1, 2, 3, 4}, stuck.passed())
+	require.NoError(t, vm.Shutdown(context.Background()))
+}
+
+func TestEventSinkCatchUpAfterRestart(t *testing.T) {
+	app := kvstore.NewApplication()
+	db := manager.NewMemDB(version.Semantic1_0_0)
+	stuck := &stuckSink{release: make(chan struct{})}
+	vm, _, _, err := newTestVMWithGenesis(
+		app,
+		db,
+		[]byte(genesis),
+		nil,
+		[]byte(`{"eventSinkQueueSize":1}`),
+		WithEventSinks(stuck),
+	)
+	require.NoError(t, err)
+	service := NewService(vm)
+	for _, tx := range []string{"a=1", "b=2", "c=3", "d=4"} {
+		mustAcceptBlock(t, vm, service, []byte(tx))
+	}
+	// the sink is passed at least the first block and the queued one, the
+	// others may be left for after the restart
+	close(stuck.release)
+	require.NoError(t, vm.Shutdown(context.Background()))
+	passed := stuck.passed()
+	require.NotEmpty(t, passed)
+
+	// the blocks it missed are passed after the restart, and not pruned
+	// meanwhile
+	restarted := &stuckSink{release: make(chan struct{})}
+	close(restarted.release)
+	vm, _, _, err = newTestVMWithGenesis(
+		app,
+		db,
+		[]byte(genesis),
+		nil,
+		[]byte(`{"eventSinkQueueSize":1}`),
+		WithEventSinks(restarted),
+	)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(passed)+len(restarted.passed()) >= 4
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int64{1, 2, 3, 4}, append(passed, restarted.passed()...))
+	require.NoError(t, vm.Shutdown(context.Background()))
+}
@@ -0,0 +1,221 @@
+package vm
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	tmbytes "github.com/consideritdone/landslidecore/libs/bytes"
+	tmjson "github.com/consideritdone/landslidecore/libs/json"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+const (
+	// StreamBlocksTopic and StreamTxsTopic are the topics, after the prefix
+	// given to WithEventStream, the block headers with the BeginBlock and
+	// EndBlock events and the tx results are published on.
+	StreamBlocksTopic = "blocks"
+	StreamTxsTopic    = "txs"
+
+	// eventStreamMinBackoff and eventStreamMaxBackoff bound the wait before
+	// publishing a block again after a failure.
+	eventStreamMinBackoff = 100 * time.Millisecond
+	eventStreamMaxBackoff = 30 * time.Second
+)
+
+// streamedHeightKey holds the height of the last block whose events were all
+// published, from which the stream resumes on restart.
+var streamedHeightKey = []byte("streamedHeight")
+
+// StreamPublisher publishes messages on the topics of a broker, such as Kafka
+// or NATS JetStream, for WithEventStream. Publish must return once the broker
+// acknowledged the message, e.g. from a synchronous Kafka producer or the ack
+// of a JetStream publish, so that the message is not lost.
+type StreamPublisher interface {
+	// Publish publishes [value] on [topic], keyed with [key], which is the
+	// big-endian height for the blocks and the hash for the txs.
+	Publish(ctx context.Context, topic string, key, value []byte) error
+}
+
+// StreamTx is the message published for each tx on the StreamTxsTopic.
+type StreamTx struct {
+	Hash   tmbytes.HexBytes       `json:"hash"`
+	Height int64                  `json:"height"`
+	Index  uint32                 `json:"index"`
+	Tx     types.Tx               `json:"tx"`
+	Result abci.ResponseDeliverTx `json:"result"`
+}
+
+// eventStream publishes the events of the accepted blocks, in order, with an
+// at-least-once delivery: the height of the last block published is recorded
+// once the broker acknowledged all its messages, and the blocks after it are
+// published again after a failure or a restart. The blocks not published yet
+// aren't pruned.
+type eventStream struct {
+	publisher   StreamPublisher
+	topicPrefix string
+	// indexed is signaled when a block is indexed.
+	indexed chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func newEventStream(publisher StreamPublisher, topicPrefix string) *eventStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &eventStream{
+		publisher:   publisher,
+		topicPrefix: topicPrefix,
+		indexed:     make(chan struct{}, 1),
+		ctx:         ctx,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+}
+
+// notify wakes the stream up to publish the blocks indexed since.
+func (s *eventStream) notify() {
+	select {
+	case s.indexed <- struct{}{}:
+	default:
+	}
+}
+
+// stop stops the stream, interrupting the block being published.
+func (s *eventStream) stop() {
+	s.cancel()
+	<-s.done
+}
+
+func (s *eventStream) topic(name string) string {
+	if s.topicPrefix == "" {
+		return name
+	}
+	return s.topicPrefix + "." + name
+}
+
+// streamEvents publishes the indexed blocks which weren't yet, until the stream
+// is stopped.
+func (vm *VM) streamEvents() {
+	s := vm.eventStream
+	defer close(s.done)
+
+	backoff := eventStreamMinBackoff
+	for {
+		err := vm.streamIndexed()
+		if err == nil {
+			backoff = eventStreamMinBackoff
+			select {
+			case <-s.indexed:
+				continue
+			case <-s.ctx.Done():
+				return
+			}
+		}
+		if s.ctx.Err() != nil {
+			return
+		}
+		vm.tmLogger.Error("failed to stream events, retrying", "retry_after", backoff, "err", err)
+		select {
+		case <-time.After(backoff):
+		case <-s.ctx.Done():
+			return
+		}
+		backoff *= 2
+		if backoff > eventStreamMaxBackoff {
+			backoff = eventStreamMaxBackoff
+		}
+	}
+}
+
+// streamIndexed publishes the blocks indexed after the last one published.
+func (vm *VM) streamIndexed() error {
+	streamed, err := vm.streamedHeight()
+	if err != nil {
+		return err
+	}
+	lastIndexed, _, err := vm.lastIndexedHeight()
+	if err != nil {
+		return err
+	}
+	for height := streamed + 1; height <= lastIndexed; height++ {
+		if err := vm.streamBlock(height); err != nil {
+			return fmt.Errorf("failed to publish block %d: %w", height, err)
+		}
+		err := vm.atomically(func() error {
+			return vm.setStreamedHeight(height)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamBlock publishes the header of the block at [height], then its txs.
+func (vm *VM) streamBlock(height int64) error {
+	s := vm.eventStream
+	block := vm.blockStore.LoadBlock(height)
+	if block == nil {
+		return errors.New("block not found")
+	}
+	abciResponses, err := vm.stateStore.LoadABCIResponses(height)
+	if err != nil {
+		return err
+	}
+	header, txResults := blockEvents(block, abciResponses)
+
+	value, err := tmjson.Marshal(header)
+	if err != nil {
+		return err
+	}
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(height))
+	if err := s.publisher.Publish(s.ctx, s.topic(StreamBlocksTopic), key, value); err != nil {
+		return err
+	}
+	for _, txResult := range txResults {
+		hash := types.Tx(txResult.Tx).Hash()
+		value, err := tmjson.Marshal(StreamTx{
+			Hash:   hash,
+			Height: txResult.Height,
+			Index:  txResult.Index,
+			Tx:     txResult.Tx,
+			Result: txResult.Result,
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.publisher.Publish(s.ctx, s.topic(StreamTxsTopic), hash, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setStreamedHeight records [height] as the height of the last block
+// published.
+func (vm *VM) setStreamedHeight(height int64) error {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, uint64(height))
+	return vm.stateDB.Set(streamedHeightKey, value)
+}
+
+// streamedHeight returns the height of the last block published, or, for a
+// new stream, the height below the oldest block kept, from which it starts.
+func (vm *VM) streamedHeight() (int64, error) {
+	value, err := vm.stateDB.Get(streamedHeightKey)
+	if err != nil {
+		return 0, err
+	}
+	if len(value) > 0 {
+		return int64(binary.BigEndian.Uint64(value)), nil
+	}
+	if base := vm.blockStore.Base(); base > 0 {
+		return base - 1, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,98 @@
+package vm
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	tmjson "github.com/consideritdone/landslidecore/libs/json"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+type streamMessage struct {
+	topic      string
+	key, value []byte
+}
+
+// recordingPublisher is a StreamPublisher recording the messages it
+// publishes, which fails the first [fail] times.
+type recordingPublisher struct {
+	mtx      sync.Mutex
+	fail     int
+	messages []streamMessage
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, topic string, key, value []byte) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.fail > 0 {
+		p.fail--
+		return errors.New("broker unavailable")
+	}
+	p.messages = append(p.messages, streamMessage{topic: topic, key: key, value: value})
+	return nil
+}
+
+func (p *recordingPublisher) published() []streamMessage {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return append([]streamMessage(nil), p.messages...)
+}
+
+func TestEventStream(t *testing.T) {
+	app := kvstore.NewApplication()
+	dbManager := manager.NewMemDB(version.Semantic1_0_0)
+	publisher := &recordingPublisher{fail: 1}
+	vm, _, _, err := newTestVMWithGenesis(app, dbManager, []byte(genesis), nil, nil,
+		WithEventStream(publisher, "chain"))
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	// the block is published again after the failure
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	require.Eventually(t, func() bool {
+		return len(publisher.published()) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+	messages := publisher.published()
+	assert.Equal(t, "chain.blocks", messages[0].topic)
+	assert.Equal(t, uint64(1), binary.BigEndian.Uint64(messages[0].key))
+	var header types.EventDataNewBlockHeader
+	require.NoError(t, tmjson.Unmarshal(messages[0].value, &header))
+	assert.Equal(t, int64(1), header.Header.Height)
+
+	assert.Equal(t, "chain.txs", messages[1].topic)
+	assert.Equal(t, []byte(types.Tx("a=1").Hash()), messages[1].key)
+	var tx StreamTx
+	require.NoError(t, tmjson.Unmarshal(messages[1].value, &tx))
+	assert.Equal(t, types.Tx("a=1"), tx.Tx)
+
+	// the blocks after the last one recorded as published are published
+	// again on restart
+	vm.eventStream.stop()
+	require.NoError(t, vm.atomically(func() error {
+		return vm.setStreamedHeight(0)
+	}))
+	publisher = &recordingPublisher{}
+	vm, _, _, err = newTestVMWithGenesis(app, dbManager, []byte(genesis), nil, nil,
+		WithEventStream(publisher, ""))
+	require.NoError(t, err)
+	mustAcceptBlock(t, vm, NewService(vm), []byte("b=2"))
+	require.Eventually(t, func() bool {
+		return len(publisher.published()) == 4
+	}, 2*time.Second, 10*time.Millisecond)
+	messages = publisher.published()
+	assert.Equal(t, "blocks", messages[2].topic)
+	assert.Equal(t, uint64(2), binary.BigEndian.Uint64(messages[2].key))
+	streamed, err := vm.streamedHeight()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), streamed)
+}
@@ -0,0 +1,27 @@
+package vm
+
+import (
+	"time"
+
+	tmstate "github.com/consideritdone/landslidecore/proto/tendermint/state"
+)
+
+// executeBlock signals the upgrades [b] activates and its P-Chain height to the
+// app, and executes it, without committing it.
+func (vm *VM) executeBlock(b *Block, initialHeight int64) (*tmstate.ABCIResponses, error) {
+	if err := vm.applyUpgrades(&b.tmBlock.Header); err != nil {
+		return nil, err
+	}
+	if err := vm.signalPChainHeight(b); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	abciResponses, err := execBlockOnProxyApp(
+		vm.tmLogger,
+		vm.proxyApp.Consensus(),
+		b.tmBlock, vm.stateStore,
+		initialHeight,
+	)
+	b.executionTime = time.Since(start)
+	return abciResponses, err
+}
@@ -0,0 +1,84 @@
+package vm
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// beginBlockApp counts the blocks it executes.
+type beginBlockApp struct {
+	*kvstore.Application
+	executed atomic.Int32
+}
+
+func (app *beginBlockApp) BeginBlock(req atypes.RequestBeginBlock) atypes.ResponseBeginBlock {
+	app.executed.Add(1)
+	return app.Application.BeginBlock(req)
+}
+
+func TestExecuteOnAccept(t *testing.T) {
+	ctx := context.Background()
+	newVM := func() (*VM, *beginBlockApp) {
+		app := &beginBlockApp{Application: kvstore.NewApplication()}
+		vm, _, _, err := newTestVMWithDB(app, manager.NewMemDB(&version.Semantic{Major: 1}), nil)
+		require.NoError(t, err)
+		service := NewService(vm)
+		reply := new(ctypes.ResultBroadcastTx)
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, reply))
+		return vm, app
+	}
+	sibling := func(vm *VM, tx []byte) *Block {
+		height := vm.tmState.LastBlockHeight + 1
+		tmBlock, _ := vm.tmState.MakeBlock(height, types.Txs{tx}, makeCommitMock(height), nil, proposerAddress)
+		tmBlock.Time = vm.nextBlockTime(vm.tmState)
+		blk, err := vm.newBlock(tmBlock)
+		require.NoError(t, err)
+		return blk
+	}
+	query := func(app *beginBlockApp, key string) []byte {
+		return app.Query(atypes.RequestQuery{Data: []byte(key)}).Value
+	}
+
+	// blocks are executed once accepted, not when verified
+	vm, app := newVM()
+	blk, err := vm.BuildBlock(ctx)
+	require.NoError(t, err)
+	require.NoError(t, blk.Verify(ctx))
+	assert.Zero(t, app.executed.Load())
+	require.NoError(t, blk.Accept(ctx))
+	assert.EqualValues(t, 1, app.executed.Load())
+
+	// so a rejected sibling is never executed
+	sibling1, sibling2 := sibling(vm, []byte("b=2")), sibling(vm, []byte("c=3"))
+	require.NoError(t, sibling1.Verify(ctx))
+	require.NoError(t, sibling2.Verify(ctx))
+	require.NoError(t, sibling2.Reject(ctx))
+	require.NoError(t, sibling1.Accept(ctx))
+	assert.EqualValues(t, 2, app.executed.Load())
+	assert.EqualValues(t, 2, vm.tmState.LastBlockHeight)
+	assert.Equal(t, []byte("2"), query(app, "b"))
+	assert.Empty(t, query(app, "c"))
+
+	// and the app state is the one of the accepted blocks alone
+	alone, aloneApp := newVM()
+	blk, err = alone.BuildBlock(ctx)
+	require.NoError(t, err)
+	require.NoError(t, blk.Verify(ctx))
+	require.NoError(t, blk.Accept(ctx))
+	accepted := sibling(alone, []byte("b=2"))
+	require.NoError(t, accepted.Verify(ctx))
+	require.NoError(t, accepted.Accept(ctx))
+	assert.EqualValues(t, 2, aloneApp.executed.Load())
+	assert.Equal(t, alone.tmState.AppHash, vm.tmState.AppHash)
+}
@@ -0,0 +1,91 @@
+package vm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	tmbytes "github.com/consideritdone/landslidecore/libs/bytes"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// defaultExportAppStatePath is the path of the ABCI query which returns the
+// JSON app state, unless the export request sets another one.
+const defaultExportAppStatePath = "/export"
+
+var errNoAppState = errors.New("the app returned no state to export")
+
+// exportGenesis returns a genesis which restarts the chain after [height],
+// with the app state the app returns for the [appStatePath] query at that
+// height. The genesis has the [chainID] chain_id if set, the one of the chain
+// otherwise, to fork it.
+func (vm *VM) exportGenesis(height int64, chainID, appStatePath string) (*types.GenesisDoc, error) {
+	blockMeta := vm.blockStore.LoadBlockMeta(height)
+	if blockMeta == nil {
+		return nil, fmt.Errorf("block %d not found", height)
+	}
+
+	// the app hash and the params and validators of the next block are those
+	// the chain would continue with
+	appHash := vm.tmState.AppHash
+	if height < vm.tmState.LastBlockHeight {
+		next := vm.blockStore.LoadBlockMeta(height + 1)
+		if next == nil {
+			return nil, fmt.Errorf("block %d not found", height+1)
+		}
+		appHash = next.Header.AppHash
+	}
+	params, err := vm.stateStore.LoadConsensusParams(height + 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load consensus params at height %d: %w", height+1, err)
+	}
+	validators, err := vm.stateStore.LoadValidators(height + 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load validators at height %d: %w", height+1, err)
+	}
+
+	res, err := vm.proxyApp.Query().QuerySync(abci.RequestQuery{
+		Path:   appStatePath,
+		Height: height,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if isAppPruned(res) {
+		return nil, ErrHeightPruned{Height: height}
+	}
+	if !res.IsOK() {
+		return nil, fmt.Errorf("failed to query the app state at %s: %s", appStatePath, res.Log)
+	}
+	if len(res.Value) == 0 {
+		return nil, errNoAppState
+	}
+	if !json.Valid(res.Value) {
+		return nil, fmt.Errorf("the app state returned at %s isn't valid JSON", appStatePath)
+	}
+
+	if chainID == "" {
+		chainID = vm.genesis.ChainID
+	}
+	genesis := &types.GenesisDoc{
+		GenesisTime:     blockMeta.Header.Time,
+		ChainID:         chainID,
+		InitialHeight:   height + 1,
+		ConsensusParams: &params,
+		Validators:      make([]types.GenesisValidator, len(validators.Validators)),
+		AppHash:         tmbytes.HexBytes(appHash),
+		AppState:        res.Value,
+	}
+	for i, v := range validators.Validators {
+		genesis.Validators[i] = types.GenesisValidator{
+			Address: v.Address,
+			PubKey:  v.PubKey,
+			Power:   v.VotingPower,
+		}
+	}
+	if err := genesis.ValidateAndComplete(); err != nil {
+		return nil, fmt.Errorf("exported genesis is invalid: %w", err)
+	}
+	return genesis, nil
+}
@@ -0,0 +1,227 @@
+package vm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	tmbytes "github.com/consideritdone/landslidecore/libs/bytes"
+	tmproto "github.com/consideritdone/landslidecore/proto/tendermint/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// sdkTimeIotaMs is the time_iota_ms of the consensus params translated from
+// a Cosmos SDK genesis, which no longer has it.
+const sdkTimeIotaMs = 1000
+
+var errInvalidGenesis = errors.New("invalid genesis")
+
+// sdkGenesis is the genesis exported by the Cosmos SDK from v0.47, which
+// nests the consensus params and validators under "consensus" and encodes the
+// initial height as a number.
+type sdkGenesis struct {
+	GenesisTime   json.RawMessage `json:"genesis_time"`
+	ChainID       string          `json:"chain_id"`
+	InitialHeight json.Number     `json:"initial_height"`
+	// AppHash is base64 encoded, rather than hex encoded.
+	AppHash   []byte          `json:"app_hash"`
+	AppState  json.RawMessage `json:"app_state"`
+	Consensus struct {
+		Validators json.RawMessage `json:"validators"`
+		Params     *struct {
+			Block struct {
+				MaxBytes json.Number `json:"max_bytes"`
+				MaxGas   json.Number `json:"max_gas"`
+			} `json:"block"`
+			Evidence  json.RawMessage `json:"evidence"`
+			Validator json.RawMessage `json:"validator"`
+			Version   struct {
+				App json.Number `json:"app"`
+			} `json:"version"`
+		} `json:"params"`
+	} `json:"consensus"`
+}
+
+// fromSDKGenesis translates [genesisData] into a Tendermint genesis if it is
+// a genesis exported by the Cosmos SDK from v0.47, and returns it unchanged
+// otherwise, as the genesis of earlier versions is a Tendermint genesis. The
+// app_name and app_version of the SDK genesis aren't part of the chain.
+func fromSDKGenesis(genesisData []byte) ([]byte, error) {
+	var format struct {
+		Consensus json.RawMessage `json:"consensus"`
+	}
+	if err := json.Unmarshal(genesisData, &format); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidGenesis, err)
+	}
+	if len(format.Consensus) == 0 || string(format.Consensus) == "null" {
+		return genesisData, nil
+	}
+	var sdk sdkGenesis
+	if err := json.Unmarshal(genesisData, &sdk); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidGenesis, err)
+	}
+
+	// tmjson encodes int64s as strings
+	genesis := map[string]interface{}{
+		"genesis_time": sdk.GenesisTime,
+		"chain_id":     sdk.ChainID,
+	}
+	if sdk.InitialHeight != "" {
+		genesis["initial_height"] = sdk.InitialHeight.String()
+	}
+	if len(sdk.AppHash) > 0 {
+		genesis["app_hash"] = tmbytes.HexBytes(sdk.AppHash)
+	}
+	if len(sdk.AppState) > 0 {
+		genesis["app_state"] = sdk.AppState
+	}
+	if len(sdk.Consensus.Validators) > 0 {
+		genesis["validators"] = sdk.Consensus.Validators
+	}
+	if params := sdk.Consensus.Params; params != nil {
+		block := map[string]interface{}{
+			"time_iota_ms": fmt.Sprint(sdkTimeIotaMs),
+		}
+		if params.Block.MaxBytes != "" {
+			block["max_bytes"] = params.Block.MaxBytes.String()
+		}
+		if params.Block.MaxGas != "" {
+			block["max_gas"] = params.Block.MaxGas.String()
+		}
+		consensusParams := map[string]interface{}{
+			"block": block,
+		}
+		if len(params.Evidence) > 0 {
+			consensusParams["evidence"] = params.Evidence
+		}
+		if len(params.Validator) > 0 {
+			consensusParams["validator"] = params.Validator
+		}
+		if params.Version.App != "" {
+			consensusParams["version"] = map[string]string{"app_version": params.Version.App.String()}
+		}
+		genesis["consensus_params"] = consensusParams
+	}
+	return json.Marshal(genesis)
+}
+
+// voteExtensionsEnableHeight returns the height from which [genesisData]
+// enables the vote extensions of ABCI 2.0, through the
+// vote_extensions_enable_height of its consensus params, or of those under
+// "consensus" for a genesis exported by the Cosmos SDK. It returns 0 if the
+// genesis doesn't enable them.
+func voteExtensionsEnableHeight(genesisData []byte) (int64, error) {
+	type abciParams struct {
+		ABCI struct {
+			VoteExtensionsEnableHeight json.Number `json:"vote_extensions_enable_height"`
+		} `json:"abci"`
+	}
+	var genesis struct {
+		ConsensusParams *abciParams `json:"consensus_params"`
+		Consensus       struct {
+			Params *abciParams `json:"params"`
+		} `json:"consensus"`
+	}
+	if err := json.Unmarshal(genesisData, &genesis); err != nil {
+		return 0, fmt.Errorf("%w: %v", errInvalidGenesis, err)
+	}
+	params := genesis.ConsensusParams
+	if params == nil {
+		params = genesis.Consensus.Params
+	}
+	if params == nil || params.ABCI.VoteExtensionsEnableHeight == "" {
+		return 0, nil
+	}
+	height, err := params.ABCI.VoteExtensionsEnableHeight.Int64()
+	if err != nil {
+		return 0, fmt.Errorf("%w: vote_extensions_enable_height: %v", errInvalidGenesis, err)
+	}
+	return height, nil
+}
+
+// checkGenesisJSON reports where [genesisData] isn't well-formed JSON, which
+// the decoder of the genesis doesn't tell.
+func checkGenesisJSON(genesisData []byte) error {
+	var syntaxErr *json.SyntaxError
+	if err := json.Unmarshal(genesisData, new(json.RawMessage)); errors.As(err, &syntaxErr) {
+		return fmt.Errorf("%w: not valid JSON at offset %d: %v", errInvalidGenesis, syntaxErr.Offset, err)
+	} else if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidGenesis, err)
+	}
+	return nil
+}
+
+// validateGenesis checks the fields of [genesis] which the app or Tendermint
+// would otherwise reject deep inside InitChain, or panic on, and names the
+// offending field in the error. The consensus params are only checked if
+// they are set, as the defaults are used otherwise.
+func validateGenesis(genesis *types.GenesisDoc) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", errInvalidGenesis, fmt.Sprintf(format, args...))
+	}
+
+	if genesis.ChainID == "" {
+		return invalid("chain_id must not be empty")
+	}
+	if len(genesis.ChainID) > types.MaxChainIDLen {
+		return invalid("chain_id must be at most %d characters, got %d", types.MaxChainIDLen, len(genesis.ChainID))
+	}
+	if genesis.InitialHeight < 0 {
+		return invalid("initial_height must be non-negative, got %d", genesis.InitialHeight)
+	}
+
+	if params := genesis.ConsensusParams; params != nil {
+		if err := validateGenesisParams(params); err != nil {
+			return invalid("consensus_params.%v", err)
+		}
+	}
+
+	if len(genesis.AppState) > 0 && !json.Valid(genesis.AppState) {
+		return invalid("app_state must be valid JSON")
+	}
+
+	for i, v := range genesis.Validators {
+		if v.PubKey == nil {
+			return invalid("validators[%d].pub_key must be set", i)
+		}
+		if v.Power <= 0 {
+			return invalid("validators[%d].power must be positive, got %d", i, v.Power)
+		}
+		if len(v.Address) > 0 && !bytes.Equal(v.PubKey.Address(), v.Address) {
+			return invalid("validators[%d].address must be %v, the address of its pub_key, got %v", i, v.PubKey.Address(), v.Address)
+		}
+		if params := genesis.ConsensusParams; params != nil && !types.IsValidPubkeyType(params.Validator, v.PubKey.Type()) {
+			return invalid("validators[%d].pub_key type %s isn't allowed by consensus_params.validator.pub_key_types %v", i, v.PubKey.Type(), params.Validator.PubKeyTypes)
+		}
+	}
+	return nil
+}
+
+// validateGenesisParams checks the ranges of the consensus params, as
+// types.ValidateConsensusParams does, with the names of their JSON fields.
+func validateGenesisParams(params *tmproto.ConsensusParams) error {
+	block, evidence := params.Block, params.Evidence
+	switch {
+	case block.MaxBytes <= 0 || block.MaxBytes > types.MaxBlockSizeBytes:
+		return fmt.Errorf("block.max_bytes must be between 1 and %d, got %d", types.MaxBlockSizeBytes, block.MaxBytes)
+	case block.MaxGas < -1:
+		return fmt.Errorf("block.max_gas must be -1 or more, got %d", block.MaxGas)
+	case block.TimeIotaMs <= 0:
+		return fmt.Errorf("block.time_iota_ms must be positive, got %d", block.TimeIotaMs)
+	case evidence.MaxAgeNumBlocks <= 0:
+		return fmt.Errorf("evidence.max_age_num_blocks must be positive, got %d", evidence.MaxAgeNumBlocks)
+	case evidence.MaxAgeDuration <= 0:
+		return fmt.Errorf("evidence.max_age_duration must be positive, got %s", evidence.MaxAgeDuration)
+	case evidence.MaxBytes < 0 || evidence.MaxBytes > block.MaxBytes:
+		return fmt.Errorf("evidence.max_bytes must be between 0 and block.max_bytes (%d), got %d", block.MaxBytes, evidence.MaxBytes)
+	case len(params.Validator.PubKeyTypes) == 0:
+		return errors.New("validator.pub_key_types must not be empty")
+	}
+	for i, keyType := range params.Validator.PubKeyTypes {
+		if _, ok := types.ABCIPubKeyTypesToNames[keyType]; !ok {
+			return fmt.Errorf("validator.pub_key_types[%d] %q is unknown", i, keyType)
+		}
+	}
+	return nil
+}
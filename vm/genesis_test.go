@@ -0,0 +1,167 @@
+package vm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+)
+
+func TestParseGenesis(t *testing.T) {
+	_, err := parseGenesis([]byte(genesis), "", staticChainID)
+	require.NoError(t, err)
+
+	pubKey := `{"type":"tendermint/PubKeyEd25519","value":"KUv7HDIDn/ILr48/zE4QRyTbKovVxSa93NHX3GwpuV4="}`
+	tests := map[string]struct {
+		genesis string
+		field   string
+	}{
+		"malformed JSON": {
+			genesis: `{"chain_id": "test",}`,
+			field:   "offset",
+		},
+		"long chain_id": {
+			genesis: `{"chain_id":"` + strings.Repeat("a", 51) + `"}`,
+			field:   "chain_id",
+		},
+		"negative initial_height": {
+			genesis: `{"chain_id":"test","initial_height":"-1"}`,
+			field:   "initial_height",
+		},
+		"max_bytes out of range": {
+			genesis: `{"chain_id":"test","consensus_params":{"block":{"max_bytes":"0","max_gas":"-1","time_iota_ms":"1000"}}}`,
+			field:   "consensus_params.block.max_bytes",
+		},
+		"missing evidence params": {
+			genesis: `{"chain_id":"test","consensus_params":{"block":{"max_bytes":"1000","max_gas":"-1","time_iota_ms":"1000"}}}`,
+			field:   "consensus_params.evidence.max_age_num_blocks",
+		},
+		"validator without pub_key": {
+			genesis: `{"chain_id":"test","validators":[{"power":"10"}]}`,
+			field:   "validators[0].pub_key",
+		},
+		"validator without power": {
+			genesis: `{"chain_id":"test","validators":[{"pub_key":` + pubKey + `,"power":"0"}]}`,
+			field:   "validators[0].power",
+		},
+		"validator with another address": {
+			genesis: `{"chain_id":"test","validators":[{"address":"0011","pub_key":` + pubKey + `,"power":"10"}]}`,
+			field:   "validators[0].address",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseGenesis([]byte(test.genesis), "", "")
+			require.ErrorIs(t, err, errInvalidGenesis)
+			assert.Contains(t, err.Error(), test.field)
+		})
+	}
+}
+
+func TestSDKGenesis(t *testing.T) {
+	sdkGenesis := func(appHash string) []byte {
+		return []byte(`{
+  "app_name": "simd",
+  "app_version": "v0.47.0",
+  "genesis_time": "2023-03-04T03:46:06.533236098Z",
+  "chain_id": "sdk-chain",
+  "initial_height": 100,
+  "app_hash": "` + appHash + `",
+  "app_state": {"bank": {"balances": []}},
+  "consensus": {
+    "validators": null,
+    "params": {
+      "block": {"max_bytes": "22020096", "max_gas": "-1"},
+      "evidence": {"max_age_num_blocks": "100000", "max_age_duration": "172800000000000", "max_bytes": "1048576"},
+      "validator": {"pub_key_types": ["ed25519"]},
+      "version": {"app": "1"}
+    }
+  }
+}`)
+	}
+
+	genesisDoc, err := parseGenesis(sdkGenesis("AQI="), "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "sdk-chain", genesisDoc.ChainID)
+	assert.EqualValues(t, 100, genesisDoc.InitialHeight)
+	assert.JSONEq(t, `{"bank": {"balances": []}}`, string(genesisDoc.AppState))
+	assert.EqualValues(t, []byte{1, 2}, genesisDoc.AppHash)
+	assert.EqualValues(t, 22020096, genesisDoc.ConsensusParams.Block.MaxBytes)
+	assert.EqualValues(t, sdkTimeIotaMs, genesisDoc.ConsensusParams.Block.TimeIotaMs)
+	assert.EqualValues(t, 1, genesisDoc.ConsensusParams.Version.AppVersion)
+
+	// the chain starts at the initial height
+	vm, _, _, err := newTestVMWithGenesis(kvstore.NewApplication(), manager.NewMemDB(&version.Semantic{Major: 1}), sdkGenesis(""), nil, nil)
+	require.NoError(t, err)
+	service := NewService(vm)
+	assert.EqualValues(t, 100, mustAcceptBlock(t, vm, service, []byte("a=1")).Height())
+	assert.EqualValues(t, 101, mustAcceptBlock(t, vm, service, []byte("b=2")).Height())
+	assert.EqualValues(t, 101, vm.tmState.LastBlockHeight)
+}
+
+// commitInfoApp records whether it was initialized and the commit info of the
+// blocks it executes.
+type commitInfoApp struct {
+	*kvstore.Application
+	initialized bool
+	commitInfos []atypes.LastCommitInfo
+}
+
+func (app *commitInfoApp) InitChain(req atypes.RequestInitChain) atypes.ResponseInitChain {
+	app.initialized = true
+	return app.Application.InitChain(req)
+}
+
+func (app *commitInfoApp) BeginBlock(req atypes.RequestBeginBlock) atypes.ResponseBeginBlock {
+	app.commitInfos = append(app.commitInfos, req.LastCommitInfo)
+	return app.Application.BeginBlock(req)
+}
+
+func TestFailOnVoteExtensions(t *testing.T) {
+	genesis := []byte(`{
+  "genesis_time": "2023-03-04T03:46:06.533236098Z",
+  "chain_id": "sdk-chain",
+  "initial_height": 1,
+  "consensus": {
+    "params": {
+      "block": {"max_bytes": "22020096", "max_gas": "-1"},
+      "evidence": {"max_age_num_blocks": "100000", "max_age_duration": "172800000000000", "max_bytes": "1048576"},
+      "validator": {"pub_key_types": ["ed25519"]},
+      "abci": {"vote_extensions_enable_height": "10"}
+    }
+  }
+}`)
+	height, err := voteExtensionsEnableHeight(genesis)
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, height)
+
+	// the app is given empty commit info by default
+	app := &commitInfoApp{Application: kvstore.NewApplication()}
+	vm, _, _, err := newTestVMWithGenesis(app, manager.NewMemDB(&version.Semantic{Major: 1}), genesis, nil, nil)
+	require.NoError(t, err)
+	service := NewService(vm)
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	mustAcceptBlock(t, vm, service, []byte("b=2"))
+	require.Len(t, app.commitInfos, 2)
+	for _, commitInfo := range app.commitInfos {
+		assert.Empty(t, commitInfo.Votes)
+	}
+
+	// or the VM fails right away, before the app is initialized
+	app = &commitInfoApp{Application: kvstore.NewApplication()}
+	_, _, _, err = newTestVMWithGenesis(app, manager.NewMemDB(&version.Semantic{Major: 1}), genesis, nil,
+		[]byte(`{"failOnVoteExtensions":true}`))
+	assert.ErrorContains(t, err, "requires vote extensions from height 10")
+	assert.False(t, app.initialized)
+
+	// unless the genesis doesn't enable them
+	_, _, _, err = newTestVMWithDB(kvstore.NewApplication(), manager.NewMemDB(&version.Semantic{Major: 1}),
+		[]byte(`{"failOnVoteExtensions":true}`))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,89 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	errABCIUnreachable   = errors.New("abci app unreachable")
+	errLastBlockTooOld   = errors.New("last block too old")
+	errMempoolSaturated  = errors.New("mempool saturated")
+	errIndexerLagging    = errors.New("indexer lagging")
+	errDatabaseUnhealthy = errors.New("database unhealthy")
+)
+
+// HealthCheck reports the health of the chain to the health API of
+// avalanchego: whether the app answers, is connected and has the blocks
+// replayed to it after a reconnection, how long ago the last block was
+// accepted, how full the mempool is, how far behind the indexer is and
+// whether the database works. The node is unhealthy if any of them crosses
+// its threshold in the config.
+func (vm *VM) HealthCheck(ctx context.Context) (interface{}, error) {
+	details := make(map[string]interface{})
+	var errs []error
+
+	if err := vm.appGuard.echo("health"); err != nil {
+		errs = append(errs, fmt.Errorf("%w: %v", errABCIUnreachable, err))
+	}
+	// slow queries don't affect the chain
+	details["abciQueryBreakerOpen"] = vm.appGuard.queryBreaker.isOpen()
+	if vm.appGuard.checkTxBreaker.isOpen() {
+		errs = append(errs, fmt.Errorf("%w: CheckTx: %v", errABCIUnreachable, errCircuitOpen))
+	}
+	if vm.appGuard.consensusBreaker.isOpen() {
+		errs = append(errs, fmt.Errorf("%w: %w", errABCIUnreachable, errAppHalted))
+	}
+	if vm.remoteApp != nil {
+		disconnected := vm.remoteApp.disconnected()
+		details["abciDisconnectedConns"] = disconnected
+		if disconnected > 0 {
+			errs = append(errs, fmt.Errorf("%w: %d connections lost, reconnecting", errABCIUnreachable, disconnected))
+		}
+	}
+	vm.appSyncMtx.Lock()
+	appSyncErr := vm.appSyncErr
+	vm.appSyncMtx.Unlock()
+	if appSyncErr != nil {
+		errs = append(errs, fmt.Errorf("%w: failed to replay blocks: %v", errABCIUnreachable, appSyncErr))
+	}
+
+	blockAge := time.Since(vm.tmState.LastBlockTime)
+	details["lastBlockHeight"] = vm.tmState.LastBlockHeight
+	details["lastBlockAge"] = blockAge.String()
+	if maxAge := vm.config.HealthMaxBlockAge.Duration; maxAge > 0 && blockAge > maxAge {
+		errs = append(errs, fmt.Errorf("%w: accepted %s ago, more than %s", errLastBlockTooOld, blockAge, maxAge))
+	}
+
+	mempoolUsage := vm.mempoolUsage()
+	details["mempoolUsage"] = mempoolUsage
+	if maxUsage := vm.config.HealthMaxMempoolUsage; maxUsage > 0 && mempoolUsage >= maxUsage {
+		errs = append(errs, fmt.Errorf("%w: %.2f of its capacity used, from %.2f", errMempoolSaturated, mempoolUsage, maxUsage))
+	}
+
+	lastIndexed, ok, err := vm.lastIndexedHeight()
+	switch {
+	case err != nil:
+		errs = append(errs, fmt.Errorf("%w: %v", errDatabaseUnhealthy, err))
+	case ok:
+		lag := vm.tmState.LastBlockHeight - lastIndexed
+		details["indexerLag"] = lag
+		if maxLag := vm.config.HealthMaxIndexerLag; maxLag > 0 && lag > maxLag {
+			errs = append(errs, fmt.Errorf("%w: %d blocks behind, more than %d", errIndexerLagging, lag, maxLag))
+		}
+	}
+
+	if _, err := vm.dbManager.Current().Database.HealthCheck(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("%w: %v", errDatabaseUnhealthy, err))
+	}
+	vm.commitMtx.Lock()
+	commitErr := vm.commitErr
+	vm.commitMtx.Unlock()
+	if commitErr != nil {
+		errs = append(errs, fmt.Errorf("%w: last commit failed: %v", errDatabaseUnhealthy, commitErr))
+	}
+
+	return details, errors.Join(errs...)
+}
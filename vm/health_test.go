@@ -0,0 +1,54 @@
+package vm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+)
+
+func TestHealthCheck(t *testing.T) {
+	ctx := context.Background()
+	newVM := func(configBytes string) (*VM, Service) {
+		vm, _, _, err := newTestVMWithDB(kvstore.NewApplication(), manager.NewMemDB(&version.Semantic{Major: 1}), []byte(configBytes))
+		require.NoError(t, err)
+		return vm, NewService(vm)
+	}
+
+	vm, service := newVM("")
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	details, err := vm.HealthCheck(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, details.(map[string]interface{})["lastBlockHeight"])
+	require.NoError(t, service.Health(nil, nil, new(ctypes.ResultHealth)))
+
+	vm, service = newVM(`{"healthMaxBlockAge":"1ns"}`)
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	time.Sleep(time.Millisecond)
+	_, err = vm.HealthCheck(ctx)
+	assert.ErrorIs(t, err, errLastBlockTooOld)
+	assert.ErrorIs(t, service.Health(nil, nil, new(ctypes.ResultHealth)), errLastBlockTooOld)
+
+	vm, service = newVM(`{"healthMaxMempoolUsage":0.0001}`)
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, reply))
+	_, err = vm.HealthCheck(ctx)
+	assert.ErrorIs(t, err, errMempoolSaturated)
+
+	vm, service = newVM(`{"healthMaxIndexerLag":1}`)
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	mustAcceptBlock(t, vm, service, []byte("b=2"))
+	vm.acceptor.flush()
+	require.NoError(t, vm.atomically(func() error {
+		return vm.setLastIndexedHeight(0)
+	}))
+	_, err = vm.HealthCheck(ctx)
+	assert.ErrorIs(t, err, errIndexerLagging)
+}
@@ -0,0 +1,111 @@
+package vm
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type (
+	// FieldInfo describes a JSON field of the params or result of a method.
+	FieldInfo struct {
+		Name string `json:"name"`
+		// Type is the Go type of the field, e.g. "*int64" or "types.Tx".
+		Type string `json:"type"`
+		// Optional is set for pointer and omitempty fields, which may be
+		// left out.
+		Optional bool `json:"optional"`
+	}
+
+	// MethodInfo describes a method of the Service.
+	MethodInfo struct {
+		// Name is the JSON-RPC method name, e.g. "landslide.Block". Requests
+		// may also write it with a lowercase first letter.
+		Name         string      `json:"name"`
+		Params       []FieldInfo `json:"params"`
+		Result       string      `json:"result"`
+		ResultFields []FieldInfo `json:"resultFields"`
+	}
+
+	MethodsReply struct {
+		Methods []MethodInfo `json:"methods"`
+	}
+
+	IntrospectionService interface {
+		Methods(_ *http.Request, _ *struct{}, reply *MethodsReply) error
+	}
+)
+
+var (
+	serviceMethodsOnce sync.Once
+	serviceMethods     []MethodInfo
+)
+
+// Methods returns the methods of the Service, with the fields of their params
+// and results, sorted by name.
+func (s *LocalService) Methods(_ *http.Request, _ *struct{}, reply *MethodsReply) error {
+	serviceMethodsOnce.Do(func() {
+		serviceMethods = describeMethods(reflect.TypeOf((*Service)(nil)).Elem())
+	})
+	reply.Methods = serviceMethods
+	return nil
+}
+
+// describeMethods describes the methods of the [service] interface, which have
+// the (*http.Request, *Args, *Reply) error signature of gorilla rpc services.
+func describeMethods(service reflect.Type) []MethodInfo {
+	methods := make([]MethodInfo, 0, service.NumMethod())
+	for i := 0; i < service.NumMethod(); i++ {
+		method := service.Method(i)
+		if method.Type.NumIn() != 3 {
+			continue
+		}
+		args, reply := method.Type.In(1).Elem(), method.Type.In(2).Elem()
+		methods = append(methods, MethodInfo{
+			Name:         Name + "." + method.Name,
+			Params:       describeFields(args),
+			Result:       reply.String(),
+			ResultFields: describeFields(reply),
+		})
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].Name < methods[j].Name
+	})
+	return methods
+}
+
+// describeFields returns the JSON fields of the struct type [t], following
+// encoding/json rules for tags and embedded structs. Non-struct types have no
+// fields.
+func describeFields(t reflect.Type) []FieldInfo {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	fields := []FieldInfo{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, describeFields(field.Type)...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, FieldInfo{
+			Name:     name,
+			Type:     field.Type.String(),
+			Optional: field.Type.Kind() == reflect.Ptr || strings.Contains(opts, "omitempty"),
+		})
+	}
+	return fields
+}
@@ -0,0 +1,49 @@
+package localnet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/utils/rpc"
+
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
+	"github.com/consideritdone/landslidecore/vm"
+)
+
+// Client is a client of the RPC of a chain of the VM on a node.
+type Client struct {
+	requester rpc.EndpointRequester
+}
+
+// NewClient returns a client of the RPC of the chain [chainID] on the node
+// whose API is at [nodeURI].
+func NewClient(nodeURI, chainID string) *Client {
+	return &Client{
+		requester: rpc.NewEndpointRequester(fmt.Sprintf("%s/ext/bc/%s/rpc", nodeURI, chainID)),
+	}
+}
+
+// Call calls the [method] of the service of the VM, e.g. "status", with
+// [args] and decodes the result into [reply].
+func (c *Client) Call(ctx context.Context, method string, args, reply interface{}) error {
+	return c.requester.SendRequest(ctx, vm.Name+"."+method, args, reply)
+}
+
+// Status returns the status of the chain on the node.
+func (c *Client) Status(ctx context.Context) (*ctypes.ResultStatus, error) {
+	reply := new(ctypes.ResultStatus)
+	return reply, c.Call(ctx, "status", struct{}{}, reply)
+}
+
+// BroadcastTxCommit broadcasts [tx] and waits for it to be committed.
+func (c *Client) BroadcastTxCommit(ctx context.Context, tx types.Tx) (*vm.BroadcastTxCommitReply, error) {
+	reply := new(vm.BroadcastTxCommitReply)
+	return reply, c.Call(ctx, "broadcastTxCommit", &vm.BroadcastTxCommitArgs{Tx: tx}, reply)
+}
+
+// ABCIQuery queries the app at [path] with [data].
+func (c *Client) ABCIQuery(ctx context.Context, path string, data []byte) (*ctypes.ResultABCIQuery, error) {
+	reply := new(ctypes.ResultABCIQuery)
+	return reply, c.Call(ctx, "abciQuery", &vm.ABCIQueryArgs{Path: path, Data: data}, reply)
+}
@@ -0,0 +1,280 @@
+// Package localnet spins up a local Avalanche network running the Landslide
+// VM, for the e2e tests and for the integration tests of the apps.
+//
+// The network is run by the avalanche-network-runner server, which must be
+// started beforehand, e.g. with:
+//
+//	avalanche-network-runner server --port=:8080 --grpc-gateway-port=:8081
+//
+// The package talks to the JSON gateway of the server rather than importing
+// the network-runner module, whose avalanchego dependency would have to match
+// the one of the VM.
+package localnet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+const (
+	// DefaultRunnerURL is the address of the JSON gateway of the
+	// network-runner server.
+	DefaultRunnerURL = "http://127.0.0.1:8081"
+	// DefaultVMName is the name the VM is registered with, which its ID is
+	// derived from, see VMID.
+	DefaultVMName = "landslidevm"
+
+	defaultNumNodes       = 5
+	defaultHealthyTimeout = 5 * time.Minute
+	healthyPollInterval   = time.Second
+)
+
+var (
+	errNoAvalancheGo = errors.New("no avalanchego binary")
+	errVMNameTooLong = errors.New("vm name is longer than 32 bytes")
+	errNoPlugin      = errors.New("no plugin binary of the VM")
+	errNoChain       = errors.New("the network has no chain of the VM")
+)
+
+// Config is the configuration of a local network.
+type Config struct {
+	// RunnerURL is the address of the JSON gateway of the network-runner
+	// server, DefaultRunnerURL if empty.
+	RunnerURL string
+	// AvalancheGoPath is the path of the avalanchego binary the nodes run.
+	AvalancheGoPath string
+	// PluginDir is the plugins directory of avalanchego, which holds the
+	// plugin binary of the VM named after its VM ID.
+	PluginDir string
+	// VMName is the name of the VM, DefaultVMName if empty.
+	VMName string
+	// NumNodes is the number of nodes of the network, 5 if zero.
+	NumNodes uint32
+	// Genesis is the genesis of the chain.
+	Genesis []byte
+	// ChainConfig is the config of the VM, see vm.Config.
+	ChainConfig []byte
+	// LogLevel is the log level of the nodes.
+	LogLevel string
+	// HealthyTimeout is how long to wait for the network and the chain to be
+	// healthy, 5 minutes if zero.
+	HealthyTimeout time.Duration
+}
+
+// VMID returns the ID of the VM named [name], as network-runner and
+// avalanche-cli derive it: the name padded to 32 bytes.
+func VMID(name string) (ids.ID, error) {
+	if len(name) > len(ids.Empty) {
+		return ids.Empty, fmt.Errorf("%w: %q", errVMNameTooLong, name)
+	}
+	var id ids.ID
+	copy(id[:], name)
+	return id, nil
+}
+
+// Network is a local network running a chain of the VM.
+type Network struct {
+	runner *runnerClient
+	// ChainID is the ID of the chain of the VM.
+	ChainID string
+	// NodeURIs are the URIs of the APIs of the nodes, by node name.
+	NodeURIs map[string]string
+	// Clients are the clients of the RPC of the chain on each node, sorted
+	// by node name.
+	Clients []*Client
+}
+
+// Start starts a network with [config], creates the chain of the VM from the
+// genesis and waits for the chain to be healthy on all the nodes.
+func Start(ctx context.Context, config Config) (*Network, error) {
+	if config.RunnerURL == "" {
+		config.RunnerURL = DefaultRunnerURL
+	}
+	if config.VMName == "" {
+		config.VMName = DefaultVMName
+	}
+	if config.NumNodes == 0 {
+		config.NumNodes = defaultNumNodes
+	}
+	if config.HealthyTimeout == 0 {
+		config.HealthyTimeout = defaultHealthyTimeout
+	}
+	if _, err := os.Stat(config.AvalancheGoPath); err != nil {
+		return nil, fmt.Errorf("%w: %s", errNoAvalancheGo, err)
+	}
+	vmID, err := VMID(config.VMName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(config.PluginDir, vmID.String())); err != nil {
+		return nil, fmt.Errorf("%w: %s", errNoPlugin, err)
+	}
+
+	// the runner reads the genesis from a file
+	genesisDir, err := os.MkdirTemp("", "landslide-genesis")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(genesisDir)
+	genesisPath := filepath.Join(genesisDir, "genesis.json")
+	if err := os.WriteFile(genesisPath, config.Genesis, 0o600); err != nil {
+		return nil, err
+	}
+
+	runner := &runnerClient{url: config.RunnerURL, client: http.DefaultClient}
+	req := startRequest{
+		ExecPath:  config.AvalancheGoPath,
+		NumNodes:  config.NumNodes,
+		LogLevel:  config.LogLevel,
+		PluginDir: config.PluginDir,
+		BlockchainSpecs: []blockchainSpec{{
+			VMName:      config.VMName,
+			Genesis:     genesisPath,
+			ChainConfig: string(config.ChainConfig),
+		}},
+	}
+	if err := runner.call(ctx, "/v1/control/start", req, nil); err != nil {
+		return nil, fmt.Errorf("failed to start the network: %w", err)
+	}
+
+	info, err := runner.waitForHealthy(ctx, config.HealthyTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return newNetwork(runner, info, vmID)
+}
+
+// newNetwork returns the network of [info], whose chain of the VM has the ID
+// [vmID].
+func newNetwork(runner *runnerClient, info *clusterInfo, vmID ids.ID) (*Network, error) {
+	n := &Network{
+		runner:   runner,
+		NodeURIs: make(map[string]string, len(info.NodeInfos)),
+	}
+	for chainID, chain := range info.CustomChains {
+		if chain.VMID == vmID.String() {
+			n.ChainID = chainID
+		}
+	}
+	if n.ChainID == "" {
+		return nil, fmt.Errorf("%w: %s", errNoChain, vmID)
+	}
+	names := make([]string, 0, len(info.NodeInfos))
+	for name, node := range info.NodeInfos {
+		n.NodeURIs[name] = node.URI
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		n.Clients = append(n.Clients, NewClient(n.NodeURIs[name], n.ChainID))
+	}
+	return n, nil
+}
+
+// Stop stops the nodes of the network.
+func (n *Network) Stop(ctx context.Context) error {
+	return n.runner.call(ctx, "/v1/control/stop", struct{}{}, nil)
+}
+
+// startRequest, blockchainSpec and clusterInfo are the messages of the
+// network-runner server used here, in the JSON form of the gateway.
+type startRequest struct {
+	ExecPath        string           `json:"execPath"`
+	NumNodes        uint32           `json:"numNodes"`
+	LogLevel        string           `json:"logLevel,omitempty"`
+	PluginDir       string           `json:"pluginDir"`
+	BlockchainSpecs []blockchainSpec `json:"blockchainSpecs"`
+}
+
+type blockchainSpec struct {
+	VMName      string `json:"vmName"`
+	Genesis     string `json:"genesis"`
+	ChainConfig string `json:"chainConfig,omitempty"`
+}
+
+type clusterInfo struct {
+	Healthy             bool `json:"healthy"`
+	CustomChainsHealthy bool `json:"customChainsHealthy"`
+	NodeInfos           map[string]struct {
+		URI string `json:"uri"`
+	} `json:"nodeInfos"`
+	CustomChains map[string]struct {
+		ChainName string `json:"chainName"`
+		VMID      string `json:"vmId"`
+	} `json:"customChains"`
+}
+
+type statusResponse struct {
+	ClusterInfo *clusterInfo `json:"clusterInfo"`
+}
+
+// runnerClient calls the JSON gateway of the network-runner server.
+type runnerClient struct {
+	url    string
+	client *http.Client
+}
+
+// call posts [req] to [path] and decodes the response into [resp], unless it
+// is nil.
+func (c *runnerClient) call(ctx context.Context, path string, req, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := c.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s: %s", path, httpResp.Status, respBody)
+	}
+	if resp == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, resp)
+}
+
+// waitForHealthy polls the status of the network until the nodes and the
+// custom chains are healthy, for at most [timeout].
+func (c *runnerClient) waitForHealthy(ctx context.Context, timeout time.Duration) (*clusterInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(healthyPollInterval)
+	defer ticker.Stop()
+	for {
+		var resp statusResponse
+		err := c.call(ctx, "/v1/control/status", struct{}{}, &resp)
+		if err == nil && resp.ClusterInfo != nil && resp.ClusterInfo.Healthy && resp.ClusterInfo.CustomChainsHealthy {
+			return resp.ClusterInfo, nil
+		}
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				err = ctx.Err()
+			}
+			return nil, fmt.Errorf("network isn't healthy: %w", err)
+		case <-ticker.C:
+		}
+	}
+}
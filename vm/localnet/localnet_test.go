@@ -0,0 +1,115 @@
+package localnet
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVMID(t *testing.T) {
+	// the ID of subnet-evm, derived the same way
+	vmID, err := VMID("subnetevm")
+	require.NoError(t, err)
+	assert.Equal(t, "srEXiWaHuhNyGwPUi444Tu47ZEDwxTWrbQiuD7FmgSAQ6X7Dy", vmID.String())
+
+	// the plugin name of vm/scripts/build.sh
+	vmID, err = VMID(DefaultVMName)
+	require.NoError(t, err)
+	assert.Equal(t, "pjSL9ksard4YE96omaiTkGL5H6XX2W5VEo3ZgWC9S2P6gzs9A", vmID.String())
+
+	_, err = VMID(strings.Repeat("a", 33))
+	assert.ErrorIs(t, err, errVMNameTooLong)
+}
+
+func TestStart(t *testing.T) {
+	const chainID = "2CA6j5zYzasynPsFeNoqWkmTCt3VScMvXUZHbfDJ8k3oGzAPtU"
+	vmID, err := VMID(DefaultVMName)
+	require.NoError(t, err)
+
+	// a node serving the status of the chain
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/ext/bc/"+chainID+"/rpc", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.Contains(t, string(body), `"method":"landslide.status"`)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"node_info":{"network":"landslide-test"}}}`))
+	}))
+	defer node.Close()
+
+	// a runner whose network is healthy on the second status
+	var statuses, stopped int32
+	var start startRequest
+	runner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/control/start":
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&start))
+			genesis, err := os.ReadFile(start.BlockchainSpecs[0].Genesis)
+			assert.NoError(t, err)
+			assert.Equal(t, `{"chain_id":"landslide-test"}`, string(genesis))
+			_, _ = w.Write([]byte(`{}`))
+		case "/v1/control/status":
+			healthy := atomic.AddInt32(&statuses, 1) > 1
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"clusterInfo": map[string]interface{}{
+					"healthy":             healthy,
+					"customChainsHealthy": healthy,
+					"nodeInfos": map[string]interface{}{
+						"node1": map[string]string{"uri": node.URL},
+					},
+					"customChains": map[string]interface{}{
+						chainID: map[string]string{"chainName": "landslide", "vmId": vmID.String()},
+					},
+				},
+			})
+		case "/v1/control/stop":
+			atomic.AddInt32(&stopped, 1)
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer runner.Close()
+
+	dir := t.TempDir()
+	avalanchego := filepath.Join(dir, "avalanchego")
+	require.NoError(t, os.WriteFile(avalanchego, nil, 0o600))
+	config := Config{
+		RunnerURL:       runner.URL,
+		AvalancheGoPath: avalanchego,
+		PluginDir:       dir,
+		Genesis:         []byte(`{"chain_id":"landslide-test"}`),
+		HealthyTimeout:  10 * time.Second,
+	}
+	ctx := context.Background()
+
+	// the plugin must be installed
+	_, err = Start(ctx, config)
+	require.ErrorIs(t, err, errNoPlugin)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, vmID.String()), nil, 0o600))
+
+	network, err := Start(ctx, config)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(defaultNumNodes), start.NumNodes)
+	assert.Equal(t, DefaultVMName, start.BlockchainSpecs[0].VMName)
+	assert.Equal(t, chainID, network.ChainID)
+	assert.Equal(t, map[string]string{"node1": node.URL}, network.NodeURIs)
+
+	require.Len(t, network.Clients, 1)
+	status, err := network.Clients[0].Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "landslide-test", status.NodeInfo.Network)
+
+	require.NoError(t, network.Stop(ctx))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&stopped))
+}
@@ -0,0 +1,142 @@
+package vm
+
+import (
+	"sync/atomic"
+
+	"github.com/consideritdone/landslidecore/libs/cli/flags"
+	"github.com/consideritdone/landslidecore/libs/log"
+	tmmath "github.com/consideritdone/landslidecore/libs/math"
+	tmsync "github.com/consideritdone/landslidecore/libs/sync"
+)
+
+const (
+	// defaultLogLevel keeps every log event, matching the behaviour of the
+	// unfiltered logger the VM used before levels became configurable.
+	defaultLogLevel = "debug"
+
+	logModuleKey = "module"
+	vmLogModule  = "vm"
+)
+
+var (
+	_ log.Logger = (*leveledLogger)(nil)
+)
+
+// logLevels holds the filtered root logger shared by every logger derived
+// from the VM logger. Replacing the root takes effect immediately for all of
+// them, so module levels can be changed at runtime.
+type logLevels struct {
+	mtx   tmsync.RWMutex
+	base  log.Logger
+	root  log.Logger
+	level string
+	// generation is incremented every time the root is replaced, so that
+	// derived loggers know when to rebuild.
+	generation uint64
+}
+
+func newLogLevels(base log.Logger, level string) (*logLevels, error) {
+	l := &logLevels{base: base}
+	if err := l.SetLevel(level); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// SetLevel parses [level] using the Tendermint log_level syntax
+// (e.g. "mempool:debug,rpc:error,*:info") and applies it to all loggers.
+func (l *logLevels) SetLevel(level string) error {
+	root, err := flags.ParseLogLevel(level, l.base, defaultLogLevel)
+	if err != nil {
+		return err
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.root = root
+	l.level = level
+	l.generation++
+	return nil
+}
+
+// Level returns the currently applied log level string.
+func (l *logLevels) Level() string {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+	return l.level
+}
+
+// Root returns the filtered root logger and its generation.
+func (l *logLevels) Root() (log.Logger, uint64) {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+	return l.root, l.generation
+}
+
+// leveledLogger derives its filtered logger from the current root, and
+// rebuilds it whenever [logLevels.SetLevel] replaced the root.
+type leveledLogger struct {
+	levels  *logLevels
+	keyvals []interface{}
+
+	cached atomic.Value // *derivedLogger
+}
+
+// derivedLogger is the logger derived from the root of a given generation.
+type derivedLogger struct {
+	generation uint64
+	logger     log.Logger
+}
+
+// newLeveledLogger returns the root VM logger. Loggers derived from it with
+// With("module", ...) are filtered by that module's level, everything else is
+// filtered as the "vm" module.
+func newLeveledLogger(levels *logLevels) log.Logger {
+	return &leveledLogger{
+		levels:  levels,
+		keyvals: []interface{}{logModuleKey, vmLogModule},
+	}
+}
+
+func (l *leveledLogger) Debug(msg string, keyvals ...interface{}) {
+	l.logger().Debug(msg, keyvals...)
+}
+
+func (l *leveledLogger) Info(msg string, keyvals ...interface{}) {
+	l.logger().Info(msg, keyvals...)
+}
+
+func (l *leveledLogger) Error(msg string, keyvals ...interface{}) {
+	l.logger().Error(msg, keyvals...)
+}
+
+// With returns a logger with [keyvals] appended. A "module" key replaces the
+// module inherited from the parent instead of being added next to it.
+func (l *leveledLogger) With(keyvals ...interface{}) log.Logger {
+	merged := make([]interface{}, len(l.keyvals), len(l.keyvals)+len(keyvals))
+	copy(merged, l.keyvals)
+
+	for i := 0; i < len(keyvals); i += 2 {
+		if keyvals[i] == logModuleKey && i+1 < len(keyvals) {
+			// the module is always the first pair
+			merged[1] = keyvals[i+1]
+			continue
+		}
+		merged = append(merged, keyvals[i:tmmath.MinInt(i+2, len(keyvals))]...)
+	}
+
+	return &leveledLogger{
+		levels:  l.levels,
+		keyvals: merged,
+	}
+}
+
+func (l *leveledLogger) logger() log.Logger {
+	root, generation := l.levels.Root()
+	if cached, ok := l.cached.Load().(*derivedLogger); ok && cached.generation == generation {
+		return cached.logger
+	}
+	logger := root.With(l.keyvals...)
+	l.cached.Store(&derivedLogger{generation: generation, logger: logger})
+	return logger
+}
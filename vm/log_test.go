@@ -0,0 +1,38 @@
+package vm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/libs/log"
+)
+
+func TestLeveledLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	levels, err := newLogLevels(log.NewTMLogger(buf), "mempool:error,*:debug")
+	require.NoError(t, err)
+	logger := newLeveledLogger(levels)
+	mempoolLogger := logger.With("module", "mempool")
+
+	mempoolLogger.Debug("mempool debug")
+	mempoolLogger.Info("mempool info")
+	assert.Empty(t, buf.String())
+	mempoolLogger.Error("mempool error")
+	assert.Contains(t, buf.String(), "mempool error")
+
+	buf.Reset()
+	logger.Debug("vm debug")
+	assert.Contains(t, buf.String(), "vm debug")
+
+	// level changes apply to loggers derived before the change
+	require.NoError(t, levels.SetLevel("mempool:debug,*:error"))
+	buf.Reset()
+	mempoolLogger.Debug("mempool debug")
+	assert.Contains(t, buf.String(), "mempool debug")
+	buf.Reset()
+	logger.Debug("vm debug")
+	assert.Empty(t, buf.String())
+}
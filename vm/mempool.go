@@ -0,0 +1,148 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	mempl "github.com/consideritdone/landslidecore/mempool"
+	tmproto "github.com/consideritdone/landslidecore/proto/tendermint/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+const (
+	// mempoolRecheckAsync, mempoolRecheckSync and mempoolRecheckOff are the
+	// values of the mempoolRecheck config.
+	mempoolRecheckAsync = "async"
+	mempoolRecheckSync  = "sync"
+	mempoolRecheckOff   = "off"
+
+	// reapStrategyFIFO, reapStrategyPriority and reapStrategyRoundRobin are
+	// the values of the reapStrategy config.
+	reapStrategyFIFO       = "fifo"
+	reapStrategyPriority   = "priority"
+	reapStrategyRoundRobin = "roundRobin"
+)
+
+// reapStrategies are the strategies of the reapStrategy config.
+var reapStrategies = map[string]mempl.ReapStrategy{
+	reapStrategyFIFO:       mempl.FIFOReapStrategy{},
+	reapStrategyPriority:   mempl.PriorityReapStrategy{},
+	reapStrategyRoundRobin: mempl.RoundRobinReapStrategy{},
+}
+
+// requestContext returns the context of [req], which is done once the client
+// goes away or the VM shuts down. [req] may be nil, e.g. for the calls from
+// the tests or over the Unix socket without a request.
+func (vm *VM) requestContext(req *http.Request) (context.Context, context.CancelFunc) {
+	ctx := context.Background()
+	if req != nil {
+		ctx = req.Context()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-vm.closing:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// checkTx sends [tx] to the mempool, giving up on it once [ctx] is done if
+// the mempool supports it.
+func (vm *VM) checkTx(ctx context.Context, tx types.Tx, cb func(*abci.Response), txInfo mempl.TxInfo) error {
+	if mempool, ok := vm.mempool.(contextTxChecker); ok {
+		return mempool.CheckTxContext(ctx, tx, cb, txInfo)
+	}
+	return vm.mempool.CheckTx(tx, cb, txInfo)
+}
+
+// mempoolTxsKey holds the txs of the mempool saved on shutdown and every
+// PersistMempoolInterval, when PersistMempool is set.
+var mempoolTxsKey = []byte("mempoolTxs")
+
+// saveMempool saves the txs of the mempool, so that they are checked into the
+// mempool again on restart rather than lost. The txs saved before are
+// replaced, or deleted if the mempool is empty.
+func (vm *VM) saveMempool() error {
+	txs := vm.mempool.ReapMaxTxs(-1)
+	if len(txs) == 0 {
+		return vm.atomically(func() error {
+			return vm.stateDB.Delete(mempoolTxsKey)
+		})
+	}
+	saved := tmproto.Data{Txs: make([][]byte, len(txs))}
+	for i, tx := range txs {
+		saved.Txs[i] = tx
+	}
+	data, err := saved.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal mempool txs: %w", err)
+	}
+	return vm.atomically(func() error {
+		return vm.stateDB.Set(mempoolTxsKey, data)
+	})
+}
+
+// txExpired publishes the expiration of [tx], which the mempool evicted after
+// the block at [height] for outliving its TTL.
+func (vm *VM) txExpired(tx types.Tx, height int64) {
+	if err := vm.eventBus.PublishEventTxExpired(types.EventDataTxExpired{Tx: tx, Height: height}); err != nil {
+		vm.tmLogger.Error("failed to publish tx expiration", "tx", tx.Hash(), "err", err)
+	}
+}
+
+// txEvicted publishes the eviction of [tx] from the mempool for [reason].
+func (vm *VM) txEvicted(tx types.Tx, reason string) {
+	if err := vm.eventBus.PublishEventTxEvicted(types.EventDataTxEvicted{Tx: tx, Reason: reason}); err != nil {
+		vm.tmLogger.Error("failed to publish tx eviction", "tx", tx.Hash(), "err", err)
+	}
+}
+
+// saveMempoolPeriodically saves the txs of the mempool every [interval] until
+// the VM shuts down, then closes [done].
+func (vm *VM) saveMempoolPeriodically(interval time.Duration, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := vm.saveMempool(); err != nil {
+				vm.tmLogger.Error("failed to save mempool", "err", err)
+			}
+		case <-vm.closing:
+			return
+		}
+	}
+}
+
+// restoreMempool checks the txs saved by saveMempool into the mempool. The txs
+// which are no longer valid are dropped.
+func (vm *VM) restoreMempool() error {
+	data, err := vm.stateDB.Get(mempoolTxsKey)
+	if err != nil || len(data) == 0 {
+		return err
+	}
+	var saved tmproto.Data
+	if err := saved.Unmarshal(data); err != nil {
+		return fmt.Errorf("failed to unmarshal mempool txs: %w", err)
+	}
+	txs := make(types.Txs, len(saved.Txs))
+	for i, tx := range saved.Txs {
+		txs[i] = tx
+	}
+	for i, err := range vm.mempool.CheckTxBatch(txs, nil, mempl.TxInfo{}) {
+		if err != nil {
+			vm.tmLogger.Debug("saved tx not restored to the mempool", "tx", txs[i].Hash(), "err", err)
+		}
+	}
+	return vm.atomically(func() error {
+		return vm.stateDB.Delete(mempoolTxsKey)
+	})
+}
@@ -0,0 +1,603 @@
+package vm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/ava-labs/avalanchego/vms/components/chain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+	tmquery "github.com/consideritdone/landslidecore/libs/pubsub/query"
+	mempl "github.com/consideritdone/landslidecore/mempool"
+	tmproto "github.com/consideritdone/landslidecore/proto/tendermint/types"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// onceApp is a kvstore app whose keys can only be set once, so that a block
+// setting a key invalidates the other txs setting it.
+type onceApp struct {
+	*kvstore.Application
+	set map[string]bool
+}
+
+func (app *onceApp) key(tx []byte) string {
+	return string(bytes.SplitN(tx, []byte("="), 2)[0])
+}
+
+func (app *onceApp) CheckTx(req atypes.RequestCheckTx) atypes.ResponseCheckTx {
+	if app.set[app.key(req.Tx)] {
+		return atypes.ResponseCheckTx{Code: 1, Log: "key already set"}
+	}
+	return app.Application.CheckTx(req)
+}
+
+func (app *onceApp) DeliverTx(req atypes.RequestDeliverTx) atypes.ResponseDeliverTx {
+	app.set[app.key(req.Tx)] = true
+	return app.Application.DeliverTx(req)
+}
+
+func TestMempoolRecheck(t *testing.T) {
+	for _, test := range []struct {
+		recheck string
+		size    int
+	}{
+		{recheck: mempoolRecheckSync, size: 0},
+		{recheck: mempoolRecheckOff, size: 1},
+	} {
+		t.Run(test.recheck, func(t *testing.T) {
+			dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+			configBytes := []byte(fmt.Sprintf(`{"mempoolRecheck": %q}`, test.recheck))
+			app := &onceApp{Application: kvstore.NewApplication(), set: make(map[string]bool)}
+			vm, _, _, err := newTestVMWithDB(app, dbManager, configBytes)
+			require.NoError(t, err)
+			service := NewService(vm)
+			ctx := context.Background()
+
+			reply := new(ctypes.ResultBroadcastTx)
+			require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, reply))
+			blk, err := vm.BuildBlock(ctx)
+			require.NoError(t, err)
+			// a tx setting the same key is valid until the block is accepted
+			require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=2")}, reply))
+			require.Equal(t, atypes.CodeTypeOK, reply.Code)
+
+			require.NoError(t, blk.Accept(ctx))
+			assert.Equal(t, test.size, vm.mempool.Size())
+		})
+	}
+}
+
+func TestMempoolRecheckBatch(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	configBytes := []byte(`{"mempoolRecheck":"sync","mempoolRecheckBatchSize":1}`)
+	app := &onceApp{Application: kvstore.NewApplication(), set: make(map[string]bool)}
+	vm, _, _, err := newTestVMWithDB(app, dbManager, configBytes)
+	require.NoError(t, err)
+	service := NewService(vm)
+	ctx := context.Background()
+
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, reply))
+	blk, err := vm.BuildBlock(ctx)
+	require.NoError(t, err)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=2")}, reply))
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=3")}, reply))
+
+	// only the first tx is rechecked after the block
+	require.NoError(t, blk.Accept(ctx))
+	assert.Equal(t, types.Txs{types.Tx("a=3")}, vm.mempool.ReapMaxTxs(-1))
+
+	// and the next one after the next block
+	vm.mempool.Lock()
+	require.NoError(t, vm.mempool.Update(2, nil, nil, nil, nil))
+	require.NoError(t, vm.mempool.FlushAppConn())
+	vm.mempool.Unlock()
+	assert.Zero(t, vm.mempool.Size())
+}
+
+// priorityApp is a kvstore app whose txs have the priority of their value,
+// e.g. "a=3" has priority 3.
+type priorityApp struct {
+	*kvstore.Application
+}
+
+func (app *priorityApp) CheckTx(req atypes.RequestCheckTx) atypes.ResponseCheckTx {
+	res := app.Application.CheckTx(req)
+	if parts := bytes.SplitN(req.Tx, []byte("="), 2); len(parts) == 2 {
+		res.Priority, _ = strconv.ParseInt(string(parts[1]), 10, 64)
+	}
+	return res
+}
+
+func TestMempoolPriority(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	app := &priorityApp{Application: kvstore.NewApplication()}
+	vm, _, _, err := newTestVMWithDB(app, dbManager, []byte(`{"mempoolSize":3}`))
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	assertMempool := func(txs ...string) {
+		t.Helper()
+		expected := make(types.Txs, len(txs))
+		for i, tx := range txs {
+			expected[i] = types.Tx(tx)
+		}
+		assert.Equal(t, expected, vm.mempool.ReapMaxTxs(-1))
+	}
+	broadcast := func(tx string) {
+		t.Helper()
+		reply := new(ctypes.ResultBroadcastTx)
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte(tx)}, reply))
+		require.Equal(t, atypes.CodeTypeOK, reply.Code)
+	}
+
+	// reaped by priority, then in arrival order
+	broadcast("a=1")
+	broadcast("b=2")
+	broadcast("c=2")
+	assertMempool("b=2", "c=2", "a=1")
+
+	// a full mempool evicts the lowest priority tx
+	broadcast("d=3")
+	assertMempool("d=3", "b=2", "c=2")
+
+	// but not txs of the same or a higher priority, the tx being rejected
+	for _, tx := range []string{"e=0", "f=2"} {
+		reply := new(ctypes.ResultBroadcastTx)
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte(tx)}, reply))
+		assert.Equal(t, mempl.CodeTypeMempoolFull, reply.Code)
+		assert.Equal(t, mempl.Codespace, reply.Codespace)
+	}
+	assertMempool("d=3", "b=2", "c=2")
+
+	// and, among equal priorities, the newest tx first
+	broadcast("g=5")
+	assertMempool("g=5", "d=3", "b=2")
+}
+
+// sequenceApp is a kvstore app whose txs "sender/sequence/priority" report
+// their sender, sequence and priority from CheckTx, and the txs
+// "sender/sequence/priority/conflict" a conflict key as well.
+type sequenceApp struct {
+	*kvstore.Application
+}
+
+func (app *sequenceApp) CheckTx(req atypes.RequestCheckTx) atypes.ResponseCheckTx {
+	res := app.Application.CheckTx(req)
+	parts := bytes.Split(req.Tx, []byte("/"))
+	res.Priority, _ = strconv.ParseInt(string(parts[2]), 10, 64)
+	res.Events = []atypes.Event{{
+		Type: "tx",
+		Attributes: []atypes.EventAttribute{
+			{Key: []byte(mempl.SenderAttributeKey), Value: parts[0]},
+			{Key: []byte(mempl.SequenceAttributeKey), Value: parts[1]},
+		},
+	}}
+	if len(parts) > 3 {
+		res.Events[0].Attributes = append(res.Events[0].Attributes,
+			atypes.EventAttribute{Key: []byte(mempl.ConflictAttributeKey), Value: parts[3]})
+	}
+	return res
+}
+
+func TestMempoolSequences(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	vm, _, _, err := newTestVMWithDB(&sequenceApp{Application: kvstore.NewApplication()}, dbManager, nil)
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	broadcast := func(tx string) *ctypes.ResultBroadcastTx {
+		t.Helper()
+		reply := new(ctypes.ResultBroadcastTx)
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte(tx)}, reply))
+		return reply
+	}
+	assertMempool := func(txs ...string) {
+		t.Helper()
+		expected := make(types.Txs, len(txs))
+		for i, tx := range txs {
+			expected[i] = types.Tx(tx)
+		}
+		assert.Equal(t, expected, vm.mempool.ReapMaxTxs(-1))
+	}
+
+	// the txs of a sender are reaped in the order of their sequences
+	broadcast("alice/1/1")
+	broadcast("alice/2/5")
+	broadcast("bob/1/3")
+	assertMempool("alice/1/1", "bob/1/3", "alice/2/5")
+
+	// a tx of the same sender and sequence needs a higher priority
+	reply := broadcast("alice/1/0")
+	assert.Equal(t, mempl.CodeTypeReplacementUnderpriced, reply.Code)
+	assert.Equal(t, mempl.Codespace, reply.Codespace)
+	assertMempool("alice/1/1", "bob/1/3", "alice/2/5")
+
+	// to replace it, e.g. to speed it up
+	reply = broadcast("alice/1/4")
+	assert.Equal(t, atypes.CodeTypeOK, reply.Code)
+	assertMempool("alice/1/4", "alice/2/5", "bob/1/3")
+}
+
+func TestMempoolReplacementFull(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	vm, _, _, err := newTestVMWithDB(&sequenceApp{Application: kvstore.NewApplication()}, dbManager,
+		[]byte(`{"mempoolMaxTxsBytes":20,"mempoolMaxTxBytes":20}`))
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	broadcast := func(tx string) uint32 {
+		t.Helper()
+		reply := new(ctypes.ResultBroadcastTx)
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte(tx)}, reply))
+		return reply.Code
+	}
+
+	require.Equal(t, atypes.CodeTypeOK, broadcast("alice/1/9"))
+	require.Equal(t, atypes.CodeTypeOK, broadcast("bob/1/20"))
+
+	// a replacement which doesn't fit, even in the room of the tx it replaces,
+	// leaves the tx in the mempool
+	assert.Equal(t, mempl.CodeTypeMempoolFull, broadcast("alice/1/10/abcd"))
+	assert.Equal(t, types.Txs{types.Tx("bob/1/20"), types.Tx("alice/1/9")}, vm.mempool.ReapMaxTxs(-1))
+
+	// while one fitting in its room replaces it
+	assert.Equal(t, atypes.CodeTypeOK, broadcast("alice/1/10"))
+	assert.Equal(t, types.Txs{types.Tx("bob/1/20"), types.Tx("alice/1/10")}, vm.mempool.ReapMaxTxs(-1))
+}
+
+func TestMempoolOriginLimits(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	vm, _, _, err := newTestVMWithDB(kvstore.NewApplication(), dbManager, []byte(`{"mempoolOriginMaxTxs":1}`))
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	broadcast := func(remoteAddr string, tx string) error {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.RemoteAddr = remoteAddr
+		return service.BroadcastTxSync(req, &BroadcastTxArgs{Tx: []byte(tx)}, new(ctypes.ResultBroadcastTx))
+	}
+
+	require.NoError(t, broadcast("10.0.0.1:1000", "a=1"))
+	// the client is limited by its address, whatever its port
+	err = broadcast("10.0.0.1:1001", "b=1")
+	var txErr *txError
+	require.ErrorAs(t, err, &txErr)
+	assert.Equal(t, ErrCodeOriginLimited, txErr.code)
+
+	require.NoError(t, broadcast("10.0.0.2:1000", "b=1"))
+	assert.Equal(t, 2, vm.mempool.Size())
+}
+
+// laneApp is a kvstore app putting the txs prefixed with "oracle/" in the
+// oracle lane.
+type laneApp struct {
+	*kvstore.Application
+}
+
+func (app *laneApp) CheckTx(req atypes.RequestCheckTx) atypes.ResponseCheckTx {
+	res := app.Application.CheckTx(req)
+	if bytes.HasPrefix(req.Tx, []byte("oracle/")) {
+		res.Events = []atypes.Event{{
+			Type:       "tx",
+			Attributes: []atypes.EventAttribute{{Key: []byte(mempl.LaneAttributeKey), Value: []byte("oracle")}},
+		}}
+	}
+	return res
+}
+
+func TestMempoolLanes(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	vm, _, _, err := newTestVMWithDB(&laneApp{Application: kvstore.NewApplication()}, dbManager,
+		[]byte(`{"mempoolSize":1,"mempoolLanes":[{"name":"oracle","size":1,"maxTxsBytes":1024,"blockShare":0.2}]}`))
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	broadcast := func(tx string) uint32 {
+		t.Helper()
+		reply := new(ctypes.ResultBroadcastTx)
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte(tx)}, reply))
+		return reply.Code
+	}
+
+	// the user txs filling the mempool don't crowd out the oracle txs
+	require.Equal(t, atypes.CodeTypeOK, broadcast("a=1"))
+	assert.Equal(t, mempl.CodeTypeMempoolFull, broadcast("b=1"))
+	require.Equal(t, atypes.CodeTypeOK, broadcast("oracle/a=1"))
+	assert.Equal(t, mempl.CodeTypeMempoolFull, broadcast("oracle/b=1"))
+	assert.Equal(t, 2, vm.mempool.Size())
+
+	// lanes must be valid
+	_, _, _, err = newTestVMWithDB(&laneApp{Application: kvstore.NewApplication()},
+		manager.NewMemDB(&version.Semantic{Major: 1}),
+		[]byte(`{"mempoolLanes":[{"name":"a","size":1,"maxTxsBytes":1,"blockShare":0.6},{"name":"b","size":1,"maxTxsBytes":1,"blockShare":0.6}]}`))
+	assert.ErrorContains(t, err, "blockShares add up")
+}
+
+func TestMempoolConflicts(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	vm, _, _, err := newTestVMWithDB(&sequenceApp{Application: kvstore.NewApplication()}, dbManager, nil)
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	broadcast := func(tx string) uint32 {
+		t.Helper()
+		reply := new(ctypes.ResultBroadcastTx)
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte(tx)}, reply))
+		return reply.Code
+	}
+
+	require.Equal(t, atypes.CodeTypeOK, broadcast("alice/1/2/coin"))
+	require.Equal(t, atypes.CodeTypeOK, broadcast("bob/1/1/other"))
+
+	// a tx spending the same coin needs a higher priority
+	assert.Equal(t, mempl.CodeTypeReplacementUnderpriced, broadcast("carol/1/2/coin"))
+	assert.Equal(t, atypes.CodeTypeOK, broadcast("carol/1/3/coin"))
+	assert.Equal(t, types.Txs{types.Tx("carol/1/3/coin"), types.Tx("bob/1/1/other")}, vm.mempool.ReapMaxTxs(-1))
+
+	// than all the txs it conflicts with
+	assert.Equal(t, mempl.CodeTypeReplacementUnderpriced, broadcast("bob/1/2/coin"))
+	assert.Equal(t, atypes.CodeTypeOK, broadcast("bob/1/4/coin"))
+	assert.Equal(t, types.Txs{types.Tx("bob/1/4/coin")}, vm.mempool.ReapMaxTxs(-1))
+}
+
+func TestReapStrategies(t *testing.T) {
+	for strategy, expected := range map[string][]string{
+		reapStrategyFIFO:       {"alice/1/1", "alice/2/2", "alice/3/3", "bob/1/5", "carol/1/4"},
+		reapStrategyPriority:   {"bob/1/5", "carol/1/4", "alice/1/1", "alice/2/2", "alice/3/3"},
+		reapStrategyRoundRobin: {"alice/1/1", "bob/1/5", "carol/1/4", "alice/2/2", "alice/3/3"},
+	} {
+		t.Run(strategy, func(t *testing.T) {
+			dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+			configBytes := []byte(fmt.Sprintf(`{"reapStrategy":%q}`, strategy))
+			vm, _, _, err := newTestVMWithDB(&sequenceApp{Application: kvstore.NewApplication()}, dbManager, configBytes)
+			require.NoError(t, err)
+			service := NewService(vm)
+
+			reply := new(ctypes.ResultBroadcastTx)
+			for _, tx := range []string{"alice/1/1", "alice/2/2", "alice/3/3", "bob/1/5", "carol/1/4"} {
+				require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte(tx)}, reply))
+			}
+			blk, err := vm.BuildBlock(context.Background())
+			require.NoError(t, err)
+			txs := blk.(*chain.BlockWrapper).Block.(*Block).tmBlock.Txs
+			require.Len(t, txs, len(expected))
+			for i, tx := range expected {
+				assert.Equal(t, tx, string(txs[i]))
+			}
+		})
+	}
+}
+
+func TestMempoolEvictionEvents(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	app := &priorityApp{Application: kvstore.NewApplication()}
+	vm, _, _, err := newTestVMWithDB(app, dbManager, []byte(`{"mempoolSize":1}`))
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	// the client follows its tx by its hash
+	tx := types.Tx("a=1")
+	q := tmquery.MustParse(fmt.Sprintf("%s='%X'", types.TxHashKey, tx.Hash()))
+	sub, err := vm.eventBus.Subscribe(context.Background(), "test", q, 1)
+	require.NoError(t, err)
+
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: tx}, reply))
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("b=2")}, reply))
+	require.Equal(t, atypes.CodeTypeOK, reply.Code)
+
+	select {
+	case msg := <-sub.Out():
+		assert.Equal(t, types.EventDataTxEvicted{Tx: tx, Reason: mempl.EvictedLowPriority}, msg.Data())
+		assert.Equal(t, []string{types.EventTxEvicted}, msg.Events()[types.EventTypeKey])
+	case <-time.After(time.Second):
+		t.Fatal("no eviction event")
+	}
+}
+
+func TestMempoolTTL(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	vm, _, _, err := newTestVMWithDB(kvstore.NewApplication(), dbManager, []byte(`{"mempoolTTLDuration":"10ms"}`))
+	require.NoError(t, err)
+	service := NewService(vm)
+	ctx := context.Background()
+
+	sub, err := vm.eventBus.Subscribe(ctx, "test", types.EventQueryTxExpired, 1)
+	require.NoError(t, err)
+
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, reply))
+	blk, err := vm.BuildBlock(ctx)
+	require.NoError(t, err)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("b=2")}, reply))
+
+	// the tx left in the mempool expires once the block is accepted
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, blk.Accept(ctx))
+	assert.Zero(t, vm.mempool.Size())
+
+	select {
+	case msg := <-sub.Out():
+		assert.Equal(t, types.EventDataTxExpired{Tx: types.Tx("b=2"), Height: 1}, msg.Data())
+	case <-time.After(time.Second):
+		t.Fatal("no expiration event")
+	}
+
+	// and can be submitted again
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("b=2")}, reply))
+	assert.Equal(t, 1, vm.mempool.Size())
+}
+
+func TestPersistMempoolPeriodically(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	configBytes := []byte(`{"persistMempool":true,"persistMempoolInterval":"10ms"}`)
+	vm, _, _, err := newTestVMWithDB(kvstore.NewApplication(), dbManager, configBytes)
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	savedTxs := func() [][]byte {
+		data, err := vm.stateDB.Get(mempoolTxsKey)
+		require.NoError(t, err)
+		var saved tmproto.Data
+		require.NoError(t, saved.Unmarshal(data))
+		return saved.Txs
+	}
+
+	// the txs are saved without a shutdown, so they survive a crash
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, reply))
+	require.Eventually(t, func() bool {
+		return len(savedTxs()) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []byte("a=1"), savedTxs()[0])
+
+	// and deleted once they are committed
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, blk.Accept(context.Background()))
+	require.Eventually(t, func() bool {
+		return len(savedTxs()) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, vm.Shutdown(context.Background()))
+}
+
+func TestMempoolLimits(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	configBytes := []byte(`{"mempoolMaxTxsBytes":8,"mempoolMaxTxBytes":4,"mempoolCacheSize":0}`)
+	vm, _, _, err := newTestVMWithDB(kvstore.NewApplication(), dbManager, configBytes)
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	reply := new(ctypes.ResultBroadcastTx)
+	err = service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("abcd=1")}, reply)
+	assert.ErrorAs(t, err, &mempl.ErrTxTooLarge{})
+	for _, tx := range []string{"a=1", "b=2", "c=3"} {
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte(tx)}, reply))
+	}
+	// the last tx doesn't fit
+	assert.EqualValues(t, 6, vm.mempool.TxsBytes())
+
+	// the metrics of the mempool are gathered with the ones of avalanchego
+	families, err := vm.ctx.Metrics.Gather()
+	require.NoError(t, err)
+	var size float64
+	for _, family := range families {
+		if family.GetName() == "mempool_size" {
+			size = family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	assert.EqualValues(t, 2, size)
+}
+
+func TestMempoolCacheTTL(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	vm, _, _, err := newTestVMWithDB(kvstore.NewApplication(), dbManager, []byte(`{"mempoolCacheTTL":"50ms"}`))
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	reply := new(ctypes.ResultBroadcastTx)
+	err = service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, reply)
+	require.ErrorIs(t, err, mempl.ErrTxInCache)
+
+	// the tx is checked again once it expired from the cache
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, reply))
+	assert.Equal(t, 1, vm.mempool.Size())
+}
+
+// countingApp is a kvstore app counting the txs it checked.
+type countingApp struct {
+	*kvstore.Application
+	checked int
+}
+
+func (app *countingApp) CheckTx(req atypes.RequestCheckTx) atypes.ResponseCheckTx {
+	app.checked++
+	return app.Application.CheckTx(req)
+}
+
+func TestMempoolFilters(t *testing.T) {
+	app := &countingApp{Application: kvstore.NewApplication()}
+	noKey := func(tx types.Tx) error {
+		if bytes.HasPrefix(tx, []byte("=")) {
+			return fmt.Errorf("tx has no key")
+		}
+		return nil
+	}
+	config := []byte(`{"mempoolBannedPrefixes":["7370616D"]}`) // "spam"
+	vm, _, _, err := newTestVMWithGenesis(app, manager.NewMemDB(&version.Semantic{Major: 1}), []byte(genesis), nil, config, WithTxFilters(noKey))
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	// the filtered txs don't reach the app
+	for tx, reason := range map[string]string{
+		"spam=1": "tx prefix 7370616D is banned",
+		"=1":     "tx has no key",
+	} {
+		reply := new(ctypes.ResultBroadcastTx)
+		assert.ErrorContains(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte(tx)}, reply), reason)
+	}
+	assert.Zero(t, app.checked)
+
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	assert.Equal(t, 1, app.checked)
+
+	_, _, _, err = newTestVMWithDB(kvstore.NewApplication(), manager.NewMemDB(&version.Semantic{Major: 1}), []byte(`{"mempoolBannedPrefixes":[""]}`))
+	assert.ErrorContains(t, err, "mempoolBannedPrefixes mustn't contain an empty prefix")
+}
+
+func TestBroadcastTxCanceled(t *testing.T) {
+	vm, service, _ := mustNewKVTestVm(t)
+
+	// broadcast a tx while the mempool is being updated
+	broadcast := func(req *http.Request) <-chan error {
+		errCh := make(chan error, 1)
+		go func() {
+			reply := new(ctypes.ResultBroadcastTx)
+			errCh <- service.BroadcastTxSync(req, &BroadcastTxArgs{Tx: []byte("a=1")}, reply)
+		}()
+		return errCh
+	}
+	awaitErr := func(errCh <-chan error) error {
+		select {
+		case err := <-errCh:
+			return err
+		case <-time.After(time.Second):
+			t.Fatal("BroadcastTxSync still waiting on the mempool")
+			return nil
+		}
+	}
+
+	vm.mempool.Lock()
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodPost, "/rpc", nil).WithContext(ctx)
+	errCh := broadcast(req)
+	cancel()
+	assert.ErrorIs(t, awaitErr(errCh), context.Canceled)
+
+	// the requests in flight end on shutdown
+	errCh = broadcast(nil)
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- vm.Shutdown(context.Background())
+	}()
+	assert.ErrorIs(t, awaitErr(errCh), context.Canceled)
+	vm.mempool.Unlock()
+	require.NoError(t, <-shutdownErr)
+	assert.Zero(t, vm.mempool.Size())
+}
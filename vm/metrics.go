@@ -0,0 +1,196 @@
+package vm
+
+import (
+	"time"
+
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+
+	mempl "github.com/consideritdone/landslidecore/mempool"
+)
+
+const (
+	vmMetricsPrefix      = "vm"
+	mempoolMetricsPrefix = "mempool"
+)
+
+// vmMetrics are the metrics of the blocks, txs and calls to the app of the
+// VM, exported with the metrics of avalanchego under the namespace of the
+// chain.
+type vmMetrics struct {
+	blocksBuilt    prometheus.Counter
+	blocksAccepted prometheus.Counter
+	blocksRejected prometheus.Counter
+	txsAccepted    prometheus.Counter
+	mempoolSize    prometheus.GaugeFunc
+	mempoolBytes   prometheus.GaugeFunc
+	// blockTxsLimit is the number of txs of the blocks built while the app
+	// is falling behind, 0 if unlimited.
+	blockTxsLimit prometheus.Gauge
+	// abciLatency is labeled with the ABCI method called.
+	abciLatency *prometheus.HistogramVec
+	// eventSinkErrors and eventSinkDeferrals are labeled with the type of the
+	// event sink.
+	eventSinkErrors    *prometheus.CounterVec
+	eventSinkDeferrals *prometheus.CounterVec
+}
+
+// newVMMetrics returns the metrics of [vm], along with gauges of its mempool,
+// which must be created before the metrics are gathered.
+func newVMMetrics(vm *VM, registerer prometheus.Registerer) (*vmMetrics, error) {
+	m := &vmMetrics{
+		blocksBuilt: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "blocks_built",
+			Help: "Number of blocks built.",
+		}),
+		blocksAccepted: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "blocks_accepted",
+			Help: "Number of blocks accepted.",
+		}),
+		blocksRejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "blocks_rejected",
+			Help: "Number of blocks rejected.",
+		}),
+		txsAccepted: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "txs_accepted",
+			Help: "Number of txs in the accepted blocks.",
+		}),
+		blockTxsLimit: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "block_txs_limit",
+			Help: "Number of txs of the blocks built while the app is falling behind, 0 if unlimited.",
+		}),
+		abciLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "abci_call_duration_seconds",
+			Help:    "Duration of the synchronous calls to the app, by ABCI method.",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 4, 10),
+		}, []string{"method"}),
+		eventSinkErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "event_sink_errors",
+			Help: "Number of failures of the event sinks to index the events of a block, by sink.",
+		}, []string{"sink"}),
+		eventSinkDeferrals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "event_sink_deferred_blocks",
+			Help: "Number of blocks left out of the queue of the event sink as it fell behind, and passed once it caught up, by sink.",
+		}, []string{"sink"}),
+		mempoolSize: prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "mempool_size",
+			Help: "Number of txs in the mempool.",
+		}, func() float64 {
+			return float64(vm.mempool.Size())
+		}),
+		mempoolBytes: prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "mempool_size_bytes",
+			Help: "Total size of the txs in the mempool.",
+		}, func() float64 {
+			return float64(vm.mempool.TxsBytes())
+		}),
+	}
+	for _, c := range []prometheus.Collector{
+		m.blocksBuilt, m.blocksAccepted, m.blocksRejected, m.txsAccepted,
+		m.mempoolSize, m.mempoolBytes, m.blockTxsLimit, m.abciLatency,
+		m.eventSinkErrors, m.eventSinkDeferrals,
+	} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// observeABCICall records the duration of a call to [method] which started
+// at [start]. m may be nil.
+func (m *vmMetrics) observeABCICall(method string, start time.Time) {
+	if m == nil {
+		return
+	}
+	m.abciLatency.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// eventSinkFailed counts a failure of [sink]. m may be nil.
+func (m *vmMetrics) eventSinkFailed(sink string) {
+	if m == nil {
+		return
+	}
+	m.eventSinkErrors.WithLabelValues(sink).Inc()
+}
+
+// eventSinkDeferred counts a block left out of the queue of [sink]. m may be
+// nil.
+func (m *vmMetrics) eventSinkDeferred(sink string) {
+	if m == nil {
+		return
+	}
+	m.eventSinkDeferrals.WithLabelValues(sink).Inc()
+}
+
+// newMempoolMetrics returns the metrics of the mempool, registered with
+// [registerer] rather than with the global registry of
+// mempl.PrometheusMetrics, which the chains of a node would share.
+func newMempoolMetrics(registerer prometheus.Registerer) (*mempl.Metrics, error) {
+	size := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "size",
+		Help: "Number of txs in the mempool.",
+	}, nil)
+	sizeBytes := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "size_bytes",
+		Help: "Total size of the txs in the mempool.",
+	}, nil)
+	txSizeBytes := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "tx_size_bytes",
+		Help:    "Sizes of the txs added to the mempool.",
+		Buckets: prometheus.ExponentialBuckets(1, 3, 17),
+	}, nil)
+	recheckDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "recheck_duration_seconds",
+		Help:    "Time taken to recheck the txs after a block.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 15),
+	}, nil)
+	txAge := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "tx_age_seconds",
+		Help:    "Time the txs spent in the mempool before their removal.",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 16),
+	}, nil)
+	counter := func(name, help string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, nil)
+	}
+	failedTxs := counter("failed_txs", "Number of txs the app rejected in CheckTx.")
+	recheckTimes := counter("recheck_times", "Number of txs rechecked.")
+	evictedTxs := counter("evicted_txs", "Number of txs evicted to make room for higher priority ones.")
+	expiredTxs := counter("expired_txs", "Number of txs evicted for outliving the TTL of the mempool.")
+	replacedTxs := counter("replaced_txs", "Number of txs replaced by a higher priority one conflicting with them.")
+	cacheHits := counter("cache_hits", "Number of txs rejected as already seen by the cache.")
+	cacheMisses := counter("cache_misses", "Number of txs not in the cache, which are checked.")
+	addedTxs := counter("added_txs", "Number of txs added to the mempool.")
+	invalidatedTxs := counter("invalidated_txs", "Number of txs removed as invalid by a recheck.")
+	rejectedTxs := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "rejected_txs",
+		Help: "Number of txs rejected, by reason.",
+	}, []string{"reason"})
+
+	for _, c := range []prometheus.Collector{
+		size, sizeBytes, txSizeBytes, recheckDuration, txAge, failedTxs, recheckTimes, evictedTxs, expiredTxs,
+		replacedTxs, cacheHits, cacheMisses, addedTxs, invalidatedTxs, rejectedTxs,
+	} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return &mempl.Metrics{
+		Size:         kitprometheus.NewGauge(size),
+		TxSizeBytes:  kitprometheus.NewHistogram(txSizeBytes),
+		FailedTxs:    kitprometheus.NewCounter(failedTxs),
+		RecheckTimes: kitprometheus.NewCounter(recheckTimes),
+		EvictedTxs:   kitprometheus.NewCounter(evictedTxs),
+		ExpiredTxs:   kitprometheus.NewCounter(expiredTxs),
+		ReplacedTxs:  kitprometheus.NewCounter(replacedTxs),
+		CacheHits:    kitprometheus.NewCounter(cacheHits),
+		CacheMisses:  kitprometheus.NewCounter(cacheMisses),
+
+		SizeBytes:              kitprometheus.NewGauge(sizeBytes),
+		AddedTxs:               kitprometheus.NewCounter(addedTxs),
+		RejectedTxs:            kitprometheus.NewCounter(rejectedTxs),
+		RecheckDurationSeconds: kitprometheus.NewHistogram(recheckDuration),
+		InvalidatedTxs:         kitprometheus.NewCounter(invalidatedTxs),
+		TxAgeSeconds:           kitprometheus.NewHistogram(txAge),
+	}, nil
+}
@@ -0,0 +1,79 @@
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mempl "github.com/consideritdone/landslidecore/mempool"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+)
+
+func TestVMMetrics(t *testing.T) {
+	vm, service, _ := mustNewKVTestVm(t)
+	ctx := context.Background()
+
+	mustAcceptBlock(t, vm, service, []byte("a=1"), []byte("b=2"))
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("c=3")}, reply))
+	require.Error(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("c=3")}, reply))
+	blk, err := vm.BuildBlock(ctx)
+	require.NoError(t, err)
+	require.NoError(t, blk.Reject(ctx))
+
+	assert.EqualValues(t, 2, testutil.ToFloat64(vm.metrics.blocksBuilt))
+	assert.EqualValues(t, 1, testutil.ToFloat64(vm.metrics.blocksAccepted))
+	assert.EqualValues(t, 1, testutil.ToFloat64(vm.metrics.blocksRejected))
+	assert.EqualValues(t, 2, testutil.ToFloat64(vm.metrics.txsAccepted))
+	// the tx of the rejected block is back in the mempool
+	assert.EqualValues(t, 1, testutil.ToFloat64(vm.metrics.mempoolSize))
+
+	// the metrics are gathered with the ones of avalanchego
+	families, err := vm.ctx.Metrics.Gather()
+	require.NoError(t, err)
+	names := make(map[string]bool)
+	for _, family := range families {
+		names[family.GetName()] = true
+	}
+	assert.True(t, names["vm_blocks_accepted"])
+	assert.True(t, names["vm_abci_call_duration_seconds"])
+	assert.True(t, names["vm_mempool_size"])
+	assert.True(t, names["mempool_size_bytes"])
+	assert.True(t, names["mempool_tx_age_seconds"])
+	assert.EqualValues(t, 3, gatheredValue(t, vm, "mempool_added_txs", ""))
+	// c=3 broadcast again, then returned by the rejected block while still
+	// in the mempool
+	assert.EqualValues(t, 2, mempoolRejectedTxs(t, vm, mempl.RejectedDuplicate))
+}
+
+// mempoolRejectedTxs returns the number of txs the mempool of [vm] rejected
+// for [reason].
+func mempoolRejectedTxs(t *testing.T, vm *VM, reason string) float64 {
+	return gatheredValue(t, vm, "mempool_rejected_txs", reason)
+}
+
+// gatheredValue returns the value of the counter [name] gathered from [vm],
+// labeled with [label] if not empty.
+func gatheredValue(t *testing.T, vm *VM, name, label string) float64 {
+	families, err := vm.ctx.Metrics.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetValue() == label {
+					return m.GetCounter().GetValue()
+				}
+			}
+			if label == "" && len(m.GetLabel()) == 0 {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
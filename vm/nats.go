@@ -0,0 +1,256 @@
+package vm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tmrand "github.com/consideritdone/landslidecore/libs/rand"
+)
+
+// natsTimeout bounds the connection to the NATS server and the wait for the
+// ack of a message, unless the context of Publish ends sooner.
+const natsTimeout = 10 * time.Second
+
+var _ StreamPublisher = (*natsPublisher)(nil)
+
+// natsPublisher is a StreamPublisher publishing to NATS JetStream over the
+// NATS client protocol: a message is acknowledged once a JetStream stream,
+// which must capture the topics as subjects, stored it. The key of a message
+// is its Nats-Msg-Id, so that JetStream drops the messages published again
+// within the duplicate window of the stream. It connects to the server again
+// after a failure.
+type natsPublisher struct {
+	addr string
+
+	mtx  sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+	// inbox is the subject prefix the acks are received on.
+	inbox  string
+	nextID uint64
+}
+
+func newNATSPublisher(addr string) *natsPublisher {
+	return &natsPublisher{addr: addr}
+}
+
+// Publish publishes [value] on the subject [topic] and waits for the ack of
+// JetStream.
+func (p *natsPublisher) Publish(ctx context.Context, topic string, key, value []byte) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	deadline := time.Now().Add(natsTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if p.conn == nil {
+		if err := p.connect(deadline); err != nil {
+			return fmt.Errorf("failed to connect to NATS at %s: %w", p.addr, err)
+		}
+	}
+	if err := p.publish(ctx, deadline, topic, key, value); err != nil {
+		p.close()
+		return err
+	}
+	return nil
+}
+
+// connect connects to the server and subscribes to the inbox.
+func (p *natsPublisher) connect(deadline time.Time) error {
+	conn, err := net.DialTimeout("tcp", p.addr, time.Until(deadline))
+	if err != nil {
+		return err
+	}
+	p.conn, p.r = conn, bufio.NewReader(conn)
+	p.inbox = "_INBOX." + tmrand.Str(22)
+	if err := p.handshake(deadline); err != nil {
+		p.close()
+		return err
+	}
+	return nil
+}
+
+func (p *natsPublisher) handshake(deadline time.Time) error {
+	if err := p.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	line, err := p.readLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("unexpected greeting %q", line)
+	}
+	connect := "CONNECT " +
+		`{"verbose":false,"pedantic":false,"headers":true,"no_responders":true,"name":"landslide"}` + "\r\n" +
+		"SUB " + p.inbox + ".* 1\r\n" +
+		"PING\r\n"
+	if _, err := io.WriteString(p.conn, connect); err != nil {
+		return err
+	}
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("server error: %s", line)
+		}
+	}
+}
+
+// publish sends the message and reads the replies of the server until the
+// ack of the message, answering its pings.
+func (p *natsPublisher) publish(ctx context.Context, deadline time.Time, topic string, key, value []byte) error {
+	if err := p.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	// the read is interrupted once ctx is done
+	conn := p.conn
+	published := make(chan struct{})
+	defer close(published)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetDeadline(time.Now())
+		case <-published:
+		}
+	}()
+
+	p.nextID++
+	reply := p.inbox + "." + strconv.FormatUint(p.nextID, 10)
+	header := fmt.Sprintf("NATS/1.0\r\nNats-Msg-Id: %s-%X\r\n\r\n", topic, key)
+	msg := fmt.Sprintf("HPUB %s %s %d %d\r\n%s", topic, reply, len(header), len(header)+len(value), header)
+	if _, err := io.WriteString(p.conn, msg); err != nil {
+		return p.interrupted(ctx, err)
+	}
+	if _, err := p.conn.Write(value); err != nil {
+		return p.interrupted(ctx, err)
+	}
+	if _, err := io.WriteString(p.conn, "\r\n"); err != nil {
+		return p.interrupted(ctx, err)
+	}
+
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return p.interrupted(ctx, err)
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "PING":
+			if _, err := io.WriteString(p.conn, "PONG\r\n"); err != nil {
+				return p.interrupted(ctx, err)
+			}
+		case "-ERR":
+			return fmt.Errorf("server error: %s", line)
+		case "MSG", "HMSG":
+			subject, header, payload, err := p.readMsg(fields)
+			if err != nil {
+				return p.interrupted(ctx, err)
+			}
+			// the late acks of the messages which timed out are skipped
+			if subject != reply {
+				continue
+			}
+			return jetStreamAck(header, payload)
+		}
+	}
+}
+
+// readMsg reads the header and payload of the MSG or HMSG whose protocol line
+// has [fields], and returns its subject.
+func (p *natsPublisher) readMsg(fields []string) (string, []byte, []byte, error) {
+	// MSG <subject> <sid> [reply-to] <#bytes>
+	// HMSG <subject> <sid> [reply-to] <#header bytes> <#total bytes>
+	minFields := 4
+	if fields[0] == "HMSG" {
+		minFields = 5
+	}
+	if len(fields) < minFields {
+		return "", nil, nil, fmt.Errorf("malformed %s", fields[0])
+	}
+	total, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || total < 0 {
+		return "", nil, nil, fmt.Errorf("malformed %s size", fields[0])
+	}
+	headerLen := 0
+	if fields[0] == "HMSG" {
+		headerLen, err = strconv.Atoi(fields[len(fields)-2])
+		if err != nil || headerLen < 0 || headerLen > total {
+			return "", nil, nil, fmt.Errorf("malformed %s header size", fields[0])
+		}
+	}
+	data := make([]byte, total+2)
+	if _, err := io.ReadFull(p.r, data); err != nil {
+		return "", nil, nil, err
+	}
+	return fields[1], data[:headerLen], data[headerLen:total], nil
+}
+
+// jetStreamAck returns the error of the ack of JetStream with [header] and
+// [payload], if any.
+func jetStreamAck(header, payload []byte) error {
+	// e.g. "NATS/1.0 503" when no stream captures the subject
+	if status := bytes.Fields(bytes.SplitN(header, []byte("\r\n"), 2)[0]); len(status) > 1 {
+		return fmt.Errorf("no ack from JetStream, status %s", status[1])
+	}
+	var ack struct {
+		Stream string `json:"stream"`
+		Error  *struct {
+			Code        int    `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(payload, &ack); err != nil {
+		return fmt.Errorf("malformed JetStream ack: %w", err)
+	}
+	if ack.Error != nil {
+		return fmt.Errorf("JetStream error %d: %s", ack.Error.Code, ack.Error.Description)
+	}
+	if ack.Stream == "" {
+		return errors.New("JetStream ack without a stream")
+	}
+	return nil
+}
+
+func (p *natsPublisher) readLine() (string, error) {
+	line, err := p.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// interrupted returns the error of ctx if it ended the publishing, [err]
+// otherwise.
+func (p *natsPublisher) interrupted(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+func (p *natsPublisher) close() {
+	if p.conn != nil {
+		_ = p.conn.Close()
+	}
+	p.conn, p.r = nil, nil
+}
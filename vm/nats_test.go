@@ -0,0 +1,142 @@
+package vm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	tmjson "github.com/consideritdone/landslidecore/libs/json"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+type natsMessage struct {
+	subject string
+	header  string
+	payload []byte
+}
+
+// fakeJetStream is a NATS server with a JetStream stream capturing every
+// subject, which answers the first [noResponders] messages as if no stream
+// captured them.
+type fakeJetStream struct {
+	listener net.Listener
+
+	mtx          sync.Mutex
+	noResponders int
+	messages     []natsMessage
+}
+
+func newFakeJetStream(t *testing.T, noResponders int) *fakeJetStream {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeJetStream{listener: listener, noResponders: noResponders}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeJetStream) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	fmt.Fprint(conn, "INFO {\"headers\":true}\r\n")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		case "HPUB":
+			// HPUB <subject> <reply> <#header bytes> <#total bytes>
+			headerLen, _ := strconv.Atoi(fields[3])
+			total, _ := strconv.Atoi(fields[4])
+			data := make([]byte, total+2)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return
+			}
+			s.mtx.Lock()
+			if s.noResponders > 0 {
+				s.noResponders--
+				s.mtx.Unlock()
+				status := "NATS/1.0 503\r\n\r\n"
+				fmt.Fprintf(conn, "HMSG %s 1 %d %d\r\n%s\r\n", fields[2], len(status), len(status), status)
+				continue
+			}
+			s.messages = append(s.messages, natsMessage{
+				subject: fields[1],
+				header:  string(data[:headerLen]),
+				payload: data[headerLen:total],
+			})
+			ack := fmt.Sprintf(`{"stream":"EVENTS","seq":%d}`, len(s.messages))
+			s.mtx.Unlock()
+			fmt.Fprintf(conn, "MSG %s 1 %d\r\n%s\r\n", fields[2], len(ack), ack)
+		}
+	}
+}
+
+func (s *fakeJetStream) stored() []natsMessage {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]natsMessage(nil), s.messages...)
+}
+
+func TestNATSEventStream(t *testing.T) {
+	server := newFakeJetStream(t, 1)
+	configBytes := fmt.Sprintf(`{"eventStreamNATS":"nats://%s","eventStreamTopicPrefix":"chain"}`, server.listener.Addr())
+	vm, _, _, err := newTestVMWithDB(kvstore.NewApplication(), manager.NewMemDB(version.Semantic1_0_0), []byte(configBytes))
+	require.NoError(t, err)
+
+	// the block is published again once a stream captures its subjects
+	mustAcceptBlock(t, vm, NewService(vm), []byte("a=1"))
+	require.Eventually(t, func() bool {
+		streamed, err := vm.streamedHeight()
+		return err == nil && streamed == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	messages := server.stored()
+	require.Len(t, messages, 2)
+
+	assert.Equal(t, "chain.blocks", messages[0].subject)
+	assert.Contains(t, messages[0].header, "Nats-Msg-Id: chain.blocks-0000000000000001\r\n")
+	var header types.EventDataNewBlockHeader
+	require.NoError(t, tmjson.Unmarshal(messages[0].payload, &header))
+	assert.Equal(t, int64(1), header.Header.Height)
+
+	assert.Equal(t, "chain.txs", messages[1].subject)
+	assert.Contains(t, messages[1].header, fmt.Sprintf("Nats-Msg-Id: chain.txs-%X\r\n", types.Tx("a=1").Hash()))
+	var tx StreamTx
+	require.NoError(t, tmjson.Unmarshal(messages[1].payload, &tx))
+	assert.Equal(t, types.Tx("a=1"), tx.Tx)
+}
+
+func TestNATSEventStreamConfig(t *testing.T) {
+	config := DefaultConfig()
+	config.EventStreamNATS = "http://localhost:4222"
+	assert.Error(t, config.Validate())
+	config.EventStreamNATS = "nats://localhost:4222"
+	assert.NoError(t, config.Validate())
+}
@@ -0,0 +1,253 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/version"
+)
+
+type (
+	// appRequest is the envelope of the app requests between VMs. Exactly one
+	// of its fields is set.
+	appRequest struct {
+		Chunk *chunkRequest `json:"chunk,omitempty"`
+		Txs   *txsRequest   `json:"txs,omitempty"`
+	}
+
+	// appGossipMsg is the envelope of the app gossip between VMs.
+	appGossipMsg struct {
+		Txs *txsGossip `json:"txs,omitempty"`
+	}
+
+	// pendingRequest is an app request in flight.
+	pendingRequest struct {
+		peer  ids.NodeID
+		resCh chan []byte
+	}
+
+	// peerInfo is a connected peer. avalanchego doesn't tell the VMs the IPs
+	// of the peers.
+	peerInfo struct {
+		nodeID ids.NodeID
+		// version is the version of avalanchego the peer runs, nil if it
+		// isn't known.
+		version     *version.Application
+		connectedAt time.Time
+	}
+
+	// appNetwork tracks the connected peers and the app requests in flight,
+	// which the state sync and the tx fetch protocol share.
+	appNetwork struct {
+		mtx sync.Mutex
+		// peers are the connected peers, and peerIDs their IDs in ascending
+		// order.
+		peers     map[ids.NodeID]*peerInfo
+		peerIDs   []ids.NodeID
+		requests  map[uint32]pendingRequest
+		requestID uint32
+		// nextPeerIndex is the index in peerIDs of the next peer nextPeer
+		// returns.
+		nextPeerIndex int
+	}
+)
+
+func newAppNetwork() *appNetwork {
+	return &appNetwork{
+		peers:    make(map[ids.NodeID]*peerInfo),
+		requests: make(map[uint32]pendingRequest),
+	}
+}
+
+// connected records [nodeID] running [version] as connected.
+func (n *appNetwork) connected(nodeID ids.NodeID, version *version.Application) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	if peer, ok := n.peers[nodeID]; ok {
+		peer.version = version
+		return
+	}
+	n.peers[nodeID] = &peerInfo{nodeID: nodeID, version: version, connectedAt: time.Now()}
+	i := sort.Search(len(n.peerIDs), func(i int) bool { return nodeID.Less(n.peerIDs[i]) })
+	n.peerIDs = append(n.peerIDs, ids.EmptyNodeID)
+	copy(n.peerIDs[i+1:], n.peerIDs[i:])
+	n.peerIDs[i] = nodeID
+}
+
+// disconnected records [nodeID] as disconnected.
+func (n *appNetwork) disconnected(nodeID ids.NodeID) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	if _, ok := n.peers[nodeID]; !ok {
+		return
+	}
+	delete(n.peers, nodeID)
+	for i, peerID := range n.peerIDs {
+		if peerID == nodeID {
+			n.peerIDs = append(n.peerIDs[:i], n.peerIDs[i+1:]...)
+			break
+		}
+	}
+}
+
+// nextPeer returns a connected peer, the peers being returned in turn.
+func (n *appNetwork) nextPeer() (ids.NodeID, bool) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	if len(n.peerIDs) == 0 {
+		return ids.EmptyNodeID, false
+	}
+	n.nextPeerIndex %= len(n.peerIDs)
+	peer := n.peerIDs[n.nextPeerIndex]
+	n.nextPeerIndex++
+	return peer, true
+}
+
+// samplePeers returns up to [max] connected peers, picked at random.
+func (n *appNetwork) samplePeers(max int) set.Set[ids.NodeID] {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	if max > len(n.peerIDs) {
+		max = len(n.peerIDs)
+	}
+	sample := set.NewSet[ids.NodeID](max)
+	for _, i := range rand.Perm(len(n.peerIDs))[:max] {
+		sample.Add(n.peerIDs[i])
+	}
+	return sample
+}
+
+// peerInfos returns the connected peers, in the order of their IDs.
+func (n *appNetwork) peerInfos() []peerInfo {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	peers := make([]peerInfo, len(n.peerIDs))
+	for i, peerID := range n.peerIDs {
+		peers[i] = *n.peers[peerID]
+	}
+	return peers
+}
+
+// sendAppRequest sends [req] to [peer] and waits for its response until [ctx]
+// is done. The response is nil if the request failed.
+func (vm *VM) sendAppRequest(ctx context.Context, peer ids.NodeID, req appRequest) ([]byte, error) {
+	n := vm.network
+	n.mtx.Lock()
+	requestID := n.requestID
+	n.requestID++
+	resCh := make(chan []byte, 1)
+	n.requests[requestID] = pendingRequest{peer: peer, resCh: resCh}
+	n.mtx.Unlock()
+
+	defer func() {
+		n.mtx.Lock()
+		delete(n.requests, requestID)
+		n.mtx.Unlock()
+	}()
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	nodeIDs := set.NewSet[ids.NodeID](1)
+	nodeIDs.Add(peer)
+	if err := vm.appSender.SendAppRequest(ctx, nodeIDs, requestID, reqBytes); err != nil {
+		return nil, err
+	}
+
+	select {
+	case res := <-resCh:
+		return res, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("app request to %s: %w", peer, ctx.Err())
+	}
+}
+
+// AppRequest serves the snapshot chunk requests of state syncing peers, and
+// the tx requests of peers missing gossiped txs.
+func (vm *VM) AppRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, _ time.Time, request []byte) error {
+	var req appRequest
+	if err := json.Unmarshal(request, &req); err != nil {
+		vm.tmLogger.Debug("dropping malformed app request", "nodeID", nodeID, "err", err)
+		return nil
+	}
+	switch {
+	case req.Chunk != nil:
+		return vm.serveChunk(ctx, nodeID, requestID, req.Chunk)
+	case req.Txs != nil:
+		return vm.serveTxs(ctx, nodeID, requestID, req.Txs)
+	default:
+		vm.tmLogger.Debug("dropping unknown app request", "nodeID", nodeID)
+		return nil
+	}
+}
+
+// AppResponse delivers the response of [nodeID] to the pending request
+// [requestID].
+func (vm *VM) AppResponse(_ context.Context, nodeID ids.NodeID, requestID uint32, response []byte) error {
+	vm.responseReceived(nodeID, requestID, response)
+	return nil
+}
+
+func (vm *VM) AppRequestFailed(_ context.Context, nodeID ids.NodeID, requestID uint32) error {
+	vm.responseReceived(nodeID, requestID, nil)
+	return nil
+}
+
+// AppGossip handles the tx hashes announced by peers.
+func (vm *VM) AppGossip(_ context.Context, nodeID ids.NodeID, msg []byte) error {
+	var gossip appGossipMsg
+	if err := json.Unmarshal(msg, &gossip); err != nil {
+		vm.tmLogger.Debug("dropping malformed app gossip", "nodeID", nodeID, "err", err)
+		return nil
+	}
+	if gossip.Txs != nil {
+		vm.txsGossiped(nodeID, gossip.Txs)
+	}
+	return nil
+}
+
+// responseReceived delivers the [response] of [nodeID], nil if the request
+// failed, to the pending request [requestID].
+func (vm *VM) responseReceived(nodeID ids.NodeID, requestID uint32, response []byte) {
+	vm.network.mtx.Lock()
+	pending, ok := vm.network.requests[requestID]
+	vm.network.mtx.Unlock()
+	if !ok || pending.peer != nodeID {
+		return
+	}
+	select {
+	case pending.resCh <- response:
+	default:
+	}
+}
+
+// Connected records [nodeID], which runs [nodeVersion], as a peer the app
+// requests and gossip are sent to.
+func (vm *VM) Connected(_ context.Context, nodeID ids.NodeID, nodeVersion *version.Application) error {
+	if nodeID == vm.ctx.NodeID {
+		return nil
+	}
+	vm.network.connected(nodeID, nodeVersion)
+	vm.tmLogger.Debug("peer connected", "nodeID", nodeID, "version", nodeVersion)
+	return nil
+}
+
+// Disconnected stops sending app requests and gossip to [nodeID].
+func (vm *VM) Disconnected(_ context.Context, nodeID ids.NodeID) error {
+	vm.network.disconnected(nodeID)
+	vm.tmLogger.Debug("peer disconnected", "nodeID", nodeID)
+	return nil
+}
@@ -0,0 +1,45 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppNetworkPeers(t *testing.T) {
+	n := newAppNetwork()
+	_, ok := n.nextPeer()
+	assert.False(t, ok)
+
+	peerIDs := []ids.NodeID{{3}, {1}, {2}}
+	for _, peerID := range peerIDs {
+		n.connected(peerID, nil)
+	}
+	// a reconnected peer is recorded once, with its new version
+	n.connected(ids.NodeID{2}, version.CurrentApp)
+	peers := n.peerInfos()
+	require.Len(t, peers, 3)
+	assert.Equal(t, ids.NodeID{1}, peers[0].nodeID)
+	assert.Equal(t, version.CurrentApp, peers[1].version)
+
+	// the peers are returned in turn
+	for _, expected := range []ids.NodeID{{1}, {2}, {3}, {1}} {
+		peer, ok := n.nextPeer()
+		require.True(t, ok)
+		assert.Equal(t, expected, peer)
+	}
+
+	assert.Equal(t, 2, n.samplePeers(2).Len())
+	assert.Equal(t, 3, n.samplePeers(10).Len())
+
+	n.disconnected(ids.NodeID{2})
+	n.disconnected(ids.NodeID{4})
+	assert.Len(t, n.peerInfos(), 2)
+	for _, expected := range []ids.NodeID{{3}, {1}} {
+		peer, _ := n.nextPeer()
+		assert.Equal(t, expected, peer)
+	}
+}
@@ -0,0 +1,107 @@
+package vm
+
+import (
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/timer/mockable"
+
+	"github.com/consideritdone/landslidecore/libs/log"
+	mempl "github.com/consideritdone/landslidecore/mempool"
+	"github.com/consideritdone/landslidecore/proxy"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// Option configures a VM created with NewVM, for the projects embedding
+// Landslide as a library.
+type Option func(*VM)
+
+// WithConfig sets the configuration the config bytes given to Initialize
+// are applied on top of, instead of DefaultConfig.
+func WithConfig(config Config) Option {
+	return func(vm *VM) {
+		vm.baseConfig = &config
+	}
+}
+
+// WithClientCreator sets the ClientCreator of the ABCI connections to the
+// app, e.g. to wrap the app or to connect to it over a custom transport. It
+// takes precedence over the in-process app and the proxyApp address of the
+// config.
+func WithClientCreator(clientCreator proxy.ClientCreator) Option {
+	return func(vm *VM) {
+		vm.clientCreator = clientCreator
+	}
+}
+
+// WithLogger sets the logger of the VM, instead of the logger of the chain
+// given to Initialize. The logLevel of the config still applies to it.
+func WithLogger(logger log.Logger) Option {
+	return func(vm *VM) {
+		vm.logger = logger
+	}
+}
+
+// WithClock sets the clock the time of the blocks is built and checked
+// against, which tests may fake.
+func WithClock(clock *mockable.Clock) Option {
+	return func(vm *VM) {
+		vm.clock = clock
+	}
+}
+
+// WithDatabaseWrapper wraps the database of the chain given to Initialize,
+// e.g. to instrument or cache it, before the VM stores anything in it.
+func WithDatabaseWrapper(wrap func(database.Database) database.Database) Option {
+	return func(vm *VM) {
+		vm.wrapDB = wrap
+	}
+}
+
+// WithEventSinks adds [sinks] receiving the events of the accepted blocks.
+func WithEventSinks(sinks ...EventSink) Option {
+	return func(vm *VM) {
+		vm.eventSinks = append(vm.eventSinks, sinks...)
+	}
+}
+
+// WithEventStream publishes the events of the accepted blocks with
+// [publisher], e.g. to Kafka or NATS, on the StreamBlocksTopic and
+// StreamTxsTopic after [topicPrefix] and a dot, if not empty. Unlike the
+// event sinks, no block is missed: the stream resumes after the last block
+// the broker acknowledged, across restarts. It takes precedence over the
+// eventStreamNATS config.
+func WithEventStream(publisher StreamPublisher, topicPrefix string) Option {
+	return func(vm *VM) {
+		vm.eventStream = newEventStream(publisher, topicPrefix)
+	}
+}
+
+// WithTxSender sets the function returning the sender of a tx before the app
+// checked it, so that the txs of a sender are checked in the order they were
+// submitted when they are spread over the abciCheckTxConnections, which
+// require it.
+func WithTxSender(sender func(tx types.Tx) string) Option {
+	return func(vm *VM) {
+		vm.txSender = sender
+	}
+}
+
+// WithTxFilters adds [filters] rejecting the txs submitted to the mempool
+// before the app checks them, e.g. those which can't be decoded, so that they
+// don't cost the app anything. A rejected tx fails with the error of the
+// filter.
+func WithTxFilters(filters ...mempl.PreCheckFunc) Option {
+	return func(vm *VM) {
+		vm.txFilters = append(vm.txFilters, filters...)
+	}
+}
+
+// WithAttestationKey sets the BLS key signing the attestations of the
+// attestCommits option, e.g. the node's key read from its signer key file,
+// which avalanchego doesn't give the chain. The attestations are
+// domain-separated, so they can't be replayed as warp messages.
+func WithAttestationKey(sk *bls.SecretKey) Option {
+	return func(vm *VM) {
+		vm.attestationKey = sk
+	}
+}
@@ -0,0 +1,57 @@
+package vm
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	tmbytes "github.com/consideritdone/landslidecore/libs/bytes"
+)
+
+type (
+	ProposerArgs struct {
+		Height *int64 `json:"height"`
+	}
+
+	// ProposerReply is the proposer of a block: the node which built it, and
+	// the address it is known by to the app.
+	ProposerReply struct {
+		Height  int64            `json:"height"`
+		Address tmbytes.HexBytes `json:"address"`
+		NodeID  ids.NodeID       `json:"nodeId"`
+	}
+
+	ProposerService interface {
+		Proposer(_ *http.Request, args *ProposerArgs, reply *ProposerReply) error
+	}
+)
+
+// proposerAddressOf returns the proposer address of the blocks built by
+// [nodeID]. Node IDs and Tendermint addresses are both 20 bytes, so the
+// address is the node ID itself, and apps can map it back.
+func proposerAddressOf(nodeID ids.NodeID) []byte {
+	return nodeID.Bytes()
+}
+
+// Proposer returns the node which built the block at [args.Height], the
+// latest one if it isn't set. The blocks built before proposer addresses were
+// derived from node IDs have the empty node ID.
+func (s *LocalService) Proposer(_ *http.Request, args *ProposerArgs, reply *ProposerReply) error {
+	height, err := getHeight(s.vm.blockStore, args.Height)
+	if err != nil {
+		return err
+	}
+	blockMeta := s.vm.blockStore.LoadBlockMeta(height)
+	if blockMeta == nil {
+		return fmt.Errorf("block at height %d not found", height)
+	}
+	nodeID, err := ids.ToNodeID(blockMeta.Header.ProposerAddress)
+	if err != nil {
+		return err
+	}
+	reply.Height = height
+	reply.Address = blockMeta.Header.ProposerAddress
+	reply.NodeID = nodeID
+	return nil
+}
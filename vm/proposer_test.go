@@ -0,0 +1,28 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+)
+
+func TestProposer(t *testing.T) {
+	vm, service, _ := mustNewKVTestVm(t)
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+
+	reply := new(ProposerReply)
+	require.NoError(t, service.Proposer(nil, &ProposerArgs{}, reply))
+	assert.EqualValues(t, 1, reply.Height)
+	assert.Equal(t, vm.ctx.NodeID, reply.NodeID)
+	assert.EqualValues(t, vm.ctx.NodeID.Bytes(), reply.Address)
+
+	unknownHeight := int64(2)
+	assert.Error(t, service.Proposer(nil, &ProposerArgs{Height: &unknownHeight}, reply))
+
+	status := new(ctypes.ResultStatus)
+	require.NoError(t, service.Status(nil, nil, status))
+	assert.EqualValues(t, vm.ctx.NodeID.Bytes(), status.ValidatorInfo.Address)
+}
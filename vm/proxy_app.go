@@ -0,0 +1,168 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	abcicli "github.com/consideritdone/landslidecore/abci/client"
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	"github.com/consideritdone/landslidecore/libs/log"
+	tmnet "github.com/consideritdone/landslidecore/libs/net"
+	"github.com/consideritdone/landslidecore/libs/service"
+	tmsync "github.com/consideritdone/landslidecore/libs/sync"
+	"github.com/consideritdone/landslidecore/proxy"
+)
+
+const (
+	proxyAppMinBackoff = 100 * time.Millisecond
+	proxyAppMaxBackoff = 5 * time.Second
+)
+
+var (
+	errNoApp      = errors.New("no in-process app, and no proxyApp address to connect to")
+	errNoTxSender = errors.New("abciCheckTxConnections requires the tx sender to be set, see WithTxSender")
+)
+
+// newClientCreator returns the ClientCreator of the ABCI connections to the
+// app: the one set by WithClientCreator, or the in-process app the VM was
+// created with, unless the config sets the address of an out-of-process app.
+func (vm *VM) newClientCreator() (proxy.ClientCreator, error) {
+	if vm.clientCreator != nil {
+		return vm.clientCreator, nil
+	}
+	if vm.config.ProxyApp == "" {
+		if vm.app == nil {
+			return nil, errNoApp
+		}
+		return proxy.NewLocalClientCreator(vm.app), nil
+	}
+	vm.remoteApp = &remoteClientCreator{
+		addr:        vm.config.ProxyApp,
+		transport:   vm.config.ABCITransport,
+		dialTimeout: vm.config.ProxyAppDialTimeout.Duration,
+		logger:      vm.tmLogger.With("module", "proxy"),
+		onReconnect: vm.appReconnected,
+	}
+	return vm.remoteApp, nil
+}
+
+// newCheckTxClientCreator returns the ClientCreator of the checkTx
+// connections, see newCheckTxPool: [clientCreator], unless the app is the
+// in-process one, whose checkTx connections don't share the lock of the other
+// connections, so that it checks txs in parallel.
+func (vm *VM) newCheckTxClientCreator(clientCreator proxy.ClientCreator) proxy.ClientCreator {
+	if vm.clientCreator != nil || vm.config.ProxyApp != "" {
+		return clientCreator
+	}
+	return &concurrentLocalClientCreator{app: vm.app}
+}
+
+// concurrentLocalClientCreator creates clients of the in-process app, each
+// with its own lock.
+type concurrentLocalClientCreator struct {
+	app abci.Application
+}
+
+func (c *concurrentLocalClientCreator) NewABCIClient() (abcicli.Client, error) {
+	return abcicli.NewLocalClient(new(tmsync.Mutex), c.app), nil
+}
+
+// remoteClientCreator creates clients of an out-of-process app. Unlike
+// proxy.NewRemoteClientCreator, which either fails on the first attempt or
+// retries forever, it waits for the app to listen with an exponential
+// backoff, up to [dialTimeout].
+//
+// Once connected, a lost connection is supervised by supervisedClient, which
+// connects to the app again rather than terminating the process as in
+// Tendermint, and [onReconnect] is called once it is connected again.
+type remoteClientCreator struct {
+	addr        string
+	transport   string
+	dialTimeout time.Duration
+	logger      log.Logger
+	onReconnect func()
+
+	mtx     sync.Mutex
+	clients []*supervisedClient
+}
+
+func (c *remoteClientCreator) NewABCIClient() (abcicli.Client, error) {
+	if err := c.waitForApp(); err != nil {
+		return nil, err
+	}
+	client := &supervisedClient{creator: c}
+	client.BaseService = *service.NewBaseService(nil, "supervisedClient", client)
+
+	c.mtx.Lock()
+	c.clients = append(c.clients, client)
+	c.mtx.Unlock()
+	return client, nil
+}
+
+// disconnected returns the number of connections to the app which are lost.
+func (c *remoteClientCreator) disconnected() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	var n int
+	for _, client := range c.clients {
+		if client.IsRunning() && !client.connected() {
+			n++
+		}
+	}
+	return n
+}
+
+// waitForApp dials the app until it accepts a connection.
+func (c *remoteClientCreator) waitForApp() error {
+	deadline := time.Now().Add(c.dialTimeout)
+	backoff := proxyAppMinBackoff
+	for {
+		conn, err := tmnet.Connect(c.addr)
+		if err == nil {
+			return conn.Close()
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return fmt.Errorf("failed to connect to the app at %s within %s: %w", c.addr, c.dialTimeout, err)
+		}
+		c.logger.Info("app not reachable, retrying", "addr", c.addr, "backoff", backoff, "err", err)
+		time.Sleep(backoff)
+		backoff = nextBackoff(backoff)
+	}
+}
+
+// nextBackoff doubles [backoff], up to proxyAppMaxBackoff.
+func nextBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > proxyAppMaxBackoff {
+		return proxyAppMaxBackoff
+	}
+	return backoff
+}
+
+// appReconnected replays the blocks the app lost once all the connections to
+// it are back, e.g. the blocks it didn't persist before it crashed. The node
+// is reported unhealthy until it succeeds.
+func (vm *VM) appReconnected() {
+	if vm.remoteApp.disconnected() > 0 {
+		return
+	}
+
+	vm.mempool.Lock()
+	defer vm.mempool.Unlock()
+
+	err := vm.atomically(func() error {
+		return vm.doHandshake(vm.genesis, vm.tmLogger.With("module", "consensus"))
+	})
+	if err != nil {
+		vm.tmLogger.Error("failed to replay the blocks to the reconnected app", "err", err)
+	} else {
+		vm.tmLogger.Info("app reconnected and in sync")
+	}
+
+	vm.appSyncMtx.Lock()
+	vm.appSyncErr = err
+	vm.appSyncMtx.Unlock()
+}
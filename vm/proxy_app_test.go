@@ -0,0 +1,216 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	abciserver "github.com/consideritdone/landslidecore/abci/server"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+	"github.com/consideritdone/landslidecore/libs/log"
+	"github.com/consideritdone/landslidecore/libs/service"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+func TestProxyApp(t *testing.T) {
+	addr := "unix://" + filepath.Join(t.TempDir(), "app.sock")
+	config := []byte(fmt.Sprintf(`{"proxyApp":%q,"proxyAppDialTimeout":"200ms"}`, addr))
+	newDBManager := func() manager.Manager {
+		return manager.NewMemDB(&version.Semantic{Major: 1})
+	}
+
+	// the app isn't listening yet
+	_, _, _, err := newTestVMWithDB(nil, newDBManager(), config)
+	assert.ErrorContains(t, err, "failed to connect to the app")
+
+	server := abciserver.NewSocketServer(addr, kvstore.NewApplication())
+	server.SetLogger(log.TestingLogger())
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		_ = server.Stop()
+	})
+
+	vm, _, _, err := newTestVMWithDB(nil, newDBManager(), config)
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	mustAcceptBlock(t, vm, service, []byte("name=satoshi"))
+	reply := new(ctypes.ResultABCIQuery)
+	require.NoError(t, service.ABCIQuery(nil, &ABCIQueryArgs{Path: "/key", Data: []byte("name")}, reply))
+	assert.Equal(t, []byte("satoshi"), reply.Response.Value)
+
+	_, _, _, err = newTestVMWithDB(nil, newDBManager(), nil)
+	assert.ErrorIs(t, err, errNoApp)
+}
+
+func TestQueryConnections(t *testing.T) {
+	addr := "unix://" + filepath.Join(t.TempDir(), "app.sock")
+	server := abciserver.NewSocketServer(addr, kvstore.NewApplication())
+	server.SetLogger(log.TestingLogger())
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		_ = server.Stop()
+	})
+
+	config := []byte(fmt.Sprintf(`{"proxyApp":%q,"abciQueryConnections":3}`, addr))
+	vm, _, _, err := newTestVMWithDB(nil, manager.NewMemDB(&version.Semantic{Major: 1}), config)
+	require.NoError(t, err)
+	service := NewService(vm)
+	// the 4 connections of the AppConns and 2 more query connections
+	assert.Len(t, vm.remoteApp.clients, 6)
+
+	mustAcceptBlock(t, vm, service, []byte("name=satoshi"))
+	for i := 0; i < 3; i++ {
+		reply := new(ctypes.ResultABCIQuery)
+		require.NoError(t, service.ABCIQuery(nil, &ABCIQueryArgs{Path: "/key", Data: []byte("name")}, reply))
+		assert.Equal(t, []byte("satoshi"), reply.Response.Value)
+	}
+	require.NoError(t, vm.Shutdown(context.Background()))
+
+	// the in-process app has a single query connection
+	_, _, _, err = newTestVMWithDB(kvstore.NewApplication(), manager.NewMemDB(&version.Semantic{Major: 1}), []byte(`{"abciQueryConnections":3}`))
+	assert.ErrorContains(t, err, "abciQueryConnections requires proxyApp")
+}
+
+// nonceApp accepts the "sender/nonce" txs of each sender in nonce order only.
+// Its CheckTx is safe to call concurrently.
+type nonceApp struct {
+	*kvstore.Application
+	mtx    sync.Mutex
+	nonces map[string]int
+}
+
+func (app *nonceApp) CheckTx(req atypes.RequestCheckTx) atypes.ResponseCheckTx {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+
+	parts := strings.Split(string(req.Tx), "/")
+	if nonce, _ := strconv.Atoi(parts[1]); nonce != app.nonces[parts[0]] {
+		return atypes.ResponseCheckTx{Code: 1, Log: fmt.Sprintf("expected nonce %d", app.nonces[parts[0]])}
+	}
+	app.nonces[parts[0]]++
+	return atypes.ResponseCheckTx{Code: atypes.CodeTypeOK, GasWanted: 1}
+}
+
+func TestCheckTxConnections(t *testing.T) {
+	addr := "unix://" + filepath.Join(t.TempDir(), "app.sock")
+	server := abciserver.NewSocketServer(addr, &nonceApp{Application: kvstore.NewApplication(), nonces: map[string]int{}})
+	server.SetLogger(log.TestingLogger())
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		_ = server.Stop()
+	})
+
+	config := []byte(fmt.Sprintf(`{"proxyApp":%q,"abciCheckTxConnections":3}`, addr))
+	sender := func(tx types.Tx) string {
+		return strings.Split(string(tx), "/")[0]
+	}
+	vm, _, _, err := newTestVMWithGenesis(nil, manager.NewMemDB(&version.Semantic{Major: 1}), []byte(genesis), nil, config, WithTxSender(sender))
+	require.NoError(t, err)
+	service := NewService(vm)
+	// the 4 connections of the AppConns and 2 more checkTx connections
+	assert.Len(t, vm.remoteApp.clients, 6)
+
+	// the txs of each sender are checked in order, over all the connections
+	const senders, nonces = 8, 10
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for nonce := 0; nonce < nonces; nonce++ {
+				tx := []byte(fmt.Sprintf("sender%d/%d", i, nonce))
+				reply := new(ctypes.ResultBroadcastTx)
+				assert.NoError(t, service.BroadcastTxAsync(nil, &BroadcastTxArgs{Tx: tx}, reply))
+			}
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, vm.mempool.FlushAppConn())
+	assert.Equal(t, senders*nonces, vm.mempool.Size())
+	require.NoError(t, vm.Shutdown(context.Background()))
+
+	// the txs can't be kept in order without their sender
+	_, _, _, err = newTestVMWithGenesis(nil, manager.NewMemDB(&version.Semantic{Major: 1}), []byte(genesis), nil, config)
+	assert.ErrorIs(t, err, errNoTxSender)
+}
+
+func TestCheckTxConnectionsInProcess(t *testing.T) {
+	app := &nonceApp{Application: kvstore.NewApplication(), nonces: map[string]int{}}
+	sender := func(tx types.Tx) string {
+		return strings.Split(string(tx), "/")[0]
+	}
+	vm, _, _, err := newTestVMWithGenesis(app, manager.NewMemDB(&version.Semantic{Major: 1}), []byte(genesis), nil,
+		[]byte(`{"abciCheckTxConnections":3}`), WithTxSender(sender))
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	// the txs of each sender of a batch are checked in order, by the workers
+	// of all the connections
+	const senders, nonces = 8, 10
+	var txs []types.Tx
+	for nonce := 0; nonce < nonces; nonce++ {
+		for i := 0; i < senders; i++ {
+			txs = append(txs, []byte(fmt.Sprintf("sender%d/%d", i, nonce)))
+		}
+	}
+	reply := new(BroadcastTxBatchReply)
+	require.NoError(t, service.BroadcastTxBatch(nil, &BroadcastTxBatchArgs{Txs: txs}, reply))
+	for _, result := range reply.Results {
+		assert.Equal(t, atypes.CodeTypeOK, result.Code, result.Log)
+	}
+	assert.Equal(t, senders*nonces, vm.mempool.Size())
+}
+
+func TestProxyAppReconnect(t *testing.T) {
+	addr := "unix://" + filepath.Join(t.TempDir(), "app.sock")
+	config := []byte(fmt.Sprintf(`{"proxyApp":%q}`, addr))
+	app := kvstore.NewApplication()
+	startServer := func() service.Service {
+		server := abciserver.NewSocketServer(addr, app)
+		server.SetLogger(log.TestingLogger())
+		require.NoError(t, server.Start())
+		return server
+	}
+
+	server := startServer()
+	vm, _, _, err := newTestVMWithDB(nil, manager.NewMemDB(&version.Semantic{Major: 1}), config)
+	require.NoError(t, err)
+	service := NewService(vm)
+	ctx := context.Background()
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+
+	// the node is unhealthy while the app is gone
+	require.NoError(t, server.Stop())
+	require.Eventually(t, func() bool {
+		_, err := vm.HealthCheck(ctx)
+		return errors.Is(err, errABCIUnreachable)
+	}, 5*time.Second, 10*time.Millisecond)
+	reply := new(ctypes.ResultBroadcastTx)
+	assert.Error(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("b=2")}, reply))
+
+	// and recovers once it is back
+	server = startServer()
+	t.Cleanup(func() {
+		_ = server.Stop()
+	})
+	require.Eventually(t, func() bool {
+		_, err := vm.HealthCheck(ctx)
+		return err == nil
+	}, 10*time.Second, 10*time.Millisecond)
+	mustAcceptBlock(t, vm, service, []byte("b=2"))
+	require.NoError(t, vm.Shutdown(ctx))
+}
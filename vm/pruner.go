@@ -0,0 +1,76 @@
+package vm
+
+// pruneBatchSize is the largest number of heights pruned at once, so that
+// catching up with a retain height far above the base doesn't hold the
+// stores for long.
+const pruneBatchSize = 1000
+
+// pruner prunes the blocks below the retain heights the app returns from
+// Commit, in the background.
+type pruner struct {
+	// retainHeights holds the latest retain height not pruned yet.
+	retainHeights chan int64
+	quit          chan struct{}
+	done          chan struct{}
+}
+
+func newPruner() *pruner {
+	return &pruner{
+		retainHeights: make(chan int64, 1),
+		quit:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// retain schedules the pruning of the blocks below [retainHeight]. It replaces
+// the retain height which may be waiting, as the app only raises it.
+func (p *pruner) retain(retainHeight int64) {
+	select {
+	case <-p.retainHeights:
+	default:
+	}
+	p.retainHeights <- retainHeight
+}
+
+// stop stops pruning and waits for the batch being pruned.
+func (p *pruner) stop() {
+	close(p.quit)
+	<-p.done
+}
+
+// pruneInBackground prunes up to the retain heights scheduled with retain,
+// until the pruner is stopped.
+func (vm *VM) pruneInBackground() {
+	p := vm.pruner
+	defer close(p.done)
+
+	for {
+		select {
+		case retainHeight := <-p.retainHeights:
+			vm.pruneInBatches(retainHeight)
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+func (vm *VM) pruneInBatches(retainHeight int64) {
+	for base := vm.blockStore.Base(); base < retainHeight; base = vm.blockStore.Base() {
+		batchRetainHeight := base + pruneBatchSize
+		if batchRetainHeight > retainHeight {
+			batchRetainHeight = retainHeight
+		}
+		pruned, err := vm.pruneBlocks(batchRetainHeight)
+		if err != nil {
+			vm.tmLogger.Error("failed to prune blocks", "retain_height", batchRetainHeight, "err", err)
+			return
+		}
+		vm.tmLogger.Debug("pruned blocks", "pruned", pruned, "retain_height", batchRetainHeight)
+
+		select {
+		case <-vm.pruner.quit:
+			return
+		default:
+		}
+	}
+}
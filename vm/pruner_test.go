@@ -0,0 +1,42 @@
+package vm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+)
+
+// retainApp keeps the last [keep] blocks.
+type retainApp struct {
+	*kvstore.Application
+	keep   int64
+	height int64
+}
+
+func (app *retainApp) Commit() atypes.ResponseCommit {
+	res := app.Application.Commit()
+	app.height++
+	if app.height > app.keep {
+		res.RetainHeight = app.height - app.keep + 1
+	}
+	return res
+}
+
+func TestRetainHeight(t *testing.T) {
+	vm, _, _, err := newTestVM(&retainApp{Application: kvstore.NewApplication(), keep: 2})
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	for i := 0; i < 5; i++ {
+		mustAcceptBlock(t, vm, service, []byte{byte('a' + i)})
+	}
+	require.Eventually(t, func() bool {
+		return vm.blockStore.Base() == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Nil(t, vm.blockStore.LoadBlock(3))
+	require.NotNil(t, vm.blockStore.LoadBlock(4))
+}
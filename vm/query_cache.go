@@ -0,0 +1,169 @@
+package vm
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/cache"
+
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+const (
+	blockQuery        = "block"
+	blockResultsQuery = "block_results"
+	commitQuery       = "commit"
+	validatorsQuery   = "validators"
+)
+
+type queryCacheKey struct {
+	query  string
+	height int64
+}
+
+// queryCache caches the replies of idempotent read endpoints by height. All
+// of its methods are safe to call on a nil cache, which disables caching.
+type queryCache struct {
+	entries cache.Cacher[queryCacheKey, interface{}]
+}
+
+func newQueryCache(size int) *queryCache {
+	if size <= 0 {
+		return nil
+	}
+	return &queryCache{
+		entries: &cache.LRU[queryCacheKey, interface{}]{Size: size},
+	}
+}
+
+func (c *queryCache) get(query string, height int64) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	return c.entries.Get(queryCacheKey{query, height})
+}
+
+func (c *queryCache) put(query string, height int64, value interface{}) {
+	if c == nil {
+		return
+	}
+	c.entries.Put(queryCacheKey{query, height}, value)
+}
+
+func (c *queryCache) getBlock(height int64) (ctypes.ResultBlock, bool) {
+	v, ok := c.get(blockQuery, height)
+	if !ok {
+		return ctypes.ResultBlock{}, false
+	}
+	return v.(ctypes.ResultBlock), true
+}
+
+func (c *queryCache) getBlockResults(height int64) (ctypes.ResultBlockResults, bool) {
+	v, ok := c.get(blockResultsQuery, height)
+	if !ok {
+		return ctypes.ResultBlockResults{}, false
+	}
+	return v.(ctypes.ResultBlockResults), true
+}
+
+func (c *queryCache) getCommit(height int64) (ctypes.ResultCommit, bool) {
+	v, ok := c.get(commitQuery, height)
+	if !ok {
+		return ctypes.ResultCommit{}, false
+	}
+	return v.(ctypes.ResultCommit), true
+}
+
+func (c *queryCache) getValidators(height int64) (*types.ValidatorSet, bool) {
+	v, ok := c.get(validatorsQuery, height)
+	if !ok {
+		return nil, false
+	}
+	return v.(*types.ValidatorSet), true
+}
+
+// accepted must be called once the block at [height] is committed. The commit
+// of the previous height becomes canonical, so its cached reply is dropped.
+func (c *queryCache) accepted(height int64) {
+	if c == nil {
+		return
+	}
+	c.entries.Evict(queryCacheKey{commitQuery, height - 1})
+}
+
+// flush drops every cached reply. It must be called when stored heights are
+// removed, e.g. by pruning or rollback.
+func (c *queryCache) flush() {
+	if c == nil {
+		return
+	}
+	c.entries.Flush()
+}
+
+// abciInfoCache caches the reply of the ABCIInfo endpoint, so that frequent
+// pollers don't contend with block execution on the query connection. The
+// reply is dropped when a block is committed, and refetched once older than
+// the ttl in case the app changed otherwise. All of its methods are safe to
+// call on a nil cache, which disables caching.
+type abciInfoCache struct {
+	ttl time.Duration
+
+	mtx       sync.Mutex
+	reply     *ctypes.ResultABCIInfo
+	fetchedAt time.Time
+	// generation is incremented by invalidate, so that a reply fetched before
+	// is not put back in the cache.
+	generation uint64
+}
+
+func newABCIInfoCache(ttl time.Duration) *abciInfoCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &abciInfoCache{ttl: ttl}
+}
+
+// get returns the cached reply, if fresh, or else the generation to put the
+// reply fetched instead with.
+func (c *abciInfoCache) get() (ctypes.ResultABCIInfo, uint64, bool) {
+	if c == nil {
+		return ctypes.ResultABCIInfo{}, 0, false
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.reply == nil || time.Since(c.fetchedAt) >= c.ttl {
+		return ctypes.ResultABCIInfo{}, c.generation, false
+	}
+	return *c.reply, c.generation, true
+}
+
+// put caches [reply], fetched after get returned [generation]. It is dropped
+// if the cache was invalidated since, as the reply may predate the change.
+func (c *abciInfoCache) put(reply ctypes.ResultABCIInfo, generation uint64) {
+	if c == nil {
+		return
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	c.reply = &reply
+	c.fetchedAt = time.Now()
+}
+
+// invalidate must be called once the app commits a block or its state is
+// otherwise changed.
+func (c *abciInfoCache) invalidate() {
+	if c == nil {
+		return
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.reply = nil
+	c.generation++
+}
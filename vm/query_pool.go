@@ -0,0 +1,70 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	abcicli "github.com/consideritdone/landslidecore/abci/client"
+	"github.com/consideritdone/landslidecore/libs/log"
+	"github.com/consideritdone/landslidecore/proxy"
+)
+
+var errQueriesBusy = errors.New("too many abci queries in progress")
+
+// queryPool spreads the queries over several connections to an
+// out-of-process app, so that a slow query doesn't hold up the next ones, and
+// bounds the number of queries made at once, so that they don't compete with
+// the execution of blocks for the app.
+type queryPool struct {
+	// conns are the query connections besides the one of the AppConns.
+	conns   []proxy.AppConnQuery
+	clients []abcicli.Client
+	counter atomic.Uint64
+	// slots bounds the queries made at once, nil if they aren't bounded.
+	slots chan struct{}
+}
+
+// newQueryPool returns the query pool of the abciQueryConnections and
+// abciMaxConcurrentQueries config, or nil if neither is set. The extra
+// connections are created by [clientCreator].
+func newQueryPool(clientCreator proxy.ClientCreator, config Config, logger log.Logger) (*queryPool, error) {
+	if config.ABCIQueryConnections <= 1 && config.ABCIMaxConcurrentQueries == 0 {
+		return nil, nil
+	}
+	pool := &queryPool{}
+	if config.ABCIMaxConcurrentQueries > 0 {
+		pool.slots = make(chan struct{}, config.ABCIMaxConcurrentQueries)
+	}
+	for i := 1; i < config.ABCIQueryConnections; i++ {
+		client, err := clientCreator.NewABCIClient()
+		if err != nil {
+			pool.stop()
+			return nil, fmt.Errorf("error creating ABCI client (query connection %d): %w", i, err)
+		}
+		client.SetLogger(logger.With("module", "abci-client", "connection", fmt.Sprintf("query-%d", i)))
+		if err := client.Start(); err != nil {
+			pool.stop()
+			return nil, fmt.Errorf("error starting ABCI client (query connection %d): %w", i, err)
+		}
+		pool.clients = append(pool.clients, client)
+		pool.conns = append(pool.conns, proxy.NewAppConnQuery(client))
+	}
+	return pool, nil
+}
+
+// next returns the connection the next query is made on, round robin over
+// [conn], the query connection of the AppConns, and those of the pool.
+func (p *queryPool) next(conn proxy.AppConnQuery) proxy.AppConnQuery {
+	i := p.counter.Add(1) % uint64(len(p.conns)+1)
+	if i == 0 {
+		return conn
+	}
+	return p.conns[i-1]
+}
+
+func (p *queryPool) stop() {
+	for _, client := range p.clients {
+		_ = client.Stop()
+	}
+}
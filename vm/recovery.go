@@ -0,0 +1,84 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consideritdone/landslidecore/proxy"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+var errAppAheadOfStore = errors.New("app is ahead of the block store")
+
+// recoverJournaledBlock completes the block the node crashed while accepting.
+// Such a block is in the block store, with its ABCI responses, but the state
+// is one height behind. If the app committed the block, the state is updated
+// with the saved responses. Otherwise, the block is removed from the block
+// store and returned, to be delivered to the app again once the VM is
+// initialized. It returns nil if the block store and the state are at the
+// same height.
+func (vm *VM) recoverJournaledBlock() (*types.Block, error) {
+	state, err := vm.stateStore.Load()
+	if err != nil {
+		return nil, err
+	}
+	height := vm.blockStore.Height()
+	if height == 0 || height != nextHeight(&state) {
+		return nil, nil
+	}
+
+	info, err := vm.proxyApp.Query().InfoSync(proxy.RequestInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the app height: %w", err)
+	}
+	tmBlock := vm.blockStore.LoadBlock(height)
+	if tmBlock == nil {
+		return nil, fmt.Errorf("block %d not found", height)
+	}
+	vm.tmLogger.Info("recovering block accepted before a crash",
+		"height", height, "app_height", info.LastBlockHeight)
+
+	switch {
+	case info.LastBlockHeight > height:
+		return nil, fmt.Errorf("%w: app at height %d, block store at height %d",
+			errAppAheadOfStore, info.LastBlockHeight, height)
+
+	case info.LastBlockHeight == height:
+		// the app committed the block, but the state wasn't saved
+		abciResponses, err := vm.stateStore.LoadABCIResponses(height)
+		if err != nil {
+			return nil, err
+		}
+		blk, err := vm.newBlock(tmBlock)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := vm.saveBlockState(state, blk, abciResponses, info.LastBlockAppHash); err != nil {
+			return nil, fmt.Errorf("failed to save the state of block %d: %w", height, err)
+		}
+		return nil, nil
+
+	default:
+		// the app didn't commit the block, the handshake replays the blocks
+		// it is missing below it
+		err := vm.atomically(vm.blockStore.DeleteLatestBlock)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete block %d: %w", height, err)
+		}
+		return tmBlock, nil
+	}
+}
+
+// redeliverBlock accepts [tmBlock] again, as recovered by
+// recoverJournaledBlock.
+func (vm *VM) redeliverBlock(tmBlock *types.Block) error {
+	blk, err := vm.newBlock(tmBlock)
+	if err != nil {
+		return err
+	}
+	if err := vm.applyBlock(blk); err != nil {
+		return fmt.Errorf("failed to deliver block %d again: %w", tmBlock.Height, err)
+	}
+	vm.tmLogger.Info("delivered block again", "height", tmBlock.Height)
+	return nil
+}
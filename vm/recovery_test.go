@@ -0,0 +1,78 @@
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/ava-labs/avalanchego/vms/components/chain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+func TestRecoverJournaledBlock(t *testing.T) {
+	// journal builds a block at height 3 and persists it with its ABCI
+	// responses, as if the node crashed before its state was saved
+	journal := func(t *testing.T, app *heightApp) manager.Manager {
+		dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+		vm, _, _, err := newTestVMWithDB(app, dbManager, nil)
+		require.NoError(t, err)
+		service := NewService(vm)
+		for i := byte(0); i < 2; i++ {
+			mustAcceptBlock(t, vm, service, []byte{i})
+		}
+
+		reply := new(ctypes.ResultBroadcastTx)
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte{2}}, reply))
+		blk, err := vm.BuildBlock(context.Background())
+		require.NoError(t, err)
+		tmBlock := blk.(*chain.BlockWrapper).Block.(*Block).tmBlock
+		require.NoError(t, vm.atomically(func() error {
+			abciResponses, err := execBlockOnProxyApp(vm.tmLogger, vm.proxyApp.Consensus(), tmBlock, vm.stateStore, vm.tmState.InitialHeight)
+			if err != nil {
+				return err
+			}
+			if err := vm.stateStore.SaveABCIResponses(tmBlock.Height, abciResponses); err != nil {
+				return err
+			}
+			vm.blockStore.SaveBlock(tmBlock, tmBlock.MakePartSet(types.BlockPartSizeBytes), tmBlock.LastCommit)
+			return nil
+		}))
+		return dbManager
+	}
+
+	// the app didn't commit the block, it is delivered again
+	app := &heightApp{}
+	dbManager := journal(t, app)
+	require.EqualValues(t, 2, app.height)
+	vm, _, _, err := newTestVMWithDB(app, dbManager, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, app.height)
+	assert.EqualValues(t, 3, vm.blockStore.Height())
+	assert.EqualValues(t, 3, vm.tmState.LastBlockHeight)
+	vm.acceptor.flush()
+	r, err := vm.txIndexer.Get(types.Tx{2}.Hash())
+	require.NoError(t, err)
+	assert.NotNil(t, r)
+
+	// the app committed the block, the state is saved from its responses
+	app = &heightApp{}
+	dbManager = journal(t, app)
+	app.height = 3
+	vm, _, _, err = newTestVMWithDB(app, dbManager, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, app.height)
+	assert.EqualValues(t, 3, vm.blockStore.Height())
+	assert.EqualValues(t, 3, vm.tmState.LastBlockHeight)
+
+	// the app can't be ahead of the block store
+	app = &heightApp{}
+	dbManager = journal(t, app)
+	app.height = 4
+	_, _, _, err = newTestVMWithDB(app, dbManager, nil)
+	assert.ErrorIs(t, err, errAppAheadOfStore)
+}
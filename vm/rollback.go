@@ -0,0 +1,179 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	tmquery "github.com/consideritdone/landslidecore/libs/pubsub/query"
+	"github.com/consideritdone/landslidecore/proxy"
+	sm "github.com/consideritdone/landslidecore/state"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// rollbackMarkerKey is set in the state database once the rollback requested
+// by the config has been done, so that it isn't done again on every restart.
+// It is removed when the node is started without the rollback flag.
+var rollbackMarkerKey = []byte("rollbackDone")
+
+// txIndexDeleter is implemented by tx indexers which support removing
+// indexed transactions, such as the kv indexer.
+type txIndexDeleter interface {
+	Delete(result *abci.TxResult) error
+}
+
+// blockIndexDeleter is implemented by block indexers which support removing
+// indexed blocks, such as the kv indexer.
+type blockIndexDeleter interface {
+	Delete(bh types.EventDataNewBlockHeader) error
+}
+
+// rollbackOnStartup rolls back by one height if the config asks for it and
+// it wasn't done by a previous start with the same config. The marker is
+// committed with the rollback, so that it is done exactly once.
+func (vm *VM) rollbackOnStartup() error {
+	return vm.atomically(func() error {
+		done, err := vm.stateDB.Has(rollbackMarkerKey)
+		if err != nil {
+			return err
+		}
+		if !vm.config.Rollback {
+			if done {
+				return vm.stateDB.DeleteSync(rollbackMarkerKey)
+			}
+			return nil
+		}
+		if done {
+			vm.tmLogger.Info("rollback already done, unset the rollback flag to roll back again")
+			return nil
+		}
+
+		height, err := vm.rollback()
+		if err != nil {
+			return fmt.Errorf("failed to roll back state: %w ", err)
+		}
+		if err := vm.stateDB.SetSync(rollbackMarkerKey, []byte{}); err != nil {
+			return err
+		}
+		vm.tmLogger.Info("rolled back state on startup", "height", height)
+		return nil
+	})
+}
+
+// rollback overwrites the current state (height n) with the state at height
+// n - 1 and removes block n from the block store and the indexers. It does
+// not affect the application state, which must have been rolled back to
+// n - 1 or below beforehand, so that the block is executed again when it is
+// accepted. It returns the height rolled back to.
+//
+// NOTE: the caller must hold the mempool lock, if the mempool exists, and run
+// it atomically.
+func (vm *VM) rollback() (int64, error) {
+	invalidState, err := vm.stateStore.Load()
+	if err != nil {
+		return -1, err
+	}
+	if invalidState.IsEmpty() {
+		return -1, errors.New("no state found")
+	}
+
+	height := vm.blockStore.Height()
+
+	// Persistence of state and blocks doesn't happen atomically. If the block
+	// store is one height ahead, the block was never applied to the state, so
+	// it is enough to remove it.
+	if height == nextHeight(&invalidState) {
+		if err := vm.blockStore.DeleteLatestBlock(); err != nil {
+			return -1, fmt.Errorf("failed to delete block %d: %w", height, err)
+		}
+		vm.queryCache.flush()
+		vm.abciInfoCache.invalidate()
+		return invalidState.LastBlockHeight, nil
+	}
+
+	rollbackHeight := height - 1
+	if rollbackHeight < invalidState.InitialHeight {
+		return -1, fmt.Errorf("cannot roll back below the initial height %d", invalidState.InitialHeight)
+	}
+
+	info, err := vm.proxyApp.Query().InfoSync(proxy.RequestInfo)
+	if err != nil {
+		return -1, fmt.Errorf("failed to query the app height: %w", err)
+	}
+	if info.LastBlockHeight > rollbackHeight {
+		return -1, fmt.Errorf("app is at height %d, it must be rolled back to height %d first",
+			info.LastBlockHeight, rollbackHeight)
+	}
+
+	if err := vm.deleteIndexedBlock(height); err != nil {
+		return -1, err
+	}
+	if err := vm.setLastIndexedHeight(height - 1); err != nil {
+		return -1, err
+	}
+	if err := vm.deleteWarpMessages(height); err != nil {
+		return -1, err
+	}
+
+	// the validator sets and consensus params of height n - 1 are restored
+	// from the state store
+	rollbackHeight, _, err = sm.Rollback(vm.blockStore, vm.stateStore)
+	if err != nil {
+		return -1, err
+	}
+	rolledBackState, err := vm.stateStore.Load()
+	if err != nil {
+		return -1, err
+	}
+	*vm.tmState = rolledBackState
+
+	if err := vm.blockStore.DeleteLatestBlock(); err != nil {
+		return -1, fmt.Errorf("failed to delete block %d: %w", height, err)
+	}
+	vm.queryCache.flush()
+	vm.abciInfoCache.invalidate()
+
+	vm.tmLogger.Info("rolled back state", "height", rollbackHeight)
+	return rollbackHeight, nil
+}
+
+// deleteIndexedBlock removes the block at [height] and its transactions from
+// the indexers, if they support it. The indexed transactions are looked up in
+// the tx index, and the block events in the ABCI responses of the block.
+func (vm *VM) deleteIndexedBlock(height int64) error {
+	if txIndexer, ok := vm.txIndexer.(txIndexDeleter); ok {
+		q, err := tmquery.New(fmt.Sprintf("%s = %d", types.TxHeightKey, height))
+		if err != nil {
+			return err
+		}
+		results, err := vm.txIndexer.Search(context.Background(), q)
+		if err != nil {
+			return fmt.Errorf("failed to search txs of block %d: %w", height, err)
+		}
+		for _, result := range results {
+			if err := txIndexer.Delete(result); err != nil {
+				return fmt.Errorf("failed to delete tx %X from the index: %w", types.Tx(result.Tx).Hash(), err)
+			}
+		}
+	}
+
+	if blockIndexer, ok := vm.blockIndexer.(blockIndexDeleter); ok {
+		abciResponses, err := vm.stateStore.LoadABCIResponses(height)
+		if err != nil {
+			return err
+		}
+		bh := types.EventDataNewBlockHeader{Header: types.Header{Height: height}}
+		if abciResponses.BeginBlock != nil {
+			bh.ResultBeginBlock = *abciResponses.BeginBlock
+		}
+		if abciResponses.EndBlock != nil {
+			bh.ResultEndBlock = *abciResponses.EndBlock
+		}
+		if err := blockIndexer.Delete(bh); err != nil {
+			return fmt.Errorf("failed to delete block %d from the index: %w", height, err)
+		}
+	}
+
+	return nil
+}
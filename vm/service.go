@@ -8,16 +8,20 @@ import (
 	"sort"
 	"time"
 
+	"github.com/gorilla/rpc/v2/json2"
+
 	abci "github.com/consideritdone/landslidecore/abci/types"
+	"github.com/consideritdone/landslidecore/crypto"
+	"github.com/consideritdone/landslidecore/crypto/merkle"
 	tmbytes "github.com/consideritdone/landslidecore/libs/bytes"
 	tmmath "github.com/consideritdone/landslidecore/libs/math"
 	tmquery "github.com/consideritdone/landslidecore/libs/pubsub/query"
 	mempl "github.com/consideritdone/landslidecore/mempool"
 	"github.com/consideritdone/landslidecore/p2p"
-	"github.com/consideritdone/landslidecore/proxy"
 	"github.com/consideritdone/landslidecore/rpc/core"
 	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
 	"github.com/consideritdone/landslidecore/types"
+	tmversion "github.com/consideritdone/landslidecore/version"
 )
 
 type (
@@ -32,6 +36,10 @@ type (
 		SignService
 		StatusService
 		MempoolService
+		IntrospectionService
+		UpgradeService
+		WarpService
+		ProposerService
 	}
 
 	ABCIQueryArgs struct {
@@ -52,6 +60,62 @@ type (
 
 	BroadcastTxArgs struct {
 		Tx types.Tx `json:"tx"`
+		// WaitForRecheck makes BroadcastTxSync wait for the recheck started by
+		// the last accepted block to complete, and fail if the tx didn't stay
+		// in the mempool. It is ignored by BroadcastTxAsync.
+		WaitForRecheck bool `json:"waitForRecheck"`
+		// CheckTxErrors makes BroadcastTxSync fail with ErrCodeCheckTxFailed,
+		// or ErrCodeMempoolFull, when the tx is rejected after CheckTx,
+		// rather than returning the code in the result. It is ignored by
+		// BroadcastTxAsync.
+		CheckTxErrors bool `json:"checkTxErrors"`
+	}
+
+	BroadcastTxCommitArgs struct {
+		Tx types.Tx `json:"tx"`
+		// CompositeKeys additionally returns the DeliverTx events indexed by
+		// their composite key, e.g. "transfer.recipient".
+		CompositeKeys bool `json:"compositeKeys"`
+		// CheckTxErrors makes BroadcastTxCommit fail as BroadcastTxSync does
+		// with it, when the tx is rejected after CheckTx.
+		CheckTxErrors bool `json:"checkTxErrors"`
+	}
+
+	EventAttribute struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+		Index bool   `json:"index"`
+	}
+
+	Event struct {
+		Type       string           `json:"type"`
+		Attributes []EventAttribute `json:"attributes"`
+	}
+
+	BroadcastTxCommitReply struct {
+		ctypes.ResultBroadcastTxCommit
+		// Events are the DeliverTx events with keys and values decoded as
+		// strings.
+		Events          []Event             `json:"events"`
+		CompositeEvents map[string][]string `json:"compositeEvents,omitempty"`
+	}
+
+	BroadcastTxBatchArgs struct {
+		Txs []types.Tx `json:"txs"`
+	}
+
+	BroadcastTxBatchResult struct {
+		ctypes.ResultBroadcastTx
+		// Error is set when the tx was not admitted for checking, e.g. because
+		// the mempool is full or the tx is already in the cache.
+		Error string `json:"error,omitempty"`
+		// ErrorCode is the JSON-RPC error code of Error, as BroadcastTxSync
+		// would fail with, e.g. ErrCodeMempoolFull, or 0 if it has none.
+		ErrorCode json2.ErrorCode `json:"errorCode,omitempty"`
+	}
+
+	BroadcastTxBatchReply struct {
+		Results []BroadcastTxBatchResult `json:"results"`
 	}
 
 	ABCIService interface {
@@ -61,9 +125,10 @@ type (
 		ABCIQueryWithOptions(_ *http.Request, args *ABCIQueryWithOptionsArgs, reply *ctypes.ResultABCIQuery) error
 
 		// Writing to abci app
-		BroadcastTxCommit(_ *http.Request, args *BroadcastTxArgs, reply *ctypes.ResultBroadcastTxCommit) error
+		BroadcastTxCommit(_ *http.Request, args *BroadcastTxCommitArgs, reply *BroadcastTxCommitReply) error
 		BroadcastTxAsync(_ *http.Request, args *BroadcastTxArgs, reply *ctypes.ResultBroadcastTx) error
 		BroadcastTxSync(_ *http.Request, args *BroadcastTxArgs, reply *ctypes.ResultBroadcastTx) error
+		BroadcastTxBatch(_ *http.Request, args *BroadcastTxBatchArgs, reply *BroadcastTxBatchReply) error
 	}
 
 	BlockHeightArgs struct {
@@ -74,27 +139,107 @@ type (
 		Hash []byte `json:"hash"`
 	}
 
+	LatestBlockEventsReply struct {
+		BlockID          types.BlockID  `json:"blockId"`
+		Header           types.Header   `json:"header"`
+		BeginBlockEvents []abci.Event   `json:"beginBlockEvents"`
+		TxsEvents        [][]abci.Event `json:"txsEvents"`
+		EndBlockEvents   []abci.Event   `json:"endBlockEvents"`
+	}
+
 	CommitArgs struct {
 		Height *int64 `json:"height"`
 	}
 
+	CommitReply struct {
+		ctypes.ResultCommit
+		// Attestation is the node's signature over the header, set when the
+		// attestCommits option is enabled.
+		Attestation *CommitAttestation `json:"attestation,omitempty"`
+	}
+
 	ValidatorsArgs struct {
 		Height  *int64 `json:"height"`
 		Page    *int   `json:"page"`
 		PerPage *int   `json:"perPage"`
 	}
 
+	ValidatorSetChangesArgs struct {
+		Height *int64 `json:"height"`
+	}
+
+	ValidatorPowerChange struct {
+		Address  crypto.Address `json:"address"`
+		PubKey   crypto.PubKey  `json:"pubKey"`
+		OldPower int64          `json:"oldPower"`
+		NewPower int64          `json:"newPower"`
+	}
+
+	ValidatorSetChangesReply struct {
+		BlockHeight  int64                  `json:"blockHeight"`
+		Joined       []*types.Validator     `json:"joined"`
+		Left         []*types.Validator     `json:"left"`
+		PowerChanges []ValidatorPowerChange `json:"powerChanges"`
+	}
+
 	TxArgs struct {
 		Hash  []byte `json:"hash"`
 		Prove bool   `json:"prove"`
 	}
 
+	TxStatusArgs struct {
+		Hash []byte `json:"hash"`
+	}
+
+	TxStatusReply struct {
+		// Status is one of "unknown", "pending" or "committed".
+		Status string `json:"status"`
+
+		// Position is the number of txs ahead of a pending tx in the
+		// mempool, and PendingFor how long it has been there.
+		Position   *int      `json:"position,omitempty"`
+		PendingFor *Duration `json:"pendingFor,omitempty"`
+
+		// Height, Index and TxResult are set for a committed tx.
+		Height   int64                   `json:"height,omitempty"`
+		Index    uint32                  `json:"index"`
+		TxResult *abci.ResponseDeliverTx `json:"txResult,omitempty"`
+	}
+
+	TxProofArgs struct {
+		Hash []byte `json:"hash"`
+	}
+
+	TxProofReply struct {
+		Hash     tmbytes.HexBytes `json:"hash"`
+		Height   int64            `json:"height"`
+		Index    uint32           `json:"index"`
+		BlockID  types.BlockID    `json:"blockId"`
+		DataHash tmbytes.HexBytes `json:"dataHash"`
+		Proof    merkle.Proof     `json:"proof"`
+		// Verified reports whether the proof was checked against the
+		// DataHash of the block header which includes the tx.
+		Verified          bool   `json:"verified"`
+		VerificationError string `json:"verificationError,omitempty"`
+	}
+
 	TxSearchArgs struct {
 		Query   string `json:"query"`
 		Prove   bool   `json:"prove"`
 		Page    *int   `json:"page"`
 		PerPage *int   `json:"perPage"`
 		OrderBy string `json:"orderBy"`
+		// IncludePending also searches the txs in the mempool, matching the
+		// query against their CheckTx events.
+		IncludePending bool `json:"includePending"`
+	}
+
+	TxSearchReply struct {
+		ctypes.ResultTxSearch
+		// PendingTxs are the mempool txs matching the query, oldest first and
+		// at most a page of them, when IncludePending is set. They have no
+		// height and no DeliverTx result yet.
+		PendingTxs []*ctypes.ResultTx `json:"pendingTxs,omitempty"`
 	}
 
 	BlockSearchArgs struct {
@@ -108,10 +253,14 @@ type (
 		Block(_ *http.Request, args *BlockHeightArgs, reply *ctypes.ResultBlock) error
 		BlockByHash(_ *http.Request, args *BlockHashArgs, reply *ctypes.ResultBlock) error
 		BlockResults(_ *http.Request, args *BlockHeightArgs, reply *ctypes.ResultBlockResults) error
-		Commit(_ *http.Request, args *CommitArgs, reply *ctypes.ResultCommit) error
+		LatestBlockEvents(_ *http.Request, _ *struct{}, reply *LatestBlockEventsReply) error
+		Commit(_ *http.Request, args *CommitArgs, reply *CommitReply) error
 		Validators(_ *http.Request, args *ValidatorsArgs, reply *ctypes.ResultValidators) error
+		ValidatorSetChanges(_ *http.Request, args *ValidatorSetChangesArgs, reply *ValidatorSetChangesReply) error
 		Tx(_ *http.Request, args *TxArgs, reply *ctypes.ResultTx) error
-		TxSearch(_ *http.Request, args *TxSearchArgs, reply *ctypes.ResultTxSearch) error
+		TxStatus(_ *http.Request, args *TxStatusArgs, reply *TxStatusReply) error
+		TxProof(_ *http.Request, args *TxProofArgs, reply *TxProofReply) error
+		TxSearch(_ *http.Request, args *TxSearchArgs, reply *TxSearchReply) error
 		BlockSearch(_ *http.Request, args *BlockSearchArgs, reply *ctypes.ResultBlockSearch) error
 	}
 
@@ -128,10 +277,17 @@ type (
 		BlockchainInfo(_ *http.Request, args *BlockchainInfoArgs, reply *ctypes.ResultBlockchainInfo) error
 		Genesis(_ *http.Request, _ *struct{}, reply *ctypes.ResultGenesis) error
 		GenesisChunked(_ *http.Request, args *GenesisChunkedArgs, reply *ctypes.ResultGenesisChunk) error
+		GenesisHash(_ *http.Request, _ *struct{}, reply *GenesisHashReply) error
+	}
+
+	GenesisHashReply struct {
+		// Hash is the SHA-256 of the genesis bytes the chain was created with.
+		Hash tmbytes.HexBytes `json:"hash"`
 	}
 
 	StatusService interface {
 		Status(_ *http.Request, _ *struct{}, reply *ctypes.ResultStatus) error
+		BuildInfo(_ *http.Request, _ *struct{}, reply *BuildInfo) error
 	}
 
 	ConsensusParamsArgs struct {
@@ -148,6 +304,25 @@ type (
 
 	UnconfirmedTxsArgs struct {
 		Limit *int `json:"limit"`
+		// Sender, if set, restricts the txs to those the app reported as sent
+		// by this account, through a "sender" attribute of the CheckTx events.
+		Sender string `json:"sender"`
+	}
+
+	TxSizeBucket struct {
+		// MaxBytes is the inclusive upper bound of the bucket. It is omitted
+		// for the last bucket, which has no upper bound.
+		MaxBytes int `json:"maxBytes,omitempty"`
+		Count    int `json:"count"`
+	}
+
+	NumUnconfirmedTxsReply struct {
+		ctypes.ResultUnconfirmedTxs
+		// OldestTxAge is how long the oldest tx has been in the mempool, as a
+		// string such as "1.5s". It is zero if the mempool is empty.
+		OldestTxAge Duration `json:"oldestTxAge"`
+		// SizeHistogram counts the txs in the mempool by size.
+		SizeHistogram []TxSizeBucket `json:"sizeHistogram"`
 	}
 
 	CheckTxArgs struct {
@@ -156,7 +331,7 @@ type (
 
 	MempoolService interface {
 		UnconfirmedTxs(_ *http.Request, args *UnconfirmedTxsArgs, reply *ctypes.ResultUnconfirmedTxs) error
-		NumUnconfirmedTxs(_ *http.Request, _ *struct{}, reply *ctypes.ResultUnconfirmedTxs) error
+		NumUnconfirmedTxs(_ *http.Request, _ *struct{}, reply *NumUnconfirmedTxsReply) error
 		CheckTx(_ *http.Request, args *CheckTxArgs, reply *ctypes.ResultCheckTx) error
 	}
 )
@@ -170,11 +345,11 @@ func NewService(vm *VM) Service {
 }
 
 func (s *LocalService) ABCIInfo(_ *http.Request, _ *struct{}, reply *ctypes.ResultABCIInfo) error {
-	resInfo, err := s.vm.proxyApp.Query().InfoSync(proxy.RequestInfo)
+	resInfo, err := s.vm.abciInfo()
 	if err != nil {
 		return err
 	}
-	reply.Response = *resInfo
+	reply.Response = resInfo
 	return nil
 }
 
@@ -187,6 +362,13 @@ func (s *LocalService) ABCIQueryWithOptions(
 	args *ABCIQueryWithOptionsArgs,
 	reply *ctypes.ResultABCIQuery,
 ) error {
+	// 0 queries the latest height, any other height must still be available
+	if args.Opts.Height != 0 {
+		if _, err := getHeight(s.vm.blockStore, &args.Opts.Height); err != nil {
+			return err
+		}
+	}
+
 	resQuery, err := s.vm.proxyApp.Query().QuerySync(abci.RequestQuery{
 		Path:   args.Path,
 		Data:   args.Data,
@@ -196,14 +378,17 @@ func (s *LocalService) ABCIQueryWithOptions(
 	if err != nil {
 		return err
 	}
+	if args.Opts.Height != 0 && isAppPruned(resQuery) {
+		return ErrHeightPruned{Height: args.Opts.Height}
+	}
 	reply.Response = *resQuery
 	return nil
 }
 
 func (s *LocalService) BroadcastTxCommit(
-	_ *http.Request,
-	args *BroadcastTxArgs,
-	reply *ctypes.ResultBroadcastTxCommit,
+	req *http.Request,
+	args *BroadcastTxCommitArgs,
+	reply *BroadcastTxCommitReply,
 ) error {
 	subscriber := ""
 
@@ -225,36 +410,60 @@ func (s *LocalService) BroadcastTxCommit(
 		}
 	}()
 
+	if err := s.vm.checkBackpressure(); err != nil {
+		return err
+	}
+
+	ctx, cancelReq := s.vm.requestContext(req)
+	defer cancelReq()
+
 	// Broadcast tx and wait for CheckTx result
 	checkTxResCh := make(chan *abci.Response, 1)
-	err = s.vm.mempool.CheckTx(args.Tx, func(res *abci.Response) {
+	err = s.vm.checkTx(ctx, args.Tx, func(res *abci.Response) {
 		checkTxResCh <- res
-	}, mempl.TxInfo{})
+	}, mempl.TxInfo{Origin: rpcOrigin(req)})
 	if err != nil {
 		s.vm.tmLogger.Error("Error on broadcastTxCommit", "err", err)
-		return fmt.Errorf("error on broadcastTxCommit: %v", err)
+		return admissionError(err)
+	}
+	checkTxRes, err := s.vm.awaitCheckTx(ctx, checkTxResCh)
+	if err != nil {
+		return err
 	}
-	checkTxResMsg := <-checkTxResCh
-	checkTxRes := checkTxResMsg.GetCheckTx()
 	if checkTxRes.Code != abci.CodeTypeOK {
-		*reply = ctypes.ResultBroadcastTxCommit{
+		if args.CheckTxErrors {
+			return checkTxError(&ctypes.ResultBroadcastTx{
+				Code:      checkTxRes.Code,
+				Data:      checkTxRes.Data,
+				Log:       checkTxRes.Log,
+				Codespace: checkTxRes.Codespace,
+				Hash:      args.Tx.Hash(),
+			})
+		}
+		reply.ResultBroadcastTxCommit = ctypes.ResultBroadcastTxCommit{
 			CheckTx:   *checkTxRes,
 			DeliverTx: abci.ResponseDeliverTx{},
 			Hash:      args.Tx.Hash(),
 		}
+		reply.Events = []Event{}
 		return nil
 	}
+	s.vm.gossipTxs(types.Txs{args.Tx})
 
 	// Wait for the tx to be included in a block or timeout.
 	select {
 	case msg := <-deliverTxSub.Out(): // The tx was included in a block.
 		deliverTxRes := msg.Data().(types.EventDataTx)
-		*reply = ctypes.ResultBroadcastTxCommit{
+		reply.ResultBroadcastTxCommit = ctypes.ResultBroadcastTxCommit{
 			CheckTx:   *checkTxRes,
 			DeliverTx: deliverTxRes.Result,
 			Hash:      args.Tx.Hash(),
 			Height:    deliverTxRes.Height,
 		}
+		reply.Events = decodeEvents(deliverTxRes.Result.Events)
+		if args.CompositeKeys {
+			reply.CompositeEvents = compositeEvents(deliverTxRes.Result.Events)
+		}
 		return nil
 	case <-deliverTxSub.Cancelled():
 		var reason string
@@ -266,6 +475,8 @@ func (s *LocalService) BroadcastTxCommit(
 		err = fmt.Errorf("deliverTxSub was cancelled (reason: %s)", reason)
 		s.vm.tmLogger.Error("Error on broadcastTxCommit", "err", err)
 		return err
+	case <-ctx.Done():
+		return fmt.Errorf("stopped waiting for the tx to be included in a block: %w", ctx.Err())
 	// TODO: use config for timeout
 	case <-time.After(10 * time.Second):
 		err = errors.New("timed out waiting for tx to be included in a block")
@@ -275,29 +486,42 @@ func (s *LocalService) BroadcastTxCommit(
 }
 
 func (s *LocalService) BroadcastTxAsync(
-	_ *http.Request,
+	req *http.Request,
 	args *BroadcastTxArgs,
 	reply *ctypes.ResultBroadcastTx,
 ) error {
-	err := s.vm.mempool.CheckTx(args.Tx, nil, mempl.TxInfo{})
-	if err != nil {
+	if err := s.vm.checkBackpressure(); err != nil {
 		return err
 	}
+	ctx, cancel := s.vm.requestContext(req)
+	defer cancel()
+	err := s.vm.checkTx(ctx, args.Tx, s.vm.gossipTxOnCheck(args.Tx), mempl.TxInfo{Origin: rpcOrigin(req)})
+	if err != nil {
+		return admissionError(err)
+	}
 	reply.Hash = args.Tx.Hash()
 	return nil
 }
 
-func (s *LocalService) BroadcastTxSync(_ *http.Request, args *BroadcastTxArgs, reply *ctypes.ResultBroadcastTx) error {
+func (s *LocalService) BroadcastTxSync(req *http.Request, args *BroadcastTxArgs, reply *ctypes.ResultBroadcastTx) error {
+	if err := s.vm.checkBackpressure(); err != nil {
+		return err
+	}
+	ctx, cancel := s.vm.requestContext(req)
+	defer cancel()
+
 	resCh := make(chan *abci.Response, 1)
-	err := s.vm.mempool.CheckTx(args.Tx, func(res *abci.Response) {
-		s.vm.tmLogger.With("module", "service").Debug("handled response from checkTx")
+	err := s.vm.checkTx(ctx, args.Tx, func(res *abci.Response) {
+		s.vm.tmLogger.With("module", "rpc").Debug("handled response from checkTx")
 		resCh <- res
-	}, mempl.TxInfo{})
+	}, mempl.TxInfo{Origin: rpcOrigin(req)})
+	if err != nil {
+		return admissionError(err)
+	}
+	r, err := s.vm.awaitCheckTx(ctx, resCh)
 	if err != nil {
 		return err
 	}
-	res := <-resCh
-	r := res.GetCheckTx()
 
 	reply.Code = r.Code
 	reply.Data = r.Data
@@ -305,6 +529,131 @@ func (s *LocalService) BroadcastTxSync(_ *http.Request, args *BroadcastTxArgs, r
 	reply.Codespace = r.Codespace
 	reply.Hash = args.Tx.Hash()
 
+	if r.Code == abci.CodeTypeOK {
+		s.vm.gossipTxs(types.Txs{args.Tx})
+	} else if args.CheckTxErrors {
+		return checkTxError(reply)
+	}
+	if args.WaitForRecheck && r.Code == abci.CodeTypeOK {
+		return s.waitForRecheck(ctx, args.Tx)
+	}
+	return nil
+}
+
+// waitForRecheck waits for the recheck in progress to complete and returns an
+// error if [tx] is no longer in the mempool.
+func (s *LocalService) waitForRecheck(ctx context.Context, tx types.Tx) error {
+	mempool, ok := s.vm.mempool.(recheckWaiter)
+	if !ok {
+		return errors.New("the mempool doesn't support waiting for the recheck")
+	}
+	ctx, cancel := context.WithTimeout(ctx, recheckTimeout)
+	defer cancel()
+
+	select {
+	case <-mempool.RecheckDone():
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for the mempool recheck: %w", ctx.Err())
+	}
+
+	locator, ok := s.vm.mempool.(pendingTxLocator)
+	if !ok {
+		return nil
+	}
+	if _, _, ok := locator.PendingTx(mempl.TxKey(tx)); !ok {
+		return fmt.Errorf("tx %X was removed from the mempool by the recheck", tx.Hash())
+	}
+	return nil
+}
+
+// BroadcastTxBatch checks all the txs under a single acquisition of the
+// mempool lock and returns the CheckTx result of each tx, in order. Batches
+// of more than the configured maxBatchTxs are rejected.
+func (s *LocalService) BroadcastTxBatch(
+	req *http.Request,
+	args *BroadcastTxBatchArgs,
+	reply *BroadcastTxBatchReply,
+) error {
+	if len(args.Txs) > s.vm.config.MaxBatchTxs {
+		return fmt.Errorf("batch has %d txs, the maximum is %d", len(args.Txs), s.vm.config.MaxBatchTxs)
+	}
+	if err := s.vm.checkBackpressure(); err != nil {
+		return err
+	}
+
+	type indexedResponse struct {
+		index int
+		res   *abci.Response
+	}
+
+	resCh := make(chan indexedResponse, len(args.Txs))
+	errs := s.vm.mempool.CheckTxBatch(args.Txs, func(i int, res *abci.Response) {
+		resCh <- indexedResponse{i, res}
+	}, mempl.TxInfo{Origin: rpcOrigin(req)})
+
+	results := make([]BroadcastTxBatchResult, len(args.Txs))
+	pending := 0
+	for i, tx := range args.Txs {
+		results[i].Hash = tx.Hash()
+		if errs[i] != nil {
+			results[i].Error = errs[i].Error()
+			var txErr *txError
+			if errors.As(admissionError(errs[i]), &txErr) {
+				results[i].ErrorCode = txErr.code
+			}
+			continue
+		}
+		pending++
+	}
+
+	var timedOut <-chan time.Time
+	if timeout := s.vm.config.ABCICheckTxTimeout.Duration; timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timedOut = timer.C
+	}
+	ctx, cancel := s.vm.requestContext(req)
+	defer cancel()
+	answered := make([]bool, len(args.Txs))
+	accepted := make(types.Txs, 0, pending)
+collect:
+	for ; pending > 0; pending-- {
+		var r indexedResponse
+		select {
+		case r = <-resCh:
+		case <-timedOut:
+			// the txs still unanswered may yet enter the mempool
+			for i := range results {
+				if errs[i] == nil && !answered[i] {
+					results[i].Error = errAppTimeout.Error()
+				}
+			}
+			break collect
+		case <-ctx.Done():
+			for i := range results {
+				if errs[i] == nil && !answered[i] {
+					results[i].Error = ctx.Err().Error()
+				}
+			}
+			break collect
+		}
+		answered[r.index] = true
+		checkTxRes, err := checkTxResponse(r.res)
+		if err != nil {
+			results[r.index].Error = err.Error()
+			continue
+		}
+		results[r.index].Code = checkTxRes.Code
+		results[r.index].Data = checkTxRes.Data
+		results[r.index].Log = checkTxRes.Log
+		results[r.index].Codespace = checkTxRes.Codespace
+		if checkTxRes.Code == abci.CodeTypeOK {
+			accepted = append(accepted, args.Txs[r.index])
+		}
+	}
+	s.vm.gossipTxs(accepted)
+
+	reply.Results = results
 	return nil
 }
 
@@ -313,6 +662,11 @@ func (s *LocalService) Block(_ *http.Request, args *BlockHeightArgs, reply *ctyp
 	if err != nil {
 		return err
 	}
+	if cached, ok := s.vm.queryCache.getBlock(height); ok {
+		*reply = cached
+		return nil
+	}
+
 	block := s.vm.blockStore.LoadBlock(height)
 	blockMeta := s.vm.blockStore.LoadBlockMeta(height)
 
@@ -320,6 +674,9 @@ func (s *LocalService) Block(_ *http.Request, args *BlockHeightArgs, reply *ctyp
 		reply.BlockID = blockMeta.BlockID
 	}
 	reply.Block = block
+	if block != nil && blockMeta != nil {
+		s.vm.queryCache.put(blockQuery, height, *reply)
+	}
 	return nil
 }
 
@@ -341,6 +698,10 @@ func (s *LocalService) BlockResults(_ *http.Request, args *BlockHeightArgs, repl
 	if err != nil {
 		return err
 	}
+	if cached, ok := s.vm.queryCache.getBlockResults(height); ok {
+		*reply = cached
+		return nil
+	}
 
 	results, err := s.vm.stateStore.LoadABCIResponses(height)
 	if err != nil {
@@ -353,26 +714,64 @@ func (s *LocalService) BlockResults(_ *http.Request, args *BlockHeightArgs, repl
 	reply.EndBlockEvents = results.EndBlock.Events
 	reply.ValidatorUpdates = results.EndBlock.ValidatorUpdates
 	reply.ConsensusParamUpdates = results.EndBlock.ConsensusParamUpdates
+	s.vm.queryCache.put(blockResultsQuery, height, *reply)
+	return nil
+}
+
+// LatestBlockEvents returns the header of the latest accepted block along with
+// its BeginBlock, DeliverTx and EndBlock events, which otherwise takes a Block
+// and a BlockResults call.
+func (s *LocalService) LatestBlockEvents(_ *http.Request, _ *struct{}, reply *LatestBlockEventsReply) error {
+	height := s.vm.blockStore.Height()
+	blockMeta := s.vm.blockStore.LoadBlockMeta(height)
+	if blockMeta == nil {
+		return errors.New("no block has been accepted yet")
+	}
+
+	results := new(ctypes.ResultBlockResults)
+	if err := s.BlockResults(nil, &BlockHeightArgs{Height: &height}, results); err != nil {
+		return err
+	}
+
+	reply.BlockID = blockMeta.BlockID
+	reply.Header = blockMeta.Header
+	reply.BeginBlockEvents = results.BeginBlockEvents
+	reply.TxsEvents = make([][]abci.Event, len(results.TxsResults))
+	for i, txResult := range results.TxsResults {
+		reply.TxsEvents[i] = txResult.Events
+	}
+	reply.EndBlockEvents = results.EndBlockEvents
 	return nil
 }
 
-func (s *LocalService) Commit(_ *http.Request, args *CommitArgs, reply *ctypes.ResultCommit) error {
+func (s *LocalService) Commit(_ *http.Request, args *CommitArgs, reply *CommitReply) error {
 	height, err := getHeight(s.vm.blockStore, args.Height)
 	if err != nil {
 		return err
 	}
+	if cached, ok := s.vm.queryCache.getCommit(height); ok {
+		reply.ResultCommit = cached
+	} else {
+		blockMeta := s.vm.blockStore.LoadBlockMeta(height)
+		if blockMeta == nil {
+			return nil
+		}
 
-	blockMeta := s.vm.blockStore.LoadBlockMeta(height)
-	if blockMeta == nil {
-		return nil
-	}
+		header := blockMeta.Header
+		commit := s.vm.blockStore.LoadBlockCommit(height)
+		res := ctypes.NewResultCommit(&header, commit, !(height == s.vm.blockStore.Height()))
 
-	header := blockMeta.Header
-	commit := s.vm.blockStore.LoadBlockCommit(height)
-	res := ctypes.NewResultCommit(&header, commit, !(height == s.vm.blockStore.Height()))
+		reply.SignedHeader = res.SignedHeader
+		reply.CanonicalCommit = res.CanonicalCommit
+		s.vm.queryCache.put(commitQuery, height, reply.ResultCommit)
+	}
 
-	reply.SignedHeader = res.SignedHeader
-	reply.CanonicalCommit = res.CanonicalCommit
+	if s.vm.config.AttestCommits {
+		reply.Attestation, err = s.vm.attestHeader(reply.Header)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -382,13 +781,17 @@ func (s *LocalService) Validators(_ *http.Request, args *ValidatorsArgs, reply *
 		return err
 	}
 
-	validators, err := s.vm.stateStore.LoadValidators(height)
-	if err != nil {
-		return err
+	validators, ok := s.vm.queryCache.getValidators(height)
+	if !ok {
+		validators, err = s.vm.stateStore.LoadValidators(height)
+		if err != nil {
+			return err
+		}
+		s.vm.queryCache.put(validatorsQuery, height, validators)
 	}
 
 	totalCount := len(validators.Validators)
-	perPage := validatePerPage(args.PerPage)
+	perPage := validatePerPage(args.PerPage, s.vm.config)
 	page, err := validatePage(args.Page, perPage, totalCount)
 	if err != nil {
 		return err
@@ -403,6 +806,37 @@ func (s *LocalService) Validators(_ *http.Request, args *ValidatorsArgs, reply *
 	return nil
 }
 
+// ValidatorSetChanges returns the validators which joined or left the set, and
+// the ones whose voting power changed, between the previous height and the
+// given one. At the initial height every validator is reported as joined.
+func (s *LocalService) ValidatorSetChanges(
+	_ *http.Request,
+	args *ValidatorSetChangesArgs,
+	reply *ValidatorSetChangesReply,
+) error {
+	height, err := getHeight(s.vm.blockStore, args.Height)
+	if err != nil {
+		return err
+	}
+
+	validators, err := s.vm.stateStore.LoadValidators(height)
+	if err != nil {
+		return err
+	}
+
+	prevValidators := types.NewValidatorSet(nil)
+	if height > s.vm.genesis.InitialHeight {
+		prevValidators, err = s.vm.stateStore.LoadValidators(height - 1)
+		if err != nil {
+			return err
+		}
+	}
+
+	reply.BlockHeight = height
+	reply.Joined, reply.Left, reply.PowerChanges = diffValidatorSets(prevValidators, validators)
+	return nil
+}
+
 func (s *LocalService) Tx(_ *http.Request, args *TxArgs, reply *ctypes.ResultTx) error {
 	r, err := s.vm.txIndexer.Get(args.Hash)
 	if err != nil {
@@ -431,11 +865,79 @@ func (s *LocalService) Tx(_ *http.Request, args *TxArgs, reply *ctypes.ResultTx)
 	return nil
 }
 
-func (s *LocalService) TxSearch(req *http.Request, args *TxSearchArgs, reply *ctypes.ResultTxSearch) error {
+// TxStatus reports whether the tx is committed, pending in the mempool or
+// unknown. A tx may be briefly reported as unknown between its block being
+// accepted and it being indexed.
+func (s *LocalService) TxStatus(_ *http.Request, args *TxStatusArgs, reply *TxStatusReply) error {
+	r, err := s.vm.txIndexer.Get(args.Hash)
+	if err != nil {
+		return err
+	}
+	if r != nil {
+		reply.Status = TxStatusCommitted
+		reply.Height = r.Height
+		reply.Index = r.Index
+		reply.TxResult = &r.Result
+		return nil
+	}
+
+	if mempool, ok := s.vm.mempool.(pendingTxLocator); ok && len(args.Hash) == mempl.TxKeySize {
+		var txKey [mempl.TxKeySize]byte
+		copy(txKey[:], args.Hash)
+		if position, addedAt, ok := mempool.PendingTx(txKey); ok {
+			reply.Status = TxStatusPending
+			reply.Position = &position
+			reply.PendingFor = &Duration{time.Since(addedAt)}
+			return nil
+		}
+	}
+
+	reply.Status = TxStatusUnknown
+	return nil
+}
+
+// TxProof returns the Merkle proof of inclusion of a committed tx together
+// with a reference to the block header it was verified against. Unlike Tx, it
+// doesn't return the tx body.
+func (s *LocalService) TxProof(_ *http.Request, args *TxProofArgs, reply *TxProofReply) error {
+	r, err := s.vm.txIndexer.Get(args.Hash)
+	if err != nil {
+		return err
+	}
+
+	if r == nil {
+		return fmt.Errorf("tx (%X) not found", args.Hash)
+	}
+
+	block := s.vm.blockStore.LoadBlock(r.Height)
+	blockMeta := s.vm.blockStore.LoadBlockMeta(r.Height)
+	if block == nil || blockMeta == nil {
+		return fmt.Errorf("block at height %d is not available, lowest height is %d", r.Height, s.vm.blockStore.Base())
+	}
+
+	proof := block.Data.Txs.Proof(int(r.Index)) // XXX: overflow on 32-bit machines
+
+	reply.Hash = args.Hash
+	reply.Height = r.Height
+	reply.Index = r.Index
+	reply.BlockID = blockMeta.BlockID
+	reply.DataHash = block.DataHash
+	reply.Proof = proof.Proof
+	if err := proof.Validate(block.DataHash); err != nil {
+		reply.VerificationError = err.Error()
+	} else {
+		reply.Verified = true
+	}
+	return nil
+}
+
+func (s *LocalService) TxSearch(req *http.Request, args *TxSearchArgs, reply *TxSearchReply) error {
+	timer := newQueryTimer()
 	q, err := tmquery.New(args.Query)
 	if err != nil {
 		return err
 	}
+	timer.phase("parse")
 
 	var ctx context.Context
 	if req != nil {
@@ -448,6 +950,7 @@ func (s *LocalService) TxSearch(req *http.Request, args *TxSearchArgs, reply *ct
 	if err != nil {
 		return err
 	}
+	timer.phase("search")
 
 	// sort results (must be done before pagination)
 	switch args.OrderBy {
@@ -471,7 +974,7 @@ func (s *LocalService) TxSearch(req *http.Request, args *TxSearchArgs, reply *ct
 
 	// paginate results
 	totalCount := len(results)
-	perPage := validatePerPage(args.PerPage)
+	perPage := validatePerPage(args.PerPage, s.vm.config)
 
 	page, err := validatePage(args.Page, perPage, totalCount)
 	if err != nil {
@@ -503,10 +1006,50 @@ func (s *LocalService) TxSearch(req *http.Request, args *TxSearchArgs, reply *ct
 
 	reply.Txs = apiResults
 	reply.TotalCount = totalCount
+	timer.phase("load")
+
+	if args.IncludePending {
+		reply.PendingTxs, err = s.searchPendingTxs(q, perPage)
+		if err != nil {
+			return err
+		}
+		timer.phase("pending")
+	}
+	s.logSlowQuery("TxSearch", args.Query, totalCount, timer)
 	return nil
 }
 
+// searchPendingTxs returns up to [max] mempool txs whose CheckTx events match
+// [q], oldest first.
+func (s *LocalService) searchPendingTxs(q *tmquery.Query, max int) ([]*ctypes.ResultTx, error) {
+	mempool, ok := s.vm.mempool.(pendingTxWalker)
+	if !ok {
+		return nil, errors.New("the mempool doesn't support searching pending txs")
+	}
+
+	var (
+		results  = []*ctypes.ResultTx{}
+		matchErr error
+	)
+	mempool.WalkTxs(func(tx types.Tx, events []abci.Event) bool {
+		composite := compositeEvents(events)
+		composite[types.EventTypeKey] = []string{types.EventTx}
+		composite[types.TxHashKey] = []string{fmt.Sprintf("%X", tx.Hash())}
+		match, err := q.Matches(composite)
+		if err != nil {
+			matchErr = err
+			return false
+		}
+		if match {
+			results = append(results, &ctypes.ResultTx{Hash: tx.Hash(), Tx: tx})
+		}
+		return len(results) < max
+	})
+	return results, matchErr
+}
+
 func (s *LocalService) BlockSearch(req *http.Request, args *BlockSearchArgs, reply *ctypes.ResultBlockSearch) error {
+	timer := newQueryTimer()
 	q, err := tmquery.New(args.Query)
 	if err != nil {
 		return err
@@ -519,26 +1062,60 @@ func (s *LocalService) BlockSearch(req *http.Request, args *BlockSearchArgs, rep
 		ctx = context.Background()
 	}
 
-	results, err := s.vm.blockIndexer.Search(ctx, q)
+	conditions, err := q.Conditions()
 	if err != nil {
 		return err
 	}
+	timer.phase("parse")
 
-	// sort results (must be done before pagination)
-	switch args.OrderBy {
-	case "desc", "":
-		sort.Slice(results, func(i, j int) bool { return results[i] > results[j] })
+	var (
+		totalCount int
+		heightAt   func(i int) int64
+	)
+	if minHeight, maxHeight, ok := blockHeightRange(conditions); ok {
+		// pure height range queries are served from the block store directly,
+		// without searching the indexer
+		minHeight = tmmath.MaxInt64(minHeight, s.vm.blockStore.Base())
+		maxHeight = tmmath.MinInt64(maxHeight, s.vm.blockStore.Height())
+		if minHeight <= maxHeight {
+			totalCount = int(maxHeight - minHeight + 1)
+		}
 
-	case "asc":
-		sort.Slice(results, func(i, j int) bool { return results[i] < results[j] })
+		switch args.OrderBy {
+		case "desc", "":
+			heightAt = func(i int) int64 { return maxHeight - int64(i) }
 
-	default:
-		return errors.New("expected order_by to be either `asc` or `desc` or empty")
+		case "asc":
+			heightAt = func(i int) int64 { return minHeight + int64(i) }
+
+		default:
+			return errors.New("expected order_by to be either `asc` or `desc` or empty")
+		}
+	} else {
+		results, err := s.vm.blockIndexer.Search(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		// sort results (must be done before pagination)
+		switch args.OrderBy {
+		case "desc", "":
+			sort.Slice(results, func(i, j int) bool { return results[i] > results[j] })
+
+		case "asc":
+			sort.Slice(results, func(i, j int) bool { return results[i] < results[j] })
+
+		default:
+			return errors.New("expected order_by to be either `asc` or `desc` or empty")
+		}
+
+		totalCount = len(results)
+		heightAt = func(i int) int64 { return results[i] }
 	}
+	timer.phase("search")
 
 	// paginate results
-	totalCount := len(results)
-	perPage := validatePerPage(args.PerPage)
+	perPage := validatePerPage(args.PerPage, s.vm.config)
 
 	page, err := validatePage(args.Page, perPage, totalCount)
 	if err != nil {
@@ -550,7 +1127,7 @@ func (s *LocalService) BlockSearch(req *http.Request, args *BlockSearchArgs, rep
 
 	apiResults := make([]*ctypes.ResultBlock, 0, pageSize)
 	for i := skipCount; i < skipCount+pageSize; i++ {
-		block := s.vm.blockStore.LoadBlock(results[i])
+		block := s.vm.blockStore.LoadBlock(heightAt(i))
 		if block != nil {
 			blockMeta := s.vm.blockStore.LoadBlockMeta(block.Height)
 			if blockMeta != nil {
@@ -564,6 +1141,8 @@ func (s *LocalService) BlockSearch(req *http.Request, args *BlockSearchArgs, rep
 
 	reply.Blocks = apiResults
 	reply.TotalCount = totalCount
+	timer.phase("load")
+	s.logSlowQuery("BlockSearch", args.Query, totalCount, timer)
 	return nil
 }
 
@@ -606,6 +1185,11 @@ func (s *LocalService) Genesis(_ *http.Request, _ *struct{}, reply *ctypes.Resul
 	return nil
 }
 
+func (s *LocalService) GenesisHash(_ *http.Request, _ *struct{}, reply *GenesisHashReply) error {
+	reply.Hash = s.vm.genesisHash
+	return nil
+}
+
 func (s *LocalService) GenesisChunked(_ *http.Request, args *GenesisChunkedArgs, reply *ctypes.ResultGenesisChunk) error {
 	if s.vm.genChunks == nil {
 		return fmt.Errorf("service configuration error, genesis chunks are not initialized")
@@ -659,8 +1243,18 @@ func (s *LocalService) Status(_ *http.Request, _ *struct{}, reply *ctypes.Result
 	}
 
 	reply.NodeInfo = p2p.DefaultNodeInfo{
+		ProtocolVersion: p2p.NewProtocolVersion(
+			tmversion.P2PProtocol,
+			s.vm.tmState.Version.Consensus.Block,
+			s.vm.tmState.Version.Consensus.App,
+		),
 		DefaultNodeID: p2p.ID(s.vm.ctx.NodeID.String()),
-		Network:       fmt.Sprintf("%d", s.vm.ctx.NetworkID),
+		Network:       s.vm.genesis.ChainID,
+		Version:       tmversion.TMCoreSemVer,
+		Moniker:       Name + " " + Version.String(),
+	}
+	reply.ValidatorInfo = ctypes.ValidatorInfo{
+		Address: proposerAddressOf(s.vm.ctx.NodeID),
 	}
 	reply.SyncInfo = ctypes.SyncInfo{
 		LatestBlockHash:     latestBlockHash,
@@ -675,8 +1269,35 @@ func (s *LocalService) Status(_ *http.Request, _ *struct{}, reply *ctypes.Result
 	return nil
 }
 
-// ToDo: no peers, because it's vm
+func (s *LocalService) BuildInfo(_ *http.Request, _ *struct{}, reply *BuildInfo) error {
+	*reply = s.vm.buildInfo()
+	return nil
+}
+
+// NetInfo returns the connected peers running the chain. Their IPs and
+// listen addresses aren't known to the VM, their version is the version of
+// avalanchego they run.
 func (s *LocalService) NetInfo(_ *http.Request, _ *struct{}, reply *ctypes.ResultNetInfo) error {
+	peers := s.vm.network.peerInfos()
+	reply.Listening = true
+	reply.NPeers = len(peers)
+	reply.Peers = make([]ctypes.Peer, 0, len(peers))
+	for _, peer := range peers {
+		var peerVersion string
+		if peer.version != nil {
+			peerVersion = peer.version.String()
+		}
+		reply.Peers = append(reply.Peers, ctypes.Peer{
+			NodeInfo: p2p.DefaultNodeInfo{
+				DefaultNodeID: p2p.ID(peer.nodeID.String()),
+				Network:       s.vm.genesis.ChainID,
+				Version:       peerVersion,
+			},
+			ConnectionStatus: p2p.ConnectionStatus{
+				Duration: time.Since(peer.connectedAt),
+			},
+		})
+	}
 	return nil
 }
 
@@ -690,30 +1311,78 @@ func (s *LocalService) ConsensusState(_ *http.Request, _ *struct{}, reply *ctype
 	return nil
 }
 
+// ConsensusParams returns the consensus params of the block at the height. If
+// it is unset, it returns the latest height with the params the next block
+// will be built with, which include the updates of the app up to that height.
 func (s *LocalService) ConsensusParams(_ *http.Request, args *ConsensusParamsArgs, reply *ctypes.ResultConsensusParams) error {
-	reply.BlockHeight = s.vm.blockStore.Height()
-	reply.ConsensusParams = *s.vm.genesis.ConsensusParams
+	if args == nil || args.Height == nil {
+		reply.BlockHeight = s.vm.blockStore.Height()
+		reply.ConsensusParams = s.vm.tmState.ConsensusParams
+		return nil
+	}
+
+	height, err := getHeight(s.vm.blockStore, args.Height)
+	if err != nil {
+		return err
+	}
+	params, err := s.vm.stateStore.LoadConsensusParams(height)
+	if err != nil {
+		return err
+	}
+	reply.BlockHeight = height
+	reply.ConsensusParams = params
 	return nil
 }
 
-func (s *LocalService) Health(_ *http.Request, _ *struct{}, reply *ctypes.ResultHealth) error {
+// Health fails if the node is unhealthy, see HealthCheck.
+func (s *LocalService) Health(req *http.Request, _ *struct{}, reply *ctypes.ResultHealth) error {
+	ctx := context.Background()
+	if req != nil {
+		ctx = req.Context()
+	}
+	if _, err := s.vm.HealthCheck(ctx); err != nil {
+		return err
+	}
 	*reply = ctypes.ResultHealth{}
 	return nil
 }
 
 func (s *LocalService) UnconfirmedTxs(_ *http.Request, args *UnconfirmedTxsArgs, reply *ctypes.ResultUnconfirmedTxs) error {
-	limit := validatePerPage(args.Limit)
-	txs := s.vm.mempool.ReapMaxTxs(limit)
+	limit := validatePerPage(args.Limit, s.vm.config)
+	var txs types.Txs
+	if args.Sender != "" {
+		mempool, ok := s.vm.mempool.(senderTxsReaper)
+		if !ok {
+			return errors.New("the mempool doesn't support filtering by sender")
+		}
+		txs = mempool.ReapMaxTxsBySender(args.Sender, limit)
+	} else {
+		txs = s.vm.mempool.ReapMaxTxs(limit)
+	}
 	reply.Count = len(txs)
 	reply.Total = s.vm.mempool.Size()
 	reply.Txs = txs
 	return nil
 }
 
-func (s *LocalService) NumUnconfirmedTxs(_ *http.Request, _ *struct{}, reply *ctypes.ResultUnconfirmedTxs) error {
+func (s *LocalService) NumUnconfirmedTxs(_ *http.Request, _ *struct{}, reply *NumUnconfirmedTxsReply) error {
 	reply.Count = s.vm.mempool.Size()
 	reply.Total = s.vm.mempool.Size()
 	reply.TotalBytes = s.vm.mempool.TxsBytes()
+	if mempool, ok := s.vm.mempool.(oldestTxTimer); ok {
+		if oldest, ok := mempool.OldestTxTime(); ok {
+			reply.OldestTxAge = Duration{time.Since(oldest)}
+		}
+	}
+	if mempool, ok := s.vm.mempool.(txSizeWalker); ok {
+		histogram := newTxSizeHistogram()
+		mempool.TxSizes(func(size int) {
+			countTxSize(histogram, size)
+		})
+		reply.SizeHistogram = histogram
+	} else {
+		reply.SizeHistogram = txSizeHistogram(s.vm.mempool.ReapMaxTxs(-1))
+	}
 	return nil
 }
 
@@ -1,12 +1,35 @@
 package vm
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+
+	"github.com/consideritdone/landslidecore/abci/example/counter"
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
 	atypes "github.com/consideritdone/landslidecore/abci/types"
+	"github.com/consideritdone/landslidecore/crypto"
+	"github.com/consideritdone/landslidecore/crypto/ed25519"
+	cryptoenc "github.com/consideritdone/landslidecore/crypto/encoding"
+	"github.com/consideritdone/landslidecore/libs/log"
+	tmquery "github.com/consideritdone/landslidecore/libs/pubsub/query"
+	mempl "github.com/consideritdone/landslidecore/mempool"
+	"github.com/consideritdone/landslidecore/p2p"
 	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
+	tmversion "github.com/consideritdone/landslidecore/version"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,7 +51,7 @@ func TestABCIService(t *testing.T) {
 		k, v, tx := MakeTxKV()
 
 		replyBroadcast := new(ctypes.ResultBroadcastTx)
-		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{tx}, replyBroadcast))
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: tx}, replyBroadcast))
 
 		blk, err := vm.BuildBlock(context.Background())
 		require.NoError(t, err)
@@ -67,12 +90,14 @@ func TestABCIService(t *testing.T) {
 			}
 		}(ctx)
 
-		_, _, tx := MakeTxKV()
-		reply := new(ctypes.ResultBroadcastTxCommit)
-		assert.NoError(t, service.BroadcastTxCommit(nil, &BroadcastTxArgs{tx}, reply))
+		k, _, tx := MakeTxKV()
+		reply := new(BroadcastTxCommitReply)
+		assert.NoError(t, service.BroadcastTxCommit(nil, &BroadcastTxCommitArgs{Tx: tx, CompositeKeys: true}, reply))
 		assert.True(t, reply.CheckTx.IsOK())
 		assert.True(t, reply.DeliverTx.IsOK())
 		assert.Equal(t, 0, vm.mempool.Size())
+		assert.Len(t, reply.Events, len(reply.DeliverTx.Events))
+		assert.Equal(t, []string{string(k)}, reply.CompositeEvents["app.key"])
 	})
 
 	t.Run("BroadcastTxAsync", func(t *testing.T) {
@@ -82,12 +107,41 @@ func TestABCIService(t *testing.T) {
 		_, _, tx := MakeTxKV()
 
 		reply := new(ctypes.ResultBroadcastTx)
-		assert.NoError(t, service.BroadcastTxAsync(nil, &BroadcastTxArgs{tx}, reply))
+		assert.NoError(t, service.BroadcastTxAsync(nil, &BroadcastTxArgs{Tx: tx}, reply))
 		assert.NotNil(t, reply.Hash)
 		assert.Equal(t, initMempoolSize+1, vm.mempool.Size())
 		assert.EqualValues(t, tx, vm.mempool.ReapMaxTxs(-1)[0])
 	})
 
+	t.Run("BroadcastTxBatch", func(t *testing.T) {
+		defer vm.mempool.Flush()
+
+		initMempoolSize := vm.mempool.Size()
+		_, _, tx1 := MakeTxKV()
+		_, _, tx2 := MakeTxKV()
+
+		reply := new(BroadcastTxBatchReply)
+		assert.NoError(t, service.BroadcastTxBatch(nil, &BroadcastTxBatchArgs{Txs: []types.Tx{tx1, tx2, tx1}}, reply))
+		if assert.Len(t, reply.Results, 3) {
+			assert.Equal(t, atypes.CodeTypeOK, reply.Results[0].Code)
+			assert.EqualValues(t, types.Tx(tx1).Hash(), reply.Results[0].Hash)
+			assert.Empty(t, reply.Results[0].Error)
+			assert.Equal(t, atypes.CodeTypeOK, reply.Results[1].Code)
+			assert.EqualValues(t, types.Tx(tx2).Hash(), reply.Results[1].Hash)
+			// the duplicate is rejected by the cache
+			assert.NotEmpty(t, reply.Results[2].Error)
+		}
+		assert.Equal(t, initMempoolSize+2, vm.mempool.Size())
+
+		// batches over the limit are rejected as a whole
+		txs := make([]types.Tx, vm.config.MaxBatchTxs+1)
+		for i := range txs {
+			_, _, txs[i] = MakeTxKV()
+		}
+		assert.Error(t, service.BroadcastTxBatch(nil, &BroadcastTxBatchArgs{Txs: txs}, new(BroadcastTxBatchReply)))
+		assert.Equal(t, initMempoolSize+2, vm.mempool.Size())
+	})
+
 	t.Run("BroadcastTxSync", func(t *testing.T) {
 		defer vm.mempool.Flush()
 
@@ -125,6 +179,13 @@ func TestHistoryService(t *testing.T) {
 		assert.NoError(t, service.Genesis(nil, nil, reply))
 		assert.Equal(t, vm.genesis, reply.Genesis)
 	})
+
+	t.Run("GenesisHash", func(t *testing.T) {
+		reply := new(GenesisHashReply)
+		assert.NoError(t, service.GenesisHash(nil, nil, reply))
+		hash := sha256.Sum256([]byte(genesis))
+		assert.EqualValues(t, hash[:], reply.Hash)
+	})
 }
 
 func TestNetworkService(t *testing.T) {
@@ -133,6 +194,22 @@ func TestNetworkService(t *testing.T) {
 	t.Run("NetInfo", func(t *testing.T) {
 		reply := new(ctypes.ResultNetInfo)
 		assert.NoError(t, service.NetInfo(nil, nil, reply))
+		assert.Zero(t, reply.NPeers)
+
+		peerID := ids.GenerateTestNodeID()
+		require.NoError(t, vm.Connected(context.Background(), peerID, version.CurrentApp))
+		// the node itself isn't a peer
+		require.NoError(t, vm.Connected(context.Background(), vm.ctx.NodeID, version.CurrentApp))
+		reply = new(ctypes.ResultNetInfo)
+		assert.NoError(t, service.NetInfo(nil, nil, reply))
+		require.Equal(t, 1, reply.NPeers)
+		assert.Equal(t, p2p.ID(peerID.String()), reply.Peers[0].NodeInfo.ID())
+		assert.Equal(t, version.CurrentApp.String(), reply.Peers[0].NodeInfo.Version)
+
+		require.NoError(t, vm.Disconnected(context.Background(), peerID))
+		reply = new(ctypes.ResultNetInfo)
+		assert.NoError(t, service.NetInfo(nil, nil, reply))
+		assert.Zero(t, reply.NPeers)
 	})
 
 	t.Run("DumpConsensusState", func(t *testing.T) {
@@ -177,7 +254,7 @@ func TestSignService(t *testing.T) {
 	assert.ErrorIs(t, err, errNoPendingTxs, "expecting error no txs")
 	assert.Nil(t, blk0)
 
-	txArg := &BroadcastTxArgs{tx}
+	txArg := &BroadcastTxArgs{Tx: tx}
 	txReply := new(ctypes.ResultBroadcastTx)
 	err = service.BroadcastTxSync(nil, txArg, txReply)
 	assert.NoError(t, err)
@@ -230,6 +307,27 @@ func TestSignService(t *testing.T) {
 		}
 	})
 
+	t.Run("LatestBlockEvents", func(t *testing.T) {
+		reply := new(LatestBlockEventsReply)
+		assert.NoError(t, service.LatestBlockEvents(nil, nil, reply))
+		assert.Equal(t, height1, reply.Header.Height)
+		id := blk1.ID()
+		assert.EqualValues(t, id[:], reply.BlockID.Hash.Bytes())
+		if assert.Len(t, reply.TxsEvents, 1) {
+			// the kvstore app emits an "app" event for each tx
+			assert.NotEmpty(t, reply.TxsEvents[0])
+		}
+	})
+
+	t.Run("ValidatorSetChanges", func(t *testing.T) {
+		reply := new(ValidatorSetChangesReply)
+		assert.NoError(t, service.ValidatorSetChanges(nil, &ValidatorSetChangesArgs{Height: &height1}, reply))
+		assert.Equal(t, height1, reply.BlockHeight)
+		assert.Empty(t, reply.Joined)
+		assert.Empty(t, reply.Left)
+		assert.Empty(t, reply.PowerChanges)
+	})
+
 	t.Run("Tx", func(t *testing.T) {
 		time.Sleep(2 * time.Second)
 
@@ -239,8 +337,20 @@ func TestSignService(t *testing.T) {
 		assert.EqualValues(t, tx, reply.Tx)
 	})
 
+	t.Run("TxProof", func(t *testing.T) {
+		reply := new(TxProofReply)
+		assert.NoError(t, service.TxProof(nil, &TxProofArgs{Hash: txReply.Hash.Bytes()}, reply))
+		assert.EqualValues(t, txReply.Hash, reply.Hash)
+		assert.Equal(t, height1, reply.Height)
+		assert.True(t, reply.Verified)
+		assert.Empty(t, reply.VerificationError)
+		assert.NoError(t, reply.Proof.Verify(reply.DataHash, types.Tx(tx).Hash()))
+
+		assert.Error(t, service.TxProof(nil, &TxProofArgs{Hash: []byte("unknown")}, new(TxProofReply)))
+	})
+
 	//t.Run("TxSearch", func(t *testing.T) {
-	//	reply := new(ctypes.ResultTxSearch)
+	//	reply := new(TxSearchReply)
 	//	assert.NoError(t, service.TxSearch(nil, &TxSearchArgs{Query: "tx.height>0"}, reply))
 	//	assert.True(t, len(reply.Txs) > 0)
 	//})
@@ -280,6 +390,24 @@ func TestStatusService(t *testing.T) {
 		reply2 := new(ctypes.ResultStatus)
 		assert.NoError(t, service.Status(nil, nil, reply2))
 		assert.Equal(t, int64(1), reply2.SyncInfo.LatestBlockHeight)
+		assert.Equal(t, tmversion.TMCoreSemVer, reply2.NodeInfo.Version)
+		assert.Equal(t, vm.tmState.Version.Consensus.Block, reply2.NodeInfo.ProtocolVersion.Block)
+	})
+
+	t.Run("BuildInfo", func(t *testing.T) {
+		reply := new(BuildInfo)
+		assert.NoError(t, service.BuildInfo(nil, nil, reply))
+		assert.Equal(t, Version.String(), reply.Version)
+		assert.Equal(t, tmversion.TMCoreSemVer, reply.TendermintVersion)
+
+		infoReply := new(ctypes.ResultABCIInfo)
+		assert.NoError(t, service.ABCIInfo(nil, nil, infoReply))
+		assert.Equal(t, infoReply.Response.Version, reply.AppVersion)
+		assert.Equal(t, infoReply.Response.AppVersion, reply.AppProtocol)
+
+		version, err := vm.Version(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, reply.String(), version)
 	})
 }
 
@@ -307,10 +435,14 @@ func TestMempoolService(t *testing.T) {
 	})
 
 	t.Run("NumUnconfirmedTxs", func(t *testing.T) {
-		reply := new(ctypes.ResultUnconfirmedTxs)
+		reply := new(NumUnconfirmedTxsReply)
 		assert.NoError(t, service.NumUnconfirmedTxs(nil, nil, reply))
 		assert.Equal(t, reply.Count, 1)
 		assert.Equal(t, reply.Total, 1)
+		assert.Greater(t, reply.OldestTxAge.Duration, time.Duration(0))
+		if assert.Len(t, reply.SizeHistogram, len(txSizeBuckets)+1) {
+			assert.Equal(t, TxSizeBucket{MaxBytes: 256, Count: 1}, reply.SizeHistogram[0])
+		}
 	})
 
 	t.Run("CheckTx", func(t *testing.T) {
@@ -328,3 +460,522 @@ func TestMempoolService(t *testing.T) {
 		// ToDo: check reply2
 	})
 }
+
+func TestDiffValidatorSets(t *testing.T) {
+	val1 := types.NewValidator(ed25519.GenPrivKey().PubKey(), 10)
+	val2 := types.NewValidator(ed25519.GenPrivKey().PubKey(), 20)
+	val3 := types.NewValidator(ed25519.GenPrivKey().PubKey(), 30)
+
+	prev := types.NewValidatorSet([]*types.Validator{val1.Copy(), val2.Copy()})
+	val2.VotingPower = 25
+	next := types.NewValidatorSet([]*types.Validator{val2.Copy(), val3.Copy()})
+
+	joined, left, powerChanges := diffValidatorSets(prev, next)
+	if assert.Len(t, joined, 1) {
+		assert.Equal(t, val3.Address, joined[0].Address)
+	}
+	if assert.Len(t, left, 1) {
+		assert.Equal(t, val1.Address, left[0].Address)
+	}
+	if assert.Len(t, powerChanges, 1) {
+		assert.Equal(t, val2.Address, powerChanges[0].Address)
+		assert.Equal(t, int64(20), powerChanges[0].OldPower)
+		assert.Equal(t, int64(25), powerChanges[0].NewPower)
+	}
+
+	joined, left, powerChanges = diffValidatorSets(types.NewValidatorSet(nil), next)
+	assert.Len(t, joined, 2)
+	assert.Empty(t, left)
+	assert.Empty(t, powerChanges)
+}
+
+// pruningApp fails queries below its retain height the way the Cosmos SDK
+// does for pruned versions.
+type pruningApp struct {
+	heightApp
+	retainHeight int64
+}
+
+func (app *pruningApp) Query(req atypes.RequestQuery) atypes.ResponseQuery {
+	if req.Height != 0 && req.Height < app.retainHeight {
+		return atypes.ResponseQuery{
+			Code: 1,
+			Log: fmt.Sprintf("failed to load state at height %d; version does not exist (latest height: %d)",
+				req.Height, app.height),
+		}
+	}
+	return atypes.ResponseQuery{Height: req.Height}
+}
+
+func TestABCIQueryWithOptionsHeights(t *testing.T) {
+	app := &pruningApp{retainHeight: 3}
+	vm, _, _, err := newTestVM(app)
+	require.NoError(t, err)
+	service := NewService(vm)
+	for i := byte(0); i < 3; i++ {
+		mustAcceptBlock(t, vm, service, []byte{i})
+	}
+
+	query := func(height int64) error {
+		return service.ABCIQueryWithOptions(nil, &ABCIQueryWithOptionsArgs{
+			Path: "/store",
+			Opts: ABCIQueryOptions{Height: height},
+		}, new(ctypes.ResultABCIQuery))
+	}
+
+	// heights above the latest are rejected before reaching the app
+	err = query(4)
+	require.Error(t, err)
+	assert.False(t, errors.As(err, new(ErrHeightPruned)))
+
+	// heights pruned by the app are reported as pruned
+	assert.Equal(t, ErrHeightPruned{Height: 2}, query(2))
+	assert.NoError(t, query(3))
+	assert.NoError(t, query(0))
+}
+
+func TestValidatorSetChanges(t *testing.T) {
+	app := kvstore.NewPersistentKVStoreApplication(t.TempDir())
+	vm, _, _, err := newTestVM(app)
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	valTx := func(pubKey crypto.PubKey, power int64) []byte {
+		pk, err := cryptoenc.PubKeyToProto(pubKey)
+		require.NoError(t, err)
+		return kvstore.MakeValSetChangeTx(pk, power)
+	}
+	pubKey1 := ed25519.GenPrivKey().PubKey()
+	pubKey2 := ed25519.GenPrivKey().PubKey()
+
+	// updates returned by EndBlock at height h apply from height h + 2
+	mustAcceptBlock(t, vm, service, valTx(pubKey1, 10))
+	mustAcceptBlock(t, vm, service, valTx(pubKey1, 20))
+	mustAcceptBlock(t, vm, service, valTx(pubKey2, 5), valTx(pubKey1, 0))
+	for i := 0; i < 2; i++ {
+		_, _, tx := MakeTxKV()
+		mustAcceptBlock(t, vm, service, tx)
+	}
+
+	changes := func(height int64) *ValidatorSetChangesReply {
+		reply := new(ValidatorSetChangesReply)
+		require.NoError(t, service.ValidatorSetChanges(nil, &ValidatorSetChangesArgs{Height: &height}, reply))
+		return reply
+	}
+
+	reply := changes(2)
+	assert.Empty(t, reply.Joined)
+	assert.Empty(t, reply.Left)
+	assert.Empty(t, reply.PowerChanges)
+
+	reply = changes(3)
+	if assert.Len(t, reply.Joined, 1) {
+		assert.Equal(t, pubKey1.Address(), reply.Joined[0].Address)
+		assert.Equal(t, int64(10), reply.Joined[0].VotingPower)
+	}
+	assert.Empty(t, reply.Left)
+
+	reply = changes(4)
+	assert.Empty(t, reply.Joined)
+	if assert.Len(t, reply.PowerChanges, 1) {
+		assert.Equal(t, int64(10), reply.PowerChanges[0].OldPower)
+		assert.Equal(t, int64(20), reply.PowerChanges[0].NewPower)
+	}
+
+	reply = changes(5)
+	if assert.Len(t, reply.Joined, 1) {
+		assert.Equal(t, pubKey2.Address(), reply.Joined[0].Address)
+	}
+	if assert.Len(t, reply.Left, 1) {
+		assert.Equal(t, pubKey1.Address(), reply.Left[0].Address)
+	}
+	assert.Empty(t, reply.PowerChanges)
+
+	assert.Equal(t, int64(5), vm.tmState.LastHeightValidatorsChanged)
+}
+
+func TestQueryCache(t *testing.T) {
+	vm, service, _ := mustNewCounterTestVm(t)
+	vm.queryCache = newQueryCache(16)
+
+	mustAcceptBlock(t, vm, service, []byte{0})
+	height := int64(1)
+
+	blockReply := new(ctypes.ResultBlock)
+	require.NoError(t, service.Block(nil, &BlockHeightArgs{Height: &height}, blockReply))
+	cachedBlock, ok := vm.queryCache.getBlock(height)
+	require.True(t, ok)
+	assert.Equal(t, *blockReply, cachedBlock)
+
+	commitReply := new(CommitReply)
+	require.NoError(t, service.Commit(nil, &CommitArgs{Height: &height}, commitReply))
+	assert.False(t, commitReply.CanonicalCommit)
+
+	// accepting the next block makes the commit canonical
+	mustAcceptBlock(t, vm, service, []byte{1})
+
+	commitReply = new(CommitReply)
+	require.NoError(t, service.Commit(nil, &CommitArgs{Height: &height}, commitReply))
+	assert.True(t, commitReply.CanonicalCommit)
+
+	vm.queryCache.flush()
+	_, ok = vm.queryCache.getBlock(height)
+	assert.False(t, ok)
+}
+
+func TestABCIInfoCache(t *testing.T) {
+	vm, service, _ := mustNewCounterTestVm(t)
+	vm.abciInfoCache = newABCIInfoCache(time.Hour)
+
+	info := func() string {
+		reply := new(ctypes.ResultABCIInfo)
+		require.NoError(t, service.ABCIInfo(nil, nil, reply))
+		return reply.Response.Data
+	}
+
+	assert.Equal(t, `{"hashes":0,"txs":0}`, info())
+	_, _, ok := vm.abciInfoCache.get()
+	require.True(t, ok)
+
+	// committing a block invalidates the cached reply
+	mustAcceptBlock(t, vm, service, []byte{0})
+	_, _, ok = vm.abciInfoCache.get()
+	assert.False(t, ok)
+	assert.Equal(t, `{"hashes":1,"txs":1}`, info())
+
+	// a reply fetched before an invalidation isn't cached
+	_, generation, _ := vm.abciInfoCache.get()
+	vm.abciInfoCache.invalidate()
+	vm.abciInfoCache.put(ctypes.ResultABCIInfo{}, generation)
+	_, _, ok = vm.abciInfoCache.get()
+	assert.False(t, ok)
+	assert.Equal(t, `{"hashes":1,"txs":1}`, info())
+
+	// so does the ttl
+	vm.abciInfoCache = newABCIInfoCache(time.Millisecond)
+	info()
+	time.Sleep(2 * time.Millisecond)
+	_, _, ok = vm.abciInfoCache.get()
+	assert.False(t, ok)
+}
+
+func TestValidatePerPage(t *testing.T) {
+	config := DefaultConfig()
+	config.DefaultPerPage = 5
+	config.MaxPerPage = 10
+
+	perPage := func(v int) *int { return &v }
+	assert.Equal(t, 5, validatePerPage(nil, config))
+	assert.Equal(t, 5, validatePerPage(perPage(0), config))
+	assert.Equal(t, 7, validatePerPage(perPage(7), config))
+	assert.Equal(t, 10, validatePerPage(perPage(1000), config))
+}
+
+func TestBlockSearchHeightRange(t *testing.T) {
+	vm, service, _ := mustNewCounterTestVm(t)
+
+	for i := byte(0); i < 4; i++ {
+		mustAcceptBlock(t, vm, service, []byte{i})
+	}
+
+	heights := func(reply *ctypes.ResultBlockSearch) []int64 {
+		var heights []int64
+		for _, b := range reply.Blocks {
+			heights = append(heights, b.Block.Height)
+		}
+		return heights
+	}
+
+	reply := new(ctypes.ResultBlockSearch)
+	require.NoError(t, service.BlockSearch(nil, &BlockSearchArgs{Query: "block.height >= 2 AND block.height <= 3"}, reply))
+	assert.Equal(t, 2, reply.TotalCount)
+	assert.Equal(t, []int64{3, 2}, heights(reply))
+
+	reply = new(ctypes.ResultBlockSearch)
+	require.NoError(t, service.BlockSearch(nil, &BlockSearchArgs{Query: "block.height > 1", OrderBy: "asc"}, reply))
+	assert.Equal(t, 3, reply.TotalCount)
+	assert.Equal(t, []int64{2, 3, 4}, heights(reply))
+
+	perPage, page := 1, 2
+	reply = new(ctypes.ResultBlockSearch)
+	require.NoError(t, service.BlockSearch(nil, &BlockSearchArgs{
+		Query:   "block.height < 100",
+		Page:    &page,
+		PerPage: &perPage,
+	}, reply))
+	assert.Equal(t, 4, reply.TotalCount)
+	assert.Equal(t, []int64{3}, heights(reply))
+
+	reply = new(ctypes.ResultBlockSearch)
+	require.NoError(t, service.BlockSearch(nil, &BlockSearchArgs{Query: "block.height > 10"}, reply))
+	assert.Equal(t, 0, reply.TotalCount)
+	assert.Empty(t, reply.Blocks)
+}
+
+func TestTxSizeHistogram(t *testing.T) {
+	histogram := txSizeHistogram(types.Txs{
+		make([]byte, 10),
+		make([]byte, 256),
+		make([]byte, 257),
+		make([]byte, 100000),
+	})
+	assert.Equal(t, []TxSizeBucket{
+		{MaxBytes: 256, Count: 2},
+		{MaxBytes: 1024, Count: 1},
+		{MaxBytes: 4096},
+		{MaxBytes: 16384},
+		{MaxBytes: 65536},
+		{Count: 1},
+	}, histogram)
+}
+
+func TestBlockHeightRange(t *testing.T) {
+	tests := []struct {
+		query    string
+		min, max int64
+		ok       bool
+	}{
+		{"block.height >= 5 AND block.height <= 10", 5, 10, true},
+		{"block.height > 5 AND block.height < 10", 6, 9, true},
+		{"block.height = 7", 7, 7, true},
+		{"block.height >= 5 AND end_event.foo = 'bar'", 0, 0, false},
+		{"block.height CONTAINS '5'", 0, 0, false},
+	}
+	for _, tt := range tests {
+		conditions, err := tmquery.MustParse(tt.query).Conditions()
+		require.NoError(t, err)
+		min, max, ok := blockHeightRange(conditions)
+		assert.Equal(t, tt.ok, ok, tt.query)
+		if tt.ok {
+			assert.Equal(t, tt.min, min, tt.query)
+			assert.Equal(t, tt.max, max, tt.query)
+		}
+	}
+}
+
+// senderApp reports the key of "key=value" txs as their sender in CheckTx.
+type senderApp struct {
+	*kvstore.Application
+}
+
+func (app senderApp) CheckTx(req atypes.RequestCheckTx) atypes.ResponseCheckTx {
+	res := app.Application.CheckTx(req)
+	sender, _, _ := strings.Cut(string(req.Tx), "=")
+	res.Events = []atypes.Event{{
+		Type:       "message",
+		Attributes: []atypes.EventAttribute{{Key: []byte(mempl.SenderAttributeKey), Value: []byte(sender)}},
+	}}
+	return res
+}
+
+func TestUnconfirmedTxsBySender(t *testing.T) {
+	vm, _, _, err := newTestVM(senderApp{kvstore.NewApplication()})
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	for _, tx := range []string{"alice=1", "bob=1", "alice=2"} {
+		txReply := new(ctypes.ResultBroadcastTx)
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte(tx)}, txReply))
+		require.Equal(t, atypes.CodeTypeOK, txReply.Code)
+	}
+
+	reply := new(ctypes.ResultUnconfirmedTxs)
+	require.NoError(t, service.UnconfirmedTxs(nil, &UnconfirmedTxsArgs{Sender: "alice"}, reply))
+	assert.Equal(t, 2, reply.Count)
+	assert.Equal(t, 3, reply.Total)
+	assert.Equal(t, []types.Tx{types.Tx("alice=1"), types.Tx("alice=2")}, reply.Txs)
+
+	reply = new(ctypes.ResultUnconfirmedTxs)
+	require.NoError(t, service.UnconfirmedTxs(nil, &UnconfirmedTxsArgs{Sender: "carol"}, reply))
+	assert.Equal(t, 0, reply.Count)
+}
+
+func TestMethods(t *testing.T) {
+	_, service, _ := mustNewCounterTestVm(t)
+
+	reply := new(MethodsReply)
+	require.NoError(t, service.Methods(nil, nil, reply))
+	methods := make(map[string]MethodInfo, len(reply.Methods))
+	for _, method := range reply.Methods {
+		methods[method.Name] = method
+	}
+	assert.Len(t, methods, reflect.TypeOf((*Service)(nil)).Elem().NumMethod())
+
+	block, ok := methods[Name+".Block"]
+	require.True(t, ok)
+	assert.Equal(t, []FieldInfo{{Name: "height", Type: "*int64", Optional: true}}, block.Params)
+	assert.Equal(t, "coretypes.ResultBlock", block.Result)
+
+	// embedded structs are flattened
+	numUnconfirmedTxs, ok := methods[Name+".NumUnconfirmedTxs"]
+	require.True(t, ok)
+	assert.Empty(t, numUnconfirmedTxs.Params)
+	assert.Contains(t, numUnconfirmedTxs.ResultFields, FieldInfo{Name: "n_txs", Type: "int"})
+	assert.Contains(t, numUnconfirmedTxs.ResultFields, FieldInfo{Name: "oldestTxAge", Type: "vm.Duration"})
+
+	_, ok = methods[Name+".Methods"]
+	assert.True(t, ok)
+}
+
+func TestCommitAttestation(t *testing.T) {
+	sk, err := bls.NewSecretKey()
+	require.NoError(t, err)
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	vm, _, _, err := newTestVMWithGenesis(counter.NewApplication(true), dbManager, []byte(genesis), nil,
+		[]byte(`{"attestCommits":true}`), WithAttestationKey(sk))
+	require.NoError(t, err)
+	service := NewService(vm)
+	mustAcceptBlock(t, vm, service, []byte{0})
+
+	height := int64(1)
+	reply := new(CommitReply)
+	require.NoError(t, service.Commit(nil, &CommitArgs{Height: &height}, reply))
+	require.NotNil(t, reply.Attestation)
+
+	pk, err := bls.PublicKeyFromBytes(reply.Attestation.PublicKey)
+	require.NoError(t, err)
+	sig, err := bls.SignatureFromBytes(reply.Attestation.Signature)
+	require.NoError(t, err)
+	assert.True(t, bls.Verify(pk, sig, reply.Attestation.Message))
+	expected := append(append([]byte("landslide-header-attestation"), blockchainID[:]...), reply.Header.Hash()...)
+	assert.EqualValues(t, expected, reply.Attestation.Message)
+
+	// the attestation doesn't verify as a warp message, even if the
+	// attestation key is the warp key
+	_, err = warp.ParseUnsignedMessage(reply.Attestation.Message)
+	assert.Error(t, err)
+	warpMsg, err := warp.NewUnsignedMessage(blockchainID, ids.Empty, reply.Header.Hash())
+	require.NoError(t, err)
+	assert.False(t, bls.Verify(pk, sig, warpMsg.Bytes()))
+	warpSig, err := warp.NewSigner(sk, blockchainID).Sign(warpMsg)
+	require.NoError(t, err)
+	assert.NotEqualValues(t, warpSig, reply.Attestation.Signature)
+
+	// commits aren't attested by default
+	vm, service, _ = mustNewCounterTestVm(t)
+	mustAcceptBlock(t, vm, service, []byte{0})
+	reply = new(CommitReply)
+	require.NoError(t, service.Commit(nil, &CommitArgs{Height: &height}, reply))
+	assert.Nil(t, reply.Attestation)
+
+	// nor can they be without an attestation key
+	_, _, _, err = newTestVMWithDB(counter.NewApplication(true), manager.NewMemDB(&version.Semantic{Major: 1}), []byte(`{"attestCommits":true}`))
+	assert.ErrorIs(t, err, errNoAttestationKey)
+}
+
+func TestTxStatus(t *testing.T) {
+	vm, service, _ := mustNewCounterTestVm(t)
+
+	status := func(tx types.Tx) *TxStatusReply {
+		reply := new(TxStatusReply)
+		require.NoError(t, service.TxStatus(nil, &TxStatusArgs{Hash: tx.Hash()}, reply))
+		return reply
+	}
+
+	tx := types.Tx{0x00}
+	assert.Equal(t, TxStatusUnknown, status(tx).Status)
+
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: tx}, new(ctypes.ResultBroadcastTx)))
+	reply := status(tx)
+	assert.Equal(t, TxStatusPending, reply.Status)
+	if assert.NotNil(t, reply.Position) {
+		assert.Equal(t, 0, *reply.Position)
+	}
+	assert.NotNil(t, reply.PendingFor)
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, blk.Accept(context.Background()))
+	require.Eventually(t, func() bool {
+		return status(tx).Status == TxStatusCommitted
+	}, 5*time.Second, 100*time.Millisecond)
+	reply = status(tx)
+	assert.Equal(t, int64(1), reply.Height)
+	assert.Nil(t, reply.Position)
+	if assert.NotNil(t, reply.TxResult) {
+		assert.Equal(t, atypes.CodeTypeOK, reply.TxResult.Code)
+	}
+}
+
+func TestTxSearchIncludePending(t *testing.T) {
+	_, service, _ := mustNewKVTestVm(t)
+
+	_, _, tx1 := MakeTxKV()
+	_, _, tx2 := MakeTxKV()
+	for _, tx := range [][]byte{tx1, tx2} {
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: tx}, new(ctypes.ResultBroadcastTx)))
+	}
+
+	query := fmt.Sprintf("tx.hash = '%X'", types.Tx(tx2).Hash())
+	reply := new(TxSearchReply)
+	require.NoError(t, service.TxSearch(nil, &TxSearchArgs{Query: query}, reply))
+	assert.Empty(t, reply.Txs)
+	assert.Nil(t, reply.PendingTxs)
+
+	reply = new(TxSearchReply)
+	require.NoError(t, service.TxSearch(nil, &TxSearchArgs{Query: query, IncludePending: true}, reply))
+	assert.Empty(t, reply.Txs)
+	if assert.Len(t, reply.PendingTxs, 1) {
+		assert.EqualValues(t, tx2, reply.PendingTxs[0].Tx)
+		assert.Zero(t, reply.PendingTxs[0].Height)
+	}
+
+	// pending txs are limited to a page
+	perPage := 1
+	reply = new(TxSearchReply)
+	require.NoError(t, service.TxSearch(nil, &TxSearchArgs{
+		Query:          "tm.event = 'Tx'",
+		PerPage:        &perPage,
+		IncludePending: true,
+	}, reply))
+	if assert.Len(t, reply.PendingTxs, 1) {
+		assert.EqualValues(t, tx1, reply.PendingTxs[0].Tx)
+	}
+}
+
+func TestSlowQueryLogging(t *testing.T) {
+	vm, service, _ := mustNewCounterTestVm(t)
+	buf := new(bytes.Buffer)
+	vm.tmLogger = log.NewTMLogger(buf)
+
+	search := func() {
+		reply := new(ctypes.ResultBlockSearch)
+		require.NoError(t, service.BlockSearch(nil, &BlockSearchArgs{Query: "block.height > 0 AND end_event.foo = 'bar'"}, reply))
+	}
+
+	search()
+	assert.Empty(t, buf.String())
+
+	vm.config.SlowQueryThreshold = Duration{time.Nanosecond}
+	search()
+	logged := buf.String()
+	assert.Contains(t, logged, "slow query")
+	assert.Contains(t, logged, "method=BlockSearch")
+	for _, phase := range []string{"parse=", "search=", "load="} {
+		assert.Contains(t, logged, phase)
+	}
+}
+
+func TestBroadcastTxSyncWaitForRecheck(t *testing.T) {
+	vm, service, _ := mustNewCounterTestVm(t)
+
+	broadcast := func(tx types.Tx) *ctypes.ResultBroadcastTx {
+		reply := new(ctypes.ResultBroadcastTx)
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: tx, WaitForRecheck: true}, reply))
+		return reply
+	}
+
+	assert.Equal(t, atypes.CodeTypeOK, broadcast(types.Tx{0x00}).Code)
+	assert.Equal(t, 1, vm.mempool.Size())
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, blk.Accept(context.Background()))
+
+	// the recheck started by the block is waited for
+	assert.Equal(t, atypes.CodeTypeOK, broadcast(types.Tx{0x01}).Code)
+	assert.Equal(t, 1, vm.mempool.Size())
+
+	// a rejected tx, not in the cache yet, isn't waited for
+	assert.NotEqual(t, atypes.CodeTypeOK, broadcast(types.Tx{0x00, 0x00}).Code)
+}
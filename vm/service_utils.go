@@ -1,20 +1,139 @@
 package vm
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
 
+	abci "github.com/consideritdone/landslidecore/abci/types"
 	tmmath "github.com/consideritdone/landslidecore/libs/math"
+	tmquery "github.com/consideritdone/landslidecore/libs/pubsub/query"
+	mempl "github.com/consideritdone/landslidecore/mempool"
 	"github.com/consideritdone/landslidecore/rpc/client"
 	coretypes "github.com/consideritdone/landslidecore/rpc/core/types"
 	"github.com/consideritdone/landslidecore/store"
+	"github.com/consideritdone/landslidecore/types"
 )
 
-var (
+// Statuses reported by TxStatus.
+const (
+	TxStatusUnknown   = "unknown"
+	TxStatusPending   = "pending"
+	TxStatusCommitted = "committed"
+)
+
+const (
+	// recheckTimeout is how long BroadcastTxSync waits for the mempool
+	// recheck when asked to.
+	recheckTimeout = 10 * time.Second
+
 	// see README
 	defaultPerPage = 30
 	maxPerPage     = 100
 )
 
+// txSizeBuckets are the upper bounds, in bytes, of the buckets of the mempool
+// tx size histogram.
+var txSizeBuckets = []int{256, 1024, 4096, 16384, 65536}
+
+// oldestTxTimer is implemented by mempools which record when txs were added,
+// such as the CListMempool.
+type oldestTxTimer interface {
+	OldestTxTime() (time.Time, bool)
+}
+
+// txSizeWalker is implemented by mempools which can report the size of their
+// txs without copying them, such as the CListMempool.
+type txSizeWalker interface {
+	TxSizes(fn func(size int))
+}
+
+// pendingTxLocator is implemented by mempools which can find the position of
+// a tx, such as the CListMempool.
+type pendingTxLocator interface {
+	PendingTx(txKey [mempl.TxKeySize]byte) (int, time.Time, bool)
+}
+
+// pendingTxGetter is implemented by mempools which can look a tx up by its
+// key, such as the CListMempool.
+type pendingTxGetter interface {
+	TxByKey(txKey [mempl.TxKeySize]byte) (types.Tx, bool)
+}
+
+// pendingTxWalker is implemented by mempools which keep the CheckTx events of
+// their txs, such as the CListMempool.
+type pendingTxWalker interface {
+	WalkTxs(fn func(tx types.Tx, events []abci.Event) bool)
+}
+
+// contextTxChecker is implemented by mempools which give up on a tx once the
+// request which submitted it is done, such as the CListMempool.
+type contextTxChecker interface {
+	CheckTxContext(ctx context.Context, tx types.Tx, cb func(*abci.Response), txInfo mempl.TxInfo) error
+}
+
+// recheckWaiter is implemented by mempools which can signal the end of the
+// recheck done after a block, such as the CListMempool.
+type recheckWaiter interface {
+	RecheckDone() <-chan struct{}
+}
+
+// cacheFlusher is implemented by mempools with a cache of seen txs, such as
+// the CListMempool.
+type cacheFlusher interface {
+	FlushCache() int
+}
+
+// senderTxsReaper is implemented by mempools which record the sender accounts
+// reported by the app, such as the CListMempool.
+type senderTxsReaper interface {
+	ReapMaxTxsBySender(sender string, max int) types.Txs
+}
+
+// ErrHeightPruned is returned when the requested height is below the
+// earliest height still available in the block store, or when the app
+// reports it pruned the state of the height. EarliestHeight is 0 when it
+// isn't known, as the app doesn't report it.
+type ErrHeightPruned struct {
+	Height         int64
+	EarliestHeight int64
+}
+
+func (e ErrHeightPruned) Error() string {
+	if e.EarliestHeight == 0 {
+		return fmt.Sprintf("height %d is not available, it has been pruned by the app", e.Height)
+	}
+	return fmt.Sprintf("height %d is not available, it has been pruned; earliest available height is %d",
+		e.Height, e.EarliestHeight)
+}
+
+// appPrunedLogs are the logs with which apps fail queries at a height whose
+// state they no longer have, such as the one of the Cosmos SDK IAVL store.
+var appPrunedLogs = []string{
+	"version does not exist",
+	"pruned",
+}
+
+// isAppPruned reports whether [res] is the app failing a query because it
+// pruned the state of the queried height.
+func isAppPruned(res *abci.ResponseQuery) bool {
+	if res.IsOK() {
+		return false
+	}
+	log := strings.ToLower(res.Log)
+	for _, prunedLog := range appPrunedLogs {
+		if strings.Contains(log, prunedLog) {
+			return true
+		}
+	}
+	return false
+}
+
 // bsHeight can be either latest committed or uncommitted (+1) height.
 func getHeight(bs *store.BlockStore, heightPtr *int64) (int64, error) {
 	bsHeight := bs.Height()
@@ -28,7 +147,7 @@ func getHeight(bs *store.BlockStore, heightPtr *int64) (int64, error) {
 			return 0, fmt.Errorf("height %d must be less than or equal to the current blockchain height %d", height, bsHeight)
 		}
 		if height < bsBase {
-			return 0, fmt.Errorf("height %d is not available, lowest height is %d", height, bsBase)
+			return 0, ErrHeightPruned{Height: height, EarliestHeight: bsBase}
 		}
 		return height, nil
 	}
@@ -56,16 +175,18 @@ func validatePage(pagePtr *int, perPage, totalCount int) (int, error) {
 	return page, nil
 }
 
-func validatePerPage(perPagePtr *int) int {
+// validatePerPage returns the requested page size, the configured default if
+// none was requested, capped by the configured maximum.
+func validatePerPage(perPagePtr *int, config Config) int {
 	if perPagePtr == nil { // no per_page parameter
-		return defaultPerPage
+		return config.DefaultPerPage
 	}
 
 	perPage := *perPagePtr
 	if perPage < 1 {
-		return defaultPerPage
-	} else if perPage > maxPerPage {
-		return maxPerPage
+		return config.DefaultPerPage
+	} else if perPage > config.MaxPerPage {
+		return config.MaxPerPage
 	}
 	return perPage
 }
@@ -78,6 +199,68 @@ func validateSkipCount(page, perPage int) int {
 	return skipCount
 }
 
+// newTxSizeHistogram returns an empty histogram with the txSizeBuckets, plus
+// a last bucket for larger txs.
+func newTxSizeHistogram() []TxSizeBucket {
+	histogram := make([]TxSizeBucket, len(txSizeBuckets)+1)
+	for i, maxBytes := range txSizeBuckets {
+		histogram[i].MaxBytes = maxBytes
+	}
+	return histogram
+}
+
+// countTxSize adds a tx of [size] bytes to [histogram].
+func countTxSize(histogram []TxSizeBucket, size int) {
+	histogram[sort.SearchInts(txSizeBuckets, size)].Count++
+}
+
+// txSizeHistogram counts [txs] by size into the txSizeBuckets, plus a last
+// bucket for larger txs.
+func txSizeHistogram(txs types.Txs) []TxSizeBucket {
+	histogram := newTxSizeHistogram()
+	for _, tx := range txs {
+		countTxSize(histogram, len(tx))
+	}
+	return histogram
+}
+
+// blockHeightRange returns the inclusive range of heights matched by
+// [conditions] if they only compare block.height against integers, as in
+// "block.height >= 5 AND block.height <= 10". Otherwise ok is false.
+func blockHeightRange(conditions []tmquery.Condition) (min, max int64, ok bool) {
+	if len(conditions) == 0 {
+		return 0, 0, false
+	}
+
+	min, max = 0, math.MaxInt64
+	for _, c := range conditions {
+		if c.CompositeKey != types.BlockHeightKey {
+			return 0, 0, false
+		}
+		v, isInt := c.Operand.(int64)
+		if !isInt {
+			return 0, 0, false
+		}
+
+		switch c.Op {
+		case tmquery.OpEqual:
+			min = tmmath.MaxInt64(min, v)
+			max = tmmath.MinInt64(max, v)
+		case tmquery.OpGreaterEqual:
+			min = tmmath.MaxInt64(min, v)
+		case tmquery.OpGreater:
+			min = tmmath.MaxInt64(min, v+1)
+		case tmquery.OpLessEqual:
+			max = tmmath.MinInt64(max, v)
+		case tmquery.OpLess:
+			max = tmmath.MinInt64(max, v-1)
+		default:
+			return 0, 0, false
+		}
+	}
+	return min, max, true
+}
+
 // filterMinMax returns error if either min or max are negative or min > max
 // if 0, use blockstore base for min, latest block height for max
 // enforce limit.
@@ -111,6 +294,132 @@ func filterMinMax(base, height, min, max, limit int64) (int64, int64, error) {
 	return min, max, nil
 }
 
+// diffValidatorSets returns the validators present in [next] but not in
+// [prev], the ones present in [prev] but not in [next], and the voting power
+// changes of the ones present in both.
+func diffValidatorSets(prev, next *types.ValidatorSet) (
+	joined []*types.Validator,
+	left []*types.Validator,
+	powerChanges []ValidatorPowerChange,
+) {
+	joined = make([]*types.Validator, 0)
+	left = make([]*types.Validator, 0)
+	powerChanges = make([]ValidatorPowerChange, 0)
+
+	for _, val := range next.Validators {
+		_, prevVal := prev.GetByAddress(val.Address)
+		switch {
+		case prevVal == nil:
+			joined = append(joined, val)
+		case prevVal.VotingPower != val.VotingPower:
+			powerChanges = append(powerChanges, ValidatorPowerChange{
+				Address:  val.Address,
+				PubKey:   val.PubKey,
+				OldPower: prevVal.VotingPower,
+				NewPower: val.VotingPower,
+			})
+		}
+	}
+
+	for _, val := range prev.Validators {
+		if !next.HasAddress(val.Address) {
+			left = append(left, val)
+		}
+	}
+
+	return joined, left, powerChanges
+}
+
+// rpcOrigin returns the origin of the txs submitted by [req], for the
+// per-origin limits of the mempool: the IP address of the client, without
+// its port, so that reconnecting doesn't reset the limits.
+func rpcOrigin(req *http.Request) string {
+	if req == nil {
+		return "rpc"
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	return "rpc:" + host
+}
+
+// decodeEvents converts the keys and values of [events] to strings.
+func decodeEvents(events []abci.Event) []Event {
+	decoded := make([]Event, 0, len(events))
+	for _, event := range events {
+		attrs := make([]EventAttribute, 0, len(event.Attributes))
+		for _, attr := range event.Attributes {
+			attrs = append(attrs, EventAttribute{
+				Key:   string(attr.Key),
+				Value: string(attr.Value),
+				Index: attr.Index,
+			})
+		}
+		decoded = append(decoded, Event{
+			Type:       event.Type,
+			Attributes: attrs,
+		})
+	}
+	return decoded
+}
+
+// compositeEvents groups the attribute values of [events] by composite key
+// ("{eventType}.{attributeKey}"), the form used by event queries.
+func compositeEvents(events []abci.Event) map[string][]string {
+	composite := make(map[string][]string)
+	for _, event := range events {
+		if len(event.Type) == 0 {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if len(attr.Key) == 0 {
+				continue
+			}
+			compositeKey := fmt.Sprintf("%s.%s", event.Type, string(attr.Key))
+			composite[compositeKey] = append(composite[compositeKey], string(attr.Value))
+		}
+	}
+	return composite
+}
+
+// queryTimer measures the phases of an indexer-backed query, so that queries
+// slower than the slowQueryThreshold can be logged with a breakdown.
+type queryTimer struct {
+	start  time.Time
+	last   time.Time
+	phases []interface{}
+}
+
+func newQueryTimer() *queryTimer {
+	now := time.Now()
+	return &queryTimer{start: now, last: now}
+}
+
+// phase records the time elapsed since the previous phase as [name].
+func (t *queryTimer) phase(name string) {
+	now := time.Now()
+	t.phases = append(t.phases, name, now.Sub(t.last))
+	t.last = now
+}
+
+// logSlowQuery logs [query] of [method] if it took longer than the configured
+// slowQueryThreshold.
+func (s *LocalService) logSlowQuery(method string, query string, results int, timer *queryTimer) {
+	threshold := s.vm.config.SlowQueryThreshold.Duration
+	elapsed := time.Since(timer.start)
+	if threshold == 0 || elapsed < threshold {
+		return
+	}
+	keyvals := append([]interface{}{
+		"method", method,
+		"query", query,
+		"results", results,
+		"elapsed", elapsed,
+	}, timer.phases...)
+	s.vm.tmLogger.With("module", "rpc").Info("slow query", keyvals...)
+}
+
 func WaitForHeight(c Service, h int64, waiter client.Waiter) error {
 	if waiter == nil {
 		waiter = client.DefaultWaitStrategy
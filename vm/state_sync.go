@@ -0,0 +1,494 @@
+package vm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	tmstate "github.com/consideritdone/landslidecore/proto/tendermint/state"
+	tmproto "github.com/consideritdone/landslidecore/proto/tendermint/types"
+	"github.com/consideritdone/landslidecore/proxy"
+	sm "github.com/consideritdone/landslidecore/state"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+const (
+	// chunkRequestTimeout is how long a peer has to answer a chunk request
+	// before it is asked to another peer.
+	chunkRequestTimeout = 30 * time.Second
+	// maxChunkAttempts is the number of times a chunk is requested before the
+	// state sync fails.
+	maxChunkAttempts = 10
+)
+
+var (
+	_ block.StateSyncableVM = &VM{}
+	_ block.StateSummary    = &stateSummary{}
+
+	// ongoingSummaryKey holds the summary being synced to, until the state
+	// sync completes.
+	ongoingSummaryKey = []byte("stateSyncSummary")
+
+	errNoPeers = errors.New("no peers to request snapshot chunks from")
+)
+
+type (
+	// stateSummaryContent is what the state summary bytes encode: an app
+	// snapshot, along with the Tendermint state and block at its height.
+	stateSummaryContent struct {
+		Snapshot abci.Snapshot `json:"snapshot"`
+		// State is the protobuf encoding of the state after the block at
+		// the snapshot height.
+		State []byte `json:"state"`
+		// Block is the protobuf encoding of the block at the snapshot height.
+		Block []byte `json:"block"`
+		// AppHash is the app hash after the block at the snapshot height,
+		// which the restored app must report.
+		AppHash []byte `json:"appHash"`
+	}
+
+	// stateSummary is a snapshot of the app, which nodes can state sync to.
+	// Its chunks are fetched from peers with app requests.
+	stateSummary struct {
+		vm      *VM
+		id      ids.ID
+		bytes   []byte
+		content stateSummaryContent
+	}
+
+	// chunkRequest is the app request for a snapshot chunk. The response is
+	// the chunk, empty if the peer doesn't have it.
+	chunkRequest struct {
+		Height uint64 `json:"height"`
+		Format uint32 `json:"format"`
+		Index  uint32 `json:"index"`
+	}
+
+	// stateSyncer tracks the outcome of the state sync.
+	stateSyncer struct {
+		mtx sync.Mutex
+		// err is the error which ended the last state sync, if any.
+		err error
+	}
+)
+
+func newStateSyncer() *stateSyncer {
+	return &stateSyncer{}
+}
+
+func (vm *VM) newStateSummary(content stateSummaryContent) (*stateSummary, error) {
+	summaryBytes, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	return &stateSummary{
+		vm:      vm,
+		id:      hashing.ComputeHash256Array(summaryBytes),
+		bytes:   summaryBytes,
+		content: content,
+	}, nil
+}
+
+func (s *stateSummary) ID() ids.ID {
+	return s.id
+}
+
+func (s *stateSummary) Height() uint64 {
+	return s.content.Snapshot.Height
+}
+
+func (s *stateSummary) Bytes() []byte {
+	return s.bytes
+}
+
+// Accept offers the snapshot to the app and starts fetching its chunks, unless
+// state sync is disabled or the VM already has blocks.
+func (s *stateSummary) Accept(context.Context) (block.StateSyncMode, error) {
+	vm := s.vm
+	if !vm.config.StateSyncEnabled || vm.blockStore.Height() > 0 {
+		return block.StateSyncSkipped, nil
+	}
+
+	res, err := vm.proxyApp.Snapshot().OfferSnapshotSync(abci.RequestOfferSnapshot{
+		Snapshot: &s.content.Snapshot,
+		// the summary is trusted once accepted by the validators, and the
+		// chunks fetched from peers are verified against its app hash
+		AppHash: s.content.AppHash,
+	})
+	if err != nil {
+		return block.StateSyncSkipped, err
+	}
+	if res.Result != abci.ResponseOfferSnapshot_ACCEPT {
+		vm.tmLogger.Info("app refused snapshot", "height", s.Height(), "result", res.Result)
+		return block.StateSyncSkipped, nil
+	}
+
+	err = vm.atomically(func() error {
+		return vm.stateDB.SetSync(ongoingSummaryKey, s.bytes)
+	})
+	if err != nil {
+		return block.StateSyncSkipped, err
+	}
+	go vm.syncSnapshot(s)
+	return block.StateSyncStatic, nil
+}
+
+// StateSyncEnabled returns whether the config enables state sync.
+func (vm *VM) StateSyncEnabled(context.Context) (bool, error) {
+	return vm.config.StateSyncEnabled, nil
+}
+
+func (vm *VM) GetOngoingSyncStateSummary(ctx context.Context) (block.StateSummary, error) {
+	summaryBytes, err := vm.stateDB.Get(ongoingSummaryKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(summaryBytes) == 0 {
+		return nil, database.ErrNotFound
+	}
+	return vm.ParseStateSummary(ctx, summaryBytes)
+}
+
+// GetLastStateSummary returns the summary of the latest app snapshot.
+func (vm *VM) GetLastStateSummary(context.Context) (block.StateSummary, error) {
+	snapshots, err := vm.listSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	var latest *abci.Snapshot
+	for _, snapshot := range snapshots {
+		if latest == nil || snapshot.Height > latest.Height {
+			latest = snapshot
+		}
+	}
+	if latest == nil {
+		return nil, database.ErrNotFound
+	}
+	return vm.stateSummaryOf(latest)
+}
+
+func (vm *VM) ParseStateSummary(_ context.Context, summaryBytes []byte) (block.StateSummary, error) {
+	var content stateSummaryContent
+	if err := json.Unmarshal(summaryBytes, &content); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state summary: %w", err)
+	}
+	return &stateSummary{
+		vm:      vm,
+		id:      hashing.ComputeHash256Array(summaryBytes),
+		bytes:   summaryBytes,
+		content: content,
+	}, nil
+}
+
+// GetStateSummary returns the summary of the app snapshot at [summaryHeight].
+func (vm *VM) GetStateSummary(_ context.Context, summaryHeight uint64) (block.StateSummary, error) {
+	snapshots, err := vm.listSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Height == summaryHeight {
+			return vm.stateSummaryOf(snapshot)
+		}
+	}
+	return nil, database.ErrNotFound
+}
+
+// listSnapshots returns the app snapshots at heights the VM still has the
+// block, state and app hash of.
+func (vm *VM) listSnapshots() ([]*abci.Snapshot, error) {
+	res, err := vm.proxyApp.Snapshot().ListSnapshotsSync(abci.RequestListSnapshots{})
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]*abci.Snapshot, 0, len(res.Snapshots))
+	for _, snapshot := range res.Snapshots {
+		height := int64(snapshot.Height)
+		if height < vm.blockStore.Base() || height > vm.blockStore.Height() {
+			continue
+		}
+		if _, ok, err := vm.appHashAt(height); err != nil {
+			return nil, err
+		} else if ok {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return snapshots, nil
+}
+
+// stateSummaryOf returns the summary of [snapshot], along with the state,
+// block and app hash at its height.
+func (vm *VM) stateSummaryOf(snapshot *abci.Snapshot) (*stateSummary, error) {
+	height := int64(snapshot.Height)
+	appHash, ok, err := vm.appHashAt(height)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("app hash after block %d not found", height)
+	}
+	state, err := vm.stateAt(height)
+	if err != nil {
+		return nil, err
+	}
+	state.AppHash = appHash
+	stateProto, err := state.ToProto()
+	if err != nil {
+		return nil, err
+	}
+	stateBytes, err := stateProto.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	blockProto, err := vm.blockStore.LoadBlock(height).ToProto()
+	if err != nil {
+		return nil, err
+	}
+	blockBytes, err := blockProto.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return vm.newStateSummary(stateSummaryContent{
+		Snapshot: *snapshot,
+		State:    stateBytes,
+		Block:    blockBytes,
+		AppHash:  appHash,
+	})
+}
+
+// stateAt rebuilds the state after the block at [height] was applied from the
+// validator sets, consensus params and ABCI responses kept by the state store.
+// Its AppHash is left empty, see appHashAt.
+func (vm *VM) stateAt(height int64) (sm.State, error) {
+	current, err := vm.stateStore.Load()
+	if err != nil {
+		return sm.State{}, err
+	}
+	blockMeta := vm.blockStore.LoadBlockMeta(height)
+	if blockMeta == nil {
+		return sm.State{}, fmt.Errorf("block %d not found", height)
+	}
+
+	lastValidators := types.NewValidatorSet(nil)
+	if height > current.InitialHeight {
+		if lastValidators, err = vm.stateStore.LoadValidators(height); err != nil {
+			return sm.State{}, err
+		}
+	}
+	validators, err := vm.stateStore.LoadValidators(height + 1)
+	if err != nil {
+		return sm.State{}, err
+	}
+	nextValidators, err := vm.stateStore.LoadValidators(height + 2)
+	if err != nil {
+		return sm.State{}, err
+	}
+	params, err := vm.stateStore.LoadConsensusParams(height + 1)
+	if err != nil {
+		return sm.State{}, err
+	}
+	abciResponses, err := vm.stateStore.LoadABCIResponses(height)
+	if err != nil {
+		return sm.State{}, err
+	}
+
+	return sm.State{
+		Version:                          current.Version,
+		ChainID:                          current.ChainID,
+		InitialHeight:                    current.InitialHeight,
+		LastBlockHeight:                  height,
+		LastBlockID:                      blockMeta.BlockID,
+		LastBlockTime:                    blockMeta.Header.Time,
+		NextValidators:                   nextValidators,
+		Validators:                       validators,
+		LastValidators:                   lastValidators,
+		LastHeightValidatorsChanged:      height + 1,
+		ConsensusParams:                  params,
+		LastHeightConsensusParamsChanged: height + 1,
+		LastResultsHash:                  ABCIResponsesResultsHash(abciResponses),
+		AppHash:                          nil,
+	}, nil
+}
+
+// syncSnapshot restores the app from the chunks of the snapshot of [summary],
+// then bootstraps the state and block store at its height, and notifies the
+// engine.
+func (vm *VM) syncSnapshot(summary *stateSummary) {
+	err := vm.applySnapshotChunks(summary)
+	if err == nil {
+		vm.ctx.Lock.Lock()
+		err = vm.finishStateSync(summary)
+		vm.ctx.Lock.Unlock()
+	}
+	if err != nil {
+		vm.tmLogger.Error("state sync failed", "height", summary.Height(), "err", err)
+	}
+	vm.stateSyncer.mtx.Lock()
+	vm.stateSyncer.err = err
+	vm.stateSyncer.mtx.Unlock()
+
+	vm.toEngine <- common.StateSyncDone
+}
+
+func (vm *VM) applySnapshotChunks(summary *stateSummary) error {
+	snapshot := summary.content.Snapshot
+	for index := uint32(0); index < snapshot.Chunks; index++ {
+		attempts := 0
+		for {
+			attempts++
+			chunk, sender, err := vm.fetchChunk(chunkRequest{
+				Height: snapshot.Height,
+				Format: snapshot.Format,
+				Index:  index,
+			})
+			if err != nil {
+				if attempts < maxChunkAttempts && !errors.Is(err, errNoPeers) {
+					continue
+				}
+				return fmt.Errorf("failed to fetch chunk %d: %w", index, err)
+			}
+			res, err := vm.proxyApp.Snapshot().ApplySnapshotChunkSync(abci.RequestApplySnapshotChunk{
+				Index:  index,
+				Chunk:  chunk,
+				Sender: sender.String(),
+			})
+			if err != nil {
+				return err
+			}
+			switch res.Result {
+			case abci.ResponseApplySnapshotChunk_ACCEPT:
+			case abci.ResponseApplySnapshotChunk_RETRY:
+				if attempts < maxChunkAttempts {
+					continue
+				}
+				return fmt.Errorf("app failed to apply chunk %d", index)
+			default:
+				return fmt.Errorf("app aborted the restore of chunk %d: %v", index, res.Result)
+			}
+			vm.tmLogger.Debug("applied snapshot chunk", "height", snapshot.Height, "index", index, "chunks", snapshot.Chunks)
+			break
+		}
+	}
+	return nil
+}
+
+// fetchChunk requests a chunk from a peer, and returns it along with the peer.
+// Peers are asked in turn.
+func (vm *VM) fetchChunk(req chunkRequest) ([]byte, ids.NodeID, error) {
+	peer, ok := vm.network.nextPeer()
+	if !ok {
+		return nil, ids.EmptyNodeID, errNoPeers
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), chunkRequestTimeout)
+	defer cancel()
+	chunk, err := vm.sendAppRequest(ctx, peer, appRequest{Chunk: &req})
+	if err != nil {
+		return nil, peer, err
+	}
+	if len(chunk) == 0 {
+		return nil, peer, fmt.Errorf("peer %s doesn't have chunk %d", peer, req.Index)
+	}
+	return chunk, peer, nil
+}
+
+// finishStateSync bootstraps the state and block store at the summary height
+// once the app is restored.
+func (vm *VM) finishStateSync(summary *stateSummary) error {
+	stateProto := new(tmstate.State)
+	if err := stateProto.Unmarshal(summary.content.State); err != nil {
+		return err
+	}
+	state, err := sm.FromProto(stateProto)
+	if err != nil {
+		return err
+	}
+	blockProto := new(tmproto.Block)
+	if err := blockProto.Unmarshal(summary.content.Block); err != nil {
+		return err
+	}
+	tmBlock, err := types.BlockFromProto(blockProto)
+	if err != nil {
+		return err
+	}
+
+	// the chunks come from peers, so the restored app must report the app
+	// hash of the summary, which the next blocks carry
+	resInfo, err := vm.proxyApp.Query().InfoSync(proxy.RequestInfo)
+	if err != nil {
+		return fmt.Errorf("failed to get info of the restored app: %w", err)
+	}
+	vm.abciInfoCache.invalidate()
+	if height := int64(summary.content.Snapshot.Height); resInfo.LastBlockHeight != height {
+		return fmt.Errorf("restored app is at height %d, expected %d", resInfo.LastBlockHeight, height)
+	}
+	if !bytes.Equal(resInfo.LastBlockAppHash, summary.content.AppHash) {
+		return fmt.Errorf("restored app hash %X doesn't match the app hash %X of the summary",
+			resInfo.LastBlockAppHash, summary.content.AppHash)
+	}
+	state.AppHash = summary.content.AppHash
+
+	// the state and the block are committed with the end of the state sync
+	err = vm.atomically(func() error {
+		if err := vm.stateStore.Bootstrap(*state); err != nil {
+			return fmt.Errorf("failed to bootstrap state: %w", err)
+		}
+		vm.blockStore.SaveBlock(tmBlock, tmBlock.MakePartSet(types.BlockPartSizeBytes), tmBlock.LastCommit)
+		if err := vm.stateDB.Set(appHashKey(tmBlock.Height), state.AppHash); err != nil {
+			return err
+		}
+		// the blocks below the summary aren't known
+		if err := vm.setLastIndexedHeight(tmBlock.Height); err != nil {
+			return err
+		}
+		return vm.stateDB.DeleteSync(ongoingSummaryKey)
+	})
+	if err != nil {
+		return err
+	}
+	*vm.tmState = *state
+
+	blk, err := vm.newBlock(tmBlock)
+	if err != nil {
+		return err
+	}
+	blk.status = choices.Accepted
+	if err := vm.State.SetLastAcceptedBlock(blk); err != nil {
+		return err
+	}
+	vm.tmLogger.Info("state synced", "height", state.LastBlockHeight)
+	return nil
+}
+
+// stateSyncErr returns the error which ended the last state sync, if any.
+func (vm *VM) stateSyncErr() error {
+	vm.stateSyncer.mtx.Lock()
+	defer vm.stateSyncer.mtx.Unlock()
+
+	return vm.stateSyncer.err
+}
+
+// serveChunk answers the chunk request of a syncing peer from the app
+// snapshots.
+func (vm *VM) serveChunk(ctx context.Context, nodeID ids.NodeID, requestID uint32, req *chunkRequest) error {
+	res, err := vm.proxyApp.Snapshot().LoadSnapshotChunkSync(abci.RequestLoadSnapshotChunk{
+		Height: req.Height,
+		Format: req.Format,
+		Chunk:  req.Index,
+	})
+	if err != nil {
+		return err
+	}
+	return vm.appSender.SendAppResponse(ctx, nodeID, requestID, res.Chunk)
+}
@@ -0,0 +1,167 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+)
+
+// snapshotApp serves a single snapshot and records the chunks restored to it.
+// Each chunk restored is a tx, which it executes and commits as a block.
+type snapshotApp struct {
+	*kvstore.Application
+	snapshot *atypes.Snapshot
+	chunks   [][]byte
+	restored [][]byte
+}
+
+func (app *snapshotApp) ListSnapshots(atypes.RequestListSnapshots) atypes.ResponseListSnapshots {
+	if app.snapshot == nil {
+		return atypes.ResponseListSnapshots{}
+	}
+	return atypes.ResponseListSnapshots{Snapshots: []*atypes.Snapshot{app.snapshot}}
+}
+
+func (app *snapshotApp) LoadSnapshotChunk(req atypes.RequestLoadSnapshotChunk) atypes.ResponseLoadSnapshotChunk {
+	if app.snapshot == nil || req.Height != app.snapshot.Height || int(req.Chunk) >= len(app.chunks) {
+		return atypes.ResponseLoadSnapshotChunk{}
+	}
+	return atypes.ResponseLoadSnapshotChunk{Chunk: app.chunks[req.Chunk]}
+}
+
+func (app *snapshotApp) OfferSnapshot(atypes.RequestOfferSnapshot) atypes.ResponseOfferSnapshot {
+	return atypes.ResponseOfferSnapshot{Result: atypes.ResponseOfferSnapshot_ACCEPT}
+}
+
+func (app *snapshotApp) ApplySnapshotChunk(req atypes.RequestApplySnapshotChunk) atypes.ResponseApplySnapshotChunk {
+	app.restored = append(app.restored, req.Chunk)
+	app.DeliverTx(atypes.RequestDeliverTx{Tx: req.Chunk})
+	app.Commit()
+	return atypes.ResponseApplySnapshotChunk{Result: atypes.ResponseApplySnapshotChunk_ACCEPT}
+}
+
+func TestStateSync(t *testing.T) {
+	ctx := context.Background()
+	newDBManager := func() manager.Manager {
+		return manager.NewMemDB(&version.Semantic{Major: 1})
+	}
+
+	serverApp := &snapshotApp{Application: kvstore.NewApplication()}
+	server, _, _, err := newTestVMWithDB(serverApp, newDBManager(), nil)
+	require.NoError(t, err)
+	serverService := NewService(server)
+	for _, tx := range []string{"a=1", "b=2", "c=3"} {
+		mustAcceptBlock(t, server, serverService, []byte(tx))
+	}
+
+	_, err = server.GetLastStateSummary(ctx)
+	require.ErrorIs(t, err, database.ErrNotFound)
+
+	serverApp.snapshot = &atypes.Snapshot{Height: 2, Format: 1, Chunks: 2, Hash: []byte{1}}
+	serverApp.chunks = [][]byte{[]byte("a=1"), []byte("b=2")}
+	summary, err := server.GetLastStateSummary(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), summary.Height())
+	summaryAtHeight, err := server.GetStateSummary(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, summary.ID(), summaryAtHeight.ID())
+	_, err = server.GetStateSummary(ctx, 3)
+	require.ErrorIs(t, err, database.ErrNotFound)
+
+	// syncClient state syncs a new client to [summaryBytes], the VMs
+	// exchanging chunks through app requests
+	serverNodeID := ids.GenerateTestNodeID()
+	syncClient := func(summaryBytes []byte) (*VM, *snapshotApp, error) {
+		clientApp := &snapshotApp{Application: kvstore.NewApplication()}
+		client, _, toEngine, err := newTestVMWithDB(clientApp, newDBManager(), []byte(`{"stateSyncEnabled":true}`))
+		require.NoError(t, err)
+		enabled, err := client.StateSyncEnabled(ctx)
+		require.NoError(t, err)
+		assert.True(t, enabled)
+
+		clientNodeID := ids.GenerateTestNodeID()
+		client.appSender = &common.SenderTest{
+			T: t,
+			SendAppRequestF: func(ctx context.Context, nodeIDs set.Set[ids.NodeID], requestID uint32, request []byte) error {
+				require.True(t, nodeIDs.Contains(serverNodeID))
+				return server.AppRequest(ctx, clientNodeID, requestID, time.Now().Add(time.Minute), request)
+			},
+		}
+		server.appSender = &common.SenderTest{
+			T: t,
+			SendAppResponseF: func(ctx context.Context, nodeID ids.NodeID, requestID uint32, response []byte) error {
+				require.Equal(t, clientNodeID, nodeID)
+				return client.AppResponse(ctx, serverNodeID, requestID, response)
+			},
+		}
+		require.NoError(t, client.Connected(ctx, serverNodeID, nil))
+
+		clientSummary, err := client.ParseStateSummary(ctx, summaryBytes)
+		require.NoError(t, err)
+		mode, err := clientSummary.Accept(ctx)
+		require.NoError(t, err)
+		require.Equal(t, block.StateSyncStatic, mode)
+
+		select {
+		case msg := <-toEngine:
+			require.Equal(t, common.StateSyncDone, msg)
+		case <-time.After(10 * time.Second):
+			t.Fatal("state sync didn't complete")
+		}
+		return client, clientApp, client.SetState(ctx, snow.Bootstrapping)
+	}
+
+	// chunks restoring another state than the summary's fail the sync
+	var content stateSummaryContent
+	require.NoError(t, json.Unmarshal(summary.Bytes(), &content))
+	content.AppHash = []byte("other app hash")
+	otherSummaryBytes, err := json.Marshal(content)
+	require.NoError(t, err)
+	client, _, err := syncClient(otherSummaryBytes)
+	assert.ErrorContains(t, err, "doesn't match the app hash")
+	assert.Zero(t, client.blockStore.Height())
+
+	client, clientApp, err := syncClient(summary.Bytes())
+	require.NoError(t, err)
+
+	assert.Equal(t, serverApp.chunks, clientApp.restored)
+	assert.Equal(t, int64(2), client.blockStore.Height())
+	assert.Equal(t, int64(2), client.tmState.LastBlockHeight)
+	lastAccepted, err := client.LastAccepted(ctx)
+	require.NoError(t, err)
+	var serverBlockID ids.ID
+	copy(serverBlockID[:], server.blockStore.LoadBlock(2).Hash())
+	assert.Equal(t, serverBlockID, lastAccepted)
+	_, err = client.GetOngoingSyncStateSummary(ctx)
+	assert.ErrorIs(t, err, database.ErrNotFound)
+
+	// the app hash after the snapshot is the summary's, so the client builds
+	// the next block on it as the server did
+	serverHeader := server.blockStore.LoadBlock(3).Header
+	assert.EqualValues(t, serverHeader.LastResultsHash, client.tmState.LastResultsHash)
+	assert.EqualValues(t, serverHeader.AppHash, client.tmState.AppHash)
+	require.NoError(t, client.SetState(ctx, snow.NormalOp))
+	mustAcceptBlock(t, client, NewService(client), []byte("c=3"))
+	assert.Equal(t, serverHeader.AppHash, client.blockStore.LoadBlock(3).AppHash)
+	assert.Equal(t, server.tmState.AppHash, client.tmState.AppHash)
+
+	// a node with blocks doesn't state sync
+	mode, err := summary.Accept(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, block.StateSyncSkipped, mode)
+}
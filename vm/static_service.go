@@ -0,0 +1,95 @@
+package vm
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+
+	tmbytes "github.com/consideritdone/landslidecore/libs/bytes"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// staticChainID stands for the chain_id derived from the Avalanche chain ID,
+// which isn't known before the chain is created, when validating a genesis
+// without one.
+const staticChainID = Name + "-static"
+
+type (
+	// StaticService is served by the VM before any chain exists, so that
+	// deployment tooling can check a genesis against the installed VM.
+	StaticService interface {
+		Version(_ *http.Request, _ *struct{}, reply *BuildInfo) error
+		ValidateGenesis(_ *http.Request, args *GenesisArgs, reply *ValidateGenesisReply) error
+		ParseGenesis(_ *http.Request, args *GenesisArgs, reply *ParseGenesisReply) error
+	}
+
+	LocalStaticService struct{}
+
+	GenesisArgs struct {
+		// Genesis is the genesis document, as passed to avalanchego when
+		// creating the chain.
+		Genesis json.RawMessage `json:"genesis"`
+		// ChainID is the chainId the chain will be configured with, if any,
+		// which takes precedence over the chain_id of the genesis.
+		ChainID string `json:"chainId"`
+	}
+
+	ValidateGenesisReply struct {
+		Valid bool `json:"valid"`
+		// Error is why the genesis is invalid.
+		Error string `json:"error,omitempty"`
+	}
+
+	ParseGenesisReply struct {
+		// Genesis is the genesis document completed with its defaults, as
+		// the chain will start from.
+		Genesis *types.GenesisDoc `json:"genesis"`
+		// Hash is the SHA-256 of the genesis bytes, as the GenesisHash
+		// endpoint of the chain will report it.
+		Hash tmbytes.HexBytes `json:"hash"`
+		// ChainIDDerived is set if neither the genesis nor the args set the
+		// chain_id, which is then derived from the Avalanche chain ID once
+		// the chain is created.
+		ChainIDDerived bool `json:"chainIdDerived"`
+	}
+)
+
+func NewStaticService() StaticService {
+	return &LocalStaticService{}
+}
+
+func (s *LocalStaticService) Version(_ *http.Request, _ *struct{}, reply *BuildInfo) error {
+	*reply = StaticBuildInfo()
+	return nil
+}
+
+func (s *LocalStaticService) ValidateGenesis(_ *http.Request, args *GenesisArgs, reply *ValidateGenesisReply) error {
+	if _, err := parseGenesis(args.Genesis, args.ChainID, staticChainID); err != nil {
+		reply.Error = err.Error()
+		return nil
+	}
+	reply.Valid = true
+	return nil
+}
+
+func (s *LocalStaticService) ParseGenesis(_ *http.Request, args *GenesisArgs, reply *ParseGenesisReply) error {
+	genesis, err := parseGenesis(args.Genesis, args.ChainID, staticChainID)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(args.Genesis)
+	reply.Genesis = genesis
+	reply.Hash = hash[:]
+
+	var withChainID struct {
+		ChainID string `json:"chain_id"`
+	}
+	if err := json.Unmarshal(args.Genesis, &withChainID); err != nil {
+		return err
+	}
+	if args.ChainID == "" && withChainID.ChainID == "" {
+		reply.ChainIDDerived = true
+		genesis.ChainID = ""
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package vm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticService(t *testing.T) {
+	service := NewStaticService()
+
+	t.Run("Version", func(t *testing.T) {
+		reply := new(BuildInfo)
+		require.NoError(t, service.Version(nil, nil, reply))
+		assert.Equal(t, Version.String(), reply.Version)
+		assert.Equal(t, version.RPCChainVMProtocol, reply.RPCChainVMProtocol)
+		assert.Empty(t, reply.AppVersion)
+	})
+
+	t.Run("ValidateGenesis", func(t *testing.T) {
+		reply := new(ValidateGenesisReply)
+		require.NoError(t, service.ValidateGenesis(nil, &GenesisArgs{Genesis: json.RawMessage(genesis)}, reply))
+		assert.True(t, reply.Valid)
+
+		reply = new(ValidateGenesisReply)
+		invalid := json.RawMessage(`{"initial_height":"-1"}`)
+		require.NoError(t, service.ValidateGenesis(nil, &GenesisArgs{Genesis: invalid}, reply))
+		assert.False(t, reply.Valid)
+		assert.NotEmpty(t, reply.Error)
+	})
+
+	t.Run("ParseGenesis", func(t *testing.T) {
+		reply := new(ParseGenesisReply)
+		require.NoError(t, service.ParseGenesis(nil, &GenesisArgs{Genesis: json.RawMessage(genesis)}, reply))
+		assert.Equal(t, "test-chain-U8te75", reply.Genesis.ChainID)
+		assert.EqualValues(t, 1, reply.Genesis.InitialHeight)
+		hash := sha256.Sum256([]byte(genesis))
+		assert.EqualValues(t, hash[:], reply.Hash)
+		assert.False(t, reply.ChainIDDerived)
+
+		reply = new(ParseGenesisReply)
+		args := &GenesisArgs{Genesis: json.RawMessage(`{"genesis_time":"2023-03-04T03:46:06Z"}`)}
+		require.NoError(t, service.ParseGenesis(nil, args, reply))
+		assert.True(t, reply.ChainIDDerived)
+		assert.Empty(t, reply.Genesis.ChainID)
+
+		reply = new(ParseGenesisReply)
+		args.ChainID = "my-chain"
+		require.NoError(t, service.ParseGenesis(nil, args, reply))
+		assert.False(t, reply.ChainIDDerived)
+		assert.Equal(t, "my-chain", reply.Genesis.ChainID)
+	})
+}
+
+func TestCreateStaticHandlers(t *testing.T) {
+	handlers, err := (&VM{}).CreateStaticHandlers(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, handlers, "/static")
+}
@@ -0,0 +1,411 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	abcicli "github.com/consideritdone/landslidecore/abci/client"
+	"github.com/consideritdone/landslidecore/abci/types"
+	"github.com/consideritdone/landslidecore/libs/service"
+)
+
+var errAppDisconnected = errors.New("disconnected from the app, reconnecting")
+
+var _ abcicli.Client = (*supervisedClient)(nil)
+
+// supervisedClient is a client of an out-of-process app which connects to the
+// app again, with an exponential backoff, when its connection is lost, e.g.
+// because the app crashed. Requests fail with errAppDisconnected until it is
+// connected again.
+type supervisedClient struct {
+	service.BaseService
+	creator *remoteClientCreator
+
+	mtx sync.RWMutex
+	// client is the client of the current connection, nil while
+	// disconnected.
+	client abcicli.Client
+	resCb  abcicli.Callback
+}
+
+func (c *supervisedClient) OnStart() error {
+	client, err := c.connect()
+	if err != nil {
+		return err
+	}
+	c.setClient(client)
+	go c.supervise(client)
+	return nil
+}
+
+func (c *supervisedClient) OnStop() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.client != nil {
+		if err := c.client.Stop(); err != nil {
+			c.Logger.Error("failed to stop the app client", "err", err)
+		}
+	}
+}
+
+// connect returns a started client connected to the app.
+func (c *supervisedClient) connect() (abcicli.Client, error) {
+	client, err := abcicli.NewClient(c.creator.addr, c.creator.transport, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create abci client: %w", err)
+	}
+	client.SetLogger(c.Logger)
+	if err := client.Start(); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// supervise connects to the app again whenever the connection of [client]
+// is lost, until c is stopped.
+func (c *supervisedClient) supervise(client abcicli.Client) {
+	for {
+		select {
+		case <-c.Quit():
+			return
+		case <-client.Quit():
+		}
+		if !c.IsRunning() {
+			return
+		}
+		c.Logger.Error("connection to the app lost, reconnecting", "err", client.Error())
+		c.setClient(nil)
+
+		client = c.reconnect()
+		if client == nil {
+			return
+		}
+		c.setClient(client)
+		c.Logger.Info("reconnected to the app")
+		if c.creator.onReconnect != nil {
+			c.creator.onReconnect()
+		}
+	}
+}
+
+// reconnect dials the app until it is connected to, or returns nil once c is
+// stopped.
+func (c *supervisedClient) reconnect() abcicli.Client {
+	backoff := proxyAppMinBackoff
+	for {
+		select {
+		case <-c.Quit():
+			return nil
+		case <-time.After(backoff):
+		}
+		client, err := c.connect()
+		if err == nil {
+			return client
+		}
+		c.Logger.Info("app not reachable, retrying", "backoff", backoff, "err", err)
+		backoff = nextBackoff(backoff)
+	}
+}
+
+func (c *supervisedClient) setClient(client abcicli.Client) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.client = client
+	if client != nil && c.resCb != nil {
+		client.SetResponseCallback(c.resCb)
+	}
+}
+
+// current returns the client of the current connection, or
+// errAppDisconnected.
+func (c *supervisedClient) current() (abcicli.Client, error) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	if c.client == nil {
+		return nil, errAppDisconnected
+	}
+	return c.client, nil
+}
+
+func (c *supervisedClient) connected() bool {
+	_, err := c.current()
+	return err == nil
+}
+
+// failedReqRes is the answer to the async requests which couldn't be made
+// because of [err], e.g. while disconnected.
+func failedReqRes(req *types.Request, err error) *abcicli.ReqRes {
+	reqRes := abcicli.NewReqRes(req)
+	reqRes.Response = types.ToResponseException(err.Error())
+	reqRes.SetDone()
+	reqRes.Done()
+	return reqRes
+}
+
+func (c *supervisedClient) SetResponseCallback(cb abcicli.Callback) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.resCb = cb
+	if c.client != nil {
+		c.client.SetResponseCallback(cb)
+	}
+}
+
+// Error returns errAppDisconnected while disconnected, but nil once c is
+// stopped, which isn't an error of the app.
+func (c *supervisedClient) Error() error {
+	if !c.IsRunning() {
+		return nil
+	}
+	client, err := c.current()
+	if err != nil {
+		return err
+	}
+	return client.Error()
+}
+
+func (c *supervisedClient) FlushAsync() *abcicli.ReqRes {
+	client, err := c.current()
+	if err != nil {
+		return failedReqRes(types.ToRequestFlush(), err)
+	}
+	return client.FlushAsync()
+}
+
+func (c *supervisedClient) EchoAsync(msg string) *abcicli.ReqRes {
+	client, err := c.current()
+	if err != nil {
+		return failedReqRes(types.ToRequestEcho(msg), err)
+	}
+	return client.EchoAsync(msg)
+}
+
+func (c *supervisedClient) InfoAsync(req types.RequestInfo) *abcicli.ReqRes {
+	client, err := c.current()
+	if err != nil {
+		return failedReqRes(types.ToRequestInfo(req), err)
+	}
+	return client.InfoAsync(req)
+}
+
+func (c *supervisedClient) SetOptionAsync(req types.RequestSetOption) *abcicli.ReqRes {
+	client, err := c.current()
+	if err != nil {
+		return failedReqRes(types.ToRequestSetOption(req), err)
+	}
+	return client.SetOptionAsync(req)
+}
+
+func (c *supervisedClient) DeliverTxAsync(req types.RequestDeliverTx) *abcicli.ReqRes {
+	client, err := c.current()
+	if err != nil {
+		return failedReqRes(types.ToRequestDeliverTx(req), err)
+	}
+	return client.DeliverTxAsync(req)
+}
+
+func (c *supervisedClient) CheckTxAsync(req types.RequestCheckTx) *abcicli.ReqRes {
+	client, err := c.current()
+	if err != nil {
+		return failedReqRes(types.ToRequestCheckTx(req), err)
+	}
+	return client.CheckTxAsync(req)
+}
+
+func (c *supervisedClient) QueryAsync(req types.RequestQuery) *abcicli.ReqRes {
+	client, err := c.current()
+	if err != nil {
+		return failedReqRes(types.ToRequestQuery(req), err)
+	}
+	return client.QueryAsync(req)
+}
+
+func (c *supervisedClient) CommitAsync() *abcicli.ReqRes {
+	client, err := c.current()
+	if err != nil {
+		return failedReqRes(types.ToRequestCommit(), err)
+	}
+	return client.CommitAsync()
+}
+
+func (c *supervisedClient) InitChainAsync(req types.RequestInitChain) *abcicli.ReqRes {
+	client, err := c.current()
+	if err != nil {
+		return failedReqRes(types.ToRequestInitChain(req), err)
+	}
+	return client.InitChainAsync(req)
+}
+
+func (c *supervisedClient) BeginBlockAsync(req types.RequestBeginBlock) *abcicli.ReqRes {
+	client, err := c.current()
+	if err != nil {
+		return failedReqRes(types.ToRequestBeginBlock(req), err)
+	}
+	return client.BeginBlockAsync(req)
+}
+
+func (c *supervisedClient) EndBlockAsync(req types.RequestEndBlock) *abcicli.ReqRes {
+	client, err := c.current()
+	if err != nil {
+		return failedReqRes(types.ToRequestEndBlock(req), err)
+	}
+	return client.EndBlockAsync(req)
+}
+
+func (c *supervisedClient) ListSnapshotsAsync(req types.RequestListSnapshots) *abcicli.ReqRes {
+	client, err := c.current()
+	if err != nil {
+		return failedReqRes(types.ToRequestListSnapshots(req), err)
+	}
+	return client.ListSnapshotsAsync(req)
+}
+
+func (c *supervisedClient) OfferSnapshotAsync(req types.RequestOfferSnapshot) *abcicli.ReqRes {
+	client, err := c.current()
+	if err != nil {
+		return failedReqRes(types.ToRequestOfferSnapshot(req), err)
+	}
+	return client.OfferSnapshotAsync(req)
+}
+
+func (c *supervisedClient) LoadSnapshotChunkAsync(req types.RequestLoadSnapshotChunk) *abcicli.ReqRes {
+	client, err := c.current()
+	if err != nil {
+		return failedReqRes(types.ToRequestLoadSnapshotChunk(req), err)
+	}
+	return client.LoadSnapshotChunkAsync(req)
+}
+
+func (c *supervisedClient) ApplySnapshotChunkAsync(req types.RequestApplySnapshotChunk) *abcicli.ReqRes {
+	client, err := c.current()
+	if err != nil {
+		return failedReqRes(types.ToRequestApplySnapshotChunk(req), err)
+	}
+	return client.ApplySnapshotChunkAsync(req)
+}
+
+func (c *supervisedClient) FlushSync() error {
+	client, err := c.current()
+	if err != nil {
+		return err
+	}
+	return client.FlushSync()
+}
+
+func (c *supervisedClient) EchoSync(msg string) (*types.ResponseEcho, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.EchoSync(msg)
+}
+
+func (c *supervisedClient) InfoSync(req types.RequestInfo) (*types.ResponseInfo, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.InfoSync(req)
+}
+
+func (c *supervisedClient) SetOptionSync(req types.RequestSetOption) (*types.ResponseSetOption, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.SetOptionSync(req)
+}
+
+func (c *supervisedClient) DeliverTxSync(req types.RequestDeliverTx) (*types.ResponseDeliverTx, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.DeliverTxSync(req)
+}
+
+func (c *supervisedClient) CheckTxSync(req types.RequestCheckTx) (*types.ResponseCheckTx, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.CheckTxSync(req)
+}
+
+func (c *supervisedClient) QuerySync(req types.RequestQuery) (*types.ResponseQuery, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.QuerySync(req)
+}
+
+func (c *supervisedClient) CommitSync() (*types.ResponseCommit, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.CommitSync()
+}
+
+func (c *supervisedClient) InitChainSync(req types.RequestInitChain) (*types.ResponseInitChain, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.InitChainSync(req)
+}
+
+func (c *supervisedClient) BeginBlockSync(req types.RequestBeginBlock) (*types.ResponseBeginBlock, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.BeginBlockSync(req)
+}
+
+func (c *supervisedClient) EndBlockSync(req types.RequestEndBlock) (*types.ResponseEndBlock, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.EndBlockSync(req)
+}
+
+func (c *supervisedClient) ListSnapshotsSync(req types.RequestListSnapshots) (*types.ResponseListSnapshots, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.ListSnapshotsSync(req)
+}
+
+func (c *supervisedClient) OfferSnapshotSync(req types.RequestOfferSnapshot) (*types.ResponseOfferSnapshot, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.OfferSnapshotSync(req)
+}
+
+func (c *supervisedClient) LoadSnapshotChunkSync(req types.RequestLoadSnapshotChunk) (*types.ResponseLoadSnapshotChunk, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.LoadSnapshotChunkSync(req)
+}
+
+func (c *supervisedClient) ApplySnapshotChunkSync(req types.RequestApplySnapshotChunk) (*types.ResponseApplySnapshotChunk, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.ApplySnapshotChunkSync(req)
+}
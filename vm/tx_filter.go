@@ -0,0 +1,24 @@
+package vm
+
+import (
+	mempl "github.com/consideritdone/landslidecore/mempool"
+	sm "github.com/consideritdone/landslidecore/state"
+)
+
+// txPreCheck returns the filter the txs go through before the app checks
+// them, so that the obviously bad ones don't cost the app anything:
+// TxPreCheck under [state], the mempoolBannedPrefixes of the config, the
+// filters set with WithTxFilters and the check of the warp txs.
+func (vm *VM) txPreCheck(state sm.State) mempl.PreCheckFunc {
+	filters := []mempl.PreCheckFunc{TxPreCheck(state)}
+	if len(vm.config.MempoolBannedPrefixes) > 0 {
+		prefixes := make([][]byte, len(vm.config.MempoolBannedPrefixes))
+		for i, prefix := range vm.config.MempoolBannedPrefixes {
+			prefixes[i] = prefix
+		}
+		filters = append(filters, mempl.PreCheckBannedPrefixes(prefixes))
+	}
+	filters = append(filters, vm.txFilters...)
+	filters = append(filters, vm.warpPreCheck)
+	return mempl.PreCheckAll(filters...)
+}
@@ -0,0 +1,260 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	mempl "github.com/consideritdone/landslidecore/mempool"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+const (
+	// maxGossipTxHashes is the largest number of tx hashes in a gossip
+	// message, and of txs in a request.
+	maxGossipTxHashes = 256
+	// txsRequestTimeout is how long a peer has to answer a tx request.
+	txsRequestTimeout = 10 * time.Second
+	// txGossipPeers is the number of connected peers the hashes are gossiped
+	// to. Each peer gossips the txs it fetched in turn.
+	txGossipPeers = 10
+	// maxTxFetchesPerPeer is the largest number of tx requests in flight to a
+	// peer. The txs it announces beyond are not fetched, but the peer
+	// announces them again later.
+	maxTxFetchesPerPeer = 4
+)
+
+type (
+	// txsGossip announces the hashes of txs in the mempool of the sender.
+	// Peers missing some of them request them with a txsRequest, so that txs
+	// cross the network once per node instead of once per gossip.
+	txsGossip struct {
+		Hashes [][]byte `json:"hashes"`
+	}
+
+	// txsRequest is the app request for the txs with the given hashes. The
+	// response is a txsResponse with those the peer still has.
+	txsRequest struct {
+		Hashes [][]byte `json:"hashes"`
+	}
+
+	txsResponse struct {
+		Txs [][]byte `json:"txs"`
+	}
+
+	// txFetcher tracks the txs being requested, so that a tx announced by
+	// several peers is requested once, and the requests in flight to each
+	// peer.
+	txFetcher struct {
+		mtx      sync.Mutex
+		fetching map[[mempl.TxKeySize]byte]struct{}
+		inFlight map[ids.NodeID]int
+		quit     chan struct{}
+	}
+)
+
+func newTxFetcher() *txFetcher {
+	return &txFetcher{
+		fetching: make(map[[mempl.TxKeySize]byte]struct{}),
+		inFlight: make(map[ids.NodeID]int),
+		quit:     make(chan struct{}),
+	}
+}
+
+// gossipTxs announces the hashes of [txs] to a sample of the connected peers,
+// or to the peers avalanchego picks if none is connected yet.
+func (vm *VM) gossipTxs(txs types.Txs) {
+	if vm.appSender == nil || len(txs) == 0 {
+		return
+	}
+	for len(txs) > 0 {
+		n := len(txs)
+		if n > maxGossipTxHashes {
+			n = maxGossipTxHashes
+		}
+		gossip := &txsGossip{Hashes: make([][]byte, 0, n)}
+		for _, tx := range txs[:n] {
+			txKey := mempl.TxKey(tx)
+			gossip.Hashes = append(gossip.Hashes, txKey[:])
+		}
+		txs = txs[n:]
+
+		msg, err := json.Marshal(appGossipMsg{Txs: gossip})
+		if err != nil {
+			vm.tmLogger.Error("failed to marshal tx gossip", "err", err)
+			return
+		}
+		if err := vm.sendTxGossip(msg); err != nil {
+			vm.tmLogger.Error("failed to gossip txs", "err", err)
+			return
+		}
+	}
+}
+
+func (vm *VM) sendTxGossip(msg []byte) error {
+	if peers := vm.network.samplePeers(txGossipPeers); peers.Len() > 0 {
+		return vm.appSender.SendAppGossipSpecific(context.Background(), peers, msg)
+	}
+	return vm.appSender.SendAppGossip(context.Background(), msg)
+}
+
+// gossipTxOnCheck returns a CheckTx callback which announces [tx] once it
+// is accepted in the mempool.
+func (vm *VM) gossipTxOnCheck(tx types.Tx) func(*abci.Response) {
+	return func(res *abci.Response) {
+		if checkTxRes := res.GetCheckTx(); checkTxRes != nil && checkTxRes.Code == abci.CodeTypeOK {
+			vm.gossipTxs(types.Txs{tx})
+		}
+	}
+}
+
+// regossipTxs announces the oldest txs of the mempool every [interval], so
+// that peers which missed them, e.g. while restarting, can fetch them. It
+// pauses while the mempool is over MempoolHighWaterMark, as the peers are
+// likely saturated too.
+func (vm *VM) regossipTxs(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if usage, saturated := vm.mempoolSaturated(); saturated {
+				vm.tmLogger.Debug("mempool saturated, not announcing its txs again", "usage", usage)
+				continue
+			}
+			vm.gossipTxs(vm.mempool.ReapMaxTxs(maxGossipTxHashes))
+		case <-vm.txFetcher.quit:
+			return
+		}
+	}
+}
+
+// txsGossiped requests the txs announced by [nodeID] which are neither in the
+// mempool nor already being requested, unless maxTxFetchesPerPeer requests to
+// the peer are in flight.
+func (vm *VM) txsGossiped(nodeID ids.NodeID, gossip *txsGossip) {
+	getter, ok := vm.mempool.(pendingTxGetter)
+	if !ok {
+		return
+	}
+	txKeys := txKeysOf(gossip.Hashes)
+	missing := make([][mempl.TxKeySize]byte, 0, len(txKeys))
+	vm.txFetcher.mtx.Lock()
+	if vm.txFetcher.inFlight[nodeID] >= maxTxFetchesPerPeer {
+		vm.txFetcher.mtx.Unlock()
+		vm.tmLogger.Debug("too many tx requests in flight to peer, not fetching its txs", "nodeID", nodeID)
+		return
+	}
+	for _, txKey := range txKeys {
+		if _, ok := getter.TxByKey(txKey); ok {
+			continue
+		}
+		if _, ok := vm.txFetcher.fetching[txKey]; ok {
+			continue
+		}
+		vm.txFetcher.fetching[txKey] = struct{}{}
+		missing = append(missing, txKey)
+	}
+	if len(missing) > 0 {
+		vm.txFetcher.inFlight[nodeID]++
+	}
+	vm.txFetcher.mtx.Unlock()
+
+	if len(missing) > 0 {
+		go vm.fetchTxs(nodeID, missing)
+	}
+}
+
+// fetchTxs requests the txs with the [txKeys] from [nodeID], adds them to the
+// mempool, and announces those accepted to its own peers.
+func (vm *VM) fetchTxs(nodeID ids.NodeID, txKeys [][mempl.TxKeySize]byte) {
+	defer func() {
+		vm.txFetcher.mtx.Lock()
+		for _, txKey := range txKeys {
+			delete(vm.txFetcher.fetching, txKey)
+		}
+		if vm.txFetcher.inFlight[nodeID]--; vm.txFetcher.inFlight[nodeID] == 0 {
+			delete(vm.txFetcher.inFlight, nodeID)
+		}
+		vm.txFetcher.mtx.Unlock()
+	}()
+
+	req := &txsRequest{Hashes: make([][]byte, 0, len(txKeys))}
+	for i := range txKeys {
+		req.Hashes = append(req.Hashes, txKeys[i][:])
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), txsRequestTimeout)
+	defer cancel()
+	resBytes, err := vm.sendAppRequest(ctx, nodeID, appRequest{Txs: req})
+	if err != nil || len(resBytes) == 0 {
+		vm.tmLogger.Debug("failed to fetch txs", "nodeID", nodeID, "err", err)
+		return
+	}
+	var res txsResponse
+	if err := json.Unmarshal(resBytes, &res); err != nil {
+		vm.tmLogger.Debug("dropping malformed txs response", "nodeID", nodeID, "err", err)
+		return
+	}
+
+	requested := make(map[[mempl.TxKeySize]byte]struct{}, len(txKeys))
+	for _, txKey := range txKeys {
+		requested[txKey] = struct{}{}
+	}
+	txs := make(types.Txs, 0, len(res.Txs))
+	for _, tx := range res.Txs {
+		// the peer may only send the txs which were requested
+		if _, ok := requested[mempl.TxKey(tx)]; ok {
+			txs = append(txs, tx)
+		}
+	}
+	// checked in parallel over the abciCheckTxConnections
+	gossipOnCheck := func(i int, res *abci.Response) {
+		vm.gossipTxOnCheck(txs[i])(res)
+	}
+	for _, err := range vm.mempool.CheckTxBatch(txs, gossipOnCheck, mempl.TxInfo{Origin: nodeID.String()}) {
+		if err != nil {
+			vm.tmLogger.Debug("fetched tx not added to the mempool", "nodeID", nodeID, "err", err)
+		}
+	}
+}
+
+// serveTxs answers the tx request of a peer with the requested txs in the
+// mempool.
+func (vm *VM) serveTxs(ctx context.Context, nodeID ids.NodeID, requestID uint32, req *txsRequest) error {
+	res := txsResponse{Txs: [][]byte{}}
+	if getter, ok := vm.mempool.(pendingTxGetter); ok {
+		for _, txKey := range txKeysOf(req.Hashes) {
+			if tx, ok := getter.TxByKey(txKey); ok {
+				res.Txs = append(res.Txs, tx)
+			}
+		}
+	}
+	resBytes, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return vm.appSender.SendAppResponse(ctx, nodeID, requestID, resBytes)
+}
+
+// txKeysOf returns the mempool keys of the first maxGossipTxHashes [hashes],
+// skipping malformed ones.
+func txKeysOf(hashes [][]byte) [][mempl.TxKeySize]byte {
+	if len(hashes) > maxGossipTxHashes {
+		hashes = hashes[:maxGossipTxHashes]
+	}
+	txKeys := make([][mempl.TxKeySize]byte, 0, len(hashes))
+	for _, hash := range hashes {
+		var txKey [mempl.TxKeySize]byte
+		if len(hash) != len(txKey) {
+			continue
+		}
+		copy(txKey[:], hash)
+		txKeys = append(txKeys, txKey)
+	}
+	return txKeys
+}
@@ -0,0 +1,114 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+	mempl "github.com/consideritdone/landslidecore/mempool"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+func TestTxGossip(t *testing.T) {
+	newVM := func() *VM {
+		vm, _, _, err := newTestVMWithDB(kvstore.NewApplication(), manager.NewMemDB(&version.Semantic{Major: 1}), nil)
+		require.NoError(t, err)
+		return vm
+	}
+	sender, receiver := newVM(), newVM()
+	senderNodeID, receiverNodeID := ids.GenerateTestNodeID(), ids.GenerateTestNodeID()
+
+	// the sender announces txs, the receiver fetches them through app requests
+	sender.appSender = &common.SenderTest{
+		T: t,
+		SendAppGossipF: func(ctx context.Context, msg []byte) error {
+			return receiver.AppGossip(ctx, senderNodeID, msg)
+		},
+		SendAppResponseF: func(ctx context.Context, nodeID ids.NodeID, requestID uint32, response []byte) error {
+			require.Equal(t, receiverNodeID, nodeID)
+			return receiver.AppResponse(ctx, senderNodeID, requestID, response)
+		},
+	}
+	regossiped := make(chan []byte, 1)
+	receiver.appSender = &common.SenderTest{
+		T: t,
+		SendAppRequestF: func(ctx context.Context, nodeIDs set.Set[ids.NodeID], requestID uint32, request []byte) error {
+			require.True(t, nodeIDs.Contains(senderNodeID))
+			return sender.AppRequest(ctx, receiverNodeID, requestID, time.Now().Add(time.Minute), request)
+		},
+		SendAppGossipF: func(_ context.Context, msg []byte) error {
+			regossiped <- msg
+			return nil
+		},
+	}
+
+	tx := types.Tx("name=satoshi")
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, NewService(sender).BroadcastTxSync(nil, &BroadcastTxArgs{Tx: tx}, reply))
+	require.Equal(t, atypes.CodeTypeOK, reply.Code)
+
+	getter := receiver.mempool.(pendingTxGetter)
+	require.Eventually(t, func() bool {
+		_, ok := getter.TxByKey(mempl.TxKey(tx))
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, receiver.mempool.Size())
+
+	// the receiver announces the fetched txs in turn
+	var msg appGossipMsg
+	require.NoError(t, json.Unmarshal(<-regossiped, &msg))
+	require.NotNil(t, msg.Txs)
+	txKey := mempl.TxKey(tx)
+	assert.Equal(t, [][]byte{txKey[:]}, msg.Txs.Hashes)
+
+	// no more than maxTxFetchesPerPeer requests are in flight to a peer, once
+	// the fetch of the tx is done
+	fetcher := receiver.txFetcher
+	require.Eventually(t, func() bool {
+		fetcher.mtx.Lock()
+		defer fetcher.mtx.Unlock()
+		return len(fetcher.inFlight) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	otherTxKey := mempl.TxKey(types.Tx("other"))
+	fetcher.mtx.Lock()
+	fetcher.inFlight[senderNodeID] = maxTxFetchesPerPeer
+	fetcher.mtx.Unlock()
+	receiver.txsGossiped(senderNodeID, &txsGossip{Hashes: [][]byte{otherTxKey[:]}})
+	fetcher.mtx.Lock()
+	assert.Empty(t, fetcher.fetching)
+	delete(fetcher.inFlight, senderNodeID)
+	fetcher.mtx.Unlock()
+
+	// txs the sender doesn't have, and malformed hashes, are skipped
+	missing := mempl.TxKey(types.Tx("unknown"))
+	res, err := receiver.sendAppRequest(context.Background(), senderNodeID, appRequest{
+		Txs: &txsRequest{Hashes: [][]byte{missing[:], {1, 2}}},
+	})
+	require.NoError(t, err)
+	var txsRes txsResponse
+	require.NoError(t, json.Unmarshal(res, &txsRes))
+	assert.Empty(t, txsRes.Txs)
+
+	// once peers are connected, the txs are gossiped to them
+	gossiped := make(chan set.Set[ids.NodeID], 1)
+	sender.appSender.(*common.SenderTest).SendAppGossipSpecificF = func(_ context.Context, nodeIDs set.Set[ids.NodeID], _ []byte) error {
+		gossiped <- nodeIDs
+		return nil
+	}
+	require.NoError(t, sender.Connected(context.Background(), receiverNodeID, version.CurrentApp))
+	sender.gossipTxs(types.Txs{tx})
+	nodeIDs := <-gossiped
+	assert.Equal(t, []ids.NodeID{receiverNodeID}, nodeIDs.List())
+}
@@ -0,0 +1,110 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+)
+
+// unixSocketMode restricts the rpc unix socket to the user running the node.
+const unixSocketMode = 0o600
+
+// serveUnixSocket serves [handlers] on a Unix socket at [path], in addition to
+// the avalanchego HTTP server, under the same paths. The chain lock is taken
+// the same way avalanchego takes it for the handler's LockOptions. The
+// [admin] handler is served under /admin if it isn't nil. The socket served
+// before, by a previous call, is closed.
+func (vm *VM) serveUnixSocket(path string, handlers map[string]*common.HTTPHandler, admin *common.HTTPHandler) error {
+	if err := vm.closeUnixSocket(context.Background()); err != nil {
+		return fmt.Errorf("failed to close rpc unix socket %s: %w", path, err)
+	}
+	listener, err := listenUnixSocket(path)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	for endpoint, handler := range handlers {
+		mux.Handle(endpoint, vm.lockedHandler(handler))
+	}
+	if admin != nil {
+		mux.Handle("/admin", vm.lockedHandler(admin))
+	}
+	vm.unixServer = &http.Server{Handler: mux}
+
+	logger := vm.tmLogger.With("module", "rpc")
+	logger.Info("serving rpc on unix socket", "path", path)
+	go func() {
+		if err := vm.unixServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("rpc unix socket server stopped", "err", err)
+		}
+	}()
+	return nil
+}
+
+// listenUnixSocket listens on a Unix socket at [path], replacing the socket
+// left over by an unclean shutdown. The socket is created in a directory only
+// the user running the node can access, and restricted to unixSocketMode
+// before it is moved to [path], so that no other user can connect in between.
+// The socket isn't removed when the listener is closed, see closeUnixSocket.
+func listenUnixSocket(path string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".rpc")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rpc unix socket directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tmpPath := filepath.Join(dir, "s")
+	listener, err := net.Listen("unix", tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on rpc unix socket %s: %w", path, err)
+	}
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(tmpPath, unixSocketMode); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to restrict rpc unix socket %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to move rpc unix socket to %s: %w", path, err)
+	}
+	return listener, nil
+}
+
+// lockedHandler wraps [handler] to hold the chain lock required by its
+// LockOptions while serving a request.
+func (vm *VM) lockedHandler(handler *common.HTTPHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch handler.LockOptions {
+		case common.WriteLock:
+			vm.ctx.Lock.Lock()
+			defer vm.ctx.Lock.Unlock()
+		case common.ReadLock:
+			vm.ctx.Lock.RLock()
+			defer vm.ctx.Lock.RUnlock()
+		}
+		handler.Handler.ServeHTTP(w, r)
+	})
+}
+
+// closeUnixSocket stops the Unix socket server, if it is running, and removes
+// its socket.
+func (vm *VM) closeUnixSocket(ctx context.Context) error {
+	if vm.unixServer == nil {
+		return nil
+	}
+	if err := vm.unixServer.Shutdown(ctx); err != nil {
+		return err
+	}
+	vm.unixServer = nil
+	if err := os.Remove(vm.config.RPCUnixSocket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package vm
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// upgradeOptionKey is the key of the SetOption request which signals an
+// upgrade to the app. The value is the name of the upgrade.
+const upgradeOptionKey = "upgrade"
+
+// upgradeKeyPrefix prefixes the keys of the activation heights of the applied
+// upgrades in the state database.
+var upgradeKeyPrefix = []byte("upgrade/")
+
+var errNoOptionSetter = errors.New("the consensus connection can't set options of the app")
+
+type (
+	// Upgrade is a named network upgrade, activated by the first block at or
+	// above Height, or at or after Time. Exactly one of them is set.
+	Upgrade struct {
+		Name   string     `json:"name"`
+		Height int64      `json:"height,omitempty"`
+		Time   *time.Time `json:"time,omitempty"`
+	}
+
+	// upgradeSchedule is the format of the upgradeBytes, e.g.
+	//
+	//	{"upgrades":[{"name":"v2","height":1000},{"name":"v3","time":"2024-01-01T00:00:00Z"}]}
+	upgradeSchedule struct {
+		Upgrades []Upgrade `json:"upgrades"`
+	}
+
+	UpgradeStatus struct {
+		Upgrade
+		// AppliedHeight is the height of the block which activated the
+		// upgrade, 0 while it is pending.
+		AppliedHeight int64 `json:"appliedHeight"`
+	}
+
+	UpgradesReply struct {
+		Upgrades []UpgradeStatus `json:"upgrades"`
+	}
+
+	UpgradeService interface {
+		Upgrades(_ *http.Request, _ *struct{}, reply *UpgradesReply) error
+	}
+)
+
+// optionSetter is implemented by the consensus connections of the proxy app.
+type optionSetter interface {
+	SetOptionSync(abci.RequestSetOption) (*abci.ResponseSetOption, error)
+}
+
+// parseUpgrades decodes the upgrade schedule from [upgradeBytes], which may
+// be empty.
+func parseUpgrades(upgradeBytes []byte) ([]Upgrade, error) {
+	if len(upgradeBytes) == 0 {
+		return nil, nil
+	}
+	var schedule upgradeSchedule
+	if err := json.Unmarshal(upgradeBytes, &schedule); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal upgrades %s: %w", string(upgradeBytes), err)
+	}
+	names := make(map[string]struct{}, len(schedule.Upgrades))
+	for _, upgrade := range schedule.Upgrades {
+		if upgrade.Name == "" {
+			return nil, errors.New("upgrade name must not be empty")
+		}
+		if _, ok := names[upgrade.Name]; ok {
+			return nil, fmt.Errorf("duplicate upgrade %s", upgrade.Name)
+		}
+		names[upgrade.Name] = struct{}{}
+		if (upgrade.Height > 0) == (upgrade.Time != nil) {
+			return nil, fmt.Errorf("upgrade %s must have either a positive height or a time", upgrade.Name)
+		}
+	}
+	return schedule.Upgrades, nil
+}
+
+// due returns whether the upgrade is activated by [header].
+func (u Upgrade) due(header *types.Header) bool {
+	if u.Time != nil {
+		return !header.Time.Before(*u.Time)
+	}
+	return header.Height >= u.Height
+}
+
+func upgradeKey(name string) []byte {
+	return append(append([]byte{}, upgradeKeyPrefix...), name...)
+}
+
+// appliedHeight returns the height at which the [name] upgrade was applied,
+// or 0 if it wasn't.
+func (vm *VM) appliedHeight(name string) (int64, error) {
+	value, err := vm.stateDB.Get(upgradeKey(name))
+	if err != nil || len(value) == 0 {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(value)), nil
+}
+
+// signalAppliedUpgrades signals the upgrades applied by previous blocks to the
+// app on startup, in the order of the schedule, so that it needn't persist
+// them itself.
+func (vm *VM) signalAppliedUpgrades() error {
+	for _, upgrade := range vm.upgrades {
+		height, err := vm.appliedHeight(upgrade.Name)
+		if err != nil {
+			return err
+		}
+		if height == 0 {
+			continue
+		}
+		if err := vm.signalUpgrade(upgrade); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyUpgrades signals the upgrades activated by [header] to the app, before
+// the block is executed, and records them as applied at its height.
+func (vm *VM) applyUpgrades(header *types.Header) error {
+	for _, upgrade := range vm.upgrades {
+		if !upgrade.due(header) {
+			continue
+		}
+		height, err := vm.appliedHeight(upgrade.Name)
+		if err != nil {
+			return err
+		}
+		if height != 0 {
+			continue
+		}
+		if err := vm.signalUpgrade(upgrade); err != nil {
+			return err
+		}
+		value := make([]byte, 8)
+		binary.BigEndian.PutUint64(value, uint64(header.Height))
+		if err := vm.stateDB.SetSync(upgradeKey(upgrade.Name), value); err != nil {
+			return err
+		}
+		vm.tmLogger.Info("applied upgrade", "name", upgrade.Name, "height", header.Height)
+	}
+	return nil
+}
+
+func (vm *VM) signalUpgrade(upgrade Upgrade) error {
+	setter, ok := vm.proxyApp.Consensus().(optionSetter)
+	if !ok {
+		return errNoOptionSetter
+	}
+	res, err := setter.SetOptionSync(abci.RequestSetOption{Key: upgradeOptionKey, Value: upgrade.Name})
+	if err != nil {
+		return err
+	}
+	if res.Code != abci.CodeTypeOK {
+		return fmt.Errorf("app rejected upgrade %s: %s", upgrade.Name, res.Log)
+	}
+	return nil
+}
+
+// Upgrades returns the upgrade schedule, with the heights at which the
+// upgrades were applied.
+func (s *LocalService) Upgrades(_ *http.Request, _ *struct{}, reply *UpgradesReply) error {
+	reply.Upgrades = make([]UpgradeStatus, 0, len(s.vm.upgrades))
+	for _, upgrade := range s.vm.upgrades {
+		height, err := s.vm.appliedHeight(upgrade.Name)
+		if err != nil {
+			return err
+		}
+		reply.Upgrades = append(reply.Upgrades, UpgradeStatus{Upgrade: upgrade, AppliedHeight: height})
+	}
+	return nil
+}
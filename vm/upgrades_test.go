@@ -0,0 +1,79 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+)
+
+// upgradeApp records the upgrades signaled to it.
+type upgradeApp struct {
+	*kvstore.Application
+	upgrades []string
+}
+
+func (app *upgradeApp) SetOption(req atypes.RequestSetOption) atypes.ResponseSetOption {
+	if req.Key == upgradeOptionKey {
+		app.upgrades = append(app.upgrades, req.Value)
+	}
+	return atypes.ResponseSetOption{}
+}
+
+func TestParseUpgrades(t *testing.T) {
+	upgrades, err := parseUpgrades(nil)
+	require.NoError(t, err)
+	assert.Empty(t, upgrades)
+
+	upgrades, err = parseUpgrades([]byte(`{"upgrades":[{"name":"v2","height":10},{"name":"v3","time":"2024-01-01T00:00:00Z"}]}`))
+	require.NoError(t, err)
+	require.Len(t, upgrades, 2)
+	assert.Equal(t, int64(10), upgrades[0].Height)
+	assert.NotNil(t, upgrades[1].Time)
+
+	for _, upgradeBytes := range []string{
+		`{"upgrades":[{"height":10}]}`,
+		`{"upgrades":[{"name":"v2"}]}`,
+		`{"upgrades":[{"name":"v2","height":10,"time":"2024-01-01T00:00:00Z"}]}`,
+		`{"upgrades":[{"name":"v2","height":10},{"name":"v2","height":20}]}`,
+		`{"upgrades":`,
+	} {
+		_, err := parseUpgrades([]byte(upgradeBytes))
+		assert.Error(t, err, upgradeBytes)
+	}
+}
+
+func TestUpgrades(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	upgradeBytes := []byte(`{"upgrades":[{"name":"v2","height":2},{"name":"v3","height":100}]}`)
+	app := &upgradeApp{Application: kvstore.NewApplication()}
+	vm, _, _, err := newTestVMWithGenesis(app, dbManager, []byte(genesis), upgradeBytes, nil)
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	assert.Empty(t, app.upgrades)
+	mustAcceptBlock(t, vm, service, []byte("b=2"))
+	assert.Equal(t, []string{"v2"}, app.upgrades)
+	mustAcceptBlock(t, vm, service, []byte("c=3"))
+	assert.Equal(t, []string{"v2"}, app.upgrades)
+
+	reply := new(UpgradesReply)
+	require.NoError(t, service.Upgrades(nil, nil, reply))
+	require.Len(t, reply.Upgrades, 2)
+	assert.Equal(t, "v2", reply.Upgrades[0].Name)
+	assert.Equal(t, int64(2), reply.Upgrades[0].AppliedHeight)
+	assert.Equal(t, "v3", reply.Upgrades[1].Name)
+	assert.Equal(t, int64(0), reply.Upgrades[1].AppliedHeight)
+
+	// the applied upgrades are signaled again on restart
+	app.upgrades = nil
+	_, _, _, err = newTestVMWithGenesis(app, dbManager, []byte(genesis), upgradeBytes, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v2"}, app.upgrades)
+}
@@ -0,0 +1,194 @@
+package vm
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+
+	"github.com/consideritdone/landslidecore/libs/log"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	rpcserver "github.com/consideritdone/landslidecore/rpc/jsonrpc/server"
+	rpctypes "github.com/consideritdone/landslidecore/rpc/jsonrpc/types"
+)
+
+// uriRequestID is the id of the responses to URI requests, which have none,
+// as in Tendermint.
+var uriRequestID = rpctypes.JSONRPCIntID(-1)
+
+// uriHandlerFunc serves a URI request from its query parameters and returns
+// the result. Malformed parameters are reported as a uriParamsError.
+type uriHandlerFunc func(r *http.Request) (interface{}, error)
+
+type uriParamsError struct {
+	err error
+}
+
+func (e uriParamsError) Error() string {
+	return e.err.Error()
+}
+
+// uriHandlers returns the handlers of the GET routes which follow the URI
+// conventions of Tendermint's RPC, e.g.
+//
+//	GET /broadcast_tx_sync?tx=0x0102
+//	GET /abci_query?path="/key"&data="abc"&height=10&prove=true
+//
+// so that tooling written against Tendermint works against the chain's
+// endpoint. Byte and string parameters are either 0x-prefixed hex or a quoted
+// string.
+func uriHandlers(service *LocalService, logger log.Logger) map[string]*common.HTTPHandler {
+	routes := map[string]uriHandlerFunc{
+		"/broadcast_tx_async": func(r *http.Request) (interface{}, error) {
+			tx, err := uriBytesParam(r, "tx")
+			if err != nil {
+				return nil, err
+			}
+			reply := new(ctypes.ResultBroadcastTx)
+			return reply, service.BroadcastTxAsync(r, &BroadcastTxArgs{Tx: tx}, reply)
+		},
+		"/broadcast_tx_sync": func(r *http.Request) (interface{}, error) {
+			tx, err := uriBytesParam(r, "tx")
+			if err != nil {
+				return nil, err
+			}
+			reply := new(ctypes.ResultBroadcastTx)
+			return reply, service.BroadcastTxSync(r, &BroadcastTxArgs{Tx: tx}, reply)
+		},
+		"/broadcast_tx_commit": func(r *http.Request) (interface{}, error) {
+			tx, err := uriBytesParam(r, "tx")
+			if err != nil {
+				return nil, err
+			}
+			reply := new(BroadcastTxCommitReply)
+			if err := service.BroadcastTxCommit(r, &BroadcastTxCommitArgs{Tx: tx}, reply); err != nil {
+				return nil, err
+			}
+			return &reply.ResultBroadcastTxCommit, nil
+		},
+		"/abci_query": func(r *http.Request) (interface{}, error) {
+			path, err := uriStringParam(r, "path")
+			if err != nil {
+				return nil, err
+			}
+			data, err := uriBytesParam(r, "data")
+			if err != nil {
+				return nil, err
+			}
+			height, err := uriIntParam(r, "height")
+			if err != nil {
+				return nil, err
+			}
+			prove, err := uriBoolParam(r, "prove")
+			if err != nil {
+				return nil, err
+			}
+			args := &ABCIQueryWithOptionsArgs{
+				Path: path,
+				Data: data,
+				Opts: ABCIQueryOptions{Height: height, Prove: prove},
+			}
+			reply := new(ctypes.ResultABCIQuery)
+			return reply, service.ABCIQueryWithOptions(r, args, reply)
+		},
+	}
+
+	handlers := make(map[string]*common.HTTPHandler, len(routes))
+	for route, handle := range routes {
+		handlers[route] = &common.HTTPHandler{
+			LockOptions: common.WriteLock,
+			Handler:     newURIHandler(handle, logger),
+		}
+	}
+	return handlers
+}
+
+func newURIHandler(handle uriHandlerFunc, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		result, err := handle(r)
+		if err != nil {
+			res := rpctypes.RPCInternalError(uriRequestID, err)
+			var paramsErr uriParamsError
+			if errors.As(err, &paramsErr) {
+				res = rpctypes.RPCInvalidParamsError(uriRequestID,
+					fmt.Errorf("error converting http params to arguments: %w", err))
+			}
+			if err := rpcserver.WriteRPCResponseHTTPError(w, http.StatusInternalServerError, res); err != nil {
+				logger.Error("failed to write response", "res", res, "err", err)
+			}
+			return
+		}
+		if err := rpcserver.WriteRPCResponseHTTP(w, rpctypes.NewRPCSuccessResponse(uriRequestID, result)); err != nil {
+			logger.Error("failed to write response", "err", err)
+		}
+	})
+}
+
+// uriBytesParam decodes the [name] query parameter, which is either
+// 0x-prefixed hex or a quoted string. It is nil if the parameter is missing.
+func uriBytesParam(r *http.Request, name string) ([]byte, error) {
+	arg := r.URL.Query().Get(name)
+	switch {
+	case arg == "":
+		return nil, nil
+	case strings.HasPrefix(strings.ToLower(arg), "0x"):
+		value, err := hex.DecodeString(arg[2:])
+		if err != nil {
+			return nil, uriParamsError{fmt.Errorf("invalid hex %s: %w", name, err)}
+		}
+		return value, nil
+	case len(arg) >= 2 && strings.HasPrefix(arg, `"`) && strings.HasSuffix(arg, `"`):
+		var value string
+		if err := json.Unmarshal([]byte(arg), &value); err != nil {
+			return nil, uriParamsError{fmt.Errorf("invalid quoted string %s: %w", name, err)}
+		}
+		return []byte(value), nil
+	default:
+		return nil, uriParamsError{fmt.Errorf("%s must be 0x-prefixed hex or a quoted string, got %s", name, arg)}
+	}
+}
+
+// uriStringParam decodes the [name] query parameter like uriBytesParam.
+func uriStringParam(r *http.Request, name string) (string, error) {
+	value, err := uriBytesParam(r, name)
+	return string(value), err
+}
+
+// uriIntParam decodes the [name] query parameter, which is an integer,
+// optionally quoted. It is 0 if the parameter is missing.
+func uriIntParam(r *http.Request, name string) (int64, error) {
+	arg := strings.Trim(r.URL.Query().Get(name), `"`)
+	if arg == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return 0, uriParamsError{fmt.Errorf("invalid integer %s: %w", name, err)}
+	}
+	return value, nil
+}
+
+// uriBoolParam decodes the [name] query parameter, which is a boolean. It is
+// false if the parameter is missing.
+func uriBoolParam(r *http.Request, name string) (bool, error) {
+	arg := r.URL.Query().Get(name)
+	if arg == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(arg)
+	if err != nil {
+		return false, uriParamsError{fmt.Errorf("invalid boolean %s: %w", name, err)}
+	}
+	return value, nil
+}
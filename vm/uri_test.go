@@ -0,0 +1,82 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+	tmjson "github.com/consideritdone/landslidecore/libs/json"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	rpctypes "github.com/consideritdone/landslidecore/rpc/jsonrpc/types"
+)
+
+func TestURIHandlers(t *testing.T) {
+	vm, _, _ := mustNewKVTestVm(t)
+	handlers, err := vm.CreateHandlers(context.Background())
+	require.NoError(t, err)
+
+	get := func(route string, query string) (int, rpctypes.RPCResponse) {
+		req, err := http.NewRequest(http.MethodGet, route+"?"+query, nil)
+		require.NoError(t, err)
+		rec := httptest.NewRecorder()
+		handlers[route].Handler.ServeHTTP(rec, req)
+		var res rpctypes.RPCResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return rec.Code, res
+	}
+
+	code, res := get("/broadcast_tx_sync", "tx="+url.QueryEscape(`"name=satoshi"`))
+	require.Equal(t, http.StatusOK, code)
+	require.Nil(t, res.Error)
+	broadcastReply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, tmjson.Unmarshal(res.Result, broadcastReply))
+	assert.Equal(t, atypes.CodeTypeOK, broadcastReply.Code)
+
+	code, res = get("/broadcast_tx_async", "tx=0x"+strings.Repeat("61", 3)+"3d62")
+	require.Equal(t, http.StatusOK, code)
+	require.Nil(t, res.Error)
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, blk.Accept(context.Background()))
+
+	// "name" in hex
+	code, res = get("/abci_query", "path="+url.QueryEscape(`"/key"`)+"&data=0x6e616d65")
+	require.Equal(t, http.StatusOK, code)
+	require.Nil(t, res.Error)
+	queryReply := new(ctypes.ResultABCIQuery)
+	require.NoError(t, tmjson.Unmarshal(res.Result, queryReply))
+	assert.Equal(t, []byte("satoshi"), queryReply.Response.Value)
+
+	code, res = get("/abci_query", "data="+url.QueryEscape(`"aaa"`)+"&prove=true")
+	require.Equal(t, http.StatusOK, code)
+	require.Nil(t, res.Error)
+	require.NoError(t, tmjson.Unmarshal(res.Result, queryReply))
+	assert.Equal(t, []byte("b"), queryReply.Response.Value)
+
+	code, res = get("/broadcast_tx_sync", "tx=name")
+	assert.Equal(t, http.StatusInternalServerError, code)
+	if assert.NotNil(t, res.Error) {
+		assert.Equal(t, -32602, res.Error.Code)
+	}
+
+	code, res = get("/abci_query", "height=abc")
+	assert.Equal(t, http.StatusInternalServerError, code)
+	if assert.NotNil(t, res.Error) {
+		assert.Equal(t, -32602, res.Error.Code)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "/abci_query", nil)
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	handlers["/abci_query"].Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
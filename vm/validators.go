@@ -0,0 +1,98 @@
+package vm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+
+	"github.com/consideritdone/landslidecore/crypto/bls12381"
+	tmstate "github.com/consideritdone/landslidecore/proto/tendermint/state"
+	sm "github.com/consideritdone/landslidecore/state"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+const (
+	// validatorUpdatesApply and validatorUpdatesReject are the values of the
+	// validatorUpdates config.
+	validatorUpdatesApply  = "apply"
+	validatorUpdatesReject = "reject"
+)
+
+var errValidatorUpdatesRejected = errors.New("the app returned validator updates, which the validatorUpdates config rejects")
+
+// checkValidatorUpdates enforces the validatorUpdates config on the validator
+// updates the app returned from the EndBlock of [height].
+func (vm *VM) checkValidatorUpdates(height int64, abciResponses *tmstate.ABCIResponses) error {
+	if abciResponses.EndBlock == nil || len(abciResponses.EndBlock.ValidatorUpdates) == 0 {
+		return nil
+	}
+	updates := abciResponses.EndBlock.ValidatorUpdates
+	if vm.config.ValidatorUpdates == validatorUpdatesReject {
+		return fmt.Errorf("%w: %d updates at height %d", errValidatorUpdatesRejected, len(updates), height)
+	}
+	vm.tmLogger.Info("applying the validator updates of the app", "height", height, "updates", len(updates))
+	return nil
+}
+
+// mirrorValidators replaces the next validators of [state] with the
+// validators of the subnet at the P-Chain height [block] was verified with,
+// whenever that height moves, so that the Tendermint validator set follows the
+// P-Chain. As with the validator updates of EndBlock, the new set applies two
+// heights later. The set only depends on the P-Chain height, which the block
+// carries, so every node mirrors the same one.
+func (vm *VM) mirrorValidators(ctx context.Context, state *sm.State, block *Block) error {
+	if !vm.config.MirrorValidatorSet || block.pChainHeight == 0 || block.pChainHeight == vm.mirroredPChainHeight {
+		return nil
+	}
+	subnetValidators, err := vm.ctx.ValidatorState.GetValidatorSet(ctx, block.pChainHeight, vm.ctx.SubnetID)
+	if err != nil {
+		return fmt.Errorf("failed to get the validators at P-Chain height %d: %w", block.pChainHeight, err)
+	}
+	vm.mirroredPChainHeight = block.pChainHeight
+
+	valSet := validatorSetOf(subnetValidators)
+	if valSet.IsNilOrEmpty() {
+		vm.tmLogger.Error("no subnet validator with a BLS key to mirror", "pChainHeight", block.pChainHeight)
+		return nil
+	}
+	if bytes.Equal(valSet.Hash(), state.NextValidators.Hash()) {
+		return nil
+	}
+	state.NextValidators = valSet
+	state.LastHeightValidatorsChanged = block.tmBlock.Height + 1 + 1
+	vm.tmLogger.Info("mirrored the subnet validators",
+		"pChainHeight", block.pChainHeight, "validators", valSet.Size())
+	return nil
+}
+
+// validatorSetOf returns the Tendermint validator set of the subnet
+// [subnetValidators]. The address of a validator is its node ID, the one of
+// the blocks it proposes, and its public key is its BLS key; validators without
+// one are left out. Weights are scaled down if their total exceeds the
+// largest total voting power of Tendermint.
+func validatorSetOf(subnetValidators map[ids.NodeID]*validators.GetValidatorOutput) *types.ValidatorSet {
+	var totalWeight uint64
+	for _, val := range subnetValidators {
+		totalWeight += val.Weight
+	}
+	divisor := totalWeight/uint64(types.MaxTotalVotingPower) + 1
+
+	vals := make([]*types.Validator, 0, len(subnetValidators))
+	for nodeID, val := range subnetValidators {
+		power := int64(val.Weight / divisor)
+		if val.PublicKey == nil || power == 0 {
+			continue
+		}
+		vals = append(vals, &types.Validator{
+			Address:     proposerAddressOf(nodeID),
+			PubKey:      bls12381.PubKey(bls.PublicKeyToBytes(val.PublicKey)),
+			VotingPower: power,
+		})
+	}
+	return types.NewValidatorSet(vals)
+}
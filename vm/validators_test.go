@@ -0,0 +1,120 @@
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+	"github.com/consideritdone/landslidecore/crypto"
+	"github.com/consideritdone/landslidecore/crypto/bls12381"
+	"github.com/consideritdone/landslidecore/crypto/ed25519"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+func TestMirrorValidatorSet(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	vm, snowCtx, _, err := newTestVMWithDB(kvstore.NewApplication(), dbManager, []byte(`{"mirrorValidatorSet":true,"validatorUpdates":"reject"}`))
+	require.NoError(t, err)
+	service := NewService(vm)
+	ctx := context.Background()
+
+	// two validators with a BLS key, and one without
+	subnetValidators := make(map[ids.NodeID]*validators.GetValidatorOutput)
+	for i, weight := range []uint64{100, 200, 300} {
+		nodeID := ids.GenerateTestNodeID()
+		val := &validators.GetValidatorOutput{NodeID: nodeID, Weight: weight}
+		if i < 2 {
+			sk, err := bls.NewSecretKey()
+			require.NoError(t, err)
+			val.PublicKey = bls.PublicFromSecretKey(sk)
+		}
+		subnetValidators[nodeID] = val
+	}
+	snowCtx.ValidatorState = &validators.TestState{
+		GetValidatorSetF: func(_ context.Context, height uint64, subnetID ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+			require.Equal(t, uint64(5), height)
+			require.Equal(t, snowCtx.SubnetID, subnetID)
+			return subnetValidators, nil
+		},
+	}
+
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, reply))
+	require.Equal(t, atypes.CodeTypeOK, reply.Code)
+	blk, err := vm.BuildBlock(ctx)
+	require.NoError(t, err)
+	require.NoError(t, blk.(block.WithVerifyContext).VerifyWithContext(ctx, &block.Context{PChainHeight: 5}))
+	require.NoError(t, blk.Accept(ctx))
+
+	// the mirrored set applies two heights later
+	mustAcceptBlock(t, vm, service, []byte("b=2"))
+	mustAcceptBlock(t, vm, service, []byte("c=3"))
+
+	height := int64(3)
+	valsReply := new(ctypes.ResultValidators)
+	require.NoError(t, service.Validators(nil, &ValidatorsArgs{Height: &height}, valsReply))
+	require.Len(t, valsReply.Validators, 2)
+	for _, val := range valsReply.Validators {
+		nodeID, err := ids.ToNodeID(val.Address)
+		require.NoError(t, err)
+		subnetValidator := subnetValidators[nodeID]
+		require.NotNil(t, subnetValidator)
+		assert.EqualValues(t, subnetValidator.Weight, val.VotingPower)
+		assert.Equal(t, bls12381.PubKey(bls.PublicKeyToBytes(subnetValidator.PublicKey)), val.PubKey)
+	}
+}
+
+// valUpdatesApp returns a validator update from every EndBlock.
+type valUpdatesApp struct {
+	*kvstore.Application
+	pubKey crypto.PubKey
+}
+
+func (app *valUpdatesApp) EndBlock(req atypes.RequestEndBlock) atypes.ResponseEndBlock {
+	res := app.Application.EndBlock(req)
+	res.ValidatorUpdates = atypes.ValidatorUpdates{types.TM2PB.NewValidatorUpdate(app.pubKey, 10)}
+	return res
+}
+
+func TestValidatorUpdates(t *testing.T) {
+	newVM := func(configBytes string) (*VM, Service, crypto.PubKey) {
+		app := &valUpdatesApp{Application: kvstore.NewApplication(), pubKey: ed25519.GenPrivKey().PubKey()}
+		dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+		vm, _, _, err := newTestVMWithDB(app, dbManager, []byte(configBytes))
+		require.NoError(t, err)
+		return vm, NewService(vm), app.pubKey
+	}
+
+	// by default, the updates apply two heights later
+	vm, service, pubKey := newVM("")
+	for i := 0; i < 3; i++ {
+		mustAcceptBlock(t, vm, service, []byte{byte('a' + i)})
+	}
+	height := int64(3)
+	valsReply := new(ctypes.ResultValidators)
+	require.NoError(t, service.Validators(nil, &ValidatorsArgs{Height: &height}, valsReply))
+	require.Len(t, valsReply.Validators, 1)
+	assert.Equal(t, pubKey, valsReply.Validators[0].PubKey)
+
+	// otherwise, the block fails
+	vm, service, _ = newVM(`{"validatorUpdates":"reject"}`)
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, reply))
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(t, err)
+	assert.ErrorIs(t, blk.Accept(context.Background()), errValidatorUpdatesRejected)
+
+	_, _, _, err = newTestVMWithDB(kvstore.NewApplication(), manager.NewMemDB(&version.Semantic{Major: 1}), []byte(`{"mirrorValidatorSet":true}`))
+	assert.ErrorContains(t, err, "mirrorValidatorSet requires validatorUpdates to be reject")
+}
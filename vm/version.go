@@ -0,0 +1,103 @@
+package vm
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	avalancheversion "github.com/ava-labs/avalanchego/version"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	"github.com/consideritdone/landslidecore/proxy"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	tmversion "github.com/consideritdone/landslidecore/version"
+)
+
+// BuildInfo is the version of the VM, of the Tendermint it embeds and of the
+// app it runs, so that operators can audit the versions across their nodes.
+type BuildInfo struct {
+	// Version is the semantic version of the VM.
+	Version string `json:"version"`
+	// GitCommit is the commit the node was built from, if the binary records
+	// it.
+	GitCommit string `json:"git_commit,omitempty"`
+	GoVersion string `json:"go_version"`
+
+	TendermintVersion string `json:"tendermint_version"`
+	ABCIVersion       string `json:"abci_version"`
+	P2PProtocol       uint64 `json:"p2p_protocol"`
+	BlockProtocol     uint64 `json:"block_protocol"`
+	// RPCChainVMProtocol is the version of the rpcchainvm protocol the VM
+	// serves as a plugin, which must match the one of avalanchego.
+	RPCChainVMProtocol uint `json:"rpcchainvm_protocol"`
+
+	// AppName, AppVersion and AppProtocol are the Data, Version and
+	// AppVersion of the Info response of the app. They are empty if the app
+	// can't be reached.
+	AppName     string `json:"app_name,omitempty"`
+	AppVersion  string `json:"app_version,omitempty"`
+	AppProtocol uint64 `json:"app_protocol,omitempty"`
+}
+
+func (b BuildInfo) String() string {
+	s := fmt.Sprintf("%s (tendermint %s, abci %s, block protocol %d, rpcchainvm %d", b.Version, b.TendermintVersion, b.ABCIVersion, b.BlockProtocol, b.RPCChainVMProtocol)
+	if b.AppName != "" || b.AppVersion != "" {
+		s += fmt.Sprintf(", app %s %s protocol %d", b.AppName, b.AppVersion, b.AppProtocol)
+	}
+	return s + ")"
+}
+
+// StaticBuildInfo returns the versions of the VM and of the Tendermint it
+// embeds, which don't depend on the app.
+func StaticBuildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:            Version.String(),
+		GoVersion:          runtime.Version(),
+		TendermintVersion:  tmversion.TMCoreSemVer,
+		ABCIVersion:        tmversion.ABCISemVer,
+		P2PProtocol:        tmversion.P2PProtocol,
+		BlockProtocol:      tmversion.BlockProtocol,
+		RPCChainVMProtocol: avalancheversion.RPCChainVMProtocol,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			if setting.Key == "vcs.revision" {
+				info.GitCommit = setting.Value
+			}
+		}
+	}
+	return info
+}
+
+// buildInfo returns the versions of the VM and, once it is connected, of the
+// app.
+func (vm *VM) buildInfo() BuildInfo {
+	info := StaticBuildInfo()
+	if vm.proxyApp == nil {
+		return info
+	}
+	resInfo, err := vm.abciInfo()
+	if err != nil {
+		vm.tmLogger.Debug("failed to get app version", "err", err)
+		return info
+	}
+	info.AppName = resInfo.Data
+	info.AppVersion = resInfo.Version
+	info.AppProtocol = resInfo.AppVersion
+	return info
+}
+
+// abciInfo returns the Info response of the app, from the cache if it is
+// fresh.
+func (vm *VM) abciInfo() (abci.ResponseInfo, error) {
+	cached, generation, ok := vm.abciInfoCache.get()
+	if ok {
+		return cached.Response, nil
+	}
+	resInfo, err := vm.proxyApp.Query().InfoSync(proxy.RequestInfo)
+	if err != nil {
+		return abci.ResponseInfo{}, err
+	}
+	vm.abciInfoCache.put(ctypes.ResultABCIInfo{Response: *resInfo}, generation)
+	return *resInfo, nil
+}
@@ -1,24 +1,28 @@
 package vm
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/ava-labs/avalanchego/api/metrics"
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/manager"
 	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/database/versiondb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
-	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/timer/mockable"
 	"github.com/ava-labs/avalanchego/version"
 	"github.com/ava-labs/avalanchego/vms/components/chain"
@@ -33,10 +37,9 @@ import (
 	"github.com/consideritdone/landslidecore/libs/log"
 	mempl "github.com/consideritdone/landslidecore/mempool"
 	"github.com/consideritdone/landslidecore/node"
+	tmstate "github.com/consideritdone/landslidecore/proto/tendermint/state"
 	tmproto "github.com/consideritdone/landslidecore/proto/tendermint/types"
 	"github.com/consideritdone/landslidecore/proxy"
-	rpccore "github.com/consideritdone/landslidecore/rpc/core"
-	rpcserver "github.com/consideritdone/landslidecore/rpc/jsonrpc/server"
 	sm "github.com/consideritdone/landslidecore/state"
 	"github.com/consideritdone/landslidecore/state/indexer"
 	blockidxkv "github.com/consideritdone/landslidecore/state/indexer/block/kv"
@@ -47,7 +50,9 @@ import (
 )
 
 var (
-	_ block.ChainVM = &VM{}
+	_ block.ChainVM              = &VM{}
+	_ block.HeightIndexedChainVM = &VM{}
+	_ block.BatchedChainVM       = &VM{}
 
 	Version = &version.Semantic{
 		Major: 0,
@@ -76,26 +81,42 @@ var (
 	stateDBPrefix        = []byte("state")
 	txIndexerDBPrefix    = []byte("tx_index")
 	blockIndexerDBPrefix = []byte("block_events")
+	genesisHashKey       = []byte("genesisHash")
 
+	// proposerAddress is the proposer of the genesis block, which every node
+	// builds alike.
 	proposerAddress = []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
 )
 
 var (
-	errInvalidBlock = errors.New("invalid block")
-	errNoPendingTxs = errors.New("there is no txs to include to block")
+	errInvalidBlock  = errors.New("invalid block")
+	errBlockTooLarge = errors.New("block exceeds the MaxBytes consensus param")
+	errNoPendingTxs  = errors.New("there is no txs to include to block")
 )
 
 type VM struct {
 	ctx       *snow.Context
 	dbManager manager.Manager
+	config    Config
 
-	toEngine chan<- common.Message
+	toEngine  chan<- common.Message
+	appSender common.AppSender
 
 	// *chain.State helps to implement the VM interface by wrapping blocks
 	// with an efficient caching layer.
 	*chain.State
 
-	tmLogger log.Logger
+	tmLogger  log.Logger
+	logLevels *logLevels
+
+	// versionDB holds the writes to the block store, the state store and the
+	// indexers until they are committed together, see atomically.
+	versionDB *versiondb.Database
+	// commitMtx serializes the writes to [versionDB] and their commits.
+	commitMtx sync.Mutex
+	// commitErr is the error of the last commit to the database, if it
+	// failed, reported by HealthCheck. It is guarded by [commitMtx].
+	commitErr error
 
 	blockStoreDB dbm.DB
 	blockStore   *store.BlockStore
@@ -105,12 +126,52 @@ type VM struct {
 	tmState    *sm.State
 
 	mempool mempl.Mempool
+	builder *blockBuilder
 
 	// Tendermint Application
 	app abciTypes.Application
+	// clientCreator, if set, creates the connections to the app instead of
+	// app or the proxyApp address, see WithClientCreator.
+	clientCreator proxy.ClientCreator
+	// txSender returns the sender of a tx before it is checked, see
+	// WithTxSender.
+	txSender func(tx types.Tx) string
+	// txFilters reject the txs before the app checks them, see WithTxFilters.
+	txFilters []mempl.PreCheckFunc
+	// attestationKey signs the attestations, see WithAttestationKey.
+	attestationKey *bls.SecretKey
+	// baseConfig, if set, replaces DefaultConfig, see WithConfig.
+	baseConfig *Config
+	// logger, if set, replaces the logger of the chain, see WithLogger.
+	logger log.Logger
+	// wrapDB, if set, wraps the database of the chain, see
+	// WithDatabaseWrapper.
+	wrapDB func(database.Database) database.Database
+	// eventSinks receive the events of the accepted blocks, see
+	// WithEventSinks.
+	eventSinks []EventSink
+	// sinkWorkers pass the events to the eventSinks and the sinks of the
+	// config, each in the background.
+	sinkWorkers []*eventSinkWorker
+	// webhooksAborted is closed once the webhooks must stop retrying, see
+	// stopEventSinks.
+	webhooksAborted chan struct{}
+	// eventStream publishes the events to a broker, see WithEventStream. It
+	// is nil if there is none.
+	eventStream *eventStream
 
 	// Tendermint proxy app
 	proxyApp proxy.AppConns
+	// appGuard times out the calls to the app, see guardedAppConns. It is
+	// the proxyApp.
+	appGuard *guardedAppConns
+	// remoteApp creates the connections to the out-of-process app, nil for
+	// an in-process app.
+	remoteApp *remoteClientCreator
+	// appSyncErr is the error replaying the blocks to the app once
+	// reconnected to it, see appReconnected.
+	appSyncMtx sync.Mutex
+	appSyncErr error
 
 	// EventBus is a common bus for all events going through the system.
 	eventBus *types.EventBus
@@ -120,23 +181,75 @@ type VM struct {
 	acceptedBlockDB database.Database
 
 	genesis *types.GenesisDoc
+	// SHA-256 of the genesis bytes the chain was created with.
+	genesisHash []byte
 	// cache of chunked genesis data.
 	genChunks []string
 
 	// Metrics
-	multiGatherer metrics.MultiGatherer
+	multiGatherer   metrics.MultiGatherer
+	blockGasMetrics *blockGasMetrics
+	metrics         *vmMetrics
+	mempoolMetrics  *mempl.Metrics
 
 	txIndexer      txindex.TxIndexer
 	txIndexerDB    dbm.DB
 	blockIndexer   indexer.BlockIndexer
 	blockIndexerDB dbm.DB
-	indexerService *txindex.IndexerService
 
-	clock mockable.Clock
+	// network tracks the peers and the app requests in flight.
+	network     *appNetwork
+	stateSyncer *stateSyncer
+	txFetcher   *txFetcher
+	// mempoolSaverDone is closed once the periodic saves of the mempool
+	// stopped, nil if there are none.
+	mempoolSaverDone chan struct{}
+	// closing is closed once the VM starts shutting down, which ends the
+	// requests waiting on the mempool, see requestContext.
+	closing chan struct{}
+	// shutdownOnce makes the calls to Shutdown after the first one no-ops.
+	shutdownOnce sync.Once
+
+	// upgrades is the network upgrade schedule from the upgradeBytes.
+	upgrades []Upgrade
+	// pruner prunes the blocks below the retain height of the app.
+	pruner *pruner
+
+	// processing holds the verified blocks which aren't decided yet, see
+	// parentState.
+	processing map[ids.ID]struct{}
+	// acceptor indexes the accepted blocks and publishes their events.
+	acceptor *acceptor
+	// preferred is the block the engine prefers, which the next blocks are
+	// built on.
+	preferred ids.ID
+
+	// mirroredPChainHeight is the P-Chain height the subnet validators were
+	// last mirrored at.
+	mirroredPChainHeight uint64
+
+	queryCache    *queryCache
+	abciInfoCache *abciInfoCache
+
+	// unixServer serves the rpc handlers on a Unix socket, if configured.
+	unixServer *http.Server
+	// wsServer serves event subscriptions over websocket.
+	wsServer *wsServer
+
+	// clock is the local time, which the time of the blocks is built and
+	// checked against.
+	clock *mockable.Clock
 }
 
-func NewVM(app abciTypes.Application) *VM {
-	return &VM{app: app}
+// NewVM returns a VM running [app] in process, configured with [opts]. [app]
+// may be nil if the config sets the address of an out-of-process app, or if
+// the connections to the app are created by WithClientCreator.
+func NewVM(app abciTypes.Application, opts ...Option) *VM {
+	vm := &VM{app: app, clock: &mockable.Clock{}}
+	for _, opt := range opts {
+		opt(vm)
+	}
+	return vm
 }
 
 func (vm *VM) Initialize(
@@ -151,12 +264,69 @@ func (vm *VM) Initialize(
 	appSender common.AppSender,
 ) error {
 	vm.ctx = chainCtx
-	vm.tmLogger = log.NewTMLogger(vm.ctx.Log)
+	baseLogger := vm.logger
+	if baseLogger == nil {
+		baseLogger = log.NewTMLogger(vm.ctx.Log)
+	}
+	logLevels, err := newLogLevels(baseLogger, defaultLogLevel)
+	if err != nil {
+		return err
+	}
+	vm.logLevels = logLevels
+	vm.tmLogger = newLeveledLogger(vm.logLevels)
 	vm.dbManager = dbManager
 
-	vm.toEngine = toEngine
+	baseConfig := DefaultConfig()
+	if vm.baseConfig != nil {
+		baseConfig = *vm.baseConfig
+	}
+	vm.config, err = parseConfig(baseConfig, configBytes)
+	if err != nil {
+		return err
+	}
+	if vm.config.AttestCommits && vm.attestationKey == nil {
+		return fmt.Errorf("attestCommits: %w", errNoAttestationKey)
+	}
+	if vm.config.CrossChainRequestsEnabled && vm.attestationKey == nil {
+		return fmt.Errorf("crossChainRequestsEnabled: %w", errNoAttestationKey)
+	}
+	vm.upgrades, err = parseUpgrades(upgradeBytes)
+	if err != nil {
+		return err
+	}
+	vm.queryCache = newQueryCache(vm.config.QueryCacheSize)
+	vm.abciInfoCache = newABCIInfoCache(vm.config.ABCIInfoCacheTTL.Duration)
+	blockGasRegisterer := prometheus.NewRegistry()
+	vm.blockGasMetrics, err = newBlockGasMetrics(blockGasRegisterer)
+	if err != nil {
+		return err
+	}
+	vmRegisterer := prometheus.NewRegistry()
+	vm.metrics, err = newVMMetrics(vm, vmRegisterer)
+	if err != nil {
+		return err
+	}
+	mempoolRegisterer := prometheus.NewRegistry()
+	vm.mempoolMetrics, err = newMempoolMetrics(mempoolRegisterer)
+	if err != nil {
+		return err
+	}
 
-	baseDB := dbManager.Current().Database
+	vm.toEngine = toEngine
+	vm.appSender = appSender
+	vm.processing = make(map[ids.ID]struct{})
+	vm.network = newAppNetwork()
+	vm.stateSyncer = newStateSyncer()
+	vm.txFetcher = newTxFetcher()
+	vm.closing = make(chan struct{})
+	vm.webhooksAborted = make(chan struct{})
+
+	chainDB := dbManager.Current().Database
+	if vm.wrapDB != nil {
+		chainDB = vm.wrapDB(chainDB)
+	}
+	vm.versionDB = versiondb.New(chainDB)
+	baseDB := vm.versionDB
 
 	vm.blockStoreDB = Database{prefixdb.NewNested(blockStoreDBPrefix, baseDB)}
 	vm.blockStore = store.NewBlockStore(vm.blockStoreDB)
@@ -177,22 +347,44 @@ func (vm *VM) Initialize(
 		return fmt.Errorf("failed to load tmState from genesis: %w ", err)
 	}
 	vm.tmState = &state
+	// before the app is connected to, so that it isn't initialized nor given
+	// any block
+	if err := vm.checkVoteExtensions(genesisBytes); err != nil {
+		return err
+	}
 
 	// genesis only
 	if vm.tmState.LastBlockHeight == 0 {
 		// TODO use decoded/encoded genesis bytes
-		block, partSet := vm.tmState.MakeBlock(1, []types.Tx{genesisBytes}, nil, nil, nil)
+		block, partSet := vm.tmState.MakeBlock(nextHeight(vm.tmState), []types.Tx{genesisBytes}, nil, nil, nil)
 		vm.tmLogger.Info("init block", "b", block, "part set", partSet)
 	}
 
 	//vm.genesisHash = vm.ethConfig.Genesis.ToBlock(nil).Hash() // must create genesis hash before [vm.readLastAccepted]
 
 	// Create the proxyApp and establish connections to the ABCI app (consensus, mempool, query).
-	proxyApp, err := node.CreateAndStartProxyAppConns(proxy.NewLocalClientCreator(vm.app), vm.tmLogger)
+	clientCreator, err := vm.newClientCreator()
+	if err != nil {
+		return err
+	}
+	proxyApp, err := node.CreateAndStartProxyAppConns(clientCreator, vm.tmLogger)
 	if err != nil {
 		return fmt.Errorf("failed to create and start proxy app: %w ", err)
 	}
-	vm.proxyApp = proxyApp
+	guardedApp := newGuardedAppConns(proxyApp, vm.config, vm.metrics)
+	guardedApp.queryPool, err = newQueryPool(clientCreator, vm.config, vm.tmLogger)
+	if err != nil {
+		return err
+	}
+	if vm.config.ABCICheckTxConnections > 1 && vm.txSender == nil {
+		return errNoTxSender
+	}
+	guardedApp.checkTxPool, err = newCheckTxPool(vm.newCheckTxClientCreator(clientCreator), vm.config, vm.tmLogger)
+	if err != nil {
+		return err
+	}
+	vm.proxyApp = guardedApp
+	vm.appGuard = guardedApp
 
 	// Create EventBus
 	eventBus, err := node.CreateAndStartEventBus(vm.tmLogger)
@@ -205,14 +397,28 @@ func (vm *VM) Initialize(
 	vm.txIndexer = txidxkv.NewTxIndex(vm.txIndexerDB)
 	vm.blockIndexerDB = Database{prefixdb.NewNested(blockIndexerDBPrefix, baseDB)}
 	vm.blockIndexer = blockidxkv.New(vm.blockIndexerDB)
-	vm.indexerService = txindex.NewIndexerService(vm.txIndexer, vm.blockIndexer, eventBus)
-	vm.indexerService.SetLogger(vm.tmLogger.With("module", "txindex"))
 
-	if err := vm.indexerService.Start(); err != nil {
+	if err := vm.rollbackOnStartup(); err != nil {
 		return err
 	}
 
-	if err := vm.doHandshake(vm.genesis, vm.tmLogger.With("module", "consensus")); err != nil {
+	journaledBlock, err := vm.recoverJournaledBlock()
+	if err != nil {
+		return fmt.Errorf("failed to recover journaled block: %w", err)
+	}
+
+	// the genesis and the blocks replayed to the app are committed once the
+	// app is in sync with the state
+	err = vm.atomically(func() error {
+		if err := vm.doHandshake(vm.genesis, vm.tmLogger.With("module", "consensus")); err != nil {
+			return err
+		}
+		if err := vm.signalAppliedUpgrades(); err != nil {
+			return fmt.Errorf("failed to signal applied upgrades: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
@@ -222,21 +428,66 @@ func (vm *VM) Initialize(
 	}
 	vm.tmState = &state
 
-	genesisBlock, err := vm.buildGenesisBlock(genesisBytes)
+	vm.builder = newBlockBuilder(vm, vm.config)
+	vm.pruner = newPruner()
+	go vm.pruneInBackground()
+	if err := vm.startEventSinks(); err != nil {
+		return err
+	}
+	vm.acceptor = newAcceptor(vm, vm.config.AcceptQueueSize)
+	if err := vm.indexMissedBlocks(); err != nil {
+		return fmt.Errorf("failed to index missed blocks: %w", err)
+	}
+	if vm.eventStream != nil {
+		go vm.streamEvents()
+	}
+	vm.mempool = vm.createMempool()
+
+	if journaledBlock != nil {
+		if err := vm.redeliverBlock(journaledBlock); err != nil {
+			return err
+		}
+	}
+
+	lastAcceptedBlock, err := vm.buildGenesisBlock(genesisBytes)
 	if err != nil {
 		return fmt.Errorf("failed to build genesis block: %w ", err)
 	}
-
-	vm.mempool = vm.createMempool()
+	if lastAcceptedBlock == nil {
+		lastAcceptedBlock = vm.blockStore.LoadBlock(vm.blockStore.Height())
+	}
 
 	if err := vm.initializeMetrics(); err != nil {
 		return err
 	}
+	if err := vm.multiGatherer.Register(blockGasMetricsPrefix, blockGasRegisterer); err != nil {
+		return err
+	}
+	if err := vm.multiGatherer.Register(vmMetricsPrefix, vmRegisterer); err != nil {
+		return err
+	}
+	if err := vm.multiGatherer.Register(mempoolMetricsPrefix, mempoolRegisterer); err != nil {
+		return err
+	}
 
-	if err := vm.initChainState(genesisBlock); err != nil {
+	if err := vm.initChainState(lastAcceptedBlock); err != nil {
 		return err
 	}
 
+	if err := vm.restoreMempool(); err != nil {
+		return fmt.Errorf("failed to restore mempool: %w", err)
+	}
+
+	if vm.appSender != nil && vm.config.TxGossipInterval.Duration > 0 {
+		go vm.regossipTxs(vm.config.TxGossipInterval.Duration)
+	}
+	if vm.config.PersistMempool && vm.config.PersistMempoolInterval.Duration > 0 {
+		vm.mempoolSaverDone = make(chan struct{})
+		go vm.saveMempoolPeriodically(vm.config.PersistMempoolInterval.Duration, vm.mempoolSaverDone)
+	}
+	// schedules the first empty block, if enabled
+	vm.builder.signal()
+
 	return nil
 }
 
@@ -249,9 +500,9 @@ func (vm *VM) buildGenesisBlock(genesisData []byte) (*types.Block, error) {
 	if len(txs) == 0 {
 		return nil, errNoPendingTxs
 	}
-	height := vm.tmState.LastBlockHeight + 1
+	height := nextHeight(vm.tmState)
 
-	commit := makeCommitMock(height, time.Now())
+	commit := nextCommit(vm.tmState, height)
 	genesisBlock, _ := vm.tmState.MakeBlock(height, txs, commit, nil, proposerAddress)
 	return genesisBlock, nil
 }
@@ -264,7 +515,7 @@ func (vm *VM) initGenesis(genesisData []byte) error {
 	if err != nil {
 		if err == node.ErrNoGenesisDoc {
 			// get it from json
-			genesis, err = types.GenesisDocFromJSON(genesisData)
+			genesis, err = vm.decodeGenesis(genesisData)
 			if err != nil {
 				return fmt.Errorf("failed to decode genesis bytes: %w ", err)
 			}
@@ -276,9 +527,77 @@ func (vm *VM) initGenesis(genesisData []byte) error {
 		} else {
 			return err
 		}
+	} else if vm.config.ChainID != "" && vm.config.ChainID != genesis.ChainID {
+		return fmt.Errorf("chainId %q of the config doesn't match the chain_id %q the chain was created with",
+			vm.config.ChainID, genesis.ChainID)
 	}
 
 	vm.genesis = genesis
+	return vm.verifyGenesisHash(genesisData)
+}
+
+// decodeGenesis decodes the genesis doc from [genesisData]. Its chain_id is,
+// in order of precedence, the chainId of the config, the chain_id of the
+// genesis or one derived from the Avalanche chain ID.
+func (vm *VM) decodeGenesis(genesisData []byte) (*types.GenesisDoc, error) {
+	return parseGenesis(genesisData, vm.config.ChainID, defaultChainID(vm.ctx.ChainID))
+}
+
+// parseGenesis decodes and validates the genesis doc from [genesisData],
+// setting its chain_id to [chainID] if it isn't empty, or to
+// [fallbackChainID] if the genesis has none.
+func parseGenesis(genesisData []byte, chainID, fallbackChainID string) (*types.GenesisDoc, error) {
+	if err := checkGenesisJSON(genesisData); err != nil {
+		return nil, err
+	}
+	genesisData, err := fromSDKGenesis(genesisData)
+	if err != nil {
+		return nil, err
+	}
+	genesis := new(types.GenesisDoc)
+	if err := tmjson.Unmarshal(genesisData, genesis); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidGenesis, err)
+	}
+	switch {
+	case chainID != "":
+		genesis.ChainID = chainID
+	case genesis.ChainID == "":
+		genesis.ChainID = fallbackChainID
+	}
+	if err := validateGenesis(genesis); err != nil {
+		return nil, err
+	}
+	if err := genesis.ValidateAndComplete(); err != nil {
+		return nil, err
+	}
+	return genesis, nil
+}
+
+// defaultChainID derives the Tendermint chain_id from the Avalanche
+// [chainID], which is too long to be used as is.
+func defaultChainID(chainID ids.ID) string {
+	return fmt.Sprintf("%s-%x", Name, chainID[:8])
+}
+
+// verifyGenesisHash checks the SHA-256 of [genesisData] against the one
+// persisted when the chain was created, so that the node doesn't start on the
+// database of another chain. The hash is persisted on the first start, or the
+// first start since it is checked.
+func (vm *VM) verifyGenesisHash(genesisData []byte) error {
+	hash := sha256.Sum256(genesisData)
+	vm.genesisHash = hash[:]
+
+	persistedHash, err := vm.stateDB.Get(genesisHashKey)
+	if err != nil {
+		return err
+	}
+	if persistedHash == nil {
+		return vm.stateDB.SetSync(genesisHashKey, vm.genesisHash)
+	}
+	if !bytes.Equal(persistedHash, vm.genesisHash) {
+		return fmt.Errorf("genesis hash %X doesn't match the hash %X of the genesis the chain was created with",
+			vm.genesisHash, persistedHash)
+	}
 	return nil
 }
 
@@ -309,14 +628,48 @@ func (vm *VM) initGenesisChunks() error {
 
 func (vm *VM) createMempool() *mempl.CListMempool {
 	cfg := config.DefaultMempoolConfig()
+	cfg.Recheck = vm.config.MempoolRecheck != mempoolRecheckOff
+	cfg.RecheckBatchSize = vm.config.MempoolRecheckBatchSize
+	cfg.Size = vm.config.MempoolSize
+	cfg.MaxTxsBytes = vm.config.MempoolMaxTxsBytes
+	cfg.MaxTxBytes = vm.config.MempoolMaxTxBytes
+	cfg.CacheSize = vm.config.MempoolCacheSize
+	cfg.CacheTTL = vm.config.MempoolCacheTTL.Duration
+	cfg.TTLNumBlocks = vm.config.MempoolTTLNumBlocks
+	cfg.TTLDuration = vm.config.MempoolTTLDuration.Duration
+	cfg.OriginRate = vm.config.MempoolOriginRate
+	cfg.OriginBurst = vm.config.MempoolOriginBurst
+	cfg.OriginMaxTxs = vm.config.MempoolOriginMaxTxs
+	options := []mempl.CListMempoolOption{
+		mempl.WithMetrics(vm.mempoolMetrics),
+		mempl.WithPreCheck(vm.txPreCheck(*vm.tmState)),
+		mempl.WithPostCheck(TxPostCheck(*vm.tmState)),
+		mempl.WithExpiredCallback(vm.txExpired),
+		mempl.WithEvictedCallback(vm.txEvicted),
+		mempl.WithReapStrategy(reapStrategies[vm.config.ReapStrategy]),
+		mempl.WithTxSender(vm.txSender),
+	}
+	if guardedApp, ok := vm.proxyApp.(*guardedAppConns); ok {
+		options = append(options, mempl.WithCheckTxConns(guardedApp.CheckTxConns()...))
+	}
+	if len(vm.config.MempoolLanes) > 0 {
+		lanes := make([]mempl.Lane, len(vm.config.MempoolLanes))
+		for i, lane := range vm.config.MempoolLanes {
+			lanes[i] = mempl.Lane{
+				Name:        lane.Name,
+				Size:        lane.Size,
+				MaxTxsBytes: lane.MaxTxsBytes,
+				BlockShare:  lane.BlockShare,
+			}
+		}
+		options = append(options, mempl.WithLanes(lanes...))
+	}
 	mempool := mempl.NewCListMempool(
 		cfg,
 		vm.proxyApp.Mempool(),
 		vm.tmState.LastBlockHeight,
 		vm,
-		mempl.WithMetrics(mempl.NopMetrics()), // TODO: use prometheus metrics based on config
-		mempl.WithPreCheck(sm.TxPreCheck(*vm.tmState)),
-		mempl.WithPostCheck(sm.TxPostCheck(*vm.tmState)),
+		options...,
 	)
 	mempoolLogger := vm.tmLogger.With("module", "mempool")
 	mempool.SetLogger(mempoolLogger)
@@ -324,17 +677,6 @@ func (vm *VM) createMempool() *mempl.CListMempool {
 	return mempool
 }
 
-// NotifyBlockReady tells the consensus engine that a new block
-// is ready to be created
-func (vm *VM) NotifyBlockReady() {
-	select {
-	case vm.toEngine <- common.PendingTxs:
-		vm.tmLogger.Debug("Notify consensys engine")
-	default:
-		vm.tmLogger.Error("Failed to push PendingTxs notification to the consensus engine.")
-	}
-}
-
 func (vm *VM) doHandshake(genesis *types.GenesisDoc, consensusLogger log.Logger) error {
 	handshaker := cs.NewHandshaker(vm.stateStore, *vm.tmState, vm.blockStore, genesis)
 	handshaker.SetLogger(consensusLogger)
@@ -345,6 +687,24 @@ func (vm *VM) doHandshake(genesis *types.GenesisDoc, consensusLogger log.Logger)
 	return nil
 }
 
+// checkVoteExtensions fails, with FailOnVoteExtensions, if the genesis
+// enables the vote extensions of ABCI 2.0 for the app, which the handshake
+// brought to the height of the state.
+func (vm *VM) checkVoteExtensions(genesisData []byte) error {
+	if !vm.config.FailOnVoteExtensions {
+		return nil
+	}
+	enableHeight, err := voteExtensionsEnableHeight(genesisData)
+	if err != nil {
+		return err
+	}
+	if enableHeight > 0 {
+		return fmt.Errorf("the app, at height %d, requires vote extensions from height %d, "+
+			"which the VM can't provide, see failOnVoteExtensions", vm.tmState.LastBlockHeight, enableHeight)
+	}
+	return nil
+}
+
 // readLastAccepted reads the last accepted hash from [acceptedBlockDB] and returns the
 // last accepted block hash and height by reading directly from [vm.chaindb] instead of relying
 // on [chain].
@@ -379,14 +739,15 @@ func (vm *VM) initChainState(lastAcceptedBlock *types.Block) error {
 	block.status = choices.Accepted
 
 	config := &chain.Config{
-		DecidedCacheSize:    decidedCacheSize,
-		MissingCacheSize:    missingCacheSize,
-		UnverifiedCacheSize: unverifiedCacheSize,
-		//GetBlockIDAtHeight:  vm.GetBlockIDAtHeight,
-		GetBlock:          vm.getBlock,
-		UnmarshalBlock:    vm.parseBlock,
-		BuildBlock:        vm.buildBlock,
-		LastAcceptedBlock: block,
+		DecidedCacheSize:      decidedCacheSize,
+		MissingCacheSize:      missingCacheSize,
+		UnverifiedCacheSize:   unverifiedCacheSize,
+		GetBlockIDAtHeight:    vm.GetBlockIDAtHeight,
+		GetBlock:              vm.getBlock,
+		UnmarshalBlock:        vm.parseBlock,
+		BatchedUnmarshalBlock: vm.batchedParseBlock,
+		BuildBlock:            vm.buildBlock,
+		LastAcceptedBlock:     block,
 	}
 
 	// Register chain state metrics
@@ -410,17 +771,30 @@ func (vm *VM) initializeMetrics() error {
 	return nil
 }
 
-// parseBlock parses [b] into a block to be wrapped by ChainState.
-func (vm *VM) parseBlock(_ context.Context, b []byte) (snowman.Block, error) {
-	protoBlock := new(tmproto.Block)
-	err := protoBlock.Unmarshal(b)
-	if err != nil {
-		return nil, err
+// parseBlock parses [b] into a block to be wrapped by ChainState. The bytes
+// come from the network, so they are checked before being decoded: no block
+// is larger than MaxBlockSizeBytes, and the block must be valid on its own,
+// with the hashes of its header matching its content, and encoded as the VM
+// encodes it, so that no two encodings have the same ID.
+func (vm *VM) parseBlock(_ context.Context, b []byte) (blk snowman.Block, err error) {
+	if len(b) > types.MaxBlockSizeBytes {
+		return nil, fmt.Errorf("%w: %d bytes, more than the maximum of %d",
+			errInvalidBlock, len(b), types.MaxBlockSizeBytes)
 	}
+	defer func() {
+		// decoding malformed blocks mustn't crash the node
+		if r := recover(); r != nil {
+			blk, err = nil, fmt.Errorf("%w: %v", errInvalidBlock, r)
+		}
+	}()
 
+	protoBlock := new(tmproto.Block)
+	if err := protoBlock.Unmarshal(b); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidBlock, err)
+	}
 	tmBlock, err := types.BlockFromProto(protoBlock)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", errInvalidBlock, err)
 	}
 
 	// Note: the status of block is set by ChainState
@@ -428,16 +802,16 @@ func (vm *VM) parseBlock(_ context.Context, b []byte) (snowman.Block, error) {
 	if err != nil {
 		return nil, err
 	}
-
+	if !bytes.Equal(block.Bytes(), b) {
+		return nil, fmt.Errorf("%w: not canonically encoded", errInvalidBlock)
+	}
 	return block, nil
 }
 
 // getBlock attempts to retrieve block [id] from the VM to be wrapped
 // by ChainState.
 func (vm *VM) getBlock(_ context.Context, id ids.ID) (snowman.Block, error) {
-	var hash []byte
-	copy(hash, id[:])
-	tmBlock := vm.blockStore.LoadBlockByHash(hash)
+	tmBlock := vm.blockStore.LoadBlockByHash(id[:])
 	// If [tmBlock] is nil, return [database.ErrNotFound] here
 	// so that the miss is considered cacheable.
 	if tmBlock == nil {
@@ -447,6 +821,26 @@ func (vm *VM) getBlock(_ context.Context, id ids.ID) (snowman.Block, error) {
 	return vm.newBlock(tmBlock)
 }
 
+// VerifyHeightIndex always succeeds: the height index is the one of the block
+// store, which is written as blocks are accepted.
+func (vm *VM) VerifyHeightIndex(context.Context) error {
+	return nil
+}
+
+// GetBlockIDAtHeight returns the ID of the accepted block at [height], read
+// from the block store metadata. Unlike a separate index, it stays consistent
+// with rollbacks, pruning and state sync. It returns database.ErrNotFound for
+// heights the block store doesn't have.
+func (vm *VM) GetBlockIDAtHeight(_ context.Context, height uint64) (ids.ID, error) {
+	blockMeta := vm.blockStore.LoadBlockMeta(int64(height))
+	if blockMeta == nil {
+		return ids.Empty, database.ErrNotFound
+	}
+	var id ids.ID
+	copy(id[:], blockMeta.BlockID.Hash)
+	return id, nil
+}
+
 func (vm *VM) applyBlock(block *Block) error {
 	vm.mempool.Lock()
 	defer vm.mempool.Unlock()
@@ -459,29 +853,31 @@ func (vm *VM) applyBlock(block *Block) error {
 	if err := validateBlock(state, block.tmBlock); err != nil {
 		return err
 	}
+	// The block and its ABCI responses are persisted before the app commits
+	// it, as Tendermint does, so that a crash before the state is saved is
+	// recovered from on startup, see recoverJournaledBlock.
+	var abciResponses *tmstate.ABCIResponses
+	err = vm.atomically(func() error {
+		var err error
+		abciResponses, err = vm.executeBlock(block, state.InitialHeight)
+		if err != nil {
+			return err
+		}
 
-	abciResponses, err := execBlockOnProxyApp(
-		vm.tmLogger,
-		vm.proxyApp.Consensus(),
-		block.tmBlock, vm.stateStore,
-		state.InitialHeight,
-	)
-	if err != nil {
-		return err
-	}
-
-	// Save the results before we commit.
-	if err := vm.stateStore.SaveABCIResponses(block.tmBlock.Height, abciResponses); err != nil {
-		return err
-	}
-
-	blockID := types.BlockID{
-		Hash:          block.tmBlock.Hash(),
-		PartSetHeader: block.tmBlock.MakePartSet(types.BlockPartSizeBytes).Header(),
-	}
+		if err := vm.checkValidatorUpdates(block.tmBlock.Height, abciResponses); err != nil {
+			return err
+		}
 
-	// Update the state with the block and responses.
-	state, err = updateState(state, blockID, &block.tmBlock.Header, abciResponses)
+		// Save the results before we commit.
+		if err := vm.stateStore.SaveABCIResponses(block.tmBlock.Height, abciResponses); err != nil {
+			return err
+		}
+		if err := vm.storeWarpMessages(abciResponses); err != nil {
+			return err
+		}
+		vm.blockStore.SaveBlock(block.tmBlock, block.tmBlock.MakePartSet(types.BlockPartSizeBytes), block.tmBlock.LastCommit)
+		return nil
+	})
 	if err != nil {
 		return err
 	}
@@ -494,12 +890,15 @@ func (vm *VM) applyBlock(block *Block) error {
 	}
 
 	// Commit block, get hash back
+	commitStart := time.Now()
 	res, err := vm.proxyApp.Consensus().CommitSync()
 	if err != nil {
 		vm.tmLogger.Error("client error during proxyAppConn.CommitSync", "err", err)
 		return err
 	}
 
+	vm.builder.executed(len(block.tmBlock.Txs), block.executionTime+time.Since(commitStart))
+
 	// ResponseCommit has no error code - just data
 	vm.tmLogger.Info(
 		"committed state",
@@ -508,42 +907,239 @@ func (vm *VM) applyBlock(block *Block) error {
 		"app_hash", fmt.Sprintf("%X", res.Data),
 	)
 
-	deliverTxResponses := make([]*abciTypes.ResponseDeliverTx, len(block.tmBlock.Txs))
-	for i := range block.tmBlock.Txs {
-		deliverTxResponses[i] = &abciTypes.ResponseDeliverTx{Code: abciTypes.CodeTypeOK}
+	state, err = vm.saveBlockState(state, block, abciResponses, res.Data)
+	if err != nil {
+		return err
 	}
 
-	// Update mempool.
+	// Update mempool. The txs which failed in DeliverTx leave its cache, so
+	// that they may be submitted again, and the remaining txs are rechecked,
+	// once the recheck after the previous block is done.
+	if waiter, ok := vm.mempool.(recheckWaiter); ok && vm.config.MempoolRecheck == mempoolRecheckAsync {
+		<-waiter.RecheckDone()
+	}
 	if err := vm.mempool.Update(
 		block.tmBlock.Height,
 		block.tmBlock.Txs,
-		deliverTxResponses,
-		TxPreCheck(state),
+		abciResponses.DeliverTxs,
+		vm.txPreCheck(state),
 		TxPostCheck(state),
 	); err != nil {
 		return err
 	}
+	if vm.config.MempoolRecheck == mempoolRecheckSync {
+		// the responses to the recheck are handled once flushed
+		if err := vm.mempool.FlushAppConn(); err != nil {
+			return err
+		}
+	}
+
+	vm.blockGasMetrics.observe(sm.BlockMaxGas(vm.stateStore, block.tmBlock.Height), abciResponses)
+	block.skippedTxs = skippedTxs(block.tmBlock.Txs, abciResponses)
+	vm.metrics.blocksAccepted.Inc()
+	vm.metrics.txsAccepted.Add(float64(len(block.tmBlock.Txs)))
+	vm.queryCache.accepted(block.tmBlock.Height)
+	vm.abciInfoCache.invalidate()
+
+	vm.acceptor.accept(acceptedBlock{
+		block:         block.tmBlock,
+		abciResponses: abciResponses,
+		retainHeight:  res.RetainHeight,
+	})
+
+	vm.builder.accepted()
+	return nil
+}
+
+// saveBlockState updates [state] with [block], its [abciResponses] and the
+// [appHash] the app committed it with, and persists the new state.
+func (vm *VM) saveBlockState(
+	state sm.State,
+	block *Block,
+	abciResponses *tmstate.ABCIResponses,
+	appHash []byte,
+) (sm.State, error) {
+	blockID := types.BlockID{
+		Hash:          block.tmBlock.Hash(),
+		PartSetHeader: block.tmBlock.MakePartSet(types.BlockPartSizeBytes).Header(),
+	}
+
+	// Update the state with the block and responses.
+	state, err := updateState(state, blockID, &block.tmBlock.Header, abciResponses)
+	if err != nil {
+		return state, err
+	}
+	if err := vm.mirrorValidators(context.Background(), &state, block); err != nil {
+		return state, err
+	}
+	// the app hash is checked against the app on startup, and the next block
+	// carries it
+	state.AppHash = appHash
+
+	err = vm.atomically(func() error {
+		if err := vm.stateDB.Set(appHashKey(block.tmBlock.Height), appHash); err != nil {
+			return err
+		}
+		return vm.stateStore.Save(state)
+	})
+	if err != nil {
+		return state, err
+	}
 
 	vm.tmState.LastBlockHeight = block.tmBlock.Height
-	if err := vm.stateStore.Save(state); err != nil {
-		return err
+	// blocks are built and verified on top of the last block, after its time,
+	// and with the params and validators set by the app
+	vm.tmState.LastBlockID = state.LastBlockID
+	vm.tmState.LastBlockTime = state.LastBlockTime
+	vm.tmState.Version = state.Version
+	vm.tmState.ConsensusParams = state.ConsensusParams
+	vm.tmState.LastHeightConsensusParamsChanged = state.LastHeightConsensusParamsChanged
+	vm.tmState.Validators = state.Validators
+	vm.tmState.NextValidators = state.NextValidators
+	vm.tmState.LastValidators = state.LastValidators
+	vm.tmState.LastHeightValidatorsChanged = state.LastHeightValidatorsChanged
+	vm.tmState.AppHash = state.AppHash
+	vm.tmState.LastResultsHash = state.LastResultsHash
+	return state, nil
+}
+
+// pruneBlocks removes blocks and states below [retainHeight], along with their
+// txs and events in the tx and block indexers. It is called by the Prune admin
+// method and by the pruner, one at a time.
+func (vm *VM) pruneBlocks(retainHeight int64) (uint64, error) {
+	var pruned uint64
+	err := vm.atomically(func() error {
+		base := vm.blockStore.Base()
+		if retainHeight <= base {
+			return nil
+		}
+		if height := vm.blockStore.Height(); retainHeight > height {
+			return fmt.Errorf("cannot prune beyond the latest height %d", height)
+		}
+		// the blocks not published on the event stream yet are kept
+		if vm.eventStream != nil {
+			streamed, err := vm.streamedHeight()
+			if err != nil {
+				return err
+			}
+			if retainHeight > streamed+1 {
+				retainHeight = streamed + 1
+			}
+			if retainHeight <= base {
+				return nil
+			}
+		}
+		// nor are the blocks the event sinks missed
+		for _, w := range vm.sinkWorkers {
+			passed, ok, err := w.passedHeight()
+			if err != nil {
+				return err
+			}
+			if ok && retainHeight > passed+1 {
+				retainHeight = passed + 1
+			}
+		}
+		if retainHeight <= base {
+			return nil
+		}
+		// the indexed events are looked up in the ABCI responses, which are
+		// pruned with the states
+		for height := base; height < retainHeight; height++ {
+			if err := vm.deleteIndexedBlock(height); err != nil {
+				return fmt.Errorf("failed to prune indexers: %w", err)
+			}
+			if err := vm.stateDB.Delete(appHashKey(height)); err != nil {
+				return err
+			}
+		}
+		var err error
+		pruned, err = vm.blockStore.PruneBlocks(retainHeight)
+		if err != nil {
+			return fmt.Errorf("failed to prune block store: %w", err)
+		}
+		if err := vm.stateStore.PruneStates(base, retainHeight); err != nil {
+			return fmt.Errorf("failed to prune state database: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
-	vm.blockStore.SaveBlock(block.tmBlock, block.tmBlock.MakePartSet(types.BlockPartSizeBytes), block.tmBlock.LastCommit)
+	vm.queryCache.flush()
+	return pruned, nil
+}
 
-	fireEvents(vm.tmLogger, vm.eventBus, block.tmBlock, abciResponses)
+// compactDB compacts the whole underlying database, reclaiming the space
+// freed by pruning.
+func (vm *VM) compactDB() error {
+	if err := vm.dbManager.Current().Database.Compact(nil, nil); err != nil {
+		return fmt.Errorf("failed to compact database: %w", err)
+	}
 	return nil
 }
 
-// buildBlock builds a block to be wrapped by ChainState
-func (vm *VM) buildBlock(_ context.Context) (snowman.Block, error) {
-	txs := vm.mempool.ReapMaxBytesMaxGas(-1, -1)
-	if len(txs) == 0 {
+// contextReaper is implemented by mempools which can stop reaping once a
+// context is done, such as the CListMempool.
+type contextReaper interface {
+	ReapMaxBytesMaxGasContext(ctx context.Context, maxBytes, maxGas int64) types.Txs
+}
+
+// reapTxs reaps the txs of a block with [params] from the mempool. Once [ctx]
+// is done, it returns the txs reaped so far, or an error if the mempool is
+// still locked, e.g. by the block being accepted, so that the engine isn't
+// held past its deadline.
+func (vm *VM) reapTxs(ctx context.Context, params tmproto.ConsensusParams) (types.Txs, error) {
+	// the gas of a tx is the one the app wanted in CheckTx
+	maxBytes, maxGas := maxBlockDataBytes(params), params.Block.MaxGas
+	reaper, ok := vm.mempool.(contextReaper)
+	if !ok || ctx.Done() == nil {
+		return vm.mempool.ReapMaxBytesMaxGas(maxBytes, maxGas), nil
+	}
+
+	txsCh := make(chan types.Txs, 1)
+	go func() {
+		txsCh <- reaper.ReapMaxBytesMaxGasContext(ctx, maxBytes, maxGas)
+	}()
+	select {
+	case txs := <-txsCh:
+		if ctx.Err() != nil {
+			vm.tmLogger.Info("block building deadline reached, building a partial block", "num_txs", len(txs))
+		}
+		return txs, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to reap txs: %w", ctx.Err())
+	}
+}
+
+// buildBlock builds a block to be wrapped by ChainState. It gives up once
+// [ctx] is done, see reapTxs.
+func (vm *VM) buildBlock(ctx context.Context) (snowman.Block, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// blocks are built on the preferred block, once it is accepted
+	state, err := vm.parentState(vm.preferred)
+	if err != nil {
+		return nil, err
+	}
+	txs, err := vm.reapTxs(ctx, state.ConsensusParams)
+	if err != nil {
+		return nil, err
+	}
+	// the app is falling behind, see blockBuilder.executed
+	if limit := vm.builder.txLimit(); limit > 0 && len(txs) > limit {
+		txs = txs[:limit]
+	}
+	if len(txs) == 0 && !vm.builder.emptyBlockDue() {
 		return nil, errNoPendingTxs
 	}
-	height := vm.tmState.LastBlockHeight + 1
+	height := nextHeight(state)
 
-	commit := makeCommitMock(height, time.Now())
-	block, _ := vm.tmState.MakeBlock(height, txs, commit, nil, proposerAddress)
+	commit := nextCommit(state, height)
+	block, _ := state.MakeBlock(height, txs, commit, nil, proposerAddressOf(vm.ctx.NodeID))
+	if height != state.InitialHeight {
+		block.Time = vm.nextBlockTime(state)
+	}
 
 	// Note: the status of block is set by ChainState
 	blk, err := vm.newBlock(block)
@@ -552,135 +1148,226 @@ func (vm *VM) buildBlock(_ context.Context) (snowman.Block, error) {
 		return nil, err
 	}
 	vm.tmLogger.Debug(fmt.Sprintf("Built block %s", blk.ID()))
+	vm.builder.built()
+	vm.metrics.blocksBuilt.Inc()
 
 	return blk, nil
 }
 
-func (vm *VM) AppGossip(_ context.Context, nodeID ids.NodeID, msg []byte) error {
-	return nil
-}
-
 func (vm *VM) SetState(ctx context.Context, state snow.State) error {
+	vm.tmLogger.With("module", "sync").Info("chain state changed", "state", state)
+	if state == snow.Bootstrapping {
+		// a failed state sync must not leave the chain bootstrapping from
+		// a partially restored app
+		return vm.stateSyncErr()
+	}
 	return nil
 }
 
+// Shutdown stops the VM in the order which leaves the databases consistent
+// for the next start: no requests come in, the blocks in flight are
+// executed, indexed and their events published, and the app connections and
+// stores are closed last. Only the first call shuts the VM down.
 func (vm *VM) Shutdown(ctx context.Context) error {
-	// first stop the non-reactor services
+	var err error
+	vm.shutdownOnce.Do(func() {
+		err = vm.shutdown(ctx)
+	})
+	return err
+}
+
+// shutdown runs every step of Shutdown, even once one failed, so that the
+// stores are closed whatever happens, and returns the errors of all of them.
+func (vm *VM) shutdown(ctx context.Context) error {
+	var errs []error
+	close(vm.closing)
+	if err := vm.closeUnixSocket(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("Error closing rpc unix socket: %w ", err))
+	}
+	close(vm.txFetcher.quit)
+	vm.builder.stop()
+	vm.acceptor.stop()
+	if err := vm.stopEventSinks(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if vm.eventStream != nil {
+		vm.eventStream.stop()
+	}
+	vm.pruner.stop()
+
+	// waits for the CheckTx calls in flight
+	vm.mempool.Lock()
+	err := vm.mempool.FlushAppConn()
+	vm.mempool.Unlock()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("Error flushing mempool connection: %w ", err))
+	}
+	if vm.config.PersistMempool {
+		if vm.mempoolSaverDone != nil {
+			<-vm.mempoolSaverDone
+		}
+		if err := vm.saveMempool(); err != nil {
+			errs = append(errs, fmt.Errorf("Error saving mempool: %w ", err))
+		}
+	}
+
 	if err := vm.eventBus.Stop(); err != nil {
-		return fmt.Errorf("Error closing eventBus: %w ", err)
-	}
-	if err := vm.indexerService.Stop(); err != nil {
-		return fmt.Errorf("Error closing indexerService: %w ", err)
-	}
-	//TODO: investigate wal configuration
-	// stop mempool WAL
-	//if vm.config.Mempool.WalEnabled() {
-	//	n.mempool.CloseWAL()
-	//}
-	//if n.prometheusSrv != nil {
-	//	if err := n.prometheusSrv.Shutdown(context.Background()); err != nil {
-	//		// Error from closing listeners, or context timeout:
-	//		n.Logger.Error("Prometheus HTTP server Shutdown", "err", err)
-	//	}
-	//}
+		errs = append(errs, fmt.Errorf("Error closing eventBus: %w ", err))
+	}
+	if err := vm.proxyApp.Stop(); err != nil {
+		errs = append(errs, fmt.Errorf("Error closing proxyApp: %w ", err))
+	}
 	if err := vm.blockStore.Close(); err != nil {
-		return fmt.Errorf("Error closing blockStore: %w ", err)
+		errs = append(errs, fmt.Errorf("Error closing blockStore: %w ", err))
 	}
 	if err := vm.stateStore.Close(); err != nil {
-		return fmt.Errorf("Error closing stateStore: %w ", err)
+		errs = append(errs, fmt.Errorf("Error closing stateStore: %w ", err))
 	}
-	return nil
-	//timestampVM and deprecated landslide
-	//if vm.state == nil {
-	//	return nil
-	//}
-	//
-	//return vm.state.Close() // close versionDB
-
-	//coreth
-	//if vm.ctx == nil {
-	//	return nil
-	//}
-	//vm.Network.Shutdown()
-	//if err := vm.StateSyncClient.Shutdown(); err != nil {
-	//	log.Error("error stopping state syncer", "err", err)
-	//}
-	//close(vm.shutdownChan)
-	//vm.eth.Stop()
-	//vm.shutdownWg.Wait()
-	//return nil
+	if err := vm.txIndexerDB.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("Error closing txIndexer: %w ", err))
+	}
+	if err := vm.blockIndexerDB.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("Error closing blockIndexer: %w ", err))
+	}
+	// the database itself belongs to avalanchego
+	if err := vm.versionDB.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("Error closing versionDB: %w ", err))
+	}
+	return errors.Join(errs...)
 }
 
+// Version returns the versions of the VM, of the Tendermint it embeds and of
+// the app, see BuildInfo.
 func (vm *VM) Version(ctx context.Context) (string, error) {
-	return Version.String(), nil
+	return vm.buildInfo().String(), nil
 }
 
+// CreateStaticHandlers returns the handlers which don't depend on a chain, see
+// StaticService.
 func (vm *VM) CreateStaticHandlers(ctx context.Context) (map[string]*common.HTTPHandler, error) {
-	//TODO implement me
-	return nil, nil
+	server := rpc.NewServer()
+	server.RegisterCodec(newCodec(), "application/json")
+	server.RegisterCodec(newCodec(), "application/json;charset=UTF-8")
+	if err := server.RegisterService(NewStaticService(), Name); err != nil {
+		return nil, err
+	}
+	return map[string]*common.HTTPHandler{
+		"/static": {
+			LockOptions: common.NoLock,
+			Handler:     server,
+		},
+	}, nil
 }
 
 func (vm *VM) CreateHandlers(_ context.Context) (map[string]*common.HTTPHandler, error) {
-	mux := http.NewServeMux()
-	rpcLogger := vm.tmLogger.With("module", "rpc-server")
-	rpcserver.RegisterRPCFuncs(mux, rpccore.Routes, rpcLogger)
+	rpcLogger := vm.tmLogger.With("module", "rpc")
 
 	server := rpc.NewServer()
-	server.RegisterCodec(json.NewCodec(), "application/json")
-	server.RegisterCodec(json.NewCodec(), "application/json;charset=UTF-8")
+	server.RegisterCodec(newCodec(), "application/json")
+	server.RegisterCodec(newCodec(), "application/json;charset=UTF-8")
 	if err := server.RegisterService(NewService(vm), Name); err != nil {
 		return nil, err
 	}
 
-	return map[string]*common.HTTPHandler{
+	adminServer := rpc.NewServer()
+	adminServer.RegisterCodec(newCodec(), "application/json")
+	adminServer.RegisterCodec(newCodec(), "application/json;charset=UTF-8")
+	if err := adminServer.RegisterService(NewAdminService(vm), "admin"); err != nil {
+		return nil, err
+	}
+
+	// the websocket server is created once, its metrics can only be
+	// registered once
+	if vm.wsServer == nil {
+		wsRegisterer := prometheus.NewRegistry()
+		wsServer, err := newWSServer(vm.eventBus, vm.config, rpcLogger.With("protocol", "websocket"), wsRegisterer)
+		if err != nil {
+			return nil, err
+		}
+		if err := vm.multiGatherer.Register(wsMetricsPrefix, wsRegisterer); err != nil {
+			return nil, err
+		}
+		vm.wsServer = wsServer
+	}
+
+	handlers := map[string]*common.HTTPHandler{
 		"/rpc": {
 			LockOptions: common.WriteLock,
-			Handler:     server,
+			Handler:     newGzipHandler(server, vm.config.MaxRequestBodyBytes),
 		},
-	}, nil
+		// websocket connections are long-lived and must not hold the chain
+		// lock, the event bus is safe for concurrent use
+		"/websocket": {
+			LockOptions: common.NoLock,
+			Handler:     vm.wsServer,
+		},
+	}
+	for route, handler := range uriHandlers(&LocalService{vm}, rpcLogger) {
+		handlers[route] = handler
+	}
+	adminHandler := &common.HTTPHandler{
+		LockOptions: common.WriteLock,
+		Handler:     adminServer,
+	}
+	if vm.config.RPCUnixSocket != "" {
+		var unixAdminHandler *common.HTTPHandler
+		if vm.config.RPCUnixSocketAdmin {
+			unixAdminHandler = adminHandler
+		}
+		if err := vm.serveUnixSocket(vm.config.RPCUnixSocket, handlers, unixAdminHandler); err != nil {
+			return nil, err
+		}
+	}
+	if vm.config.AdminAPIEnabled {
+		handlers["/admin"] = adminHandler
+	}
+	return handlers, nil
 }
 
+// ProxyApp returns the ABCI connections to the app.
 func (vm *VM) ProxyApp() proxy.AppConns {
 	return vm.proxyApp
 }
 
-func (vm *VM) SetPreference(ctx context.Context, blkID ids.ID) error {
-	//TODO implement me
-	return nil
+// LastAcceptedID returns the ID of the last accepted block. It must not be
+// called before Initialize, like the other accessors below.
+func (vm *VM) LastAcceptedID() ids.ID {
+	return vm.LastAcceptedBlockInternal().ID()
 }
 
-func (vm *VM) AppRequest(_ context.Context, nodeID ids.NodeID, requestID uint32, time time.Time, request []byte) error {
-	return nil
+// LastAcceptedHeight returns the height of the last accepted block.
+func (vm *VM) LastAcceptedHeight() uint64 {
+	return vm.LastAcceptedBlockInternal().Height()
 }
 
-// This VM doesn't (currently) have any app-specific messages
-func (vm *VM) AppResponse(_ context.Context, nodeID ids.NodeID, requestID uint32, response []byte) error {
-	return nil
+// BlockStore returns the store of the accepted blocks.
+func (vm *VM) BlockStore() *store.BlockStore {
+	return vm.blockStore
 }
 
-// This VM doesn't (currently) have any app-specific messages
-func (vm *VM) AppRequestFailed(_ context.Context, nodeID ids.NodeID, requestID uint32) error {
-	return nil
+// StateStore returns the store of the state after the accepted blocks.
+func (vm *VM) StateStore() sm.Store {
+	return vm.stateStore
 }
 
-func (vm *VM) CrossChainAppRequest(_ context.Context, _ ids.ID, _ uint32, deadline time.Time, request []byte) error {
-	return nil
-}
-
-func (vm *VM) CrossChainAppRequestFailed(_ context.Context, _ ids.ID, _ uint32) error {
-	return nil
+// Mempool returns the mempool of the txs to build the blocks with.
+func (vm *VM) Mempool() mempl.Mempool {
+	return vm.mempool
 }
 
-func (vm *VM) CrossChainAppResponse(_ context.Context, _ ids.ID, _ uint32, response []byte) error {
-	return nil
+// EventBus returns the bus the events of the accepted blocks and txs are
+// published on.
+func (vm *VM) EventBus() *types.EventBus {
+	return vm.eventBus
 }
 
-func (vm *VM) Connected(_ context.Context, id ids.NodeID, nodeVersion *version.Application) error {
-	return nil // noop
+// Config returns the configuration the VM was initialized with.
+func (vm *VM) Config() Config {
+	return vm.config
 }
 
-func (vm *VM) Disconnected(_ context.Context, id ids.NodeID) error {
-	return nil // noop
+// SetPreference records the block the next blocks are built on.
+func (vm *VM) SetPreference(ctx context.Context, blkID ids.ID) error {
+	vm.preferred = blkID
+	return nil
 }
-
-func (vm *VM) HealthCheck(ctx context.Context) (interface{}, error) { return nil, nil }
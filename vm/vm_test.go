@@ -1,28 +1,46 @@
 package vm
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/consideritdone/landslidecore/abci/example/kvstore"
 
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/manager"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/timer/mockable"
 	"github.com/ava-labs/avalanchego/version"
 	"github.com/ava-labs/avalanchego/vms/components/chain"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/consideritdone/landslidecore/abci/example/counter"
 	atypes "github.com/consideritdone/landslidecore/abci/types"
+	"github.com/consideritdone/landslidecore/libs/log"
 	tmrand "github.com/consideritdone/landslidecore/libs/rand"
+	"github.com/consideritdone/landslidecore/proxy"
 	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
 )
 
 var (
@@ -50,8 +68,27 @@ func newTestVM(app atypes.Application) (*VM, *snow.Context, chan common.Message,
 		Minor: 0,
 		Patch: 0,
 	})
+	return newTestVMWithDB(app, dbManager, nil)
+}
+
+// newTestVMWithDB initializes a VM on [dbManager], which may hold the chain
+// of a previous VM, with the [configBytes] config.
+func newTestVMWithDB(app atypes.Application, dbManager manager.Manager, configBytes []byte) (*VM, *snow.Context, chan common.Message, error) {
+	return newTestVMWithGenesis(app, dbManager, []byte(genesis), nil, configBytes)
+}
+
+// newTestVMWithGenesis is newTestVMWithDB with the [genesisBytes] genesis and
+// the [upgradeBytes] upgrades, creating the VM with [opts].
+func newTestVMWithGenesis(
+	app atypes.Application,
+	dbManager manager.Manager,
+	genesisBytes []byte,
+	upgradeBytes []byte,
+	configBytes []byte,
+	opts ...Option,
+) (*VM, *snow.Context, chan common.Message, error) {
 	msgChan := make(chan common.Message, 1)
-	vm := NewVM(app)
+	vm := NewVM(app, opts...)
 	snowCtx := snow.DefaultContextTest()
 	snowCtx.Log = logging.NewLogger(
 		fmt.Sprintf("<%s Chain>", blockchainID),
@@ -62,7 +99,14 @@ func newTestVM(app atypes.Application) (*VM, *snow.Context, chan common.Message,
 		),
 	)
 	snowCtx.ChainID = blockchainID
-	err := vm.Initialize(context.TODO(), snowCtx, dbManager, []byte(genesis), nil, nil, msgChan, nil, nil)
+	// avalanchego provides the node's BLS key to the chain
+	sk, err := bls.NewSecretKey()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	snowCtx.PublicKey = bls.PublicFromSecretKey(sk)
+	snowCtx.WarpSigner = warp.NewSigner(sk, blockchainID)
+	err = vm.Initialize(context.TODO(), snowCtx, dbManager, genesisBytes, upgradeBytes, configBytes, msgChan, nil, nil)
 
 	return vm, snowCtx, msgChan, err
 }
@@ -85,6 +129,29 @@ func mustNewKVTestVm(t *testing.T) (*VM, Service, chan common.Message) {
 	return vm, service, msgChan
 }
 
+// mustAcceptBlock broadcasts [txs], builds a block with them and accepts it.
+func mustAcceptBlock(t *testing.T, vm *VM, service Service, txs ...[]byte) snowman.Block {
+	for _, tx := range txs {
+		reply := new(ctypes.ResultBroadcastTx)
+		require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: tx}, reply))
+		require.Equal(t, atypes.CodeTypeOK, reply.Code)
+	}
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, blk.Accept(context.Background()))
+	return blk
+}
+
+// newTestServer serves the [endpoint] handler of the VM over HTTP until the
+// test ends.
+func newTestServer(t *testing.T, vm *VM, endpoint string) *httptest.Server {
+	handlers, err := vm.CreateHandlers(context.Background())
+	require.NoError(t, err)
+	server := httptest.NewServer(handlers[endpoint].Handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
 // MakeTxKV returns a text transaction, allong with expected key, value pair
 func MakeTxKV() ([]byte, []byte, []byte) {
 	k := []byte(tmrand.Str(2))
@@ -166,3 +233,456 @@ func TestInitVm(t *testing.T) {
 	t.Logf("Block: %d", blk2.Height())
 	t.Logf("TM Block Tx count: %d", len(tmBlk2.Data.Txs))
 }
+
+func TestLoadAcceptedBlock(t *testing.T) {
+	vm, service, _ := mustNewCounterTestVm(t)
+	for i := byte(0); i < 2; i++ {
+		mustAcceptBlock(t, vm, service, []byte{i})
+	}
+
+	// blocks past the first height carry a LastCommit signature, which must
+	// decode back from the store
+	block := vm.blockStore.LoadBlock(2)
+	require.NotNil(t, block)
+	assert.Equal(t, vm.blockStore.LoadBlockMeta(2).BlockID.Hash, block.Hash())
+	require.Len(t, block.LastCommit.Signatures, 1)
+	assert.True(t, block.LastCommit.Signatures[0].Absent())
+}
+
+func TestGetBlockIDAtHeight(t *testing.T) {
+	vm, service, _ := mustNewCounterTestVm(t)
+	ctx := context.Background()
+	blks := make([]snowman.Block, 0, 2)
+	for i := byte(0); i < 2; i++ {
+		blks = append(blks, mustAcceptBlock(t, vm, service, []byte{i}))
+	}
+
+	require.NoError(t, vm.VerifyHeightIndex(ctx))
+	for _, blk := range blks {
+		id, err := vm.GetBlockIDAtHeight(ctx, blk.Height())
+		require.NoError(t, err)
+		assert.Equal(t, blk.ID(), id)
+	}
+	_, err := vm.GetBlockIDAtHeight(ctx, 3)
+	assert.ErrorIs(t, err, database.ErrNotFound)
+
+	// accepted blocks are found by ID once evicted from the caches
+	vm.State.Flush()
+	blk, err := vm.GetBlock(ctx, blks[0].ID())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), blk.Height())
+	assert.Equal(t, choices.Accepted, blk.Status())
+}
+
+func TestGetAncestors(t *testing.T) {
+	vm, service, _ := mustNewCounterTestVm(t)
+	ctx := context.Background()
+	blks := make([]snowman.Block, 0, 4)
+	for i := byte(0); i < 3; i++ {
+		blks = append(blks, mustAcceptBlock(t, vm, service, []byte{i}))
+	}
+	// a processing block, only known to the chain state
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte{3}}, reply))
+	blk, err := vm.BuildBlock(ctx)
+	require.NoError(t, err)
+	require.NoError(t, blk.Verify(ctx))
+	blks = append(blks, blk)
+
+	ancestors, err := vm.GetAncestors(ctx, blk.ID(), 10, 1<<20, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, ancestors, 4)
+	for i, blkBytes := range ancestors {
+		assert.Equal(t, blks[3-i].Bytes(), blkBytes)
+	}
+
+	parsed, err := vm.BatchedParseBlock(ctx, ancestors)
+	require.NoError(t, err)
+	require.Len(t, parsed, 4)
+	for i, parsedBlk := range parsed {
+		assert.Equal(t, blks[3-i].ID(), parsedBlk.ID())
+	}
+
+	ancestors, err = vm.GetAncestors(ctx, blks[2].ID(), 2, 1<<20, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{blks[2].Bytes(), blks[1].Bytes()}, ancestors)
+
+	// the first block is returned whatever its size
+	ancestors, err = vm.GetAncestors(ctx, blk.ID(), 10, 1, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{blk.Bytes()}, ancestors)
+
+	ancestors, err = vm.GetAncestors(ctx, ids.GenerateTestID(), 10, 1<<20, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, ancestors)
+}
+
+func TestGenesisHashVerification(t *testing.T) {
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	_, _, _, err := newTestVMWithDB(counter.NewApplication(true), dbManager, nil)
+	require.NoError(t, err)
+
+	// restarting with the same genesis succeeds
+	_, _, _, err = newTestVMWithDB(counter.NewApplication(true), dbManager, nil)
+	require.NoError(t, err)
+
+	// any other genesis is refused, even one that decodes to the same doc
+	_, _, _, err = newTestVMWithGenesis(counter.NewApplication(true), dbManager, []byte(genesis+" "), nil, nil)
+	require.ErrorContains(t, err, "genesis hash")
+}
+
+func TestGenesisChainID(t *testing.T) {
+	newDB := func() manager.Manager {
+		return manager.NewMemDB(&version.Semantic{Major: 1})
+	}
+	genesisWithoutChainID := []byte(strings.Replace(genesis, `"chain_id": "test-chain-U8te75",`, "", 1))
+
+	// the chain_id of the genesis is used as is
+	vm, _, _, err := newTestVMWithDB(counter.NewApplication(true), newDB(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "test-chain-U8te75", vm.tmState.ChainID)
+
+	// without one, it is derived from the Avalanche chain ID
+	vm, _, _, err = newTestVMWithGenesis(counter.NewApplication(true), newDB(), genesisWithoutChainID, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, defaultChainID(blockchainID), vm.genesis.ChainID)
+	assert.Equal(t, vm.genesis.ChainID, vm.tmState.ChainID)
+
+	// the config overrides both
+	dbManager := newDB()
+	vm, _, _, err = newTestVMWithDB(counter.NewApplication(true), dbManager, []byte(`{"chainId":"custom-1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "custom-1", vm.tmState.ChainID)
+	service := NewService(vm)
+	mustAcceptBlock(t, vm, service, []byte{0})
+	assert.Equal(t, "custom-1", vm.blockStore.LoadBlockMeta(1).Header.ChainID)
+	status := new(ctypes.ResultStatus)
+	require.NoError(t, service.Status(nil, nil, status))
+	assert.Equal(t, "custom-1", status.NodeInfo.Network)
+
+	// and can't be changed afterwards
+	_, _, _, err = newTestVMWithDB(counter.NewApplication(true), dbManager, []byte(`{"chainId":"custom-2"}`))
+	require.Error(t, err)
+}
+
+func TestParseConfig(t *testing.T) {
+	config, err := parseConfig(DefaultConfig(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig(), config)
+
+	config, err = parseConfig(DefaultConfig(), []byte(`{"defaultPerPage":50,"maxPerPage":500}`))
+	require.NoError(t, err)
+	assert.Equal(t, 50, config.DefaultPerPage)
+	assert.Equal(t, 500, config.MaxPerPage)
+
+	_, err = parseConfig(DefaultConfig(), []byte(`{"defaultPerPage":50,"maxPerPage":20}`))
+	assert.Error(t, err)
+	_, err = parseConfig(DefaultConfig(), []byte(`{"defaultPerPage":0}`))
+	assert.Error(t, err)
+}
+
+func TestEmbeddedVM(t *testing.T) {
+	config := DefaultConfig()
+	config.DefaultPerPage = 7
+	app := kvstore.NewApplication()
+	vm, _, _, err := newTestVMWithGenesis(
+		nil,
+		manager.NewMemDB(version.Semantic1_0_0),
+		[]byte(genesis),
+		nil,
+		[]byte(`{"maxPerPage":70}`),
+		WithConfig(config),
+		WithClientCreator(proxy.NewLocalClientCreator(app)),
+	)
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	// the config bytes are applied on top of the base config
+	assert.Equal(t, 7, vm.Config().DefaultPerPage)
+	assert.Equal(t, 70, vm.Config().MaxPerPage)
+
+	blk := mustAcceptBlock(t, vm, service, []byte("name=embedded"))
+	assert.Equal(t, blk.ID(), vm.LastAcceptedID())
+	assert.Equal(t, blk.Height(), vm.LastAcceptedHeight())
+	assert.Equal(t, int64(blk.Height()), vm.BlockStore().Height())
+	state, err := vm.StateStore().Load()
+	require.NoError(t, err)
+	assert.Equal(t, int64(blk.Height()), state.LastBlockHeight)
+	assert.Zero(t, vm.Mempool().Size())
+	assert.True(t, vm.EventBus().IsRunning())
+
+	// the txs were delivered to the app of the client creator
+	res := app.Query(atypes.RequestQuery{Data: []byte("name")})
+	assert.Equal(t, []byte("embedded"), res.Value)
+}
+
+// recordingSink is an EventSink recording the events it receives.
+type recordingSink struct {
+	mtx       sync.Mutex
+	headers   []types.EventDataNewBlockHeader
+	txResults []*atypes.TxResult
+}
+
+func (s *recordingSink) IndexBlockEvents(header types.EventDataNewBlockHeader) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.headers = append(s.headers, header)
+	return nil
+}
+
+func (s *recordingSink) IndexTxEvents(txResults []*atypes.TxResult) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.txResults = append(s.txResults, txResults...)
+	return nil
+}
+
+// recorded returns the events recorded so far.
+func (s *recordingSink) recorded() ([]types.EventDataNewBlockHeader, []*atypes.TxResult) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.headers, s.txResults
+}
+
+func TestVMOptions(t *testing.T) {
+	logs := new(bytes.Buffer)
+	clock := &mockable.Clock{}
+	sink := &recordingSink{}
+	var wrapped bool
+	vm, _, _, err := newTestVMWithGenesis(
+		kvstore.NewApplication(),
+		manager.NewMemDB(version.Semantic1_0_0),
+		[]byte(genesis),
+		nil,
+		nil,
+		WithLogger(log.NewTMLogger(logs)),
+		WithClock(clock),
+		WithDatabaseWrapper(func(db database.Database) database.Database {
+			wrapped = true
+			return db
+		}),
+		WithEventSinks(sink),
+	)
+	require.NoError(t, err)
+	service := NewService(vm)
+	assert.True(t, wrapped)
+	assert.NotZero(t, logs.Len())
+
+	// the blocks are built at the time of the clock
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	blockTime := vm.tmState.LastBlockTime.Add(time.Hour)
+	clock.Set(blockTime)
+	mustAcceptBlock(t, vm, service, []byte("b=2"))
+	assert.Equal(t, blockTime, vm.tmState.LastBlockTime)
+
+	require.Eventually(t, func() bool {
+		headers, _ := sink.recorded()
+		return len(headers) == 2
+	}, time.Second, 10*time.Millisecond)
+	headers, txResults := sink.recorded()
+	assert.Equal(t, int64(2), headers[1].Header.Height)
+	require.Len(t, txResults, 2)
+	assert.Equal(t, types.Tx("b=2"), types.Tx(txResults[1].Tx))
+}
+
+func TestRPCUnixSocket(t *testing.T) {
+	vm, _, _ := mustNewCounterTestVm(t)
+	vm.config.RPCUnixSocket = filepath.Join(t.TempDir(), "rpc.sock")
+	_, err := vm.CreateHandlers(context.Background())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, vm.closeUnixSocket(context.Background())) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", vm.config.RPCUnixSocket)
+		},
+	}}
+	body := `{"jsonrpc":"2.0","id":1,"method":"` + Name + `.health","params":{}}`
+	resp, err := client.Post("http://unix/rpc", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply))
+	assert.NotEmpty(t, reply.Result)
+	assert.Empty(t, reply.Error)
+
+	info, err := os.Stat(vm.config.RPCUnixSocket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(unixSocketMode), info.Mode().Perm())
+
+	// the admin handler is not served unless enabled
+	body = `{"jsonrpc":"2.0","id":1,"method":"admin.compact","params":{}}`
+	adminResp, err := client.Post("http://unix/admin", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer adminResp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, adminResp.StatusCode)
+
+	// the handlers created again replace the socket served before
+	vm.config.RPCUnixSocketAdmin = true
+	_, err = vm.CreateHandlers(context.Background())
+	require.NoError(t, err)
+	client.CloseIdleConnections()
+	adminResp, err = client.Post("http://unix/admin", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer adminResp.Body.Close()
+	assert.Equal(t, http.StatusOK, adminResp.StatusCode)
+	entries, err := os.ReadDir(filepath.Dir(vm.config.RPCUnixSocket))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, vm.closeUnixSocket(context.Background()))
+	_, err = os.Stat(vm.config.RPCUnixSocket)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestRejectReturnsTxs(t *testing.T) {
+	vm, service, _ := mustNewKVTestVm(t)
+	ctx := context.Background()
+
+	committedTx := []byte("a=1")
+	mustAcceptBlock(t, vm, service, committedTx)
+	require.Equal(t, 0, vm.mempool.Size())
+
+	// a block with a tx the node doesn't have, as built by another node
+	height := vm.tmState.LastBlockHeight + 1
+	tmBlock, _ := vm.tmState.MakeBlock(height, types.Txs{[]byte("b=2"), committedTx}, makeCommitMock(height), nil, proposerAddress)
+	blk, err := vm.newBlock(tmBlock)
+	require.NoError(t, err)
+	require.NoError(t, blk.Reject(ctx))
+
+	// only the tx which wasn't committed is back in the mempool
+	txs := vm.mempool.ReapMaxTxs(-1)
+	require.Len(t, txs, 1)
+	assert.Equal(t, types.Tx("b=2"), txs[0])
+}
+
+func TestShutdown(t *testing.T) {
+	app := kvstore.NewApplication()
+	dbManager := manager.NewMemDB(&version.Semantic{Major: 1})
+	configBytes := []byte(`{"persistMempool":true}`)
+	vm, _, _, err := newTestVMWithDB(app, dbManager, configBytes)
+	require.NoError(t, err)
+	service := NewService(vm)
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("b=2")}, reply))
+	require.NoError(t, vm.Shutdown(context.Background()))
+	// the VM is only shut down once
+	require.NoError(t, vm.Shutdown(context.Background()))
+
+	// the queued block was indexed and the mempool is restored on restart
+	vm, _, _, err = newTestVMWithDB(app, dbManager, configBytes)
+	require.NoError(t, err)
+	r, err := vm.txIndexer.Get(types.Tx("a=1").Hash())
+	require.NoError(t, err)
+	assert.NotNil(t, r)
+	txs := vm.mempool.ReapMaxTxs(-1)
+	require.Len(t, txs, 1)
+	assert.Equal(t, types.Tx("b=2"), txs[0])
+}
+
+func TestMultipleChains(t *testing.T) {
+	ctx := context.Background()
+	vm1, service1, _ := mustNewKVTestVm(t)
+	vm2, service2, _ := mustNewKVTestVm(t)
+	_, err := vm1.CreateHandlers(ctx)
+	require.NoError(t, err)
+	_, err = vm2.CreateHandlers(ctx)
+	require.NoError(t, err)
+
+	sub, err := vm2.eventBus.Subscribe(ctx, "test", types.EventQueryNewBlock, 1)
+	require.NoError(t, err)
+
+	// the blocks of one chain don't reach the other
+	mustAcceptBlock(t, vm1, service1, []byte("a=1"))
+	vm1.acceptor.flush()
+	assert.EqualValues(t, 1, vm1.tmState.LastBlockHeight)
+	assert.EqualValues(t, 0, vm2.tmState.LastBlockHeight)
+	r, err := vm2.txIndexer.Get(types.Tx("a=1").Hash())
+	require.NoError(t, err)
+	assert.Nil(t, r)
+	assert.Empty(t, sub.Out())
+
+	mustAcceptBlock(t, vm2, service2, []byte("b=2"))
+	vm2.acceptor.flush()
+	assert.Len(t, sub.Out(), 1)
+
+	// nor do their log levels
+	require.NoError(t, vm1.logLevels.SetLevel("error"))
+	assert.Equal(t, defaultLogLevel, vm2.logLevels.Level())
+}
+
+func TestParseBlock(t *testing.T) {
+	vm, service, _ := mustNewKVTestVm(t)
+	ctx := context.Background()
+	blk := mustAcceptBlock(t, vm, service, []byte("a=1"))
+
+	parsed, err := vm.parseBlock(ctx, blk.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, blk.ID(), parsed.ID())
+
+	tmBlock := blk.(*chain.BlockWrapper).Block.(*Block).tmBlock
+	tamperedBytes := func(tamper func(*types.Block)) []byte {
+		protoBlock, err := tmBlock.ToProto()
+		require.NoError(t, err)
+		tampered, err := types.BlockFromProto(protoBlock)
+		require.NoError(t, err)
+		tamper(tampered)
+		protoBlock, err = tampered.ToProto()
+		require.NoError(t, err)
+		b, err := protoBlock.Marshal()
+		require.NoError(t, err)
+		return b
+	}
+
+	tests := map[string][]byte{
+		"garbage":   []byte("garbage"),
+		"too large": make([]byte, types.MaxBlockSizeBytes+1),
+		"data hash": tamperedBytes(func(b *types.Block) {
+			b.Txs = append(b.Txs, []byte("b=2"))
+		}),
+		"height": tamperedBytes(func(b *types.Block) {
+			b.Height = -1
+		}),
+		// an unknown field, which would give the block another encoding
+		"unknown field": append(blk.Bytes(), 0xa0, 0x06, 0x01),
+	}
+	for name, b := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := vm.parseBlock(ctx, b)
+			assert.ErrorIs(t, err, errInvalidBlock)
+		})
+	}
+}
+
+func FuzzParseBlock(f *testing.F) {
+	vm, _, _, err := newKVTestVM()
+	if err != nil {
+		f.Fatal(err)
+	}
+	reply := new(ctypes.ResultBroadcastTx)
+	if err := NewService(vm).BroadcastTxSync(nil, &BroadcastTxArgs{Tx: []byte("a=1")}, reply); err != nil {
+		f.Fatal(err)
+	}
+	blk, err := vm.BuildBlock(context.Background())
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(blk.Bytes())
+	f.Add([]byte{})
+	f.Add([]byte("garbage"))
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		parsed, err := vm.parseBlock(context.Background(), b)
+		if err != nil {
+			assert.ErrorIs(t, err, errInvalidBlock)
+			return
+		}
+		assert.Equal(t, b, parsed.Bytes())
+	})
+}
@@ -0,0 +1,292 @@
+package vm
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	tmbytes "github.com/consideritdone/landslidecore/libs/bytes"
+	tmstate "github.com/consideritdone/landslidecore/proto/tendermint/state"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+const (
+	// warpEventType is the type of the events with which the app emits
+	// outbound warp messages, from DeliverTx or EndBlock. The payload
+	// attribute is the hex-encoded payload, and the optional
+	// destination_chain_id attribute the ID of the destination chain, any
+	// chain if it is missing.
+	warpEventType                   = "warp"
+	warpPayloadAttribute            = "payload"
+	warpDestinationChainIDAttribute = "destination_chain_id"
+
+	// warpQuorumNumerator and warpQuorumDenominator are the share of the
+	// source subnet's stake which must sign an inbound warp message, as in
+	// Subnet-EVM.
+	warpQuorumNumerator   = 67
+	warpQuorumDenominator = 100
+)
+
+var (
+	// warpTxPrefix starts the txs which carry an inbound warp message. It is
+	// followed by the 4-byte big-endian length of the signed message, the
+	// message, and the app's own tx. The VM only lets such txs in the mempool
+	// and in blocks if the message is signed by a quorum of the source subnet,
+	// so the app can trust the message when it executes the tx, which it
+	// receives whole.
+	warpTxPrefix = []byte{0x00, 'w', 'a', 'r', 'p'}
+
+	// warpMessageKeyPrefix prefixes the unsigned outbound warp messages, by
+	// ID, in the state database.
+	warpMessageKeyPrefix = []byte("warp/")
+
+	errWarpWithoutContext = errors.New("txs with warp messages require the proposervm P-Chain height")
+	errNoWarpSigner       = errors.New("warp messages require the node's BLS key, which the chain context doesn't provide")
+	errWarpMessageUnknown = errors.New("unknown warp message")
+)
+
+type (
+	WarpMessageArgs struct {
+		ID ids.ID `json:"id"`
+	}
+
+	// WarpMessageReply is an outbound warp message, with the node's BLS
+	// signature over it, which relayers aggregate with the ones of the other
+	// validators.
+	WarpMessageReply struct {
+		ID        ids.ID           `json:"id"`
+		Message   tmbytes.HexBytes `json:"message"`
+		Signature tmbytes.HexBytes `json:"signature"`
+	}
+
+	WarpMessagesArgs struct {
+		Height int64 `json:"height"`
+	}
+
+	WarpMessagesReply struct {
+		Messages []WarpMessageReply `json:"messages"`
+	}
+
+	WarpService interface {
+		WarpMessage(_ *http.Request, args *WarpMessageArgs, reply *WarpMessageReply) error
+		WarpMessages(_ *http.Request, args *WarpMessagesArgs, reply *WarpMessagesReply) error
+	}
+)
+
+func warpMessageKey(id ids.ID) []byte {
+	return append(append([]byte{}, warpMessageKeyPrefix...), id[:]...)
+}
+
+// parseWarpTx returns the signed warp message carried by [tx], nil if [tx]
+// carries none.
+func parseWarpTx(tx types.Tx) (*warp.Message, error) {
+	if !bytes.HasPrefix(tx, warpTxPrefix) {
+		return nil, nil
+	}
+	rest := tx[len(warpTxPrefix):]
+	if len(rest) < 4 {
+		return nil, errors.New("warp tx too short")
+	}
+	msgLen := binary.BigEndian.Uint32(rest)
+	rest = rest[4:]
+	if uint64(msgLen) > uint64(len(rest)) {
+		return nil, fmt.Errorf("warp message length %d exceeds the tx", msgLen)
+	}
+	return warp.ParseMessage(rest[:msgLen])
+}
+
+// verifyWarpTx verifies the signature of the warp message carried by [tx], if
+// any, against the validators of its source subnet at [pChainHeight].
+func (vm *VM) verifyWarpTx(ctx context.Context, tx types.Tx, pChainHeight uint64) error {
+	msg, err := parseWarpTx(tx)
+	if err != nil || msg == nil {
+		return err
+	}
+	if msg.DestinationChainID != vm.ctx.ChainID && msg.DestinationChainID != warp.AnycastID {
+		return fmt.Errorf("warp message %s is destined to chain %s", msg.ID(), msg.DestinationChainID)
+	}
+	err = msg.Signature.Verify(
+		ctx,
+		&msg.UnsignedMessage,
+		vm.ctx.ValidatorState,
+		pChainHeight,
+		warpQuorumNumerator,
+		warpQuorumDenominator,
+	)
+	if err != nil {
+		return fmt.Errorf("invalid warp message %s: %w", msg.ID(), err)
+	}
+	return nil
+}
+
+// verifyWarpTxs verifies the warp messages carried by the txs of [tmBlock]
+// at the P-Chain height of [blockCtx], the one the block is verified with, so
+// that every node verifies them against the same validators. [blockCtx] is
+// nil until the proposervm is activated, and no warp message is valid then.
+func (vm *VM) verifyWarpTxs(ctx context.Context, tmBlock *types.Block, blockCtx *block.Context) error {
+	for _, tx := range tmBlock.Txs {
+		if !bytes.HasPrefix(tx, warpTxPrefix) {
+			continue
+		}
+		if blockCtx == nil {
+			return errWarpWithoutContext
+		}
+		if err := vm.verifyWarpTx(ctx, tx, blockCtx.PChainHeight); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// warpPreCheck rejects the txs whose warp message isn't signed by a quorum of
+// its source subnet at the current P-Chain height.
+func (vm *VM) warpPreCheck(tx types.Tx) error {
+	if !bytes.HasPrefix(tx, warpTxPrefix) {
+		return nil
+	}
+	ctx := context.Background()
+	pChainHeight, err := vm.ctx.ValidatorState.GetCurrentHeight(ctx)
+	if err != nil {
+		return err
+	}
+	return vm.verifyWarpTx(ctx, tx, pChainHeight)
+}
+
+// warpMessagesOf returns the outbound warp messages emitted by the app in
+// [abciResponses]. Malformed events are logged and skipped, the block being
+// already executed.
+func (vm *VM) warpMessagesOf(abciResponses *tmstate.ABCIResponses) []*warp.UnsignedMessage {
+	var events []abci.Event
+	for _, deliverTx := range abciResponses.DeliverTxs {
+		if deliverTx != nil && deliverTx.IsOK() {
+			events = append(events, deliverTx.Events...)
+		}
+	}
+	if abciResponses.EndBlock != nil {
+		events = append(events, abciResponses.EndBlock.Events...)
+	}
+
+	var msgs []*warp.UnsignedMessage
+	for _, event := range events {
+		if event.Type != warpEventType {
+			continue
+		}
+		msg, err := vm.warpMessageOf(event)
+		if err != nil {
+			vm.tmLogger.Error("dropping malformed warp event", "err", err)
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func (vm *VM) warpMessageOf(event abci.Event) (*warp.UnsignedMessage, error) {
+	var (
+		payload            []byte
+		destinationChainID = warp.AnycastID
+		err                error
+	)
+	for _, attr := range event.Attributes {
+		switch string(attr.Key) {
+		case warpPayloadAttribute:
+			if payload, err = hex.DecodeString(string(attr.Value)); err != nil {
+				return nil, fmt.Errorf("invalid payload: %w", err)
+			}
+		case warpDestinationChainIDAttribute:
+			if destinationChainID, err = ids.FromString(string(attr.Value)); err != nil {
+				return nil, fmt.Errorf("invalid destination chain ID: %w", err)
+			}
+		}
+	}
+	if payload == nil {
+		return nil, errors.New("missing payload")
+	}
+	return warp.NewUnsignedMessage(vm.ctx.ChainID, destinationChainID, payload)
+}
+
+// storeWarpMessages stores the outbound warp messages emitted at a height,
+// for the node to sign them on request.
+func (vm *VM) storeWarpMessages(abciResponses *tmstate.ABCIResponses) error {
+	for _, msg := range vm.warpMessagesOf(abciResponses) {
+		if err := vm.stateDB.Set(warpMessageKey(msg.ID()), msg.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteWarpMessages deletes the outbound warp messages emitted at [height],
+// so that the node no longer signs them once the block is rolled back.
+func (vm *VM) deleteWarpMessages(height int64) error {
+	abciResponses, err := vm.stateStore.LoadABCIResponses(height)
+	if err != nil {
+		return err
+	}
+	for _, msg := range vm.warpMessagesOf(abciResponses) {
+		if err := vm.stateDB.Delete(warpMessageKey(msg.ID())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (vm *VM) signedWarpMessage(msg *warp.UnsignedMessage) (WarpMessageReply, error) {
+	if vm.ctx.WarpSigner == nil {
+		return WarpMessageReply{}, errNoWarpSigner
+	}
+	sig, err := vm.ctx.WarpSigner.Sign(msg)
+	if err != nil {
+		return WarpMessageReply{}, err
+	}
+	return WarpMessageReply{ID: msg.ID(), Message: msg.Bytes(), Signature: sig}, nil
+}
+
+// WarpMessage returns the outbound warp message [args.ID] emitted by the app,
+// signed by the node.
+func (s *LocalService) WarpMessage(_ *http.Request, args *WarpMessageArgs, reply *WarpMessageReply) error {
+	msgBytes, err := s.vm.stateDB.Get(warpMessageKey(args.ID))
+	if err != nil {
+		return err
+	}
+	if len(msgBytes) == 0 {
+		return fmt.Errorf("%w %s", errWarpMessageUnknown, args.ID)
+	}
+	msg, err := warp.ParseUnsignedMessage(msgBytes)
+	if err != nil {
+		return err
+	}
+	*reply, err = s.vm.signedWarpMessage(msg)
+	return err
+}
+
+// WarpMessages returns the outbound warp messages emitted by the app at
+// [args.Height], signed by the node.
+func (s *LocalService) WarpMessages(_ *http.Request, args *WarpMessagesArgs, reply *WarpMessagesReply) error {
+	height, err := getHeight(s.vm.blockStore, &args.Height)
+	if err != nil {
+		return err
+	}
+	abciResponses, err := s.vm.stateStore.LoadABCIResponses(height)
+	if err != nil {
+		return err
+	}
+	reply.Messages = []WarpMessageReply{}
+	for _, msg := range s.vm.warpMessagesOf(abciResponses) {
+		signed, err := s.vm.signedWarpMessage(msg)
+		if err != nil {
+			return err
+		}
+		reply.Messages = append(reply.Messages, signed)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package vm
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	atypes "github.com/consideritdone/landslidecore/abci/types"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+// warpApp emits an outbound warp message with the key of each tx as payload.
+type warpApp struct {
+	*kvstore.Application
+}
+
+func (app *warpApp) DeliverTx(req atypes.RequestDeliverTx) atypes.ResponseDeliverTx {
+	res := app.Application.DeliverTx(req)
+	key := req.Tx
+	if i := len(key) - 2; i > 0 && key[i] == '=' {
+		key = key[:i]
+	}
+	res.Events = append(res.Events, atypes.Event{
+		Type: warpEventType,
+		Attributes: []atypes.EventAttribute{
+			{Key: []byte(warpPayloadAttribute), Value: []byte(hex.EncodeToString(key))},
+		},
+	})
+	return res
+}
+
+func TestOutboundWarpMessages(t *testing.T) {
+	vm, snowCtx, _, err := newTestVM(&warpApp{Application: kvstore.NewApplication()})
+	require.NoError(t, err)
+	service := NewService(vm)
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+
+	reply := new(WarpMessagesReply)
+	require.NoError(t, service.WarpMessages(nil, &WarpMessagesArgs{Height: 1}, reply))
+	require.Len(t, reply.Messages, 1)
+	signed := reply.Messages[0]
+
+	msg, err := warp.ParseUnsignedMessage(signed.Message)
+	require.NoError(t, err)
+	assert.Equal(t, signed.ID, msg.ID())
+	assert.Equal(t, blockchainID, msg.SourceChainID)
+	assert.Equal(t, warp.AnycastID, msg.DestinationChainID)
+	assert.Equal(t, []byte("a"), msg.Payload)
+	sig, err := bls.SignatureFromBytes(signed.Signature)
+	require.NoError(t, err)
+	assert.True(t, bls.Verify(snowCtx.PublicKey, sig, signed.Message))
+
+	byID := new(WarpMessageReply)
+	require.NoError(t, service.WarpMessage(nil, &WarpMessageArgs{ID: signed.ID}, byID))
+	assert.Equal(t, signed, *byID)
+	err = service.WarpMessage(nil, &WarpMessageArgs{ID: ids.GenerateTestID()}, byID)
+	assert.ErrorIs(t, err, errWarpMessageUnknown)
+}
+
+func TestInboundWarpMessages(t *testing.T) {
+	vm, snowCtx, _, err := newTestVM(kvstore.NewApplication())
+	require.NoError(t, err)
+	service := NewService(vm)
+	ctx := context.Background()
+
+	// a source subnet with a single validator
+	sourceChainID, sourceSubnetID := ids.GenerateTestID(), ids.GenerateTestID()
+	sk, err := bls.NewSecretKey()
+	require.NoError(t, err)
+	snowCtx.ValidatorState = &validators.TestState{
+		GetCurrentHeightF: func(context.Context) (uint64, error) {
+			return 10, nil
+		},
+		GetSubnetIDF: func(_ context.Context, chainID ids.ID) (ids.ID, error) {
+			require.Equal(t, sourceChainID, chainID)
+			return sourceSubnetID, nil
+		},
+		GetValidatorSetF: func(_ context.Context, _ uint64, subnetID ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+			require.Equal(t, sourceSubnetID, subnetID)
+			nodeID := ids.GenerateTestNodeID()
+			return map[ids.NodeID]*validators.GetValidatorOutput{
+				nodeID: {NodeID: nodeID, PublicKey: bls.PublicFromSecretKey(sk), Weight: 1},
+			}, nil
+		},
+	}
+
+	warpTx := func(signer *bls.SecretKey, appTx string) types.Tx {
+		unsigned, err := warp.NewUnsignedMessage(sourceChainID, blockchainID, []byte("hello"))
+		require.NoError(t, err)
+		sigBytes, err := warp.NewSigner(signer, sourceChainID).Sign(unsigned)
+		require.NoError(t, err)
+		sig := &warp.BitSetSignature{Signers: set.NewBits(0).Bytes()}
+		copy(sig.Signature[:], sigBytes)
+		msg, err := warp.NewMessage(unsigned, sig)
+		require.NoError(t, err)
+
+		tx := append([]byte{}, warpTxPrefix...)
+		tx = binary.BigEndian.AppendUint32(tx, uint32(len(msg.Bytes())))
+		tx = append(tx, msg.Bytes()...)
+		return append(tx, appTx...)
+	}
+
+	reply := new(ctypes.ResultBroadcastTx)
+	require.NoError(t, service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: warpTx(sk, "a=1")}, reply))
+	assert.Equal(t, atypes.CodeTypeOK, reply.Code)
+
+	// messages not signed by the source subnet are refused
+	otherSK, err := bls.NewSecretKey()
+	require.NoError(t, err)
+	err = service.BroadcastTxSync(nil, &BroadcastTxArgs{Tx: warpTx(otherSK, "b=2")}, reply)
+	assert.ErrorContains(t, err, "invalid warp message")
+
+	// blocks with warp messages are only valid with the P-Chain height
+	blk, err := vm.BuildBlock(ctx)
+	require.NoError(t, err)
+	assert.ErrorIs(t, blk.Verify(ctx), errWarpWithoutContext)
+	require.NoError(t, blk.(block.WithVerifyContext).VerifyWithContext(ctx, &block.Context{PChainHeight: 10}))
+	require.NoError(t, blk.Accept(ctx))
+}
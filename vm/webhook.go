@@ -0,0 +1,137 @@
+package vm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	abci "github.com/consideritdone/landslidecore/abci/types"
+	tmjson "github.com/consideritdone/landslidecore/libs/json"
+	tmquery "github.com/consideritdone/landslidecore/libs/pubsub/query"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+const (
+	// webhookTimeout is how long a POST to a webhook may take.
+	webhookTimeout = 10 * time.Second
+	// defaultWebhookRetryBackoff is the wait before the first retry of a
+	// webhook whose retryBackoff isn't set.
+	defaultWebhookRetryBackoff = time.Second
+	// maxWebhookRetryBackoff caps the wait between the retries of a webhook.
+	maxWebhookRetryBackoff = 30 * time.Second
+	// maxWebhookRetries is the largest maxRetries of a webhook, as its sink
+	// holds the next blocks back while it retries.
+	maxWebhookRetries = 100
+)
+
+// webhookSink is the EventSink of a webhook of the config, which POSTs each
+// block header and tx result matching its query to its URL, as the
+// ctypes.ResultEvent the websocket subscriptions receive. A failed POST, or
+// one answered with a status other than 2xx, is retried up to maxRetries
+// times, with a backoff doubling after each attempt up to
+// maxWebhookRetryBackoff, or until [aborted] is closed on shutdown.
+type webhookSink struct {
+	hook    Webhook
+	query   *tmquery.Query
+	client  *http.Client
+	aborted <-chan struct{}
+}
+
+func newWebhookSink(hook Webhook, aborted <-chan struct{}) (*webhookSink, error) {
+	query, err := tmquery.New(hook.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query of webhook %s: %w", hook.URL, err)
+	}
+	if hook.RetryBackoff.Duration == 0 {
+		hook.RetryBackoff.Duration = defaultWebhookRetryBackoff
+	}
+	return &webhookSink{
+		hook:    hook,
+		query:   query,
+		client:  &http.Client{Timeout: webhookTimeout},
+		aborted: aborted,
+	}, nil
+}
+
+func (s *webhookSink) IndexBlockEvents(header types.EventDataNewBlockHeader) error {
+	resultEvents := append(append([]abci.Event(nil), header.ResultBeginBlock.Events...), header.ResultEndBlock.Events...)
+	events := compositeEvents(resultEvents)
+	events[types.EventTypeKey] = append(events[types.EventTypeKey], types.EventNewBlockHeader)
+	events[types.BlockHeightKey] = append(events[types.BlockHeightKey], strconv.FormatInt(header.Header.Height, 10))
+	return s.notify(header, events)
+}
+
+func (s *webhookSink) IndexTxEvents(txResults []*abci.TxResult) error {
+	for _, txResult := range txResults {
+		events := compositeEvents(txResult.Result.Events)
+		events[types.EventTypeKey] = append(events[types.EventTypeKey], types.EventTx)
+		events[types.TxHashKey] = append(events[types.TxHashKey], fmt.Sprintf("%X", types.Tx(txResult.Tx).Hash()))
+		events[types.TxHeightKey] = append(events[types.TxHeightKey], strconv.FormatInt(txResult.Height, 10))
+		if err := s.notify(types.EventDataTx{TxResult: *txResult}, events); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notify POSTs [data] if its [events] match the query.
+func (s *webhookSink) notify(data types.TMEventData, events map[string][]string) error {
+	match, err := s.query.Matches(events)
+	if err != nil || !match {
+		return err
+	}
+	body, err := tmjson.Marshal(ctypes.ResultEvent{Query: s.hook.Query, Data: data, Events: events})
+	if err != nil {
+		return err
+	}
+
+	backoff := s.hook.RetryBackoff.Duration
+	for retries := 0; ; retries++ {
+		err = s.post(body)
+		if err == nil || retries == s.hook.MaxRetries {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-s.aborted:
+			return fmt.Errorf("gave up on webhook %s on shutdown: %w", s.hook.URL, err)
+		}
+		backoff *= 2
+		if backoff > maxWebhookRetryBackoff {
+			backoff = maxWebhookRetryBackoff
+		}
+	}
+}
+
+func (s *webhookSink) post(body []byte) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.aborted:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s answered %s", s.hook.URL, resp.Status)
+	}
+	return nil
+}
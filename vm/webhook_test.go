@@ -0,0 +1,73 @@
+package vm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consideritdone/landslidecore/abci/example/kvstore"
+	tmjson "github.com/consideritdone/landslidecore/libs/json"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+func TestWebhook(t *testing.T) {
+	var (
+		mtx      sync.Mutex
+		attempts int
+		bodies   [][]byte
+	)
+	// the first POST fails
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		mtx.Lock()
+		defer mtx.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		bodies = append(bodies, body)
+	}))
+	defer server.Close()
+	received := func() [][]byte {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return bodies
+	}
+
+	config := `{"webhooks":[{"url":"` + server.URL + `","query":"tm.event = 'Tx' AND app.key = 'a'","maxRetries":3,"retryBackoff":"10ms"}]}`
+	vm, _, _, err := newTestVMWithDB(kvstore.NewApplication(), manager.NewMemDB(&version.Semantic{Major: 1}), []byte(config))
+	require.NoError(t, err)
+	service := NewService(vm)
+
+	// only the tx matching the query is POSTed, after a retry, even if the
+	// VM shuts down meanwhile
+	mustAcceptBlock(t, vm, service, []byte("a=1"))
+	mustAcceptBlock(t, vm, service, []byte("b=2"))
+	require.NoError(t, vm.Shutdown(context.Background()))
+	require.Len(t, received(), 1)
+	var event ctypes.ResultEvent
+	require.NoError(t, tmjson.Unmarshal(received()[0], &event))
+	assert.Equal(t, "tm.event = 'Tx' AND app.key = 'a'", event.Query)
+	require.IsType(t, types.EventDataTx{}, event.Data)
+	assert.Equal(t, types.Tx("a=1"), types.Tx(event.Data.(types.EventDataTx).Tx))
+	assert.Equal(t, []string{"a"}, event.Events["app.key"])
+
+	// webhooks must be valid
+	_, _, _, err = newTestVMWithDB(kvstore.NewApplication(), manager.NewMemDB(&version.Semantic{Major: 1}),
+		[]byte(`{"webhooks":[{"url":"`+server.URL+`","query":"tm.event ="}]}`))
+	assert.ErrorContains(t, err, "webhooks query")
+	_, _, _, err = newTestVMWithDB(kvstore.NewApplication(), manager.NewMemDB(&version.Semantic{Major: 1}),
+		[]byte(`{"webhooks":[{"url":"`+server.URL+`","query":"tm.event = 'Tx'","maxRetries":1000}]}`))
+	assert.ErrorContains(t, err, "webhooks maxRetries")
+}
@@ -0,0 +1,291 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/consideritdone/landslidecore/libs/log"
+	tmpubsub "github.com/consideritdone/landslidecore/libs/pubsub"
+	tmquery "github.com/consideritdone/landslidecore/libs/pubsub/query"
+	tmsync "github.com/consideritdone/landslidecore/libs/sync"
+	"github.com/consideritdone/landslidecore/rpc/core"
+	ctypes "github.com/consideritdone/landslidecore/rpc/core/types"
+	rpcserver "github.com/consideritdone/landslidecore/rpc/jsonrpc/server"
+	rpctypes "github.com/consideritdone/landslidecore/rpc/jsonrpc/types"
+	"github.com/consideritdone/landslidecore/types"
+)
+
+const (
+	wsMetricsPrefix = "websocket"
+
+	// subscriptionBufferSize is the number of events buffered per
+	// subscription, as in Tendermint's default RPC config.
+	subscriptionBufferSize = 200
+)
+
+var errMaxWSConnections = errors.New("max websocket connections reached")
+
+// WSConnectionInfo describes an open websocket connection.
+type WSConnectionInfo struct {
+	ID            string    `json:"id"`
+	RemoteAddr    string    `json:"remoteAddr"`
+	ConnectedAt   time.Time `json:"connectedAt"`
+	Subscriptions int       `json:"subscriptions"`
+	EventsSent    uint64    `json:"eventsSent"`
+	EventsDropped uint64    `json:"eventsDropped"`
+}
+
+// wsConnection holds the stats of an open websocket connection.
+type wsConnection struct {
+	remoteAddr    string
+	connectedAt   time.Time
+	eventsSent    uint64 // atomic
+	eventsDropped uint64 // atomic
+}
+
+type wsMetrics struct {
+	connections         prometheus.Gauge
+	rejectedConnections prometheus.Counter
+	eventsSent          prometheus.Counter
+	eventsDropped       prometheus.Counter
+}
+
+func newWSMetrics(registerer prometheus.Registerer) (*wsMetrics, error) {
+	m := &wsMetrics{
+		connections: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "connections",
+			Help: "Number of open websocket connections.",
+		}),
+		rejectedConnections: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "rejected_connections",
+			Help: "Number of websocket connections rejected because the limit was reached.",
+		}),
+		eventsSent: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "events_sent",
+			Help: "Number of events sent to websocket subscribers.",
+		}),
+		eventsDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "events_dropped",
+			Help: "Number of events dropped because a websocket subscriber was too slow.",
+		}),
+	}
+	for _, c := range []prometheus.Collector{m.connections, m.rejectedConnections, m.eventsSent, m.eventsDropped} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// wsServer serves event subscriptions over websocket, like the /websocket
+// endpoint of Tendermint's RPC server, and enforces the websocket limits of
+// the VM config.
+type wsServer struct {
+	eventBus *types.EventBus
+	config   Config
+	logger   log.Logger
+	metrics  *wsMetrics
+	routes   map[string]*rpcserver.RPCFunc
+
+	nextID uint64 // atomic
+
+	mtx tmsync.Mutex
+	// conns holds the open connections by connection ID. The remote address
+	// can't be used, it is the same for all clients of a Unix socket.
+	conns map[string]*wsConnection
+}
+
+func newWSServer(eventBus *types.EventBus, config Config, logger log.Logger, registerer prometheus.Registerer) (*wsServer, error) {
+	metrics, err := newWSMetrics(registerer)
+	if err != nil {
+		return nil, err
+	}
+	s := &wsServer{
+		eventBus: eventBus,
+		config:   config,
+		logger:   logger,
+		metrics:  metrics,
+		conns:    make(map[string]*wsConnection),
+	}
+
+	s.routes = map[string]*rpcserver.RPCFunc{
+		"subscribe":       rpcserver.NewWSRPCFunc(s.subscribe, "query"),
+		"unsubscribe":     rpcserver.NewWSRPCFunc(s.unsubscribe, "query"),
+		"unsubscribe_all": rpcserver.NewWSRPCFunc(s.unsubscribeAll, ""),
+	}
+	return s, nil
+}
+
+// newManager returns the websocket manager serving the connection [id]. The
+// connection reports its ID as remote address, so that subscriptions are
+// namespaced per connection.
+func (s *wsServer) newManager(id string) *rpcserver.WebsocketManager {
+	manager := rpcserver.NewWebsocketManager(s.routes,
+		rpcserver.RemoteAddr(id),
+		rpcserver.WriteChanCapacity(s.config.WSWriteBufferSize),
+		rpcserver.PingPeriod(s.config.WSPingPeriod.Duration),
+		rpcserver.ReadWait(s.config.WSPongWait.Duration),
+		rpcserver.OnDisconnect(func(id string) {
+			err := s.eventBus.UnsubscribeAll(context.Background(), id)
+			if err != nil && err != tmpubsub.ErrSubscriptionNotFound {
+				s.logger.Error("Failed to unsubscribe connection from events", "id", id, "err", err)
+			}
+		}),
+	)
+	manager.SetLogger(s.logger)
+	return manager
+}
+
+func (s *wsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := fmt.Sprintf("%s#%d", r.RemoteAddr, atomic.AddUint64(&s.nextID, 1))
+	if err := s.addConnection(id, r.RemoteAddr); err != nil {
+		s.metrics.rejectedConnections.Inc()
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer s.removeConnection(id)
+
+	s.newManager(id).WebsocketHandler(w, r) // BLOCKING
+}
+
+func (s *wsServer) addConnection(id string, remoteAddr string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.config.MaxWSConnections > 0 && len(s.conns) >= s.config.MaxWSConnections {
+		return errMaxWSConnections
+	}
+	s.conns[id] = &wsConnection{remoteAddr: remoteAddr, connectedAt: time.Now()}
+	s.metrics.connections.Set(float64(len(s.conns)))
+	return nil
+}
+
+func (s *wsServer) removeConnection(id string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	delete(s.conns, id)
+	s.metrics.connections.Set(float64(len(s.conns)))
+}
+
+func (s *wsServer) connection(id string) *wsConnection {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	conn, ok := s.conns[id]
+	if !ok {
+		// keep counting for connections which are not tracked, the stats are
+		// just not reported
+		conn = &wsConnection{connectedAt: time.Now()}
+	}
+	return conn
+}
+
+// connections returns the open connections, sorted by ID.
+func (s *wsServer) connections() []WSConnectionInfo {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	infos := make([]WSConnectionInfo, 0, len(s.conns))
+	for id, conn := range s.conns {
+		infos = append(infos, WSConnectionInfo{
+			ID:            id,
+			RemoteAddr:    conn.remoteAddr,
+			ConnectedAt:   conn.connectedAt,
+			Subscriptions: s.eventBus.NumClientSubscriptions(id),
+			EventsSent:    atomic.LoadUint64(&conn.eventsSent),
+			EventsDropped: atomic.LoadUint64(&conn.eventsDropped),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+func (s *wsServer) subscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultSubscribe, error) {
+	addr := ctx.RemoteAddr()
+
+	if s.eventBus.NumClientSubscriptions(addr) >= s.config.MaxSubscriptionsPerClient {
+		return nil, fmt.Errorf("max_subscriptions_per_client %d reached", s.config.MaxSubscriptionsPerClient)
+	}
+
+	s.logger.Info("Subscribe to query", "remote", addr, "query", query)
+
+	q, err := tmquery.New(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query: %w", err)
+	}
+
+	subCtx, cancel := context.WithTimeout(ctx.Context(), core.SubscribeTimeout)
+	defer cancel()
+
+	sub, err := s.eventBus.Subscribe(subCtx, addr, q, subscriptionBufferSize)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := s.connection(addr)
+	// Capture the current ID, since it can change in the future.
+	subscriptionID := ctx.JSONReq.ID
+	go func() {
+		for {
+			select {
+			case msg := <-sub.Out():
+				resultEvent := &ctypes.ResultEvent{Query: query, Data: msg.Data(), Events: msg.Events()}
+				resp := rpctypes.NewRPCSuccessResponse(subscriptionID, resultEvent)
+				if ctx.WSConn.TryWriteRPCResponse(resp) {
+					atomic.AddUint64(&conn.eventsSent, 1)
+					s.metrics.eventsSent.Inc()
+				} else {
+					atomic.AddUint64(&conn.eventsDropped, 1)
+					s.metrics.eventsDropped.Inc()
+					s.logger.Info("Can't write response (slow client)",
+						"to", addr, "subscriptionID", subscriptionID)
+				}
+			case <-sub.Cancelled():
+				if sub.Err() != tmpubsub.ErrUnsubscribed {
+					reason := "Landslide exited"
+					if sub.Err() != nil {
+						reason = sub.Err().Error()
+					}
+					err := fmt.Errorf("subscription was cancelled (reason: %s)", reason)
+					if !ctx.WSConn.TryWriteRPCResponse(rpctypes.RPCServerError(subscriptionID, err)) {
+						s.logger.Info("Can't write response (slow client)",
+							"to", addr, "subscriptionID", subscriptionID, "err", err)
+					}
+				}
+				return
+			}
+		}
+	}()
+
+	return &ctypes.ResultSubscribe{}, nil
+}
+
+func (s *wsServer) unsubscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultUnsubscribe, error) {
+	addr := ctx.RemoteAddr()
+	s.logger.Info("Unsubscribe from query", "remote", addr, "query", query)
+	q, err := tmquery.New(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query: %w", err)
+	}
+	if err := s.eventBus.Unsubscribe(context.Background(), addr, q); err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultUnsubscribe{}, nil
+}
+
+func (s *wsServer) unsubscribeAll(ctx *rpctypes.Context) (*ctypes.ResultUnsubscribe, error) {
+	addr := ctx.RemoteAddr()
+	s.logger.Info("Unsubscribe from all", "remote", addr)
+	if err := s.eventBus.UnsubscribeAll(context.Background(), addr); err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultUnsubscribe{}, nil
+}
@@ -0,0 +1,92 @@
+package vm
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	rpctypes "github.com/consideritdone/landslidecore/rpc/jsonrpc/types"
+)
+
+func TestWSServerLimits(t *testing.T) {
+	vm, _, _ := mustNewCounterTestVm(t)
+	vm.config.MaxWSConnections = 1
+	vm.config.MaxSubscriptionsPerClient = 1
+	_, err := vm.CreateHandlers(context.Background())
+	require.NoError(t, err)
+	// handlers can be created more than once
+	server := newTestServer(t, vm, "/websocket")
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// the second connection is over the limit
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	subscribe := func(id int, query string) rpctypes.RPCResponse {
+		req, err := rpctypes.MapToRequest(rpctypes.JSONRPCIntID(id), "subscribe", map[string]interface{}{"query": query})
+		require.NoError(t, err)
+		require.NoError(t, conn.WriteJSON(req))
+		var resp rpctypes.RPCResponse
+		require.NoError(t, conn.ReadJSON(&resp))
+		return resp
+	}
+	assert.Nil(t, subscribe(1, "tm.event = 'NewBlock'").Error)
+	assert.NotNil(t, subscribe(2, "tm.event = 'Tx'").Error)
+
+	infos := NewAdminService(vm)
+	reply := new(WSConnectionsReply)
+	require.NoError(t, infos.WSConnections(nil, nil, reply))
+	require.Len(t, reply.Connections, 1)
+	assert.Equal(t, 1, reply.Connections[0].Subscriptions)
+}
+
+func TestWSServerUnixSocket(t *testing.T) {
+	vm, _, _ := mustNewCounterTestVm(t)
+	vm.config.RPCUnixSocket = filepath.Join(t.TempDir(), "rpc.sock")
+	vm.config.MaxWSConnections = 2
+	_, err := vm.CreateHandlers(context.Background())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, vm.closeUnixSocket(context.Background())) }()
+
+	dialer := &websocket.Dialer{
+		NetDialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", vm.config.RPCUnixSocket)
+		},
+	}
+	// all clients of the socket share a remote address, they must still be
+	// told apart
+	for i := 0; i < 2; i++ {
+		conn, _, err := dialer.Dial("ws://unix/websocket", nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		req, err := rpctypes.MapToRequest(rpctypes.JSONRPCIntID(1), "subscribe", map[string]interface{}{"query": "tm.event = 'NewBlock'"})
+		require.NoError(t, err)
+		require.NoError(t, conn.WriteJSON(req))
+		var resp rpctypes.RPCResponse
+		require.NoError(t, conn.ReadJSON(&resp))
+		require.Nil(t, resp.Error)
+	}
+	_, _, err = dialer.Dial("ws://unix/websocket", nil)
+	assert.Error(t, err)
+
+	reply := new(WSConnectionsReply)
+	require.NoError(t, NewAdminService(vm).WSConnections(nil, nil, reply))
+	require.Len(t, reply.Connections, 2)
+	for _, conn := range reply.Connections {
+		assert.Equal(t, 1, conn.Subscriptions)
+	}
+}